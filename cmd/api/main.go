@@ -1,14 +1,20 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/yookibooki/erp/internal/api"
 	"github.com/yookibooki/erp/internal/auth"
 	"github.com/yookibooki/erp/internal/config"
 	"github.com/yookibooki/erp/internal/db"
+	"github.com/yookibooki/erp/internal/notify"
 )
 
 func main() {
@@ -26,14 +32,54 @@ func main() {
 	tenantRepo := db.NewTenantRepository(database)
 	userRepo := db.NewUserRepository(database)
 	
+	// Create notifiers and start checking stock movements for low stock
+	lowStockNotifier := notify.NewWebhookNotifier(cfg.Notification)
+	lowStockMonitor := db.NewLowStockMonitor(database, lowStockNotifier)
+	go lowStockMonitor.Run()
+
 	// Create module repositories
 	accountRepo := db.NewAccountRepository(database)
 	journalEntryRepo := db.NewJournalEntryRepository(database)
+	postingRuleRepo := db.NewPostingRuleRepository(database)
 	productRepo := db.NewProductRepository(database)
-	inventoryTransactionRepo := db.NewInventoryTransactionRepository(database)
+	inventoryTransactionRepo := db.NewInventoryTransactionRepository(database, lowStockMonitor)
+	locationRepo := db.NewLocationRepository(database)
+	unitOfMeasureRepo := db.NewUnitOfMeasureRepository(database)
+	productUnitRepo := db.NewProductUnitRepository(database)
+	productTemplateRepo := db.NewProductTemplateRepository(database)
+	productCategoryRepo := db.NewProductCategoryRepository(database)
+	barcodeRepo := db.NewBarcodeRepository(database)
+	lotRepo := db.NewLotRepository(database)
+	valuationRepo := db.NewStockValuationRepository(database)
+	stockLedgerRepo := db.NewStockLedgerRepository(database)
+	reservationRepo := db.NewReservationRepository(database)
+	scheduledReceiptRepo := db.NewScheduledReceiptRepository(database)
+	reorderRuleRepo := db.NewReorderRuleRepository(database)
+	replenishmentRepo := db.NewReplenishmentRepository(database)
+	stocktakeRepo := db.NewStocktakeRepository(database, inventoryTransactionRepo)
+	supplierRepo := db.NewSupplierRepository(database)
+	productSupplierRepo := db.NewProductSupplierRepository(database)
+	purchaseOrderRepo := db.NewPurchaseOrderRepository(database)
+	goodsReceiptRepo := db.NewGoodsReceiptRepository(database, inventoryTransactionRepo)
+	landedCostRepo := db.NewLandedCostRepository(database)
+	salesOrderRepo := db.NewSalesOrderRepository(database)
+	shipmentRepo := db.NewShipmentRepository(database, inventoryTransactionRepo)
+	returnRepo := db.NewReturnAuthorizationRepository(database, inventoryTransactionRepo)
+	priceListRepo := db.NewPriceListRepository(database)
+	bomRepo := db.NewBillOfMaterialsRepository(database)
+	workOrderRepo := db.NewWorkOrderRepository(database, inventoryTransactionRepo)
 	customerRepo := db.NewCustomerRepository(database)
 	contactRepo := db.NewContactRepository(database)
 	interactionRepo := db.NewInteractionRepository(database)
+	customerGroupRepo := db.NewCustomerGroupRepository(database)
+	leadRepo := db.NewLeadRepository(database)
+	pipelineStageRepo := db.NewPipelineStageRepository(database)
+	opportunityRepo := db.NewOpportunityRepository(database)
+	taskRepo := db.NewTaskRepository(database)
+	duplicateRepo := db.NewDuplicateRepository(database)
+	searchRepo := db.NewSearchRepository(database)
+	customFieldRepo := db.NewCustomFieldRepository(database)
+	segmentRepo := db.NewSegmentRepository(database)
 
 	// Create JWT service
 	jwtService := auth.NewJWTService(cfg.JWT)
@@ -44,16 +90,70 @@ func main() {
 		userRepo,
 		accountRepo,
 		journalEntryRepo,
+		postingRuleRepo,
 		productRepo,
 		inventoryTransactionRepo,
+		locationRepo,
+		unitOfMeasureRepo,
+		productUnitRepo,
+		productTemplateRepo,
+		productCategoryRepo,
+		barcodeRepo,
+		lotRepo,
+		valuationRepo,
+		stockLedgerRepo,
+		reservationRepo,
+		scheduledReceiptRepo,
+		reorderRuleRepo,
+		replenishmentRepo,
+		stocktakeRepo,
+		supplierRepo,
+		productSupplierRepo,
+		purchaseOrderRepo,
+		goodsReceiptRepo,
+		landedCostRepo,
+		salesOrderRepo,
+		shipmentRepo,
+		returnRepo,
+		priceListRepo,
+		bomRepo,
+		workOrderRepo,
 		customerRepo,
 		contactRepo,
 		interactionRepo,
+		customerGroupRepo,
+		leadRepo,
+		pipelineStageRepo,
+		opportunityRepo,
+		taskRepo,
+		duplicateRepo,
+		searchRepo,
+		customFieldRepo,
+		segmentRepo,
 		jwtService,
 	)
 
 	// Start server
 	addr := fmt.Sprintf("0.0.0.0:%s", cfg.Server.Port)
-	log.Printf("Server starting on %s", addr)
-	log.Fatal(http.ListenAndServe(addr, router))
+	server := &http.Server{Addr: addr, Handler: router}
+	go func() {
+		log.Printf("Server starting on %s", addr)
+		if err := server.ListenAndServe(); err != http.ErrServerClosed {
+			log.Fatal(err)
+		}
+	}()
+
+	// Shut down on interrupt, finishing the requests in flight and the low stock
+	// checks they queued
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	<-stop
+
+	log.Printf("Server shutting down")
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("Error shutting down server: %v", err)
+	}
+	lowStockMonitor.Close()
 }
\ No newline at end of file
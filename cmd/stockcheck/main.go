@@ -0,0 +1,94 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/yookibooki/erp/internal/config"
+	"github.com/yookibooki/erp/internal/db"
+	"github.com/yookibooki/erp/internal/models"
+)
+
+// stockcheck compares the stored stock of products, stock levels and lots with the
+// inventory transaction ledger and reports the drift. It exits with status 1 when
+// any tenant has drift.
+func main() {
+	tenantID := flag.String("tenant", "", "ID of the tenant to check (all tenants by default)")
+	flag.Parse()
+
+	// Load configuration
+	cfg := config.LoadConfig()
+
+	// Connect to database
+	database, err := db.New(cfg.Database)
+	if err != nil {
+		log.Fatalf("Error connecting to database: %v", err)
+	}
+	defer database.Close()
+
+	tenantRepo := db.NewTenantRepository(database)
+	stockLedgerRepo := db.NewStockLedgerRepository(database)
+
+	var tenants []*models.Tenant
+	if *tenantID != "" {
+		tenant, err := tenantRepo.GetByID(*tenantID)
+		if err != nil {
+			log.Fatalf("Error getting tenant: %v", err)
+		}
+		if tenant == nil {
+			log.Fatalf("Tenant not found: %s", *tenantID)
+		}
+		tenants = append(tenants, tenant)
+	} else {
+		tenants, err = tenantRepo.List()
+		if err != nil {
+			log.Fatalf("Error listing tenants: %v", err)
+		}
+	}
+
+	drifted := false
+	for _, tenant := range tenants {
+		report, err := stockLedgerRepo.CheckConsistency(tenant.ID)
+		if err != nil {
+			log.Fatalf("Error checking stock consistency of tenant %s: %v", tenant.Subdomain, err)
+		}
+
+		if report.Consistent {
+			fmt.Printf("%s: stock is consistent with the ledger\n", tenant.Subdomain)
+			continue
+		}
+
+		drifted = true
+		fmt.Printf("%s: %d stock quantities differ from the ledger\n", tenant.Subdomain, len(report.Drifts))
+		printDrifts(report.Drifts)
+	}
+
+	if drifted {
+		os.Exit(1)
+	}
+}
+
+// printDrifts prints drifts as an aligned table
+func printDrifts(drifts []*models.StockDrift) {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "KIND\tPRODUCT\tLOCATION\tLOT\tSERIAL\tSTORED\tLEDGER\tDIFFERENCE")
+	for _, drift := range drifts {
+		fmt.Fprintf(
+			writer,
+			"%s\t%s\t%s\t%s\t%s\t%g\t%g\t%g\n",
+			drift.Kind,
+			drift.ProductCode,
+			drift.LocationCode,
+			drift.LotNumber,
+			drift.SerialNumber,
+			drift.StoredQuantity,
+			drift.LedgerQuantity,
+			drift.Difference,
+		)
+	}
+	writer.Flush()
+	fmt.Println()
+}
\ No newline at end of file
@@ -213,11 +213,10 @@ func createAccount(token string) Account {
 func createProduct(token string) Product {
 	url := fmt.Sprintf("%s/inventory/products", baseURL)
 	payload := map[string]interface{}{
-		"code":           "P001",
-		"name":           "Test Product",
-		"description":    "A test product",
-		"unit_price":     19.99,
-		"stock_quantity": 100,
+		"code":        "P001",
+		"name":        "Test Product",
+		"description": "A test product",
+		"unit_price":  19.99,
 	}
 
 	jsonPayload, _ := json.Marshal(payload)
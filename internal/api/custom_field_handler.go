@@ -0,0 +1,182 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/yookibooki/erp/internal/auth"
+	"github.com/yookibooki/erp/internal/customfield"
+	"github.com/yookibooki/erp/internal/models"
+)
+
+// CustomFieldHandler handles custom field definition requests
+type CustomFieldHandler struct {
+	customFieldService models.CustomFieldService
+}
+
+// NewCustomFieldHandler creates a new custom field handler
+func NewCustomFieldHandler(customFieldService models.CustomFieldService) *CustomFieldHandler {
+	return &CustomFieldHandler{
+		customFieldService: customFieldService,
+	}
+}
+
+// GetCustomField gets a custom field definition by ID
+func (h *CustomFieldHandler) GetCustomField(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	definition := h.findCustomField(w, tenantID, id)
+	if definition == nil {
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, definition)
+}
+
+// ListCustomFields lists the custom field definitions of a tenant, optionally only those
+// of the entity_type given as a query parameter
+func (h *CustomFieldHandler) ListCustomFields(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	definitions, err := h.customFieldService.List(tenantID, r.URL.Query().Get("entity_type"))
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error listing custom fields")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, definitions)
+}
+
+// CreateCustomField creates a new custom field definition
+func (h *CustomFieldHandler) CreateCustomField(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	var definition models.CustomFieldDefinition
+	if err := json.NewDecoder(r.Body).Decode(&definition); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	// Set tenant ID from context
+	definition.TenantID = tenantID
+
+	if !validateCustomField(w, &definition) {
+		return
+	}
+
+	// Check if the key is already used by a field of the same record type
+	existingDefinition, err := h.customFieldService.GetByKey(tenantID, definition.EntityType, definition.Key)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking custom field")
+		return
+	}
+
+	if existingDefinition != nil {
+		auth.RespondWithError(w, http.StatusConflict, "Custom field with this key already exists")
+		return
+	}
+
+	// Create custom field
+	if err := h.customFieldService.Create(&definition); err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error creating custom field")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusCreated, definition)
+}
+
+// UpdateCustomField updates a custom field definition. Its entity type and key are kept.
+func (h *CustomFieldHandler) UpdateCustomField(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	var definition models.CustomFieldDefinition
+	if err := json.NewDecoder(r.Body).Decode(&definition); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	existingDefinition := h.findCustomField(w, tenantID, id)
+	if existingDefinition == nil {
+		return
+	}
+
+	// Set ID and tenant ID, and keep the values stored under the key
+	definition.ID = id
+	definition.TenantID = tenantID
+	definition.EntityType = existingDefinition.EntityType
+	definition.Key = existingDefinition.Key
+	definition.CreatedAt = existingDefinition.CreatedAt
+
+	if !validateCustomField(w, &definition) {
+		return
+	}
+
+	// Update custom field
+	if err := h.customFieldService.Update(&definition); err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error updating custom field")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, definition)
+}
+
+// DeleteCustomField deletes a custom field definition and its values
+func (h *CustomFieldHandler) DeleteCustomField(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	if h.findCustomField(w, tenantID, id) == nil {
+		return
+	}
+
+	// Delete custom field
+	if err := h.customFieldService.Delete(tenantID, id); err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error deleting custom field")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Custom field deleted successfully"})
+}
+
+// findCustomField gets a custom field definition and responds with an error if it cannot be found
+func (h *CustomFieldHandler) findCustomField(w http.ResponseWriter, tenantID, id string) *models.CustomFieldDefinition {
+	definition, err := h.customFieldService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error getting custom field")
+		return nil
+	}
+
+	if definition == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Custom field not found")
+		return nil
+	}
+
+	return definition
+}
+
+// validateCustomField validates a custom field definition and responds with an error if
+// it is invalid
+func validateCustomField(w http.ResponseWriter, definition *models.CustomFieldDefinition) bool {
+	if definition.Label == "" {
+		auth.RespondWithError(w, http.StatusBadRequest, "Label is required")
+		return false
+	}
+
+	if definition.MaxLength < 0 {
+		auth.RespondWithError(w, http.StatusBadRequest, "Maximum length must not be negative")
+		return false
+	}
+
+	if err := customfield.ValidateDefinition(definition); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return false
+	}
+
+	return true
+}
\ No newline at end of file
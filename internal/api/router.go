@@ -9,6 +9,9 @@ import (
 	"github.com/yookibooki/erp/internal/modules/accounting"
 	"github.com/yookibooki/erp/internal/modules/crm"
 	"github.com/yookibooki/erp/internal/modules/inventory"
+	"github.com/yookibooki/erp/internal/modules/manufacturing"
+	"github.com/yookibooki/erp/internal/modules/purchasing"
+	"github.com/yookibooki/erp/internal/modules/sales"
 )
 
 // Router is the HTTP router
@@ -23,11 +26,46 @@ func NewRouter(
 	userService models.UserService,
 	accountService models.AccountService,
 	journalEntryService models.JournalEntryService,
+	postingRuleService models.PostingRuleService,
 	productService models.ProductService,
 	inventoryTransactionService models.InventoryTransactionService,
+	locationService models.LocationService,
+	unitOfMeasureService models.UnitOfMeasureService,
+	productUnitService models.ProductUnitService,
+	productTemplateService models.ProductTemplateService,
+	productCategoryService models.ProductCategoryService,
+	barcodeService models.BarcodeService,
+	lotService models.LotService,
+	valuationService models.StockValuationService,
+	stockLedgerService models.StockLedgerService,
+	reservationService models.ReservationService,
+	scheduledReceiptService models.ScheduledReceiptService,
+	reorderRuleService models.ReorderRuleService,
+	replenishmentService models.ReplenishmentService,
+	stocktakeService models.StocktakeService,
+	supplierService models.SupplierService,
+	productSupplierService models.ProductSupplierService,
+	purchaseOrderService models.PurchaseOrderService,
+	goodsReceiptService models.GoodsReceiptService,
+	landedCostService models.LandedCostService,
+	salesOrderService models.SalesOrderService,
+	shipmentService models.ShipmentService,
+	returnService models.ReturnAuthorizationService,
+	priceListService models.PriceListService,
+	bomService models.BillOfMaterialsService,
+	workOrderService models.WorkOrderService,
 	customerService models.CustomerService,
 	contactService models.ContactService,
 	interactionService models.InteractionService,
+	customerGroupService models.CustomerGroupService,
+	leadService models.LeadService,
+	pipelineStageService models.PipelineStageService,
+	opportunityService models.OpportunityService,
+	taskService models.TaskService,
+	duplicateService models.DuplicateService,
+	searchService models.SearchService,
+	customFieldService models.CustomFieldService,
+	segmentService models.SegmentService,
 	jwtService *auth.JWTService,
 ) *Router {
 	r := mux.NewRouter()
@@ -37,15 +75,86 @@ func NewRouter(
 	authHandler := NewAuthHandler(userService, jwtService)
 	tenantHandler := NewTenantHandler(tenantService)
 	userHandler := NewUserHandler(userService)
+	customFieldHandler := NewCustomFieldHandler(customFieldService)
 
 	// Create module handlers
 	accountHandler := accounting.NewAccountHandler(accountService)
 	journalEntryHandler := accounting.NewJournalEntryHandler(journalEntryService)
-	productHandler := inventory.NewProductHandler(productService)
-	inventoryTransactionHandler := inventory.NewInventoryTransactionHandler(inventoryTransactionService, productService)
-	customerHandler := crm.NewCustomerHandler(customerService, contactService)
-	contactHandler := crm.NewContactHandler(contactService, customerService)
-	interactionHandler := crm.NewInteractionHandler(interactionService, customerService)
+	postingRuleHandler := accounting.NewPostingRuleHandler(postingRuleService, accountService, productCategoryService)
+	productHandler := inventory.NewProductHandler(
+		productService,
+		unitOfMeasureService,
+		productCategoryService,
+		accountService,
+		customFieldService,
+	)
+	inventoryTransactionHandler := inventory.NewInventoryTransactionHandler(
+		inventoryTransactionService,
+		productService,
+		locationService,
+	)
+	locationHandler := inventory.NewLocationHandler(locationService)
+	unitOfMeasureHandler := inventory.NewUnitOfMeasureHandler(unitOfMeasureService, productUnitService, productService)
+	productTemplateHandler := inventory.NewProductTemplateHandler(productTemplateService, unitOfMeasureService)
+	productCategoryHandler := inventory.NewProductCategoryHandler(productCategoryService, accountService, unitOfMeasureService)
+	barcodeHandler := inventory.NewBarcodeHandler(barcodeService, productService, productUnitService, locationService)
+	lotHandler := inventory.NewLotHandler(lotService, productService)
+	valuationHandler := inventory.NewValuationHandler(valuationService)
+	stockLedgerHandler := inventory.NewStockLedgerHandler(stockLedgerService, locationService)
+	reservationHandler := inventory.NewReservationHandler(reservationService, scheduledReceiptService, productService, locationService)
+	replenishmentHandler := inventory.NewReplenishmentHandler(reorderRuleService, replenishmentService, productService, locationService)
+	stocktakeHandler := inventory.NewStocktakeHandler(stocktakeService, productService, locationService)
+	supplierHandler := purchasing.NewSupplierHandler(supplierService)
+	productSupplierHandler := purchasing.NewProductSupplierHandler(
+		productSupplierService,
+		supplierService,
+		productService,
+		productUnitService,
+	)
+	purchaseOrderHandler := purchasing.NewPurchaseOrderHandler(
+		purchaseOrderService,
+		goodsReceiptService,
+		supplierService,
+		productService,
+		locationService,
+	)
+	landedCostHandler := purchasing.NewLandedCostHandler(landedCostService, accountService)
+	salesOrderHandler := sales.NewSalesOrderHandler(
+		salesOrderService,
+		shipmentService,
+		customerService,
+		productService,
+		locationService,
+		productUnitService,
+		priceListService,
+	)
+	returnHandler := sales.NewReturnHandler(
+		returnService,
+		customerService,
+		productService,
+		locationService,
+		accountService,
+	)
+	priceListHandler := sales.NewPriceListHandler(priceListService, customerService, customerGroupService, productService)
+	bomHandler := manufacturing.NewBOMHandler(bomService, productService)
+	workOrderHandler := manufacturing.NewWorkOrderHandler(workOrderService, productService, locationService)
+	customerHandler := crm.NewCustomerHandler(customerService, contactService, customerGroupService, customFieldService)
+	customerGroupHandler := crm.NewCustomerGroupHandler(customerGroupService)
+	contactHandler := crm.NewContactHandler(contactService, customerService, customFieldService)
+	interactionHandler := crm.NewInteractionHandler(interactionService, customerService, customFieldService)
+	pipelineStageHandler := crm.NewPipelineStageHandler(pipelineStageService)
+	leadHandler := crm.NewLeadHandler(leadService, customerService, userService, pipelineStageService)
+	opportunityHandler := crm.NewOpportunityHandler(
+		opportunityService,
+		pipelineStageService,
+		customerService,
+		contactService,
+		userService,
+	)
+	taskHandler := crm.NewTaskHandler(taskService, customerService, contactService, opportunityService, userService)
+	duplicateHandler := crm.NewDuplicateHandler(duplicateService, customerService, contactService)
+	searchHandler := crm.NewSearchHandler(searchService)
+	segmentHandler := crm.NewSegmentHandler(segmentService, customFieldService)
 
 	// Public routes
 	r.HandleFunc("/api/auth/login", authHandler.Login).Methods("POST")
@@ -72,6 +181,13 @@ func NewRouter(
 	tenantRouter.HandleFunc("/users/{id}", userHandler.UpdateUser).Methods("PUT")
 	tenantRouter.HandleFunc("/users/{id}", userHandler.DeleteUser).Methods("DELETE")
 
+	// Custom field routes
+	tenantRouter.HandleFunc("/custom-fields", customFieldHandler.ListCustomFields).Methods("GET")
+	tenantRouter.HandleFunc("/custom-fields", customFieldHandler.CreateCustomField).Methods("POST")
+	tenantRouter.HandleFunc("/custom-fields/{id}", customFieldHandler.GetCustomField).Methods("GET")
+	tenantRouter.HandleFunc("/custom-fields/{id}", customFieldHandler.UpdateCustomField).Methods("PUT")
+	tenantRouter.HandleFunc("/custom-fields/{id}", customFieldHandler.DeleteCustomField).Methods("DELETE")
+
 	// Accounting routes
 	tenantRouter.HandleFunc("/accounting/accounts", accountHandler.ListAccounts).Methods("GET")
 	tenantRouter.HandleFunc("/accounting/accounts", accountHandler.CreateAccount).Methods("POST")
@@ -85,6 +201,12 @@ func NewRouter(
 	tenantRouter.HandleFunc("/accounting/journal-entries/{id}", journalEntryHandler.UpdateJournalEntry).Methods("PUT")
 	tenantRouter.HandleFunc("/accounting/journal-entries/{id}", journalEntryHandler.DeleteJournalEntry).Methods("DELETE")
 
+	tenantRouter.HandleFunc("/accounting/posting-rules", postingRuleHandler.ListPostingRules).Methods("GET")
+	tenantRouter.HandleFunc("/accounting/posting-rules", postingRuleHandler.CreatePostingRule).Methods("POST")
+	tenantRouter.HandleFunc("/accounting/posting-rules/{id}", postingRuleHandler.GetPostingRule).Methods("GET")
+	tenantRouter.HandleFunc("/accounting/posting-rules/{id}", postingRuleHandler.UpdatePostingRule).Methods("PUT")
+	tenantRouter.HandleFunc("/accounting/posting-rules/{id}", postingRuleHandler.DeletePostingRule).Methods("DELETE")
+
 	// Inventory routes
 	tenantRouter.HandleFunc("/inventory/products", productHandler.ListProducts).Methods("GET")
 	tenantRouter.HandleFunc("/inventory/products", productHandler.CreateProduct).Methods("POST")
@@ -97,6 +219,168 @@ func NewRouter(
 	tenantRouter.HandleFunc("/inventory/transactions/{id}", inventoryTransactionHandler.GetTransaction).Methods("GET")
 	tenantRouter.HandleFunc("/inventory/transactions/product/{productId}", inventoryTransactionHandler.ListTransactionsByProduct).Methods("GET")
 
+	tenantRouter.HandleFunc("/inventory/locations", locationHandler.ListLocations).Methods("GET")
+	tenantRouter.HandleFunc("/inventory/locations", locationHandler.CreateLocation).Methods("POST")
+	tenantRouter.HandleFunc("/inventory/locations/{id}", locationHandler.GetLocation).Methods("GET")
+	tenantRouter.HandleFunc("/inventory/locations/{id}", locationHandler.UpdateLocation).Methods("PUT")
+	tenantRouter.HandleFunc("/inventory/locations/{id}", locationHandler.DeleteLocation).Methods("DELETE")
+
+	// Unit of measure routes
+	tenantRouter.HandleFunc("/inventory/units", unitOfMeasureHandler.ListUnits).Methods("GET")
+	tenantRouter.HandleFunc("/inventory/units", unitOfMeasureHandler.CreateUnit).Methods("POST")
+	tenantRouter.HandleFunc("/inventory/units/{id}", unitOfMeasureHandler.GetUnit).Methods("GET")
+	tenantRouter.HandleFunc("/inventory/units/{id}", unitOfMeasureHandler.UpdateUnit).Methods("PUT")
+	tenantRouter.HandleFunc("/inventory/units/{id}", unitOfMeasureHandler.DeleteUnit).Methods("DELETE")
+	tenantRouter.HandleFunc("/inventory/products/{id}/units", unitOfMeasureHandler.ListProductUnits).Methods("GET")
+	tenantRouter.HandleFunc("/inventory/products/{id}/units", unitOfMeasureHandler.CreateProductUnit).Methods("POST")
+	tenantRouter.HandleFunc("/inventory/product-units/{id}", unitOfMeasureHandler.UpdateProductUnit).Methods("PUT")
+	tenantRouter.HandleFunc("/inventory/product-units/{id}", unitOfMeasureHandler.DeleteProductUnit).Methods("DELETE")
+
+	// Product template and variant routes
+	tenantRouter.HandleFunc("/inventory/product-templates", productTemplateHandler.ListTemplates).Methods("GET")
+	tenantRouter.HandleFunc("/inventory/product-templates", productTemplateHandler.CreateTemplate).Methods("POST")
+	tenantRouter.HandleFunc("/inventory/product-templates/{id}", productTemplateHandler.GetTemplate).Methods("GET")
+	tenantRouter.HandleFunc("/inventory/product-templates/{id}", productTemplateHandler.UpdateTemplate).Methods("PUT")
+	tenantRouter.HandleFunc("/inventory/product-templates/{id}", productTemplateHandler.DeleteTemplate).Methods("DELETE")
+	tenantRouter.HandleFunc("/inventory/product-templates/{id}/variants", productTemplateHandler.ListVariants).Methods("GET")
+	tenantRouter.HandleFunc("/inventory/product-templates/{id}/variants", productTemplateHandler.CreateVariant).Methods("POST")
+	tenantRouter.HandleFunc("/inventory/product-templates/{id}/variants/generate", productTemplateHandler.GenerateVariants).Methods("POST")
+
+	// Product category routes
+	tenantRouter.HandleFunc("/inventory/categories", productCategoryHandler.ListCategories).Methods("GET")
+	tenantRouter.HandleFunc("/inventory/categories", productCategoryHandler.CreateCategory).Methods("POST")
+	tenantRouter.HandleFunc("/inventory/categories/{id}", productCategoryHandler.GetCategory).Methods("GET")
+	tenantRouter.HandleFunc("/inventory/categories/{id}", productCategoryHandler.UpdateCategory).Methods("PUT")
+	tenantRouter.HandleFunc("/inventory/categories/{id}", productCategoryHandler.DeleteCategory).Methods("DELETE")
+
+	// Barcode routes
+	tenantRouter.HandleFunc("/inventory/products/{id}/barcodes", barcodeHandler.ListProductBarcodes).Methods("GET")
+	tenantRouter.HandleFunc("/inventory/products/{id}/barcodes", barcodeHandler.CreateProductBarcode).Methods("POST")
+	tenantRouter.HandleFunc("/inventory/barcodes", barcodeHandler.LookupBarcode).Methods("GET")
+	tenantRouter.HandleFunc("/inventory/barcodes/{id}", barcodeHandler.GetBarcode).Methods("GET")
+	tenantRouter.HandleFunc("/inventory/barcodes/{id}", barcodeHandler.UpdateBarcode).Methods("PUT")
+	tenantRouter.HandleFunc("/inventory/barcodes/{id}", barcodeHandler.DeleteBarcode).Methods("DELETE")
+	tenantRouter.HandleFunc("/inventory/barcodes/{id}/label", barcodeHandler.GetBarcodeLabel).Methods("GET")
+	tenantRouter.HandleFunc("/inventory/scan", barcodeHandler.Scan).Methods("POST")
+
+	// Product supplier routes
+	tenantRouter.HandleFunc("/inventory/products/{id}/suppliers", productSupplierHandler.ListProductSuppliers).Methods("GET")
+	tenantRouter.HandleFunc("/inventory/products/{id}/suppliers", productSupplierHandler.CreateProductSupplier).Methods("POST")
+	tenantRouter.HandleFunc("/inventory/products/{id}/suppliers/ranking", productSupplierHandler.RankProductSuppliers).Methods("GET")
+	tenantRouter.HandleFunc("/inventory/product-suppliers/{id}", productSupplierHandler.UpdateProductSupplier).Methods("PUT")
+	tenantRouter.HandleFunc("/inventory/product-suppliers/{id}", productSupplierHandler.DeleteProductSupplier).Methods("DELETE")
+
+	tenantRouter.HandleFunc("/inventory/products/{id}/stock-levels", locationHandler.ListStockByProduct).Methods("GET")
+
+	tenantRouter.HandleFunc("/inventory/products/{id}/lots", lotHandler.ListLotsByProduct).Methods("GET")
+	tenantRouter.HandleFunc("/inventory/products/{id}/lots/fefo", lotHandler.SuggestFEFO).Methods("GET")
+	tenantRouter.HandleFunc("/inventory/trace", lotHandler.TraceLot).Methods("GET")
+
+	tenantRouter.HandleFunc("/inventory/valuation", valuationHandler.GetValuation).Methods("GET")
+	tenantRouter.HandleFunc("/inventory/stock", stockLedgerHandler.GetStockOnHand).Methods("GET")
+	tenantRouter.HandleFunc("/inventory/stock/consistency", stockLedgerHandler.CheckConsistency).Methods("GET")
+	tenantRouter.HandleFunc("/inventory/stock/categories", productCategoryHandler.GetStockByCategory).Methods("GET")
+
+	tenantRouter.HandleFunc("/inventory/reservations", reservationHandler.CreateReservation).Methods("POST")
+	tenantRouter.HandleFunc("/inventory/reservations/{id}", reservationHandler.GetReservation).Methods("GET")
+	tenantRouter.HandleFunc("/inventory/reservations/{id}/release", reservationHandler.ReleaseReservation).Methods("POST")
+	tenantRouter.HandleFunc("/inventory/reservations/{id}/fulfill", reservationHandler.FulfillReservation).Methods("POST")
+	tenantRouter.HandleFunc("/inventory/products/{id}/reservations", reservationHandler.ListReservationsByProduct).Methods("GET")
+	tenantRouter.HandleFunc("/inventory/scheduled-receipts", reservationHandler.CreateScheduledReceipt).Methods("POST")
+	tenantRouter.HandleFunc("/inventory/scheduled-receipts/{id}", reservationHandler.DeleteScheduledReceipt).Methods("DELETE")
+	tenantRouter.HandleFunc("/inventory/products/{id}/scheduled-receipts", reservationHandler.ListScheduledReceiptsByProduct).Methods("GET")
+	tenantRouter.HandleFunc("/inventory/products/{id}/availability", reservationHandler.GetAvailability).Methods("GET")
+
+	tenantRouter.HandleFunc("/inventory/reorder-rules", replenishmentHandler.ListReorderRules).Methods("GET")
+	tenantRouter.HandleFunc("/inventory/reorder-rules", replenishmentHandler.CreateReorderRule).Methods("POST")
+	tenantRouter.HandleFunc("/inventory/reorder-rules/{id}", replenishmentHandler.GetReorderRule).Methods("GET")
+	tenantRouter.HandleFunc("/inventory/reorder-rules/{id}", replenishmentHandler.UpdateReorderRule).Methods("PUT")
+	tenantRouter.HandleFunc("/inventory/reorder-rules/{id}", replenishmentHandler.DeleteReorderRule).Methods("DELETE")
+	tenantRouter.HandleFunc("/inventory/replenishment", replenishmentHandler.GetReplenishmentReport).Methods("GET")
+
+	tenantRouter.HandleFunc("/inventory/stocktakes", stocktakeHandler.ListStocktakes).Methods("GET")
+	tenantRouter.HandleFunc("/inventory/stocktakes", stocktakeHandler.CreateStocktake).Methods("POST")
+	tenantRouter.HandleFunc("/inventory/stocktakes/{id}", stocktakeHandler.GetStocktake).Methods("GET")
+	tenantRouter.HandleFunc("/inventory/stocktakes/{id}/variances", stocktakeHandler.GetVariances).Methods("GET")
+	tenantRouter.HandleFunc("/inventory/stocktakes/{id}/counts", stocktakeHandler.RecordCounts).Methods("POST")
+	tenantRouter.HandleFunc("/inventory/stocktakes/{id}/lines/{lineId}", stocktakeHandler.CountLine).Methods("PUT")
+	tenantRouter.HandleFunc("/inventory/stocktakes/{id}/approve", stocktakeHandler.ApproveStocktake).Methods("POST")
+	tenantRouter.HandleFunc("/inventory/stocktakes/{id}/cancel", stocktakeHandler.CancelStocktake).Methods("POST")
+	tenantRouter.HandleFunc("/inventory/cycle-counts", stocktakeHandler.GetCycleCountSchedule).Methods("GET")
+	tenantRouter.HandleFunc("/inventory/cycle-counts", stocktakeHandler.CreateCycleCount).Methods("POST")
+
+	// Purchasing routes
+	tenantRouter.HandleFunc("/purchasing/suppliers", supplierHandler.ListSuppliers).Methods("GET")
+	tenantRouter.HandleFunc("/purchasing/suppliers", supplierHandler.CreateSupplier).Methods("POST")
+	tenantRouter.HandleFunc("/purchasing/suppliers/{id}", supplierHandler.GetSupplier).Methods("GET")
+	tenantRouter.HandleFunc("/purchasing/suppliers/{id}", supplierHandler.UpdateSupplier).Methods("PUT")
+	tenantRouter.HandleFunc("/purchasing/suppliers/{id}", supplierHandler.DeleteSupplier).Methods("DELETE")
+	tenantRouter.HandleFunc("/purchasing/suppliers/{id}/products", productSupplierHandler.ListSupplierProducts).Methods("GET")
+
+	tenantRouter.HandleFunc("/purchasing/orders", purchaseOrderHandler.ListPurchaseOrders).Methods("GET")
+	tenantRouter.HandleFunc("/purchasing/orders", purchaseOrderHandler.CreatePurchaseOrder).Methods("POST")
+	tenantRouter.HandleFunc("/purchasing/orders/{id}", purchaseOrderHandler.GetPurchaseOrder).Methods("GET")
+	tenantRouter.HandleFunc("/purchasing/orders/{id}", purchaseOrderHandler.UpdatePurchaseOrder).Methods("PUT")
+	tenantRouter.HandleFunc("/purchasing/orders/{id}", purchaseOrderHandler.DeletePurchaseOrder).Methods("DELETE")
+	tenantRouter.HandleFunc("/purchasing/orders/{id}/approve", purchaseOrderHandler.ApprovePurchaseOrder).Methods("POST")
+	tenantRouter.HandleFunc("/purchasing/orders/{id}/send", purchaseOrderHandler.SendPurchaseOrder).Methods("POST")
+	tenantRouter.HandleFunc("/purchasing/orders/{id}/cancel", purchaseOrderHandler.CancelPurchaseOrder).Methods("POST")
+	tenantRouter.HandleFunc("/purchasing/orders/{id}/receipts", purchaseOrderHandler.ListGoodsReceipts).Methods("GET")
+	tenantRouter.HandleFunc("/purchasing/orders/{id}/receipts", purchaseOrderHandler.CreateGoodsReceipt).Methods("POST")
+	tenantRouter.HandleFunc("/purchasing/receipts/{id}", purchaseOrderHandler.GetGoodsReceipt).Methods("GET")
+
+	tenantRouter.HandleFunc("/purchasing/landed-costs", landedCostHandler.ListLandedCosts).Methods("GET")
+	tenantRouter.HandleFunc("/purchasing/landed-costs", landedCostHandler.CreateLandedCost).Methods("POST")
+	tenantRouter.HandleFunc("/purchasing/landed-costs/{id}", landedCostHandler.GetLandedCost).Methods("GET")
+	tenantRouter.HandleFunc("/purchasing/landed-costs/{id}/post", landedCostHandler.PostLandedCost).Methods("POST")
+	tenantRouter.HandleFunc("/purchasing/landed-costs/{id}/cancel", landedCostHandler.CancelLandedCost).Methods("POST")
+
+	// Sales routes
+	tenantRouter.HandleFunc("/sales/orders", salesOrderHandler.ListSalesOrders).Methods("GET")
+	tenantRouter.HandleFunc("/sales/orders", salesOrderHandler.CreateSalesOrder).Methods("POST")
+	tenantRouter.HandleFunc("/sales/orders/{id}", salesOrderHandler.GetSalesOrder).Methods("GET")
+	tenantRouter.HandleFunc("/sales/orders/{id}", salesOrderHandler.UpdateSalesOrder).Methods("PUT")
+	tenantRouter.HandleFunc("/sales/orders/{id}", salesOrderHandler.DeleteSalesOrder).Methods("DELETE")
+	tenantRouter.HandleFunc("/sales/orders/{id}/confirm", salesOrderHandler.ConfirmSalesOrder).Methods("POST")
+	tenantRouter.HandleFunc("/sales/orders/{id}/allocate", salesOrderHandler.AllocateSalesOrder).Methods("POST")
+	tenantRouter.HandleFunc("/sales/orders/{id}/pick", salesOrderHandler.PickSalesOrder).Methods("POST")
+	tenantRouter.HandleFunc("/sales/orders/{id}/invoice", salesOrderHandler.InvoiceSalesOrder).Methods("POST")
+	tenantRouter.HandleFunc("/sales/orders/{id}/cancel", salesOrderHandler.CancelSalesOrder).Methods("POST")
+	tenantRouter.HandleFunc("/sales/orders/{id}/shipments", salesOrderHandler.ListShipments).Methods("GET")
+	tenantRouter.HandleFunc("/sales/orders/{id}/shipments", salesOrderHandler.CreateShipment).Methods("POST")
+	tenantRouter.HandleFunc("/sales/shipments/{id}", salesOrderHandler.GetShipment).Methods("GET")
+	tenantRouter.HandleFunc("/sales/shipments/{id}/ship", salesOrderHandler.ShipShipment).Methods("POST")
+	tenantRouter.HandleFunc("/sales/backorders", salesOrderHandler.ListBackorders).Methods("GET")
+	tenantRouter.HandleFunc("/sales/returns", returnHandler.ListReturns).Methods("GET")
+	tenantRouter.HandleFunc("/sales/returns", returnHandler.CreateReturn).Methods("POST")
+	tenantRouter.HandleFunc("/sales/returns/{id}", returnHandler.GetReturn).Methods("GET")
+	tenantRouter.HandleFunc("/sales/returns/{id}/receive", returnHandler.ReceiveReturn).Methods("POST")
+	tenantRouter.HandleFunc("/sales/returns/{id}/credit", returnHandler.CreditReturn).Methods("POST")
+	tenantRouter.HandleFunc("/sales/returns/{id}/cancel", returnHandler.CancelReturn).Methods("POST")
+
+	tenantRouter.HandleFunc("/sales/price-lists", priceListHandler.ListPriceLists).Methods("GET")
+	tenantRouter.HandleFunc("/sales/price-lists", priceListHandler.CreatePriceList).Methods("POST")
+	tenantRouter.HandleFunc("/sales/price-lists/{id}", priceListHandler.GetPriceList).Methods("GET")
+	tenantRouter.HandleFunc("/sales/price-lists/{id}", priceListHandler.UpdatePriceList).Methods("PUT")
+	tenantRouter.HandleFunc("/sales/price-lists/{id}", priceListHandler.DeletePriceList).Methods("DELETE")
+	tenantRouter.HandleFunc("/sales/prices", priceListHandler.ResolvePrice).Methods("GET")
+
+	// Manufacturing routes
+	tenantRouter.HandleFunc("/manufacturing/boms", bomHandler.ListBOMs).Methods("GET")
+	tenantRouter.HandleFunc("/manufacturing/boms", bomHandler.CreateBOM).Methods("POST")
+	tenantRouter.HandleFunc("/manufacturing/boms/{id}", bomHandler.GetBOM).Methods("GET")
+	tenantRouter.HandleFunc("/manufacturing/boms/{id}", bomHandler.UpdateBOM).Methods("PUT")
+	tenantRouter.HandleFunc("/manufacturing/boms/{id}", bomHandler.DeleteBOM).Methods("DELETE")
+	tenantRouter.HandleFunc("/manufacturing/work-orders", workOrderHandler.ListWorkOrders).Methods("GET")
+	tenantRouter.HandleFunc("/manufacturing/work-orders", workOrderHandler.CreateWorkOrder).Methods("POST")
+	tenantRouter.HandleFunc("/manufacturing/work-orders/{id}", workOrderHandler.GetWorkOrder).Methods("GET")
+	tenantRouter.HandleFunc("/manufacturing/work-orders/{id}", workOrderHandler.UpdateWorkOrder).Methods("PUT")
+	tenantRouter.HandleFunc("/manufacturing/work-orders/{id}", workOrderHandler.DeleteWorkOrder).Methods("DELETE")
+	tenantRouter.HandleFunc("/manufacturing/work-orders/{id}/release", workOrderHandler.ReleaseWorkOrder).Methods("POST")
+	tenantRouter.HandleFunc("/manufacturing/work-orders/{id}/complete", workOrderHandler.CompleteWorkOrder).Methods("POST")
+	tenantRouter.HandleFunc("/manufacturing/work-orders/{id}/cancel", workOrderHandler.CancelWorkOrder).Methods("POST")
+	tenantRouter.HandleFunc("/manufacturing/requirements", workOrderHandler.GetMaterialRequirements).Methods("GET")
+
 	// CRM routes
 	tenantRouter.HandleFunc("/crm/customers", customerHandler.ListCustomers).Methods("GET")
 	tenantRouter.HandleFunc("/crm/customers", customerHandler.CreateCustomer).Methods("POST")
@@ -104,6 +388,12 @@ func NewRouter(
 	tenantRouter.HandleFunc("/crm/customers/{id}", customerHandler.UpdateCustomer).Methods("PUT")
 	tenantRouter.HandleFunc("/crm/customers/{id}", customerHandler.DeleteCustomer).Methods("DELETE")
 
+	tenantRouter.HandleFunc("/crm/customer-groups", customerGroupHandler.ListCustomerGroups).Methods("GET")
+	tenantRouter.HandleFunc("/crm/customer-groups", customerGroupHandler.CreateCustomerGroup).Methods("POST")
+	tenantRouter.HandleFunc("/crm/customer-groups/{id}", customerGroupHandler.GetCustomerGroup).Methods("GET")
+	tenantRouter.HandleFunc("/crm/customer-groups/{id}", customerGroupHandler.UpdateCustomerGroup).Methods("PUT")
+	tenantRouter.HandleFunc("/crm/customer-groups/{id}", customerGroupHandler.DeleteCustomerGroup).Methods("DELETE")
+
 	tenantRouter.HandleFunc("/crm/contacts", contactHandler.CreateContact).Methods("POST")
 	tenantRouter.HandleFunc("/crm/contacts/{id}", contactHandler.GetContact).Methods("GET")
 	tenantRouter.HandleFunc("/crm/contacts/{id}", contactHandler.UpdateContact).Methods("PUT")
@@ -116,6 +406,56 @@ func NewRouter(
 	tenantRouter.HandleFunc("/crm/interactions/{id}", interactionHandler.DeleteInteraction).Methods("DELETE")
 	tenantRouter.HandleFunc("/crm/customers/{customerId}/interactions", interactionHandler.ListInteractionsByCustomer).Methods("GET")
 
+	// Sales pipeline routes
+	tenantRouter.HandleFunc("/crm/pipeline-stages", pipelineStageHandler.ListStages).Methods("GET")
+	tenantRouter.HandleFunc("/crm/pipeline-stages", pipelineStageHandler.CreateStage).Methods("POST")
+	tenantRouter.HandleFunc("/crm/pipeline-stages/{id}", pipelineStageHandler.GetStage).Methods("GET")
+	tenantRouter.HandleFunc("/crm/pipeline-stages/{id}", pipelineStageHandler.UpdateStage).Methods("PUT")
+	tenantRouter.HandleFunc("/crm/pipeline-stages/{id}", pipelineStageHandler.DeleteStage).Methods("DELETE")
+	tenantRouter.HandleFunc("/crm/leads", leadHandler.ListLeads).Methods("GET")
+	tenantRouter.HandleFunc("/crm/leads", leadHandler.CreateLead).Methods("POST")
+	tenantRouter.HandleFunc("/crm/leads/{id}", leadHandler.GetLead).Methods("GET")
+	tenantRouter.HandleFunc("/crm/leads/{id}", leadHandler.UpdateLead).Methods("PUT")
+	tenantRouter.HandleFunc("/crm/leads/{id}", leadHandler.DeleteLead).Methods("DELETE")
+	tenantRouter.HandleFunc("/crm/leads/{id}/convert", leadHandler.ConvertLead).Methods("POST")
+	tenantRouter.HandleFunc("/crm/opportunities", opportunityHandler.ListOpportunities).Methods("GET")
+	tenantRouter.HandleFunc("/crm/opportunities", opportunityHandler.CreateOpportunity).Methods("POST")
+	tenantRouter.HandleFunc("/crm/opportunities/{id}", opportunityHandler.GetOpportunity).Methods("GET")
+	tenantRouter.HandleFunc("/crm/opportunities/{id}", opportunityHandler.UpdateOpportunity).Methods("PUT")
+	tenantRouter.HandleFunc("/crm/opportunities/{id}", opportunityHandler.DeleteOpportunity).Methods("DELETE")
+	tenantRouter.HandleFunc("/crm/opportunities/{id}/stage", opportunityHandler.ChangeStage).Methods("POST")
+	tenantRouter.HandleFunc("/crm/opportunities/{id}/stage-changes", opportunityHandler.ListStageChanges).Methods("GET")
+	tenantRouter.HandleFunc("/crm/pipeline/summary", opportunityHandler.GetPipelineSummary).Methods("GET")
+
+	// Task routes
+	tenantRouter.HandleFunc("/crm/tasks", taskHandler.ListTasks).Methods("GET")
+	tenantRouter.HandleFunc("/crm/tasks", taskHandler.CreateTask).Methods("POST")
+	tenantRouter.HandleFunc("/crm/tasks/mine", taskHandler.ListMyTasks).Methods("GET")
+	tenantRouter.HandleFunc("/crm/tasks/{id}", taskHandler.GetTask).Methods("GET")
+	tenantRouter.HandleFunc("/crm/tasks/{id}", taskHandler.UpdateTask).Methods("PUT")
+	tenantRouter.HandleFunc("/crm/tasks/{id}", taskHandler.DeleteTask).Methods("DELETE")
+	tenantRouter.HandleFunc("/crm/tasks/{id}/complete", taskHandler.CompleteTask).Methods("POST")
+	tenantRouter.HandleFunc("/crm/tasks/{id}/cancel", taskHandler.CancelTask).Methods("POST")
+
+	// Duplicate routes
+	tenantRouter.HandleFunc("/crm/duplicates/customers", duplicateHandler.ListCustomerDuplicates).Methods("GET")
+	tenantRouter.HandleFunc("/crm/duplicates/contacts", duplicateHandler.ListContactDuplicates).Methods("GET")
+	tenantRouter.HandleFunc("/crm/customers/{id}/merge", duplicateHandler.MergeCustomer).Methods("POST")
+	tenantRouter.HandleFunc("/crm/contacts/{id}/merge", duplicateHandler.MergeContact).Methods("POST")
+
+	// Search routes
+	tenantRouter.HandleFunc("/crm/search", searchHandler.Search).Methods("GET")
+
+	// Segment routes
+	tenantRouter.HandleFunc("/crm/segments", segmentHandler.ListSegments).Methods("GET")
+	tenantRouter.HandleFunc("/crm/segments", segmentHandler.CreateSegment).Methods("POST")
+	tenantRouter.HandleFunc("/crm/segments/preview", segmentHandler.PreviewSegment).Methods("POST")
+	tenantRouter.HandleFunc("/crm/segments/{id}", segmentHandler.GetSegment).Methods("GET")
+	tenantRouter.HandleFunc("/crm/segments/{id}", segmentHandler.UpdateSegment).Methods("PUT")
+	tenantRouter.HandleFunc("/crm/segments/{id}", segmentHandler.DeleteSegment).Methods("DELETE")
+	tenantRouter.HandleFunc("/crm/segments/{id}/members", segmentHandler.ListSegmentMembers).Methods("GET")
+	tenantRouter.HandleFunc("/crm/segments/{id}/export", segmentHandler.ExportSegmentMembers).Methods("GET")
+
 	// Add CORS middleware
 	r.Use(corsMiddleware)
 
@@ -84,6 +84,15 @@ func (h *TenantHandler) CreateTenant(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if tenant.CostingMethod == "" {
+		tenant.CostingMethod = models.CostingFIFO
+	}
+
+	if tenant.CostingMethod != models.CostingFIFO && tenant.CostingMethod != models.CostingAverage {
+		auth.RespondWithError(w, http.StatusBadRequest, "Costing method must be fifo or average")
+		return
+	}
+
 	// Check if tenant already exists
 	existingTenant, err := h.tenantService.GetBySubdomain(tenant.Subdomain)
 	if err != nil {
@@ -125,6 +134,11 @@ func (h *TenantHandler) UpdateTenant(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if tenant.CostingMethod != "" && tenant.CostingMethod != models.CostingFIFO && tenant.CostingMethod != models.CostingAverage {
+		auth.RespondWithError(w, http.StatusBadRequest, "Costing method must be fifo or average")
+		return
+	}
+
 	// Check if tenant exists
 	existingTenant, err := h.tenantService.GetByID(id)
 	if err != nil {
@@ -137,6 +151,25 @@ func (h *TenantHandler) UpdateTenant(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Keep the costing method unless a new one is given. Stock is valued by the
+	// cost layers or the average cost of the method it was received under, so the
+	// method cannot change while there is stock.
+	if tenant.CostingMethod == "" {
+		tenant.CostingMethod = existingTenant.CostingMethod
+	}
+	if tenant.CostingMethod != existingTenant.CostingMethod {
+		hasStock, err := h.tenantService.HasStock(id)
+		if err != nil {
+			auth.RespondWithError(w, http.StatusInternalServerError, "Error checking stock")
+			return
+		}
+
+		if hasStock {
+			auth.RespondWithError(w, http.StatusConflict, "Costing method cannot change while the tenant has stock")
+			return
+		}
+	}
+
 	// Update tenant
 	if err := h.tenantService.Update(&tenant); err != nil {
 		auth.RespondWithError(w, http.StatusInternalServerError, "Error updating tenant")
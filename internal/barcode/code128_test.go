@@ -0,0 +1,124 @@
+package barcode
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/yookibooki/erp/internal/models"
+)
+
+// code128Values decodes Code 128 modules into their symbol values
+func code128Values(t *testing.T, modules []bool) []int {
+	t.Helper()
+
+	// Bars and spaces are at most four modules wide, so each width is one digit
+	var widths strings.Builder
+	for i := 0; i < len(modules); {
+		j := i
+		for j < len(modules) && modules[j] == modules[i] {
+			j++
+		}
+		widths.WriteByte(byte('0' + j - i))
+		i = j
+	}
+
+	values := []int{}
+	rest := widths.String()
+	for len(rest) > 0 {
+		length := 6
+		if len(rest) == 7 {
+			length = 7
+		}
+		if len(rest) < length {
+			t.Fatalf("modules end with a partial symbol %q", rest)
+		}
+
+		value := -1
+		for i, pattern := range code128Patterns {
+			if pattern == rest[:length] {
+				value = i
+				break
+			}
+		}
+		if value < 0 {
+			t.Fatalf("modules contain an unknown symbol %q", rest[:length])
+		}
+		values = append(values, value)
+		rest = rest[length:]
+	}
+	return values
+}
+
+func TestEncodeCode128(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []int
+	}{
+		{"code set B", "ABC", []int{104, 33, 34, 35, 1, 106}},
+		{"even digits in code set C", "1234", []int{105, 12, 34, 82, 106}},
+		{"two digits in code set C", "12", []int{105, 12, 14, 106}},
+		{"odd digits stay in code set B", "123", []int{104, 17, 18, 19, 8, 106}},
+		{"odd digit run after text switches to C after one digit", "AB12345", []int{104, 33, 34, 17, 99, 23, 45, 7, 106}},
+		{"leading digits then text switches back to B", "123456A", []int{105, 12, 34, 56, 100, 33, 94, 106}},
+		{"odd leading digits start in B", "12345A", []int{104, 17, 99, 23, 45, 100, 33, 30, 106}},
+		{"GS1-128 GTIN", "\x1d0109501101530003", []int{105, 102, 1, 9, 50, 11, 1, 53, 0, 3, 71, 106}},
+		{"GS1-128 lot switches to B", "\x1d10AB", []int{105, 102, 10, 100, 33, 34, 5, 106}},
+		{"GS1-128 with a single digit starts in B", "\x1d1A", []int{104, 102, 17, 33, 30, 106}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			modules, err := EncodeCode128(test.text)
+			if err != nil {
+				t.Fatalf("EncodeCode128(%q) returned error: %v", test.text, err)
+			}
+			if got := code128Values(t, modules); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("EncodeCode128(%q) = %v, want %v", test.text, got, test.want)
+			}
+		})
+	}
+}
+
+func TestEncodeCode128Patterns(t *testing.T) {
+	modules, err := EncodeCode128("ABC")
+	if err != nil {
+		t.Fatalf("EncodeCode128 returned error: %v", err)
+	}
+
+	got := modulesString(modules)
+	if start := "11010010000"; !strings.HasPrefix(got, start) {
+		t.Errorf("modules start with %s, want the start B symbol %s", got[:len(start)], start)
+	}
+	if stop := "1100011101011"; !strings.HasSuffix(got, stop) {
+		t.Errorf("modules end with %s, want the stop symbol %s", got[len(got)-len(stop):], stop)
+	}
+
+	modules, err = EncodeCode128("12")
+	if err != nil {
+		t.Fatalf("EncodeCode128 returned error: %v", err)
+	}
+	if start := "11010011100"; !strings.HasPrefix(modulesString(modules), start) {
+		t.Errorf("modules do not start with the start C symbol %s", start)
+	}
+}
+
+func TestEncodeCode128Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+	}{
+		{"empty", ""},
+		{"control character", "AB\nC"},
+		{"non-ASCII", "café"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := EncodeCode128(test.text); err != models.ErrInvalidBarcode {
+				t.Errorf("EncodeCode128(%q) error = %v, want %v", test.text, err, models.ErrInvalidBarcode)
+			}
+		})
+	}
+}
\ No newline at end of file
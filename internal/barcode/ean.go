@@ -0,0 +1,116 @@
+package barcode
+
+import (
+	"github.com/yookibooki/erp/internal/models"
+)
+
+// eanLeftCodes are the odd parity (L) codes of the EAN digits. Right-hand (R) codes
+// invert them and even parity (G) codes are the R codes reversed.
+var eanLeftCodes = [10]string{
+	"0001101", "0011001", "0010011", "0111101", "0100011",
+	"0110001", "0101111", "0111011", "0110111", "0001011",
+}
+
+// ean13Parities are the parities of the left-hand digits of an EAN-13 code, which
+// encode its first digit
+var ean13Parities = [10]string{
+	"LLLLLL", "LLGLGG", "LLGGLG", "LLGGGL", "LGLLGG",
+	"LGGLLG", "LGGGLG", "LGLGLG", "LGLGGL", "LGGLGL",
+}
+
+// EncodeEAN13 encodes a 13-digit EAN-13 code, or a 12-digit UPC-A code as the
+// EAN-13 code with a leading zero, as EAN-13 modules
+func EncodeEAN13(code string) ([]bool, error) {
+	if len(code) == 12 {
+		code = "0" + code
+	}
+	if len(code) != 13 || !isDigits(code) {
+		return nil, models.ErrBarcodeDigits
+	}
+	if !ValidCheckDigit(code) {
+		return nil, models.ErrBarcodeCheckDigit
+	}
+
+	parities := ean13Parities[code[0]-'0']
+	modules := appendPattern(nil, "101")
+	for i := 1; i <= 6; i++ {
+		modules = appendPattern(modules, eanCode(code[i], parities[i-1]))
+	}
+	modules = appendPattern(modules, "01010")
+	for i := 7; i <= 12; i++ {
+		modules = appendPattern(modules, eanCode(code[i], 'R'))
+	}
+	return appendPattern(modules, "101"), nil
+}
+
+// EncodeEAN8 encodes an 8-digit EAN-8 code as EAN-8 modules
+func EncodeEAN8(code string) ([]bool, error) {
+	if len(code) != 8 || !isDigits(code) {
+		return nil, models.ErrBarcodeDigits
+	}
+	if !ValidCheckDigit(code) {
+		return nil, models.ErrBarcodeCheckDigit
+	}
+
+	modules := appendPattern(nil, "101")
+	for i := 0; i < 4; i++ {
+		modules = appendPattern(modules, eanCode(code[i], 'L'))
+	}
+	modules = appendPattern(modules, "01010")
+	for i := 4; i < 8; i++ {
+		modules = appendPattern(modules, eanCode(code[i], 'R'))
+	}
+	return appendPattern(modules, "101"), nil
+}
+
+// eanCode returns the modules of an EAN digit in the L, G or R code set
+func eanCode(digit byte, set byte) string {
+	left := eanLeftCodes[digit-'0']
+	right := make([]byte, len(left))
+	for i := range left {
+		right[i] = '0' + '1' - left[i]
+	}
+
+	switch set {
+	case 'R':
+		return string(right)
+	case 'G':
+		for i, j := 0, len(right)-1; i < j; i, j = i+1, j-1 {
+			right[i], right[j] = right[j], right[i]
+		}
+		return string(right)
+	}
+	return left
+}
+
+// appendPattern appends modules written as a string of 1s for bars and 0s for spaces
+func appendPattern(modules []bool, pattern string) []bool {
+	for _, module := range pattern {
+		modules = append(modules, module == '1')
+	}
+	return modules
+}
+
+// Encode encodes a product barcode in the symbology for its type, returning its modules
+// and the text printed below them. EAN-13 and UPC-A codes are encoded as EAN-13, EAN-8
+// codes as EAN-8, GTIN-14 codes as GS1-128 and internal codes as Code 128.
+func Encode(barcodeType, code string) ([]bool, string, error) {
+	if err := Validate(barcodeType, code); err != nil {
+		return nil, "", err
+	}
+
+	switch barcodeType {
+	case models.BarcodeEAN13, models.BarcodeUPCA:
+		modules, err := EncodeEAN13(code)
+		return modules, code, err
+	case models.BarcodeEAN8:
+		modules, err := EncodeEAN8(code)
+		return modules, code, err
+	case models.BarcodeGTIN14:
+		modules, err := EncodeCode128(string(GroupSeparator) + "01" + code)
+		return modules, "(01)" + code, err
+	}
+
+	modules, err := EncodeCode128(code)
+	return modules, code, err
+}
\ No newline at end of file
@@ -0,0 +1,145 @@
+package barcode
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yookibooki/erp/internal/models"
+)
+
+// modulesString writes modules as 1s for bars and 0s for spaces
+func modulesString(modules []bool) string {
+	var b strings.Builder
+	for _, module := range modules {
+		if module {
+			b.WriteByte('1')
+		} else {
+			b.WriteByte('0')
+		}
+	}
+	return b.String()
+}
+
+func TestEncodeEAN13(t *testing.T) {
+	tests := []struct {
+		code string
+		want string
+	}{
+		{
+			code: "0000000000000",
+			want: "101" + strings.Repeat("0001101", 6) + "01010" + strings.Repeat("1110010", 6) + "101",
+		},
+		{
+			// First digit 5 gives the left-hand parities LGGLLG
+			code: "5901234123457",
+			want: "101" +
+				"0001011" + "0100111" + "0110011" + "0010011" + "0111101" + "0011101" +
+				"01010" +
+				"1100110" + "1101100" + "1000010" + "1011100" + "1001110" + "1000100" +
+				"101",
+		},
+	}
+
+	for _, test := range tests {
+		modules, err := EncodeEAN13(test.code)
+		if err != nil {
+			t.Fatalf("EncodeEAN13(%q) returned error: %v", test.code, err)
+		}
+		if got := modulesString(modules); got != test.want {
+			t.Errorf("EncodeEAN13(%q) = %s, want %s", test.code, got, test.want)
+		}
+	}
+}
+
+func TestEncodeEAN13UPCA(t *testing.T) {
+	upca, err := EncodeEAN13("036000291452")
+	if err != nil {
+		t.Fatalf("EncodeEAN13 returned error: %v", err)
+	}
+	ean13, err := EncodeEAN13("0036000291452")
+	if err != nil {
+		t.Fatalf("EncodeEAN13 returned error: %v", err)
+	}
+
+	if modulesString(upca) != modulesString(ean13) {
+		t.Errorf("UPC-A 036000291452 is not encoded as EAN-13 0036000291452")
+	}
+	if len(upca) != 95 {
+		t.Errorf("UPC-A has %d modules, want 95", len(upca))
+	}
+}
+
+func TestEncodeEAN8(t *testing.T) {
+	code := "96385074"
+	want := "101" +
+		"0001011" + "0101111" + "0111101" + "0110111" +
+		"01010" +
+		"1001110" + "1110010" + "1000100" + "1011100" +
+		"101"
+
+	modules, err := EncodeEAN8(code)
+	if err != nil {
+		t.Fatalf("EncodeEAN8(%q) returned error: %v", code, err)
+	}
+	if got := modulesString(modules); got != want {
+		t.Errorf("EncodeEAN8(%q) = %s, want %s", code, got, want)
+	}
+}
+
+func TestEncodeEANErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		encode func(string) ([]bool, error)
+		code   string
+		want   error
+	}{
+		{"EAN-13 too short", EncodeEAN13, "59012341234", models.ErrBarcodeDigits},
+		{"EAN-13 letters", EncodeEAN13, "59012341234A7", models.ErrBarcodeDigits},
+		{"EAN-13 check digit", EncodeEAN13, "5901234123458", models.ErrBarcodeCheckDigit},
+		{"UPC-A check digit", EncodeEAN13, "036000291453", models.ErrBarcodeCheckDigit},
+		{"EAN-8 too long", EncodeEAN8, "963850745", models.ErrBarcodeDigits},
+		{"EAN-8 check digit", EncodeEAN8, "96385075", models.ErrBarcodeCheckDigit},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := test.encode(test.code); err != test.want {
+				t.Errorf("encoding %q: error = %v, want %v", test.code, err, test.want)
+			}
+		})
+	}
+}
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		barcodeType string
+		code        string
+		text        string
+		modules     int
+	}{
+		{models.BarcodeEAN13, "5901234123457", "5901234123457", 95},
+		{models.BarcodeUPCA, "036000291452", "036000291452", 95},
+		{models.BarcodeEAN8, "96385074", "96385074", 67},
+		// Start C, FNC1, eight digit pairs, check symbol and stop
+		{models.BarcodeGTIN14, "09501101530003", "(01)09501101530003", 11*11 + 13},
+		// Start B, five characters, check symbol and stop
+		{models.BarcodeInternal, "SKU-1", "SKU-1", 7*11 + 13},
+	}
+
+	for _, test := range tests {
+		modules, text, err := Encode(test.barcodeType, test.code)
+		if err != nil {
+			t.Fatalf("Encode(%q, %q) returned error: %v", test.barcodeType, test.code, err)
+		}
+		if text != test.text {
+			t.Errorf("Encode(%q, %q) text = %q, want %q", test.barcodeType, test.code, text, test.text)
+		}
+		if len(modules) != test.modules {
+			t.Errorf("Encode(%q, %q) has %d modules, want %d", test.barcodeType, test.code, len(modules), test.modules)
+		}
+	}
+
+	if _, _, err := Encode(models.BarcodeEAN13, "5901234123458"); err != models.ErrBarcodeCheckDigit {
+		t.Errorf("Encode with a wrong check digit: error = %v, want %v", err, models.ErrBarcodeCheckDigit)
+	}
+}
\ No newline at end of file
@@ -0,0 +1,324 @@
+package barcode
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yookibooki/erp/internal/models"
+)
+
+// GroupSeparator ends variable-length GS1 elements in scanned data. Scanners send
+// it for the FNC1 character of GS1-128 and GS1 DataMatrix symbols.
+const GroupSeparator = '\x1d'
+
+// symbologyIdentifiers prefix the data of GS1 symbols read by scanners that report them:
+// GS1-128, GS1 DataMatrix, GS1 QR Code and GS1 DataBar
+var symbologyIdentifiers = []string{"]C1", "]d2", "]Q3", "]e0"}
+
+// applicationIdentifier is the length of the value of a GS1 application identifier,
+// which is the maximum length for variable-length values
+type applicationIdentifier struct {
+	length   int
+	variable bool
+	numeric  bool
+}
+
+// applicationIdentifiers are the GS1 application identifiers understood by ParseGS1
+var applicationIdentifiers = map[string]applicationIdentifier{
+	"00":   {18, false, true}, // SSCC
+	"01":   {14, false, true}, // GTIN
+	"02":   {14, false, true}, // GTIN of contained trade items
+	"10":   {20, true, false}, // Batch or lot number
+	"11":   {6, false, true},  // Production date
+	"13":   {6, false, true},  // Packaging date
+	"15":   {6, false, true},  // Best before date
+	"16":   {6, false, true},  // Sell by date
+	"17":   {6, false, true},  // Expiration date
+	"21":   {20, true, false}, // Serial number
+	"30":   {8, true, true},   // Variable count of items
+	"37":   {8, true, true},   // Count of trade items
+	"240":  {30, true, false}, // Additional product identification
+	"241":  {30, true, false}, // Customer part number
+	"400":  {30, true, false}, // Customer's purchase order number
+	"3100": {6, false, true},  // Net weight in kg, with 0 to 5 decimals
+	"3101": {6, false, true},
+	"3102": {6, false, true},
+	"3103": {6, false, true},
+	"3104": {6, false, true},
+	"3105": {6, false, true},
+}
+
+// IsGS1 reports whether data is a GS1 element string, either prefixed with the
+// symbology identifier of a GS1 symbol or written with its application identifiers
+// in parentheses
+func IsGS1(data string) bool {
+	for _, identifier := range symbologyIdentifiers {
+		if strings.HasPrefix(data, identifier) {
+			return true
+		}
+	}
+	return strings.HasPrefix(data, "(")
+}
+
+// ParseGS1 parses a GS1 element string as scanned from a GS1-128 or GS1 DataMatrix
+// symbol, with or without its symbology identifier, or as printed below a symbol
+// with its application identifiers in parentheses
+func ParseGS1(data string) (*models.GS1Data, error) {
+	for _, identifier := range symbologyIdentifiers {
+		data = strings.TrimPrefix(data, identifier)
+	}
+	data = strings.TrimLeft(data, string(GroupSeparator))
+
+	var elements [][2]string
+	var err error
+	if strings.HasPrefix(data, "(") {
+		elements, err = splitBracketedElements(data)
+	} else {
+		elements, err = splitElements(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(elements) == 0 {
+		return nil, models.ErrGS1Data
+	}
+
+	result := &models.GS1Data{Elements: map[string]string{}}
+	for _, element := range elements {
+		if err := applyElement(result, element[0], element[1]); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// lookupIdentifier returns the application identifier data starts with
+func lookupIdentifier(data string) (string, applicationIdentifier, error) {
+	for length := 2; length <= 4 && length <= len(data); length++ {
+		if ai, ok := applicationIdentifiers[data[:length]]; ok {
+			return data[:length], ai, nil
+		}
+	}
+	return "", applicationIdentifier{}, models.ErrGS1Identifier
+}
+
+// splitElements splits an element string into application identifiers and values.
+// Variable-length values end with a group separator or at the end of the data.
+func splitElements(data string) ([][2]string, error) {
+	elements := [][2]string{}
+	for len(data) > 0 {
+		code, ai, err := lookupIdentifier(data)
+		if err != nil {
+			return nil, err
+		}
+		data = data[len(code):]
+
+		var value string
+		if ai.variable {
+			end := strings.IndexRune(data, GroupSeparator)
+			if end < 0 {
+				end = len(data)
+			}
+			value = data[:end]
+			data = strings.TrimPrefix(data[end:], string(GroupSeparator))
+		} else {
+			if len(data) < ai.length {
+				return nil, models.ErrGS1Data
+			}
+			value = data[:ai.length]
+			data = strings.TrimPrefix(data[ai.length:], string(GroupSeparator))
+		}
+
+		elements = append(elements, [2]string{code, value})
+	}
+	return elements, nil
+}
+
+// splitBracketedElements splits an element string written as "(01)...(10)..." into
+// application identifiers and values
+func splitBracketedElements(data string) ([][2]string, error) {
+	elements := [][2]string{}
+	for len(data) > 0 {
+		end := strings.IndexByte(data, ')')
+		if data[0] != '(' || end < 0 {
+			return nil, models.ErrGS1Data
+		}
+		code := data[1:end]
+		data = data[end+1:]
+
+		next := strings.IndexByte(data, '(')
+		if next < 0 {
+			next = len(data)
+		}
+		elements = append(elements, [2]string{code, data[:next]})
+		data = data[next:]
+	}
+	return elements, nil
+}
+
+// applyElement validates the value of an application identifier and sets it on result
+func applyElement(result *models.GS1Data, code, value string) error {
+	ai, ok := applicationIdentifiers[code]
+	if !ok {
+		return models.ErrGS1Identifier
+	}
+	if value == "" || len(value) > ai.length || (!ai.variable && len(value) != ai.length) {
+		return models.ErrGS1Data
+	}
+	if ai.numeric && !isDigits(value) {
+		return models.ErrGS1Data
+	}
+	result.Elements[code] = value
+
+	switch code {
+	case "00":
+		if !ValidCheckDigit(value) {
+			return models.ErrBarcodeCheckDigit
+		}
+		result.SSCC = value
+	case "01":
+		if !ValidCheckDigit(value) {
+			return models.ErrBarcodeCheckDigit
+		}
+		result.GTIN = value
+	case "10":
+		result.LotNumber = value
+	case "21":
+		result.SerialNumber = value
+	case "11", "15", "17":
+		date, err := parseDate(value)
+		if err != nil {
+			return err
+		}
+		switch code {
+		case "11":
+			result.ProductionDate = &date
+		case "15":
+			result.BestBefore = &date
+		case "17":
+			result.ExpiryDate = &date
+		}
+	case "30", "37":
+		quantity, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return models.ErrGS1Data
+		}
+		result.Quantity = quantity
+	case "3100", "3101", "3102", "3103", "3104", "3105":
+		weight, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return models.ErrGS1Data
+		}
+		result.NetWeight = weight / math.Pow(10, float64(code[3]-'0'))
+	}
+	return nil
+}
+
+// parseDate parses a GS1 date formatted YYMMDD. A day of 00 is the last day of the
+// month, and the century is the one that puts the year closest to the current year.
+func parseDate(value string) (time.Time, error) {
+	yy, _ := strconv.Atoi(value[0:2])
+	month, _ := strconv.Atoi(value[2:4])
+	day, _ := strconv.Atoi(value[4:6])
+	if month < 1 || month > 12 {
+		return time.Time{}, models.ErrGS1Date
+	}
+
+	currentYear := time.Now().Year()
+	year := currentYear/100*100 + yy
+	if year-currentYear > 50 {
+		year -= 100
+	} else if year-currentYear < -49 {
+		year += 100
+	}
+
+	lastDay := time.Date(year, time.Month(month)+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	if day == 0 {
+		day = lastDay
+	}
+	if day > lastDay {
+		return time.Time{}, models.ErrGS1Date
+	}
+
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC), nil
+}
+
+// CheckDigit returns the GS1 check digit of digits, which weighs the digits from the
+// right alternately by 3 and 1
+func CheckDigit(digits string) int {
+	sum := 0
+	for i := len(digits) - 1; i >= 0; i-- {
+		weight := 1
+		if (len(digits)-1-i)%2 == 0 {
+			weight = 3
+		}
+		sum += int(digits[i]-'0') * weight
+	}
+	return (10 - sum%10) % 10
+}
+
+// ValidCheckDigit reports whether the last digit of a GS1 code such as a GTIN or
+// SSCC is the check digit of the digits before it
+func ValidCheckDigit(code string) bool {
+	if len(code) < 2 || !isDigits(code) {
+		return false
+	}
+	return CheckDigit(code[:len(code)-1]) == int(code[len(code)-1]-'0')
+}
+
+// Validate checks that a product barcode code suits its type: GTINs must have the
+// number of digits of their type and a valid check digit
+func Validate(barcodeType, code string) error {
+	length := 0
+	switch barcodeType {
+	case models.BarcodeEAN13:
+		length = 13
+	case models.BarcodeEAN8:
+		length = 8
+	case models.BarcodeUPCA:
+		length = 12
+	case models.BarcodeGTIN14:
+		length = 14
+	case models.BarcodeInternal:
+		if code == "" || len(code) > 80 {
+			return models.ErrInvalidBarcode
+		}
+		for _, r := range code {
+			if r < ' ' || r > '~' {
+				return models.ErrInvalidBarcode
+			}
+		}
+		return nil
+	default:
+		return models.ErrBarcodeType
+	}
+
+	if len(code) != length || !isDigits(code) {
+		return models.ErrBarcodeDigits
+	}
+	if !ValidCheckDigit(code) {
+		return models.ErrBarcodeCheckDigit
+	}
+	return nil
+}
+
+// IsGTIN reports whether code has the digits of an EAN-8, UPC-A, EAN-13 or GTIN-14
+func IsGTIN(code string) bool {
+	switch len(code) {
+	case 8, 12, 13, 14:
+		return isDigits(code)
+	}
+	return false
+}
+
+// isDigits reports whether s consists of ASCII digits only
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
\ No newline at end of file
@@ -0,0 +1,264 @@
+package barcode
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/yookibooki/erp/internal/models"
+)
+
+func date(year int, month time.Month, day int) *time.Time {
+	d := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	return &d
+}
+
+func TestParseGS1(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want models.GS1Data
+	}{
+		{
+			name: "GTIN, expiry and lot with symbology identifier",
+			data: "]C1" + "0109501101530003" + "17250630" + "10ABC123",
+			want: models.GS1Data{GTIN: "09501101530003", ExpiryDate: date(2025, time.June, 30), LotNumber: "ABC123"},
+		},
+		{
+			name: "GS1 DataMatrix identifier",
+			data: "]d20109501101530003",
+			want: models.GS1Data{GTIN: "09501101530003"},
+		},
+		{
+			name: "variable-length lot ended by a group separator",
+			data: "0109501101530003" + "10ABC\x1d" + "21XYZ",
+			want: models.GS1Data{GTIN: "09501101530003", LotNumber: "ABC", SerialNumber: "XYZ"},
+		},
+		{
+			name: "variable-length lot without a group separator runs to the end",
+			data: "10ABC21XYZ",
+			want: models.GS1Data{LotNumber: "ABC21XYZ"},
+		},
+		{
+			name: "group separator after a fixed-length element",
+			data: "0109501101530003\x1d" + "10ABC",
+			want: models.GS1Data{GTIN: "09501101530003", LotNumber: "ABC"},
+		},
+		{
+			name: "leading group separator for FNC1",
+			data: "\x1d0109501101530003",
+			want: models.GS1Data{GTIN: "09501101530003"},
+		},
+		{
+			name: "bracketed form",
+			data: "(01)09501101530003(15)251231(10)LOT-7",
+			want: models.GS1Data{GTIN: "09501101530003", BestBefore: date(2025, time.December, 31), LotNumber: "LOT-7"},
+		},
+		{
+			name: "SSCC",
+			data: "00106141411234567897",
+			want: models.GS1Data{SSCC: "106141411234567897"},
+		},
+		{
+			name: "day 00 is the last day of the month",
+			data: "(11)250600(17)280200",
+			want: models.GS1Data{ProductionDate: date(2025, time.June, 30), ExpiryDate: date(2028, time.February, 29)},
+		},
+		{
+			name: "count of trade items",
+			data: "3712\x1d" + "0109501101530003",
+			want: models.GS1Data{GTIN: "09501101530003", Quantity: 12},
+		},
+		{
+			name: "net weight with three decimals",
+			data: "3103001250",
+			want: models.GS1Data{NetWeight: 1.25},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ParseGS1(test.data)
+			if err != nil {
+				t.Fatalf("ParseGS1(%q) returned error: %v", test.data, err)
+			}
+
+			checks := []struct {
+				field     string
+				got, want interface{}
+			}{
+				{"SSCC", got.SSCC, test.want.SSCC},
+				{"GTIN", got.GTIN, test.want.GTIN},
+				{"LotNumber", got.LotNumber, test.want.LotNumber},
+				{"SerialNumber", got.SerialNumber, test.want.SerialNumber},
+				{"ProductionDate", formatDate(got.ProductionDate), formatDate(test.want.ProductionDate)},
+				{"BestBefore", formatDate(got.BestBefore), formatDate(test.want.BestBefore)},
+				{"ExpiryDate", formatDate(got.ExpiryDate), formatDate(test.want.ExpiryDate)},
+				{"Quantity", got.Quantity, test.want.Quantity},
+				{"NetWeight", got.NetWeight, test.want.NetWeight},
+			}
+			for _, check := range checks {
+				if check.got != check.want {
+					t.Errorf("ParseGS1(%q).%s = %v, want %v", test.data, check.field, check.got, check.want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseGS1Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want error
+	}{
+		{"empty", "", models.ErrGS1Data},
+		{"only a symbology identifier", "]C1", models.ErrGS1Data},
+		{"unknown identifier", "9912345", models.ErrGS1Identifier},
+		{"unknown bracketed identifier", "(99)12345", models.ErrGS1Identifier},
+		{"truncated fixed-length value", "010950110153", models.ErrGS1Data},
+		{"short bracketed fixed-length value", "(01)0950110153", models.ErrGS1Data},
+		{"variable-length value too long", "10ABCDEFGHIJKLMNOPQRSTU", models.ErrGS1Data},
+		{"empty variable-length value", "10\x1d21XYZ", models.ErrGS1Data},
+		{"letters in a numeric value", "(17)25A630", models.ErrGS1Data},
+		{"unclosed bracket", "(01", models.ErrGS1Data},
+		{"GTIN check digit", "0109501101530004", models.ErrBarcodeCheckDigit},
+		{"SSCC check digit", "00106141411234567890", models.ErrBarcodeCheckDigit},
+		{"month 13", "17251301", models.ErrGS1Date},
+		{"month 00", "17250001", models.ErrGS1Date},
+		{"day past the end of the month", "17250231", models.ErrGS1Date},
+		{"day 32", "17250132", models.ErrGS1Date},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := ParseGS1(test.data); err != test.want {
+				t.Errorf("ParseGS1(%q) error = %v, want %v", test.data, err, test.want)
+			}
+		})
+	}
+}
+
+func TestParseDateCentury(t *testing.T) {
+	currentYear := time.Now().Year()
+	tests := []struct {
+		name string
+		year int
+	}{
+		{"current year", currentYear},
+		{"last year", currentYear - 1},
+		{"49 years ago", currentYear - 49},
+		{"50 years ahead", currentYear + 50},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			value := fmt.Sprintf("%02d0115", test.year%100)
+			got, err := parseDate(value)
+			if err != nil {
+				t.Fatalf("parseDate(%q) returned error: %v", value, err)
+			}
+			if got.Year() != test.year {
+				t.Errorf("parseDate(%q) year = %d, want %d", value, got.Year(), test.year)
+			}
+		})
+	}
+}
+
+func TestIsGS1(t *testing.T) {
+	tests := []struct {
+		data string
+		want bool
+	}{
+		{"]C10109501101530003", true},
+		{"]d20109501101530003", true},
+		{"]Q30109501101530003", true},
+		{"]e00109501101530003", true},
+		{"(01)09501101530003", true},
+		{"0109501101530003", false},
+		{"4006381333931", false},
+	}
+
+	for _, test := range tests {
+		if got := IsGS1(test.data); got != test.want {
+			t.Errorf("IsGS1(%q) = %v, want %v", test.data, got, test.want)
+		}
+	}
+}
+
+func TestCheckDigit(t *testing.T) {
+	tests := []struct {
+		digits string
+		want   int
+	}{
+		{"590123412345", 7},
+		{"400638133393", 1},
+		{"9638507", 4},
+		{"03600029145", 2},
+		{"0950110153000", 3},
+		{"10614141123456789", 7},
+		{"000000000000", 0},
+	}
+
+	for _, test := range tests {
+		if got := CheckDigit(test.digits); got != test.want {
+			t.Errorf("CheckDigit(%q) = %d, want %d", test.digits, got, test.want)
+		}
+	}
+}
+
+func TestValidCheckDigit(t *testing.T) {
+	tests := []struct {
+		code string
+		want bool
+	}{
+		{"5901234123457", true},
+		{"5901234123458", false},
+		{"96385074", true},
+		{"036000291452", true},
+		{"09501101530003", true},
+		{"106141411234567897", true},
+		{"7", false},
+		{"59012341234A7", false},
+		{"", false},
+	}
+
+	for _, test := range tests {
+		if got := ValidCheckDigit(test.code); got != test.want {
+			t.Errorf("ValidCheckDigit(%q) = %v, want %v", test.code, got, test.want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		barcodeType string
+		code        string
+		want        error
+	}{
+		{models.BarcodeEAN13, "5901234123457", nil},
+		{models.BarcodeEAN13, "590123412345", models.ErrBarcodeDigits},
+		{models.BarcodeEAN13, "5901234123458", models.ErrBarcodeCheckDigit},
+		{models.BarcodeEAN8, "96385074", nil},
+		{models.BarcodeUPCA, "036000291452", nil},
+		{models.BarcodeUPCA, "03600029145X", models.ErrBarcodeDigits},
+		{models.BarcodeGTIN14, "09501101530003", nil},
+		{models.BarcodeInternal, "SKU-001/A", nil},
+		{models.BarcodeInternal, "", models.ErrInvalidBarcode},
+		{models.BarcodeInternal, "SKU\t001", models.ErrInvalidBarcode},
+		{"qr", "5901234123457", models.ErrBarcodeType},
+	}
+
+	for _, test := range tests {
+		if err := Validate(test.barcodeType, test.code); err != test.want {
+			t.Errorf("Validate(%q, %q) = %v, want %v", test.barcodeType, test.code, err, test.want)
+		}
+	}
+}
+
+func formatDate(date *time.Time) string {
+	if date == nil {
+		return ""
+	}
+	return date.Format("2006-01-02")
+}
\ No newline at end of file
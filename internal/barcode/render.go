@@ -0,0 +1,74 @@
+package barcode
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+)
+
+// quietZone is the number of blank modules on either side of a rendered barcode
+const quietZone = 10
+
+// WritePNG renders barcode modules as a PNG image with each module scale pixels wide
+// and the bars height pixels high
+func WritePNG(w io.Writer, modules []bool, scale, height int) error {
+	width := (len(modules) + 2*quietZone) * scale
+	img := image.NewGray(image.Rect(0, 0, width, height))
+	for i := range img.Pix {
+		img.Pix[i] = 0xff
+	}
+
+	for i, bar := range modules {
+		if !bar {
+			continue
+		}
+		x := (i + quietZone) * scale
+		for dx := 0; dx < scale; dx++ {
+			for y := 0; y < height; y++ {
+				img.SetGray(x+dx, y, color.Gray{Y: 0})
+			}
+		}
+	}
+
+	return png.Encode(w, img)
+}
+
+// WriteSVG renders barcode modules as an SVG image with each module scale pixels wide
+// and the bars height pixels high, printing text below the bars unless it is empty
+func WriteSVG(w io.Writer, modules []bool, scale, height int, text string) error {
+	width := (len(modules) + 2*quietZone) * scale
+	fontSize := 5 * scale
+	totalHeight := height
+	if text != "" {
+		totalHeight += fontSize + scale
+	}
+
+	out := bufio.NewWriter(w)
+	fmt.Fprintf(out, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`, width, totalHeight, width, totalHeight)
+	fmt.Fprintf(out, `<rect width="%d" height="%d" fill="#fff"/>`, width, totalHeight)
+
+	// Draw adjacent bar modules as one rectangle
+	for i := 0; i < len(modules); {
+		if !modules[i] {
+			i++
+			continue
+		}
+		start := i
+		for i < len(modules) && modules[i] {
+			i++
+		}
+		fmt.Fprintf(out, `<rect x="%d" y="0" width="%d" height="%d" fill="#000"/>`, (start+quietZone)*scale, (i-start)*scale, height)
+	}
+
+	if text != "" {
+		fmt.Fprintf(out, `<text x="%d" y="%d" font-family="monospace" font-size="%d" text-anchor="middle">%s</text>`,
+			width/2, totalHeight-scale, fontSize, html.EscapeString(text))
+	}
+	fmt.Fprint(out, `</svg>`)
+
+	return out.Flush()
+}
\ No newline at end of file
@@ -7,9 +7,10 @@ import (
 
 // Config holds all configuration for the application
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	JWT      JWTConfig
+	Server       ServerConfig
+	Database     DatabaseConfig
+	JWT          JWTConfig
+	Notification NotificationConfig
 }
 
 // ServerConfig holds all server related configuration
@@ -33,6 +34,11 @@ type JWTConfig struct {
 	ExpireHours int
 }
 
+// NotificationConfig holds all notification related configuration
+type NotificationConfig struct {
+	LowStockWebhookURL string
+}
+
 // LoadConfig loads configuration from environment variables
 func LoadConfig() Config {
 	return Config{
@@ -51,6 +57,9 @@ func LoadConfig() Config {
 			Secret:     getEnv("JWT_SECRET", "your-secret-key"),
 			ExpireHours: getEnvAsInt("JWT_EXPIRE_HOURS", 24),
 		},
+		Notification: NotificationConfig{
+			LowStockWebhookURL: getEnv("LOW_STOCK_WEBHOOK_URL", ""),
+		},
 	}
 }
 
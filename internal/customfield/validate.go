@@ -0,0 +1,180 @@
+package customfield
+
+import (
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yookibooki/erp/internal/models"
+)
+
+// keyPattern is the form of custom field keys, which are used as JSON keys and filter names
+var keyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,62}$`)
+
+// dateLayout is the form of date values
+const dateLayout = "2006-01-02"
+
+// ValidateDefinition checks a custom field definition
+func ValidateDefinition(definition *models.CustomFieldDefinition) error {
+	switch definition.EntityType {
+	case models.CustomFieldCustomer, models.CustomFieldContact, models.CustomFieldProduct, models.CustomFieldInteraction:
+	default:
+		return models.ErrCustomFieldEntity
+	}
+
+	if !keyPattern.MatchString(definition.Key) {
+		return models.ErrCustomFieldKey
+	}
+
+	switch definition.FieldType {
+	case models.CustomFieldText, models.CustomFieldNumber, models.CustomFieldBoolean, models.CustomFieldDate:
+		definition.Options = nil
+	case models.CustomFieldSelect:
+		if len(definition.Options) == 0 {
+			return models.ErrCustomFieldOptions
+		}
+		seen := make(map[string]bool, len(definition.Options))
+		for _, option := range definition.Options {
+			if option == "" || seen[option] {
+				return models.ErrCustomFieldOptions
+			}
+			seen[option] = true
+		}
+	default:
+		return models.ErrCustomFieldType
+	}
+
+	if definition.Pattern != "" {
+		if _, err := regexp.Compile(definition.Pattern); err != nil {
+			return models.ErrCustomFieldPattern
+		}
+	}
+
+	if definition.MinValue != nil && definition.MaxValue != nil && *definition.MinValue > *definition.MaxValue {
+		return models.ErrCustomFieldRange
+	}
+
+	return nil
+}
+
+// Validate checks the custom field values of a record against the definitions of its
+// type. Null and empty values are removed, so a field is cleared by leaving it out.
+func Validate(definitions []*models.CustomFieldDefinition, values models.CustomFields) error {
+	byKey := make(map[string]*models.CustomFieldDefinition, len(definitions))
+	for _, definition := range definitions {
+		byKey[definition.Key] = definition
+	}
+
+	for key, value := range values {
+		if _, ok := byKey[key]; !ok {
+			return fieldError(key, "is not defined")
+		}
+		if value == nil || value == "" {
+			delete(values, key)
+		}
+	}
+
+	for _, definition := range definitions {
+		value, ok := values[definition.Key]
+		if !ok {
+			if definition.Required {
+				return fieldError(definition.Key, "is required")
+			}
+			continue
+		}
+
+		if err := validateValue(definition, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateValue checks a value against its definition
+func validateValue(definition *models.CustomFieldDefinition, value interface{}) error {
+	key := definition.Key
+
+	switch definition.FieldType {
+	case models.CustomFieldText:
+		text, ok := value.(string)
+		if !ok {
+			return fieldError(key, "must be text")
+		}
+		if definition.MaxLength > 0 && len([]rune(text)) > definition.MaxLength {
+			return fieldError(key, "must be at most "+strconv.Itoa(definition.MaxLength)+" characters")
+		}
+		if definition.Pattern != "" {
+			pattern, err := regexp.Compile(definition.Pattern)
+			if err != nil || !pattern.MatchString(text) {
+				return fieldError(key, "does not match the required format")
+			}
+		}
+	case models.CustomFieldNumber:
+		number, ok := value.(float64)
+		if !ok {
+			return fieldError(key, "must be a number")
+		}
+		if definition.MinValue != nil && number < *definition.MinValue {
+			return fieldError(key, "must be at least "+formatNumber(*definition.MinValue))
+		}
+		if definition.MaxValue != nil && number > *definition.MaxValue {
+			return fieldError(key, "must be at most "+formatNumber(*definition.MaxValue))
+		}
+	case models.CustomFieldBoolean:
+		if _, ok := value.(bool); !ok {
+			return fieldError(key, "must be true or false")
+		}
+	case models.CustomFieldDate:
+		date, ok := value.(string)
+		if !ok {
+			return fieldError(key, "must be a date in YYYY-MM-DD format")
+		}
+		if _, err := time.Parse(dateLayout, date); err != nil {
+			return fieldError(key, "must be a date in YYYY-MM-DD format")
+		}
+	case models.CustomFieldSelect:
+		option, ok := value.(string)
+		if ok {
+			for _, allowed := range definition.Options {
+				if option == allowed {
+					return nil
+				}
+			}
+		}
+		return fieldError(key, "must be one of "+strings.Join(definition.Options, ", "))
+	}
+
+	return nil
+}
+
+// fieldError is the error for an invalid value of a custom field
+func fieldError(key, problem string) error {
+	return &models.CustomFieldError{Message: "Custom field " + key + " " + problem}
+}
+
+// formatNumber formats a number bound for an error message
+func formatNumber(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// filterPrefix starts the names of query parameters that filter lists by custom field
+const filterPrefix = "cf."
+
+// ParseFilter reads the custom field filter of a list request from query parameters
+// named cf.<key>, such as cf.industry=retail
+func ParseFilter(params url.Values) models.CustomFieldFilter {
+	var filter models.CustomFieldFilter
+	for name, values := range params {
+		if !strings.HasPrefix(name, filterPrefix) || len(values) == 0 {
+			continue
+		}
+		if filter == nil {
+			filter = models.CustomFieldFilter{}
+		}
+		filter[strings.TrimPrefix(name, filterPrefix)] = values[0]
+	}
+	return filter
+}
\ No newline at end of file
@@ -175,7 +175,7 @@ func NewJournalEntryRepository(db *DB) *JournalEntryRepository {
 }
 
 // Create creates a new journal entry
-func (r *JournalEntryRepository) Create(entry *models.JournalEntry) error {
+func (r *JournalEntryRepository) Create(entry *models.JournalEntry) (err error) {
 	tx, err := r.db.Begin()
 	if err != nil {
 		return err
@@ -188,6 +188,11 @@ func (r *JournalEntryRepository) Create(entry *models.JournalEntry) error {
 		err = tx.Commit()
 	}()
 
+	return insertJournalEntry(tx, entry)
+}
+
+// insertJournalEntry inserts a journal entry and its lines within tx
+func insertJournalEntry(tx *sql.Tx, entry *models.JournalEntry) error {
 	// Insert journal entry
 	query := `
 		INSERT INTO journal_entries (tenant_id, entry_date, reference, description, created_by)
@@ -195,7 +200,7 @@ func (r *JournalEntryRepository) Create(entry *models.JournalEntry) error {
 		RETURNING id, created_at, updated_at
 	`
 
-	err = tx.QueryRow(
+	err := tx.QueryRow(
 		query,
 		entry.TenantID,
 		entry.EntryDate,
@@ -214,6 +219,7 @@ func (r *JournalEntryRepository) Create(entry *models.JournalEntry) error {
 	// Insert journal entry lines
 	for i := range entry.Lines {
 		line := &entry.Lines[i]
+		line.TenantID = entry.TenantID
 		line.JournalEntryID = entry.ID
 
 		query := `
@@ -0,0 +1,171 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/yookibooki/erp/internal/models"
+)
+
+const productBarcodeColumns = `id, tenant_id, product_id, code, type, COALESCE(unit_id::text, ''), created_at, updated_at`
+
+// scanProductBarcode scans a row selected with productBarcodeColumns
+func scanProductBarcode(row rowScanner) (*models.ProductBarcode, error) {
+	barcode := &models.ProductBarcode{}
+	err := row.Scan(
+		&barcode.ID,
+		&barcode.TenantID,
+		&barcode.ProductID,
+		&barcode.Code,
+		&barcode.Type,
+		&barcode.UnitID,
+		&barcode.CreatedAt,
+		&barcode.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return barcode, nil
+}
+
+// BarcodeRepository implements the BarcodeService interface
+type BarcodeRepository struct {
+	db *DB
+}
+
+// NewBarcodeRepository creates a new barcode repository
+func NewBarcodeRepository(db *DB) *BarcodeRepository {
+	return &BarcodeRepository{db: db}
+}
+
+// Create creates a new product barcode
+func (r *BarcodeRepository) Create(barcode *models.ProductBarcode) error {
+	query := `
+		INSERT INTO product_barcodes (tenant_id, product_id, code, type, unit_id)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at
+	`
+
+	return r.db.QueryRow(
+		query,
+		barcode.TenantID,
+		barcode.ProductID,
+		barcode.Code,
+		barcode.Type,
+		nullString(barcode.UnitID),
+	).Scan(
+		&barcode.ID,
+		&barcode.CreatedAt,
+		&barcode.UpdatedAt,
+	)
+}
+
+// GetByID gets a product barcode by ID
+func (r *BarcodeRepository) GetByID(tenantID, id string) (*models.ProductBarcode, error) {
+	query := `
+		SELECT ` + productBarcodeColumns + `
+		FROM product_barcodes
+		WHERE tenant_id = $1 AND id = $2
+	`
+
+	barcode, err := scanProductBarcode(r.db.QueryRow(query, tenantID, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return barcode, err
+}
+
+// GetByCode gets a product barcode by its exact code
+func (r *BarcodeRepository) GetByCode(tenantID, code string) (*models.ProductBarcode, error) {
+	query := `
+		SELECT ` + productBarcodeColumns + `
+		FROM product_barcodes
+		WHERE tenant_id = $1 AND code = $2
+	`
+
+	barcode, err := scanProductBarcode(r.db.QueryRow(query, tenantID, code))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return barcode, err
+}
+
+// GetByGTIN gets the EAN-13, EAN-8, UPC-A or GTIN-14 barcode with a GTIN, comparing
+// GTINs padded with leading zeros to 14 digits
+func (r *BarcodeRepository) GetByGTIN(tenantID, gtin string) (*models.ProductBarcode, error) {
+	query := `
+		SELECT ` + productBarcodeColumns + `
+		FROM product_barcodes
+		WHERE tenant_id = $1 AND type <> 'internal' AND LPAD(code, 14, '0') = LPAD($2, 14, '0')
+		LIMIT 1
+	`
+
+	barcode, err := scanProductBarcode(r.db.QueryRow(query, tenantID, gtin))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return barcode, err
+}
+
+// ListByProduct lists the barcodes of a product
+func (r *BarcodeRepository) ListByProduct(tenantID, productID string) ([]*models.ProductBarcode, error) {
+	query := `
+		SELECT ` + productBarcodeColumns + `
+		FROM product_barcodes
+		WHERE tenant_id = $1 AND product_id = $2
+		ORDER BY created_at
+	`
+
+	rows, err := r.db.Query(query, tenantID, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	barcodes := []*models.ProductBarcode{}
+	for rows.Next() {
+		barcode, err := scanProductBarcode(rows)
+		if err != nil {
+			return nil, err
+		}
+		barcodes = append(barcodes, barcode)
+	}
+
+	return barcodes, nil
+}
+
+// Update updates a product barcode
+func (r *BarcodeRepository) Update(barcode *models.ProductBarcode) error {
+	query := `
+		UPDATE product_barcodes
+		SET code = $1, type = $2, unit_id = $3, updated_at = $4
+		WHERE tenant_id = $5 AND id = $6
+	`
+
+	now := time.Now()
+	_, err := r.db.Exec(
+		query,
+		barcode.Code,
+		barcode.Type,
+		nullString(barcode.UnitID),
+		now,
+		barcode.TenantID,
+		barcode.ID,
+	)
+	barcode.UpdatedAt = now
+	return err
+}
+
+// Delete deletes a product barcode
+func (r *BarcodeRepository) Delete(tenantID, id string) error {
+	query := `
+		DELETE FROM product_barcodes
+		WHERE tenant_id = $1 AND id = $2
+	`
+
+	_, err := r.db.Exec(query, tenantID, id)
+	return err
+}
\ No newline at end of file
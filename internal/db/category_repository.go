@@ -0,0 +1,390 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/yookibooki/erp/internal/models"
+)
+
+// inheritedDefaultsSQL selects as a JSON object the defaults inherited from the category
+// categoryID and its ancestors: each from the nearest category that sets it, and the
+// costing method of tenant tenantID when none does. Both are SQL expressions, usually
+// columns of the enclosing query.
+func inheritedDefaultsSQL(categoryID, tenantID string) string {
+	nearest := func(column string) string {
+		return `(SELECT ` + column + ` FROM chain WHERE ` + column + ` IS NOT NULL ORDER BY depth LIMIT 1)`
+	}
+
+	return `(WITH RECURSIVE chain AS (
+			SELECT c.parent_id, c.inventory_account_id, c.cogs_account_id, c.tax_code, c.costing_method, c.unit_id, 0 AS depth
+			FROM product_categories c
+			WHERE c.id = ` + categoryID + `
+			UNION ALL
+			SELECT c.parent_id, c.inventory_account_id, c.cogs_account_id, c.tax_code, c.costing_method, c.unit_id, chain.depth + 1
+			FROM product_categories c
+			JOIN chain ON c.id = chain.parent_id
+			WHERE chain.depth < 100
+		)
+		SELECT json_build_object(
+			'inventory_account_id', ` + nearest("inventory_account_id") + `,
+			'cogs_account_id', ` + nearest("cogs_account_id") + `,
+			'tax_code', ` + nearest("tax_code") + `,
+			'costing_method', COALESCE(` + nearest("costing_method") + `,
+				(SELECT t.costing_method FROM tenants t WHERE t.id = ` + tenantID + `)),
+			'unit_id', ` + nearest("unit_id") + `
+		))`
+}
+
+// categorySubtreeSQL selects into subtree the IDs of category $2 of tenant $1 and of
+// all its subcategories
+const categorySubtreeSQL = `
+	WITH RECURSIVE subtree AS (
+		SELECT id FROM product_categories WHERE tenant_id = $1 AND id = $2
+		UNION
+		SELECT c.id FROM product_categories c JOIN subtree s ON c.parent_id = s.id
+	)
+`
+
+// overrideDefaults returns the defaults a product inherits overridden by those it sets
+// itself. The unit in effect is the product's base unit when it has one.
+func overrideDefaults(defaults models.ProductDefaults, product *models.Product) models.ProductDefaults {
+	if product.InventoryAccountID != "" {
+		defaults.InventoryAccountID = product.InventoryAccountID
+	}
+	if product.COGSAccountID != "" {
+		defaults.COGSAccountID = product.COGSAccountID
+	}
+	if product.TaxCode != "" {
+		defaults.TaxCode = product.TaxCode
+	}
+	if product.CostingMethod != "" {
+		defaults.CostingMethod = product.CostingMethod
+	}
+	if product.BaseUnitID != "" {
+		defaults.UnitID = product.BaseUnitID
+	}
+	return defaults
+}
+
+// loadProductDefaults loads the defaults in effect for a stored product
+func loadProductDefaults(q rowQueryer, product *models.Product) error {
+	var defaults []byte
+	err := q.QueryRow(
+		`SELECT `+inheritedDefaultsSQL("products.category_id", "products.tenant_id")+`
+		FROM products WHERE tenant_id = $1 AND id = $2`,
+		product.TenantID,
+		product.ID,
+	).Scan(&defaults)
+	if err != nil {
+		return err
+	}
+
+	product.Defaults = models.ProductDefaults{}
+	if err := json.Unmarshal(defaults, &product.Defaults); err != nil {
+		return err
+	}
+	product.Defaults = overrideDefaults(product.Defaults, product)
+	return nil
+}
+
+var productCategoryColumns = `id, tenant_id, COALESCE(parent_id::text, ''), code, name, description,
+	COALESCE(inventory_account_id::text, ''), COALESCE(cogs_account_id::text, ''), COALESCE(tax_code, ''),
+	COALESCE(costing_method, ''), COALESCE(unit_id::text, ''),
+	` + inheritedDefaultsSQL("product_categories.id", "product_categories.tenant_id") + `,
+	created_at, updated_at`
+
+// scanProductCategory scans a row selected with productCategoryColumns
+func scanProductCategory(row rowScanner) (*models.ProductCategory, error) {
+	category := &models.ProductCategory{}
+	var defaults []byte
+	err := row.Scan(
+		&category.ID,
+		&category.TenantID,
+		&category.ParentID,
+		&category.Code,
+		&category.Name,
+		&category.Description,
+		&category.InventoryAccountID,
+		&category.COGSAccountID,
+		&category.TaxCode,
+		&category.CostingMethod,
+		&category.UnitID,
+		&defaults,
+		&category.CreatedAt,
+		&category.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(defaults, &category.Defaults); err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+// ProductCategoryRepository implements the ProductCategoryService interface
+type ProductCategoryRepository struct {
+	db *DB
+}
+
+// NewProductCategoryRepository creates a new product category repository
+func NewProductCategoryRepository(db *DB) *ProductCategoryRepository {
+	return &ProductCategoryRepository{db: db}
+}
+
+// Create creates a new product category
+func (r *ProductCategoryRepository) Create(category *models.ProductCategory) error {
+	query := `
+		INSERT INTO product_categories (tenant_id, parent_id, code, name, description, inventory_account_id,
+			cogs_account_id, tax_code, costing_method, unit_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRow(
+		query,
+		category.TenantID,
+		nullString(category.ParentID),
+		category.Code,
+		category.Name,
+		category.Description,
+		nullString(category.InventoryAccountID),
+		nullString(category.COGSAccountID),
+		nullString(category.TaxCode),
+		nullString(category.CostingMethod),
+		nullString(category.UnitID),
+	).Scan(
+		&category.ID,
+		&category.CreatedAt,
+		&category.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	return r.loadDefaults(category)
+}
+
+// loadDefaults loads the defaults in effect for a stored category
+func (r *ProductCategoryRepository) loadDefaults(category *models.ProductCategory) error {
+	var defaults []byte
+	err := r.db.QueryRow(
+		`SELECT `+inheritedDefaultsSQL("product_categories.id", "product_categories.tenant_id")+`
+		FROM product_categories WHERE tenant_id = $1 AND id = $2`,
+		category.TenantID,
+		category.ID,
+	).Scan(&defaults)
+	if err != nil {
+		return err
+	}
+
+	category.Defaults = models.ProductDefaults{}
+	return json.Unmarshal(defaults, &category.Defaults)
+}
+
+// GetByID gets a product category by ID
+func (r *ProductCategoryRepository) GetByID(tenantID, id string) (*models.ProductCategory, error) {
+	query := `
+		SELECT ` + productCategoryColumns + `
+		FROM product_categories
+		WHERE tenant_id = $1 AND id = $2
+	`
+
+	category, err := scanProductCategory(r.db.QueryRow(query, tenantID, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return category, err
+}
+
+// GetByCode gets a product category by code
+func (r *ProductCategoryRepository) GetByCode(tenantID, code string) (*models.ProductCategory, error) {
+	query := `
+		SELECT ` + productCategoryColumns + `
+		FROM product_categories
+		WHERE tenant_id = $1 AND code = $2
+	`
+
+	category, err := scanProductCategory(r.db.QueryRow(query, tenantID, code))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return category, err
+}
+
+// List lists all product categories for a tenant
+func (r *ProductCategoryRepository) List(tenantID string) ([]*models.ProductCategory, error) {
+	query := `
+		SELECT ` + productCategoryColumns + `
+		FROM product_categories
+		WHERE tenant_id = $1
+		ORDER BY code
+	`
+
+	rows, err := r.db.Query(query, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories := []*models.ProductCategory{}
+	for rows.Next() {
+		category, err := scanProductCategory(rows)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, category)
+	}
+
+	return categories, nil
+}
+
+// Update updates a product category. A category cannot move under itself or one of
+// its subcategories.
+func (r *ProductCategoryRepository) Update(category *models.ProductCategory) error {
+	if category.ParentID != "" {
+		var cycle bool
+		err := r.db.QueryRow(
+			categorySubtreeSQL+`SELECT EXISTS (SELECT 1 FROM subtree WHERE id = $3)`,
+			category.TenantID,
+			category.ID,
+			category.ParentID,
+		).Scan(&cycle)
+		if err != nil {
+			return err
+		}
+		if cycle {
+			return models.ErrCategoryCycle
+		}
+	}
+
+	query := `
+		UPDATE product_categories
+		SET parent_id = $1, code = $2, name = $3, description = $4, inventory_account_id = $5, cogs_account_id = $6,
+			tax_code = $7, costing_method = $8, unit_id = $9, updated_at = $10
+		WHERE tenant_id = $11 AND id = $12
+	`
+
+	now := time.Now()
+	_, err := r.db.Exec(
+		query,
+		nullString(category.ParentID),
+		category.Code,
+		category.Name,
+		category.Description,
+		nullString(category.InventoryAccountID),
+		nullString(category.COGSAccountID),
+		nullString(category.TaxCode),
+		nullString(category.CostingMethod),
+		nullString(category.UnitID),
+		now,
+		category.TenantID,
+		category.ID,
+	)
+	if err != nil {
+		return err
+	}
+	category.UpdatedAt = now
+
+	return r.loadDefaults(category)
+}
+
+// Delete deletes a product category without subcategories. Its products are left
+// without a category.
+func (r *ProductCategoryRepository) Delete(tenantID, id string) error {
+	var hasChildren bool
+	err := r.db.QueryRow(
+		`SELECT EXISTS (SELECT 1 FROM product_categories WHERE tenant_id = $1 AND parent_id = $2)`,
+		tenantID,
+		id,
+	).Scan(&hasChildren)
+	if err != nil {
+		return err
+	}
+	if hasChildren {
+		return models.ErrCategoryHasChildren
+	}
+
+	query := `
+		DELETE FROM product_categories
+		WHERE tenant_id = $1 AND id = $2
+	`
+
+	_, err = r.db.Exec(query, tenantID, id)
+	return err
+}
+
+// StockByCategory values stock on hand as of a point in time per category from the
+// costed transactions and rolls the totals up the category tree
+func (r *ProductCategoryRepository) StockByCategory(tenantID string, asOf time.Time) (*models.CategoryStockReport, error) {
+	query := `
+		WITH stock AS (
+			SELECT p.category_id, SUM(` + stockChangeSQL + `) AS quantity, SUM(t.total_cost) AS value
+			FROM products p
+			JOIN inventory_transactions t ON t.tenant_id = p.tenant_id AND t.product_id = p.id
+			WHERE p.tenant_id = $1 AND t.created_at <= $2
+			GROUP BY p.category_id
+		)
+		SELECT c.id::text, c.code, c.name, COALESCE(c.parent_id::text, ''), COALESCE(s.quantity, 0), COALESCE(s.value, 0)
+		FROM product_categories c
+		LEFT JOIN stock s ON s.category_id = c.id
+		WHERE c.tenant_id = $1
+		UNION ALL
+		SELECT '', '', '', '', s.quantity, s.value
+		FROM stock s
+		WHERE s.category_id IS NULL
+		ORDER BY 2
+	`
+
+	rows, err := r.db.Query(query, tenantID, asOf)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	report := &models.CategoryStockReport{
+		AsOf:  asOf,
+		Lines: []*models.CategoryStock{},
+	}
+	categories := map[string]*models.CategoryStock{}
+	for rows.Next() {
+		line := &models.CategoryStock{}
+		err := rows.Scan(
+			&line.CategoryID,
+			&line.CategoryCode,
+			&line.CategoryName,
+			&line.ParentID,
+			&line.Quantity,
+			&line.Value,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		line.Quantity = roundQuantity(line.Quantity)
+		report.TotalValue += line.Value
+		report.Lines = append(report.Lines, line)
+		if line.CategoryID != "" {
+			categories[line.CategoryID] = line
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Add the stock of each category to its own totals and those of its ancestors
+	for _, line := range report.Lines {
+		seen := map[string]bool{}
+		for ancestor := line; ancestor != nil && !seen[ancestor.CategoryID]; ancestor = categories[ancestor.ParentID] {
+			seen[ancestor.CategoryID] = true
+			ancestor.TotalQuantity = roundQuantity(ancestor.TotalQuantity + line.Quantity)
+			ancestor.TotalValue += line.Value
+		}
+	}
+
+	return report, nil
+}
\ No newline at end of file
@@ -0,0 +1,164 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/yookibooki/erp/internal/models"
+)
+
+// movingAverageCost returns the average cost of a product after receiving quantity at unitCost
+func movingAverageCost(state *productState, quantity float64, unitCost float64) float64 {
+	onHand := state.stockQuantity
+	if onHand < 0 {
+		onHand = 0
+	}
+
+	total := onHand + quantity
+	if total <= 0 {
+		return unitCost
+	}
+
+	return (onHand*state.averageCost + quantity*unitCost) / total
+}
+
+// addCostLayer records the quantity received by a transaction as a FIFO cost layer within tx
+func addCostLayer(tx *sql.Tx, transaction *models.InventoryTransaction, quantity float64) error {
+	query := `
+		INSERT INTO cost_layers (tenant_id, product_id, transaction_id, quantity, remaining_quantity, unit_cost)
+		VALUES ($1, $2, $3, $4, $4, $5)
+	`
+
+	_, err := tx.Exec(
+		query,
+		transaction.TenantID,
+		transaction.ProductID,
+		transaction.ID,
+		quantity,
+		transaction.UnitCost,
+	)
+	return err
+}
+
+// costLayer is a quantity received at a unit cost that has not been issued yet
+type costLayer struct {
+	id        string
+	remaining float64
+	unitCost  float64
+}
+
+// issueCost returns the cost of issuing quantity of a product within tx.
+// Under FIFO the oldest cost layers are consumed first; quantities not
+// covered by a layer, such as opening stock, are costed at the average cost.
+func issueCost(tx *sql.Tx, transaction *models.InventoryTransaction, state *productState, quantity float64) (float64, error) {
+	if state.costingMethod != models.CostingFIFO {
+		return quantity * state.averageCost, nil
+	}
+
+	query := `
+		SELECT id, remaining_quantity, unit_cost
+		FROM cost_layers
+		WHERE tenant_id = $1 AND product_id = $2 AND remaining_quantity > 0
+		ORDER BY created_at, id
+		FOR UPDATE
+	`
+
+	rows, err := tx.Query(query, transaction.TenantID, transaction.ProductID)
+	if err != nil {
+		return 0, err
+	}
+
+	layers := []costLayer{}
+	for rows.Next() {
+		layer := costLayer{}
+		if err := rows.Scan(&layer.id, &layer.remaining, &layer.unitCost); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		layers = append(layers, layer)
+	}
+	rows.Close()
+
+	cost := 0.0
+	remaining := quantity
+	for _, layer := range layers {
+		if remaining <= 0 {
+			break
+		}
+
+		consumed := layer.remaining
+		if consumed > remaining {
+			consumed = remaining
+		}
+
+		_, err := tx.Exec(
+			`UPDATE cost_layers SET remaining_quantity = remaining_quantity - $1 WHERE tenant_id = $2 AND id = $3`,
+			consumed,
+			transaction.TenantID,
+			layer.id,
+		)
+		if err != nil {
+			return 0, err
+		}
+
+		cost += consumed * layer.unitCost
+		remaining = roundQuantity(remaining - consumed)
+	}
+
+	return cost + remaining*state.averageCost, nil
+}
+
+// StockValuationRepository implements the StockValuationService interface
+type StockValuationRepository struct {
+	db *DB
+}
+
+// NewStockValuationRepository creates a new stock valuation repository
+func NewStockValuationRepository(db *DB) *StockValuationRepository {
+	return &StockValuationRepository{db: db}
+}
+
+// Valuation values stock on hand as of a point in time from the costed transactions
+func (r *StockValuationRepository) Valuation(tenantID string, asOf time.Time) (*models.StockValuation, error) {
+	query := `
+		SELECT p.id, p.code, p.name, SUM(` + stockChangeSQL + `), SUM(t.total_cost)
+		FROM products p
+		JOIN inventory_transactions t ON t.tenant_id = p.tenant_id AND t.product_id = p.id
+		WHERE p.tenant_id = $1 AND t.created_at <= $2
+		GROUP BY p.id, p.code, p.name
+		HAVING SUM(` + stockChangeSQL + `) <> 0 OR SUM(t.total_cost) <> 0
+		ORDER BY p.code
+	`
+
+	rows, err := r.db.Query(query, tenantID, asOf)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	valuation := &models.StockValuation{
+		AsOf:  asOf,
+		Lines: []*models.StockValuationLine{},
+	}
+	for rows.Next() {
+		line := &models.StockValuationLine{}
+		err := rows.Scan(
+			&line.ProductID,
+			&line.ProductCode,
+			&line.ProductName,
+			&line.Quantity,
+			&line.Value,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if line.Quantity != 0 {
+			line.UnitCost = line.Value / line.Quantity
+		}
+		valuation.TotalValue += line.Value
+		valuation.Lines = append(valuation.Lines, line)
+	}
+
+	return valuation, nil
+}
\ No newline at end of file
@@ -4,9 +4,52 @@ import (
 	"database/sql"
 	"time"
 
+	"github.com/lib/pq"
 	"github.com/yookibooki/erp/internal/models"
 )
 
+// tagsOrEmpty returns the tags of a record, or no tags when they are not set, for a
+// NOT NULL array column
+func tagsOrEmpty(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+	return tags
+}
+
+const customerColumns = `id, tenant_id, COALESCE(customer_group_id::text, ''), name, email, phone, address, tags,
+	custom_fields, created_at, updated_at`
+
+// scanCustomer scans a row selected with customerColumns
+func scanCustomer(row rowScanner) (*models.Customer, error) {
+	customer := &models.Customer{}
+	var customFields []byte
+	err := row.Scan(
+		&customer.ID,
+		&customer.TenantID,
+		&customer.GroupID,
+		&customer.Name,
+		&customer.Email,
+		&customer.Phone,
+		&customer.Address,
+		pq.Array(&customer.Tags),
+		&customFields,
+		&customer.CreatedAt,
+		&customer.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if customer.Tags == nil {
+		customer.Tags = []string{}
+	}
+	customer.CustomFields, err = unmarshalCustomFields(customFields)
+	if err != nil {
+		return nil, err
+	}
+	return customer, nil
+}
+
 // CustomerRepository implements the CustomerService interface
 type CustomerRepository struct {
 	db *DB
@@ -20,18 +63,26 @@ func NewCustomerRepository(db *DB) *CustomerRepository {
 // Create creates a new customer
 func (r *CustomerRepository) Create(customer *models.Customer) error {
 	query := `
-		INSERT INTO customers (tenant_id, name, email, phone, address)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO customers (tenant_id, customer_group_id, name, email, phone, address, tags, custom_fields)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		RETURNING id, created_at, updated_at
 	`
 
+	customFields, err := marshalCustomFields(customer.CustomFields)
+	if err != nil {
+		return err
+	}
+
 	return r.db.QueryRow(
 		query,
 		customer.TenantID,
+		nullString(customer.GroupID),
 		customer.Name,
 		customer.Email,
 		customer.Phone,
 		customer.Address,
+		pq.Array(tagsOrEmpty(customer.Tags)),
+		customFields,
 	).Scan(
 		&customer.ID,
 		&customer.CreatedAt,
@@ -42,23 +93,12 @@ func (r *CustomerRepository) Create(customer *models.Customer) error {
 // GetByID gets a customer by ID
 func (r *CustomerRepository) GetByID(tenantID, id string) (*models.Customer, error) {
 	query := `
-		SELECT id, tenant_id, name, email, phone, address, created_at, updated_at
+		SELECT ` + customerColumns + `
 		FROM customers
 		WHERE tenant_id = $1 AND id = $2
 	`
 
-	customer := &models.Customer{}
-	err := r.db.QueryRow(query, tenantID, id).Scan(
-		&customer.ID,
-		&customer.TenantID,
-		&customer.Name,
-		&customer.Email,
-		&customer.Phone,
-		&customer.Address,
-		&customer.CreatedAt,
-		&customer.UpdatedAt,
-	)
-
+	customer, err := scanCustomer(r.db.QueryRow(query, tenantID, id))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -66,16 +106,22 @@ func (r *CustomerRepository) GetByID(tenantID, id string) (*models.Customer, err
 	return customer, err
 }
 
-// List lists all customers for a tenant
-func (r *CustomerRepository) List(tenantID string) ([]*models.Customer, error) {
+// List lists the customers of a tenant, optionally only those with a tag and the custom
+// field values of a filter
+func (r *CustomerRepository) List(tenantID string, filter models.CustomerFilter) ([]*models.Customer, error) {
 	query := `
-		SELECT id, tenant_id, name, email, phone, address, created_at, updated_at
+		SELECT ` + customerColumns + `
 		FROM customers
-		WHERE tenant_id = $1
+		WHERE tenant_id = $1 AND ($2 = '' OR tags @> ARRAY[$2::text]) AND ` + customFieldFilterSQL("$3") + `
 		ORDER BY name
 	`
 
-	rows, err := r.db.Query(query, tenantID)
+	customFields, err := marshalCustomFieldFilter(filter.CustomFields)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(query, tenantID, filter.Tag, customFields)
 	if err != nil {
 		return nil, err
 	}
@@ -83,41 +129,40 @@ func (r *CustomerRepository) List(tenantID string) ([]*models.Customer, error) {
 
 	customers := []*models.Customer{}
 	for rows.Next() {
-		customer := &models.Customer{}
-		err := rows.Scan(
-			&customer.ID,
-			&customer.TenantID,
-			&customer.Name,
-			&customer.Email,
-			&customer.Phone,
-			&customer.Address,
-			&customer.CreatedAt,
-			&customer.UpdatedAt,
-		)
+		customer, err := scanCustomer(rows)
 		if err != nil {
 			return nil, err
 		}
 		customers = append(customers, customer)
 	}
 
-	return customers, nil
+	return customers, rows.Err()
 }
 
 // Update updates a customer
 func (r *CustomerRepository) Update(customer *models.Customer) error {
 	query := `
 		UPDATE customers
-		SET name = $1, email = $2, phone = $3, address = $4, updated_at = $5
-		WHERE tenant_id = $6 AND id = $7
+		SET customer_group_id = $1, name = $2, email = $3, phone = $4, address = $5, tags = $6, custom_fields = $7,
+			updated_at = $8
+		WHERE tenant_id = $9 AND id = $10
 	`
 
+	customFields, err := marshalCustomFields(customer.CustomFields)
+	if err != nil {
+		return err
+	}
+
 	now := time.Now()
-	_, err := r.db.Exec(
+	_, err = r.db.Exec(
 		query,
+		nullString(customer.GroupID),
 		customer.Name,
 		customer.Email,
 		customer.Phone,
 		customer.Address,
+		pq.Array(tagsOrEmpty(customer.Tags)),
+		customFields,
 		now,
 		customer.TenantID,
 		customer.ID,
@@ -137,6 +182,180 @@ func (r *CustomerRepository) Delete(tenantID, id string) error {
 	return err
 }
 
+const customerGroupColumns = `id, tenant_id, name, description, created_at, updated_at`
+
+// scanCustomerGroup scans a row selected with customerGroupColumns
+func scanCustomerGroup(row rowScanner) (*models.CustomerGroup, error) {
+	group := &models.CustomerGroup{}
+	err := row.Scan(
+		&group.ID,
+		&group.TenantID,
+		&group.Name,
+		&group.Description,
+		&group.CreatedAt,
+		&group.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+// CustomerGroupRepository implements the CustomerGroupService interface
+type CustomerGroupRepository struct {
+	db *DB
+}
+
+// NewCustomerGroupRepository creates a new customer group repository
+func NewCustomerGroupRepository(db *DB) *CustomerGroupRepository {
+	return &CustomerGroupRepository{db: db}
+}
+
+// Create creates a new customer group
+func (r *CustomerGroupRepository) Create(group *models.CustomerGroup) error {
+	query := `
+		INSERT INTO customer_groups (tenant_id, name, description)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at, updated_at
+	`
+
+	return r.db.QueryRow(
+		query,
+		group.TenantID,
+		group.Name,
+		group.Description,
+	).Scan(
+		&group.ID,
+		&group.CreatedAt,
+		&group.UpdatedAt,
+	)
+}
+
+// GetByID gets a customer group by ID
+func (r *CustomerGroupRepository) GetByID(tenantID, id string) (*models.CustomerGroup, error) {
+	query := `
+		SELECT ` + customerGroupColumns + `
+		FROM customer_groups
+		WHERE tenant_id = $1 AND id = $2
+	`
+
+	group, err := scanCustomerGroup(r.db.QueryRow(query, tenantID, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return group, err
+}
+
+// GetByName gets a customer group by name
+func (r *CustomerGroupRepository) GetByName(tenantID, name string) (*models.CustomerGroup, error) {
+	query := `
+		SELECT ` + customerGroupColumns + `
+		FROM customer_groups
+		WHERE tenant_id = $1 AND name = $2
+	`
+
+	group, err := scanCustomerGroup(r.db.QueryRow(query, tenantID, name))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return group, err
+}
+
+// List lists all customer groups for a tenant
+func (r *CustomerGroupRepository) List(tenantID string) ([]*models.CustomerGroup, error) {
+	query := `
+		SELECT ` + customerGroupColumns + `
+		FROM customer_groups
+		WHERE tenant_id = $1
+		ORDER BY name
+	`
+
+	rows, err := r.db.Query(query, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	groups := []*models.CustomerGroup{}
+	for rows.Next() {
+		group, err := scanCustomerGroup(rows)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, group)
+	}
+
+	return groups, rows.Err()
+}
+
+// Update updates a customer group
+func (r *CustomerGroupRepository) Update(group *models.CustomerGroup) error {
+	query := `
+		UPDATE customer_groups
+		SET name = $1, description = $2, updated_at = $3
+		WHERE tenant_id = $4 AND id = $5
+	`
+
+	now := time.Now()
+	_, err := r.db.Exec(
+		query,
+		group.Name,
+		group.Description,
+		now,
+		group.TenantID,
+		group.ID,
+	)
+	group.UpdatedAt = now
+	return err
+}
+
+// Delete deletes a customer group. Its customers are left without a group.
+func (r *CustomerGroupRepository) Delete(tenantID, id string) error {
+	query := `
+		DELETE FROM customer_groups
+		WHERE tenant_id = $1 AND id = $2
+	`
+
+	_, err := r.db.Exec(query, tenantID, id)
+	return err
+}
+
+const contactColumns = `id, tenant_id, customer_id, first_name, last_name, email, phone, position, tags,
+	custom_fields, created_at, updated_at`
+
+// scanContact scans a row selected with contactColumns
+func scanContact(row rowScanner) (*models.Contact, error) {
+	contact := &models.Contact{}
+	var customFields []byte
+	err := row.Scan(
+		&contact.ID,
+		&contact.TenantID,
+		&contact.CustomerID,
+		&contact.FirstName,
+		&contact.LastName,
+		&contact.Email,
+		&contact.Phone,
+		&contact.Position,
+		pq.Array(&contact.Tags),
+		&customFields,
+		&contact.CreatedAt,
+		&contact.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if contact.Tags == nil {
+		contact.Tags = []string{}
+	}
+	contact.CustomFields, err = unmarshalCustomFields(customFields)
+	if err != nil {
+		return nil, err
+	}
+	return contact, nil
+}
+
 // ContactRepository implements the ContactService interface
 type ContactRepository struct {
 	db *DB
@@ -150,11 +369,16 @@ func NewContactRepository(db *DB) *ContactRepository {
 // Create creates a new contact
 func (r *ContactRepository) Create(contact *models.Contact) error {
 	query := `
-		INSERT INTO contacts (tenant_id, customer_id, first_name, last_name, email, phone, position)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO contacts (tenant_id, customer_id, first_name, last_name, email, phone, position, tags, custom_fields)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		RETURNING id, created_at, updated_at
 	`
 
+	customFields, err := marshalCustomFields(contact.CustomFields)
+	if err != nil {
+		return err
+	}
+
 	return r.db.QueryRow(
 		query,
 		contact.TenantID,
@@ -164,6 +388,8 @@ func (r *ContactRepository) Create(contact *models.Contact) error {
 		contact.Email,
 		contact.Phone,
 		contact.Position,
+		pq.Array(tagsOrEmpty(contact.Tags)),
+		customFields,
 	).Scan(
 		&contact.ID,
 		&contact.CreatedAt,
@@ -174,25 +400,12 @@ func (r *ContactRepository) Create(contact *models.Contact) error {
 // GetByID gets a contact by ID
 func (r *ContactRepository) GetByID(tenantID, id string) (*models.Contact, error) {
 	query := `
-		SELECT id, tenant_id, customer_id, first_name, last_name, email, phone, position, created_at, updated_at
+		SELECT ` + contactColumns + `
 		FROM contacts
 		WHERE tenant_id = $1 AND id = $2
 	`
 
-	contact := &models.Contact{}
-	err := r.db.QueryRow(query, tenantID, id).Scan(
-		&contact.ID,
-		&contact.TenantID,
-		&contact.CustomerID,
-		&contact.FirstName,
-		&contact.LastName,
-		&contact.Email,
-		&contact.Phone,
-		&contact.Position,
-		&contact.CreatedAt,
-		&contact.UpdatedAt,
-	)
-
+	contact, err := scanContact(r.db.QueryRow(query, tenantID, id))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -200,16 +413,22 @@ func (r *ContactRepository) GetByID(tenantID, id string) (*models.Contact, error
 	return contact, err
 }
 
-// ListByCustomer lists all contacts for a customer
-func (r *ContactRepository) ListByCustomer(tenantID, customerID string) ([]*models.Contact, error) {
+// ListByCustomer lists the contacts of a customer, optionally only those with a tag and
+// the custom field values of a filter
+func (r *ContactRepository) ListByCustomer(tenantID, customerID string, filter models.ContactFilter) ([]*models.Contact, error) {
 	query := `
-		SELECT id, tenant_id, customer_id, first_name, last_name, email, phone, position, created_at, updated_at
+		SELECT ` + contactColumns + `
 		FROM contacts
-		WHERE tenant_id = $1 AND customer_id = $2
+		WHERE tenant_id = $1 AND customer_id = $2 AND ($3 = '' OR tags @> ARRAY[$3::text]) AND ` + customFieldFilterSQL("$4") + `
 		ORDER BY last_name, first_name
 	`
 
-	rows, err := r.db.Query(query, tenantID, customerID)
+	customFields, err := marshalCustomFieldFilter(filter.CustomFields)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(query, tenantID, customerID, filter.Tag, customFields)
 	if err != nil {
 		return nil, err
 	}
@@ -217,38 +436,32 @@ func (r *ContactRepository) ListByCustomer(tenantID, customerID string) ([]*mode
 
 	contacts := []*models.Contact{}
 	for rows.Next() {
-		contact := &models.Contact{}
-		err := rows.Scan(
-			&contact.ID,
-			&contact.TenantID,
-			&contact.CustomerID,
-			&contact.FirstName,
-			&contact.LastName,
-			&contact.Email,
-			&contact.Phone,
-			&contact.Position,
-			&contact.CreatedAt,
-			&contact.UpdatedAt,
-		)
+		contact, err := scanContact(rows)
 		if err != nil {
 			return nil, err
 		}
 		contacts = append(contacts, contact)
 	}
 
-	return contacts, nil
+	return contacts, rows.Err()
 }
 
 // Update updates a contact
 func (r *ContactRepository) Update(contact *models.Contact) error {
 	query := `
 		UPDATE contacts
-		SET customer_id = $1, first_name = $2, last_name = $3, email = $4, phone = $5, position = $6, updated_at = $7
-		WHERE tenant_id = $8 AND id = $9
+		SET customer_id = $1, first_name = $2, last_name = $3, email = $4, phone = $5, position = $6, tags = $7,
+			custom_fields = $8, updated_at = $9
+		WHERE tenant_id = $10 AND id = $11
 	`
 
+	customFields, err := marshalCustomFields(contact.CustomFields)
+	if err != nil {
+		return err
+	}
+
 	now := time.Now()
-	_, err := r.db.Exec(
+	_, err = r.db.Exec(
 		query,
 		contact.CustomerID,
 		contact.FirstName,
@@ -256,6 +469,8 @@ func (r *ContactRepository) Update(contact *models.Contact) error {
 		contact.Email,
 		contact.Phone,
 		contact.Position,
+		pq.Array(tagsOrEmpty(contact.Tags)),
+		customFields,
 		now,
 		contact.TenantID,
 		contact.ID,
@@ -275,6 +490,36 @@ func (r *ContactRepository) Delete(tenantID, id string) error {
 	return err
 }
 
+const interactionColumns = `id, tenant_id, customer_id, COALESCE(contact_id::text, ''), interaction_type, description,
+	interaction_date, custom_fields, created_by, created_at, updated_at`
+
+// scanInteraction scans a row selected with interactionColumns
+func scanInteraction(row rowScanner) (*models.Interaction, error) {
+	interaction := &models.Interaction{}
+	var customFields []byte
+	err := row.Scan(
+		&interaction.ID,
+		&interaction.TenantID,
+		&interaction.CustomerID,
+		&interaction.ContactID,
+		&interaction.InteractionType,
+		&interaction.Description,
+		&interaction.InteractionDate,
+		&customFields,
+		&interaction.CreatedBy,
+		&interaction.CreatedAt,
+		&interaction.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	interaction.CustomFields, err = unmarshalCustomFields(customFields)
+	if err != nil {
+		return nil, err
+	}
+	return interaction, nil
+}
+
 // InteractionRepository implements the InteractionService interface
 type InteractionRepository struct {
 	db *DB
@@ -288,19 +533,26 @@ func NewInteractionRepository(db *DB) *InteractionRepository {
 // Create creates a new interaction
 func (r *InteractionRepository) Create(interaction *models.Interaction) error {
 	query := `
-		INSERT INTO interactions (tenant_id, customer_id, contact_id, interaction_type, description, interaction_date, created_by)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO interactions (tenant_id, customer_id, contact_id, interaction_type, description, interaction_date,
+			custom_fields, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		RETURNING id, created_at, updated_at
 	`
 
+	customFields, err := marshalCustomFields(interaction.CustomFields)
+	if err != nil {
+		return err
+	}
+
 	return r.db.QueryRow(
 		query,
 		interaction.TenantID,
 		interaction.CustomerID,
-		interaction.ContactID,
+		nullString(interaction.ContactID),
 		interaction.InteractionType,
 		interaction.Description,
 		interaction.InteractionDate,
+		customFields,
 		interaction.CreatedBy,
 	).Scan(
 		&interaction.ID,
@@ -312,50 +564,35 @@ func (r *InteractionRepository) Create(interaction *models.Interaction) error {
 // GetByID gets an interaction by ID
 func (r *InteractionRepository) GetByID(tenantID, id string) (*models.Interaction, error) {
 	query := `
-		SELECT id, tenant_id, customer_id, contact_id, interaction_type, description, interaction_date, created_by, created_at, updated_at
+		SELECT ` + interactionColumns + `
 		FROM interactions
 		WHERE tenant_id = $1 AND id = $2
 	`
 
-	interaction := &models.Interaction{}
-	var contactID sql.NullString
-	err := r.db.QueryRow(query, tenantID, id).Scan(
-		&interaction.ID,
-		&interaction.TenantID,
-		&interaction.CustomerID,
-		&contactID,
-		&interaction.InteractionType,
-		&interaction.Description,
-		&interaction.InteractionDate,
-		&interaction.CreatedBy,
-		&interaction.CreatedAt,
-		&interaction.UpdatedAt,
-	)
-
+	interaction, err := scanInteraction(r.db.QueryRow(query, tenantID, id))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
-	if err != nil {
-		return nil, err
-	}
-
-	if contactID.Valid {
-		interaction.ContactID = contactID.String
-	}
 
-	return interaction, nil
+	return interaction, err
 }
 
-// ListByCustomer lists all interactions for a customer
-func (r *InteractionRepository) ListByCustomer(tenantID, customerID string) ([]*models.Interaction, error) {
+// ListByCustomer lists the interactions of a customer, latest first, optionally only
+// those with the custom field values of a filter
+func (r *InteractionRepository) ListByCustomer(tenantID, customerID string, customFields models.CustomFieldFilter) ([]*models.Interaction, error) {
 	query := `
-		SELECT id, tenant_id, customer_id, contact_id, interaction_type, description, interaction_date, created_by, created_at, updated_at
+		SELECT ` + interactionColumns + `
 		FROM interactions
-		WHERE tenant_id = $1 AND customer_id = $2
+		WHERE tenant_id = $1 AND customer_id = $2 AND ` + customFieldFilterSQL("$3") + `
 		ORDER BY interaction_date DESC
 	`
 
-	rows, err := r.db.Query(query, tenantID, customerID)
+	filter, err := marshalCustomFieldFilter(customFields)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(query, tenantID, customerID, filter)
 	if err != nil {
 		return nil, err
 	}
@@ -363,50 +600,39 @@ func (r *InteractionRepository) ListByCustomer(tenantID, customerID string) ([]*
 
 	interactions := []*models.Interaction{}
 	for rows.Next() {
-		interaction := &models.Interaction{}
-		var contactID sql.NullString
-		err := rows.Scan(
-			&interaction.ID,
-			&interaction.TenantID,
-			&interaction.CustomerID,
-			&contactID,
-			&interaction.InteractionType,
-			&interaction.Description,
-			&interaction.InteractionDate,
-			&interaction.CreatedBy,
-			&interaction.CreatedAt,
-			&interaction.UpdatedAt,
-		)
+		interaction, err := scanInteraction(rows)
 		if err != nil {
 			return nil, err
 		}
-
-		if contactID.Valid {
-			interaction.ContactID = contactID.String
-		}
-
 		interactions = append(interactions, interaction)
 	}
 
-	return interactions, nil
+	return interactions, rows.Err()
 }
 
 // Update updates an interaction
 func (r *InteractionRepository) Update(interaction *models.Interaction) error {
 	query := `
 		UPDATE interactions
-		SET customer_id = $1, contact_id = $2, interaction_type = $3, description = $4, interaction_date = $5, updated_at = $6
-		WHERE tenant_id = $7 AND id = $8
+		SET customer_id = $1, contact_id = $2, interaction_type = $3, description = $4, interaction_date = $5,
+			custom_fields = $6, updated_at = $7
+		WHERE tenant_id = $8 AND id = $9
 	`
 
+	customFields, err := marshalCustomFields(interaction.CustomFields)
+	if err != nil {
+		return err
+	}
+
 	now := time.Now()
-	_, err := r.db.Exec(
+	_, err = r.db.Exec(
 		query,
 		interaction.CustomerID,
-		interaction.ContactID,
+		nullString(interaction.ContactID),
 		interaction.InteractionType,
 		interaction.Description,
 		interaction.InteractionDate,
+		customFields,
 		now,
 		interaction.TenantID,
 		interaction.ID,
@@ -0,0 +1,249 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/yookibooki/erp/internal/models"
+)
+
+// customFieldTables are the tables holding the custom field values of each record type
+var customFieldTables = map[string]string{
+	models.CustomFieldCustomer:    "customers",
+	models.CustomFieldContact:     "contacts",
+	models.CustomFieldProduct:     "products",
+	models.CustomFieldInteraction: "interactions",
+}
+
+// customFieldFilterSQL is true for rows whose custom_fields column has every value of
+// the filter given as a JSON object by the parameter
+func customFieldFilterSQL(param string) string {
+	return `NOT EXISTS (SELECT 1 FROM jsonb_each_text(` + param + `::jsonb) f
+		WHERE custom_fields ->> f.key IS DISTINCT FROM f.value)`
+}
+
+// marshalCustomFields encodes custom field values for a JSONB column
+func marshalCustomFields(fields models.CustomFields) (string, error) {
+	if fields == nil {
+		return "{}", nil
+	}
+	data, err := json.Marshal(fields)
+	return string(data), err
+}
+
+// marshalCustomFieldFilter encodes a custom field filter for customFieldFilterSQL
+func marshalCustomFieldFilter(filter models.CustomFieldFilter) (string, error) {
+	if filter == nil {
+		return "{}", nil
+	}
+	data, err := json.Marshal(filter)
+	return string(data), err
+}
+
+// unmarshalCustomFields decodes custom field values read from a JSONB column
+func unmarshalCustomFields(data []byte) (models.CustomFields, error) {
+	fields := models.CustomFields{}
+	if data == nil {
+		return fields, nil
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+const customFieldColumns = `id, tenant_id, entity_type, key, label, field_type, required, options, pattern,
+	min_value, max_value, max_length, position, created_at, updated_at`
+
+// scanCustomField scans a row selected with customFieldColumns
+func scanCustomField(row rowScanner) (*models.CustomFieldDefinition, error) {
+	definition := &models.CustomFieldDefinition{}
+	err := row.Scan(
+		&definition.ID,
+		&definition.TenantID,
+		&definition.EntityType,
+		&definition.Key,
+		&definition.Label,
+		&definition.FieldType,
+		&definition.Required,
+		pq.Array(&definition.Options),
+		&definition.Pattern,
+		&definition.MinValue,
+		&definition.MaxValue,
+		&definition.MaxLength,
+		&definition.Position,
+		&definition.CreatedAt,
+		&definition.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return definition, nil
+}
+
+// CustomFieldRepository implements the CustomFieldService interface
+type CustomFieldRepository struct {
+	db *DB
+}
+
+// NewCustomFieldRepository creates a new custom field repository
+func NewCustomFieldRepository(db *DB) *CustomFieldRepository {
+	return &CustomFieldRepository{db: db}
+}
+
+// Create creates a new custom field definition
+func (r *CustomFieldRepository) Create(definition *models.CustomFieldDefinition) error {
+	query := `
+		INSERT INTO custom_field_definitions (tenant_id, entity_type, key, label, field_type, required, options, pattern,
+			min_value, max_value, max_length, position)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING id, created_at, updated_at
+	`
+
+	return r.db.QueryRow(
+		query,
+		definition.TenantID,
+		definition.EntityType,
+		definition.Key,
+		definition.Label,
+		definition.FieldType,
+		definition.Required,
+		pq.Array(definition.Options),
+		definition.Pattern,
+		definition.MinValue,
+		definition.MaxValue,
+		definition.MaxLength,
+		definition.Position,
+	).Scan(
+		&definition.ID,
+		&definition.CreatedAt,
+		&definition.UpdatedAt,
+	)
+}
+
+// GetByID gets a custom field definition by ID
+func (r *CustomFieldRepository) GetByID(tenantID, id string) (*models.CustomFieldDefinition, error) {
+	query := `
+		SELECT ` + customFieldColumns + `
+		FROM custom_field_definitions
+		WHERE tenant_id = $1 AND id = $2
+	`
+
+	definition, err := scanCustomField(r.db.QueryRow(query, tenantID, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return definition, err
+}
+
+// GetByKey gets the custom field definition of a record type by key
+func (r *CustomFieldRepository) GetByKey(tenantID, entityType, key string) (*models.CustomFieldDefinition, error) {
+	query := `
+		SELECT ` + customFieldColumns + `
+		FROM custom_field_definitions
+		WHERE tenant_id = $1 AND entity_type = $2 AND key = $3
+	`
+
+	definition, err := scanCustomField(r.db.QueryRow(query, tenantID, entityType, key))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return definition, err
+}
+
+// List lists the custom field definitions of a tenant in display order, optionally
+// only those of one record type
+func (r *CustomFieldRepository) List(tenantID, entityType string) ([]*models.CustomFieldDefinition, error) {
+	query := `
+		SELECT ` + customFieldColumns + `
+		FROM custom_field_definitions
+		WHERE tenant_id = $1 AND ($2 = '' OR entity_type = $2)
+		ORDER BY entity_type, position, label
+	`
+
+	rows, err := r.db.Query(query, tenantID, entityType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	definitions := []*models.CustomFieldDefinition{}
+	for rows.Next() {
+		definition, err := scanCustomField(rows)
+		if err != nil {
+			return nil, err
+		}
+		definitions = append(definitions, definition)
+	}
+
+	return definitions, rows.Err()
+}
+
+// Update updates a custom field definition. Its record type and key cannot change.
+func (r *CustomFieldRepository) Update(definition *models.CustomFieldDefinition) error {
+	query := `
+		UPDATE custom_field_definitions
+		SET label = $1, field_type = $2, required = $3, options = $4, pattern = $5, min_value = $6, max_value = $7,
+			max_length = $8, position = $9, updated_at = $10
+		WHERE tenant_id = $11 AND id = $12
+	`
+
+	now := time.Now()
+	_, err := r.db.Exec(
+		query,
+		definition.Label,
+		definition.FieldType,
+		definition.Required,
+		pq.Array(definition.Options),
+		definition.Pattern,
+		definition.MinValue,
+		definition.MaxValue,
+		definition.MaxLength,
+		definition.Position,
+		now,
+		definition.TenantID,
+		definition.ID,
+	)
+	definition.UpdatedAt = now
+	return err
+}
+
+// Delete deletes a custom field definition and removes its values from the records of its type
+func (r *CustomFieldRepository) Delete(tenantID, id string) (err error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	var entityType, key string
+	err = tx.QueryRow(
+		`DELETE FROM custom_field_definitions WHERE tenant_id = $1 AND id = $2 RETURNING entity_type, key`,
+		tenantID,
+		id,
+	).Scan(&entityType, &key)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		`UPDATE `+customFieldTables[entityType]+` SET custom_fields = custom_fields - $1::text
+		WHERE tenant_id = $2 AND custom_fields ? $1::text`,
+		key,
+		tenantID,
+	)
+	return err
+}
\ No newline at end of file
@@ -38,4 +38,9 @@ func New(cfg config.DatabaseConfig) (*DB, error) {
 // Close closes the database connection
 func (db *DB) Close() error {
 	return db.DB.Close()
+}
+
+// nullString converts an empty string to NULL
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
 }
\ No newline at end of file
@@ -0,0 +1,336 @@
+package db
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yookibooki/erp/internal/models"
+)
+
+// customerReferenceTables are the tables whose customer_id a customer merge moves to the survivor
+var customerReferenceTables = []string{
+	"contacts",
+	"interactions",
+	"sales_orders",
+	"return_authorizations",
+	"opportunities",
+	"leads",
+	"tasks",
+}
+
+// contactReferenceTables are the tables whose contact_id a contact merge moves to the survivor
+var contactReferenceTables = []string{
+	"interactions",
+	"opportunities",
+	"leads",
+	"tasks",
+}
+
+// duplicatePairsSQL selects the pairs of records of table that score at least $2. name is the
+// name expression of a record aliased {t}, lowercased to match its trigram index, and
+// customerID its customer. Pairs are limited to records of the same customer when
+// sameCustomer is set.
+//
+// Only pairs matched by an indexed predicate are scored: equal normalized emails, equal
+// normalized phones, or names similar by the pg_trgm % operator, whose threshold the caller
+// sets to the minimum score.
+func duplicatePairsSQL(table, name, customerID string, sameCustomer bool) string {
+	column := func(expression, alias string) string {
+		return strings.ReplaceAll(expression, "{t}", alias)
+	}
+	normEmail := `LOWER(TRIM({t}.email))`
+	normPhone := `REGEXP_REPLACE({t}.phone, '[^0-9]', '', 'g')`
+	normName := `LOWER(` + name + `)`
+
+	join := `JOIN ` + table + ` b ON b.tenant_id = a.tenant_id AND b.id > a.id`
+	if sameCustomer {
+		join += ` AND b.customer_id = a.customer_id`
+	}
+
+	return `
+		WITH candidates AS (
+			SELECT a.id, b.id AS duplicate_id
+			FROM ` + table + ` a
+			` + join + ` AND ` + column(normEmail, "b") + ` = ` + column(normEmail, "a") + `
+			WHERE a.tenant_id = $1 AND ` + column(normEmail, "a") + ` <> ''
+			UNION
+			SELECT a.id, b.id
+			FROM ` + table + ` a
+			` + join + ` AND ` + column(normPhone, "b") + ` = ` + column(normPhone, "a") + `
+			WHERE a.tenant_id = $1 AND LENGTH(` + column(normPhone, "a") + `) >= 7
+			UNION
+			SELECT a.id, b.id
+			FROM ` + table + ` a
+			` + join + ` AND ` + column(normName, "b") + ` % ` + column(normName, "a") + `
+			WHERE a.tenant_id = $1
+		), pairs AS (
+			SELECT a.id, ` + column(name, "a") + ` AS name, a.email, a.phone, b.id AS duplicate_id,
+				` + column(name, "b") + ` AS duplicate_name, b.email AS duplicate_email, b.phone AS duplicate_phone,
+				` + column(customerID, "a") + ` AS customer_id,
+				` + column(normEmail, "a") + ` <> '' AND ` + column(normEmail, "a") + ` = ` + column(normEmail, "b") + ` AS email_match,
+				LENGTH(` + column(normPhone, "a") + `) >= 7 AND ` + column(normPhone, "a") + ` = ` + column(normPhone, "b") + ` AS phone_match,
+				SIMILARITY(` + column(normName, "a") + `, ` + column(normName, "b") + `)::numeric AS name_similarity
+			FROM candidates c
+			JOIN ` + table + ` a ON a.id = c.id
+			JOIN ` + table + ` b ON b.id = c.duplicate_id
+		), scored AS (
+			SELECT *, GREATEST(
+				CASE WHEN email_match THEN 1 ELSE 0 END,
+				CASE WHEN phone_match THEN 0.9 ELSE 0 END,
+				name_similarity
+			) AS score
+			FROM pairs
+		)
+		SELECT id, name, email, phone, duplicate_id, duplicate_name, duplicate_email, duplicate_phone,
+			customer_id, email_match, phone_match, name_similarity >= $2, ROUND(score, 2)
+		FROM scored
+		WHERE score >= $2
+		ORDER BY score DESC, name, duplicate_name
+	`
+}
+
+// DuplicateRepository implements the DuplicateService interface
+type DuplicateRepository struct {
+	db *DB
+}
+
+// NewDuplicateRepository creates a new duplicate repository
+func NewDuplicateRepository(db *DB) *DuplicateRepository {
+	return &DuplicateRepository{db: db}
+}
+
+// FindCustomerDuplicates lists the pairs of customers that may be the same, best match first
+func (r *DuplicateRepository) FindCustomerDuplicates(tenantID string, minScore float64) ([]*models.DuplicatePair, error) {
+	return r.findDuplicates(duplicatePairsSQL("customers", "{t}.name", "''", false), tenantID, minScore)
+}
+
+// FindContactDuplicates lists the pairs of contacts of the same customer that may be the
+// same person, best match first
+func (r *DuplicateRepository) FindContactDuplicates(tenantID string, minScore float64) ([]*models.DuplicatePair, error) {
+	query := duplicatePairsSQL("contacts", "{t}.first_name || ' ' || {t}.last_name", "{t}.customer_id::text", true)
+
+	return r.findDuplicates(query, tenantID, minScore)
+}
+
+// findDuplicates runs a query built by duplicatePairsSQL in a transaction that sets the
+// trigram similarity threshold to the minimum score
+func (r *DuplicateRepository) findDuplicates(query, tenantID string, minScore float64) (pairs []*models.DuplicatePair, err error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	_, err = tx.Exec(
+		`SELECT set_config('pg_trgm.similarity_threshold', $1, true)`,
+		strconv.FormatFloat(minScore, 'f', -1, 64),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(query, tenantID, minScore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	pairs = []*models.DuplicatePair{}
+	for rows.Next() {
+		pair := &models.DuplicatePair{MatchedOn: []string{}}
+		var emailMatch, phoneMatch, nameMatch bool
+		err := rows.Scan(
+			&pair.ID,
+			&pair.Name,
+			&pair.Email,
+			&pair.Phone,
+			&pair.DuplicateID,
+			&pair.DuplicateName,
+			&pair.DuplicateEmail,
+			&pair.DuplicatePhone,
+			&pair.CustomerID,
+			&emailMatch,
+			&phoneMatch,
+			&nameMatch,
+			&pair.Score,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if emailMatch {
+			pair.MatchedOn = append(pair.MatchedOn, models.MatchEmail)
+		}
+		if phoneMatch {
+			pair.MatchedOn = append(pair.MatchedOn, models.MatchPhone)
+		}
+		if nameMatch {
+			pair.MatchedOn = append(pair.MatchedOn, models.MatchName)
+		}
+
+		pairs = append(pairs, pair)
+	}
+
+	return pairs, rows.Err()
+}
+
+// MergeCustomers merges a duplicate customer into the surviving customer. Its contacts,
+// interactions, orders, returns, opportunities, leads, tasks and price lists move to the
+// survivor, whose empty email, phone, address and group and missing custom fields are
+// taken from the duplicate. The survivor keeps the tags of both.
+func (r *DuplicateRepository) MergeCustomers(tenantID, survivorID, duplicateID string) (err error) {
+	if survivorID == duplicateID {
+		return models.ErrMergeSelf
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	// Lock both customers in a fixed order so concurrent merges cannot deadlock
+	_, err = tx.Exec(
+		`SELECT id FROM customers WHERE tenant_id = $1 AND id IN ($2, $3) ORDER BY id FOR UPDATE`,
+		tenantID,
+		survivorID,
+		duplicateID,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		`UPDATE customers s
+		SET email = CASE WHEN s.email = '' THEN d.email ELSE s.email END,
+			phone = CASE WHEN s.phone = '' THEN d.phone ELSE s.phone END,
+			address = CASE WHEN s.address = '' THEN d.address ELSE s.address END,
+			customer_group_id = COALESCE(s.customer_group_id, d.customer_group_id),
+			tags = ARRAY(SELECT DISTINCT t FROM UNNEST(s.tags || d.tags) t ORDER BY t),
+			custom_fields = d.custom_fields || s.custom_fields,
+			updated_at = $1
+		FROM customers d
+		WHERE s.tenant_id = $2 AND s.id = $3 AND d.tenant_id = $2 AND d.id = $4`,
+		time.Now(),
+		tenantID,
+		survivorID,
+		duplicateID,
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, table := range customerReferenceTables {
+		_, err = tx.Exec(
+			`UPDATE `+table+` SET customer_id = $1 WHERE tenant_id = $2 AND customer_id = $3`,
+			survivorID,
+			tenantID,
+			duplicateID,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Price lists of the duplicate apply to the survivor; the duplicate's own rows go with it
+	_, err = tx.Exec(
+		`INSERT INTO price_list_customers (price_list_id, customer_id)
+		SELECT price_list_id, $1 FROM price_list_customers WHERE customer_id = $2
+		ON CONFLICT DO NOTHING`,
+		survivorID,
+		duplicateID,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`DELETE FROM customers WHERE tenant_id = $1 AND id = $2`, tenantID, duplicateID)
+	return err
+}
+
+// MergeContacts merges a duplicate contact into the surviving contact of the same
+// customer. Its interactions, opportunities, leads and tasks move to the survivor, whose
+// empty email, phone and position and missing custom fields are taken from the duplicate.
+// The survivor keeps the tags of both.
+func (r *DuplicateRepository) MergeContacts(tenantID, survivorID, duplicateID string) (err error) {
+	if survivorID == duplicateID {
+		return models.ErrMergeSelf
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	// Lock both contacts in a fixed order so concurrent merges cannot deadlock
+	var customers int
+	err = tx.QueryRow(
+		`SELECT COUNT(DISTINCT customer_id) FROM (
+			SELECT customer_id FROM contacts WHERE tenant_id = $1 AND id IN ($2, $3) ORDER BY id FOR UPDATE
+		) c`,
+		tenantID,
+		survivorID,
+		duplicateID,
+	).Scan(&customers)
+	if err != nil {
+		return err
+	}
+
+	if customers > 1 {
+		return models.ErrMergeContactOwners
+	}
+
+	_, err = tx.Exec(
+		`UPDATE contacts s
+		SET email = CASE WHEN s.email = '' THEN d.email ELSE s.email END,
+			phone = CASE WHEN s.phone = '' THEN d.phone ELSE s.phone END,
+			position = CASE WHEN s.position = '' THEN d.position ELSE s.position END,
+			tags = ARRAY(SELECT DISTINCT t FROM UNNEST(s.tags || d.tags) t ORDER BY t),
+			custom_fields = d.custom_fields || s.custom_fields,
+			updated_at = $1
+		FROM contacts d
+		WHERE s.tenant_id = $2 AND s.id = $3 AND d.tenant_id = $2 AND d.id = $4`,
+		time.Now(),
+		tenantID,
+		survivorID,
+		duplicateID,
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, table := range contactReferenceTables {
+		_, err = tx.Exec(
+			`UPDATE `+table+` SET contact_id = $1 WHERE tenant_id = $2 AND contact_id = $3`,
+			survivorID,
+			tenantID,
+			duplicateID,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(`DELETE FROM contacts WHERE tenant_id = $1 AND id = $2`, tenantID, duplicateID)
+	return err
+}
\ No newline at end of file
@@ -2,11 +2,96 @@ package db
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/yookibooki/erp/internal/models"
 )
 
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// queryer is implemented by *DB and *sql.Tx
+type queryer interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// rowQueryer is implemented by *DB and *sql.Tx
+type rowQueryer interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+var productColumns = `id, tenant_id, code, name, description, unit_price,
+	COALESCE(base_unit_id::text, ''), COALESCE(purchase_unit_id::text, ''), COALESCE(sales_unit_id::text, ''), weight, volume, stock_quantity,
+	(SELECT COALESCE(SUM(r.quantity), 0) FROM stock_reservations r
+		WHERE r.tenant_id = products.tenant_id AND r.product_id = products.id AND ` + activeReservationSQL + `),
+	(SELECT COALESCE(SUM(s.quantity), 0) FROM stock_levels s JOIN locations l ON l.id = s.location_id
+		WHERE s.tenant_id = products.tenant_id AND s.product_id = products.id AND l.quarantine),
+	tracking_mode, average_cost, allow_negative_stock, COALESCE(template_id::text, ''),
+	(SELECT json_object_agg(a.name, v.value) FROM product_variant_values pvv
+		JOIN product_attribute_values v ON v.id = pvv.attribute_value_id
+		JOIN product_attributes a ON a.id = v.attribute_id
+		WHERE pvv.product_id = products.id),
+	COALESCE(category_id::text, ''), COALESCE(inventory_account_id::text, ''), COALESCE(cogs_account_id::text, ''),
+	COALESCE(tax_code, ''), COALESCE(costing_method, ''), ` + inheritedDefaultsSQL("products.category_id", "products.tenant_id") + `,
+	custom_fields, created_at, updated_at`
+
+// scanProduct scans a row selected with productColumns
+func scanProduct(row rowScanner) (*models.Product, error) {
+	product := &models.Product{}
+	var attributes, defaults, customFields []byte
+	err := row.Scan(
+		&product.ID,
+		&product.TenantID,
+		&product.Code,
+		&product.Name,
+		&product.Description,
+		&product.UnitPrice,
+		&product.BaseUnitID,
+		&product.PurchaseUnitID,
+		&product.SalesUnitID,
+		&product.Weight,
+		&product.Volume,
+		&product.StockQuantity,
+		&product.ReservedQuantity,
+		&product.QuarantineQuantity,
+		&product.TrackingMode,
+		&product.AverageCost,
+		&product.AllowNegativeStock,
+		&product.TemplateID,
+		&attributes,
+		&product.CategoryID,
+		&product.InventoryAccountID,
+		&product.COGSAccountID,
+		&product.TaxCode,
+		&product.CostingMethod,
+		&defaults,
+		&customFields,
+		&product.CreatedAt,
+		&product.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if attributes != nil {
+		if err := json.Unmarshal(attributes, &product.Attributes); err != nil {
+			return nil, err
+		}
+	}
+	if err := json.Unmarshal(defaults, &product.Defaults); err != nil {
+		return nil, err
+	}
+	product.CustomFields, err = unmarshalCustomFields(customFields)
+	if err != nil {
+		return nil, err
+	}
+	product.Defaults = overrideDefaults(product.Defaults, product)
+	product.AvailableQuantity = product.StockQuantity - product.ReservedQuantity - product.QuarantineQuantity
+	return product, nil
+}
+
 // ProductRepository implements the ProductService interface
 type ProductRepository struct {
 	db *DB
@@ -19,48 +104,69 @@ func NewProductRepository(db *DB) *ProductRepository {
 
 // Create creates a new product
 func (r *ProductRepository) Create(product *models.Product) error {
+	return insertProduct(r.db, product)
+}
+
+// insertProduct inserts a product, which is a variant when it has a template. New
+// products start without stock, which only comes in through inventory transactions.
+func insertProduct(q rowQueryer, product *models.Product) error {
 	query := `
-		INSERT INTO products (tenant_id, code, name, description, unit_price, stock_quantity)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO products (tenant_id, code, name, description, unit_price, base_unit_id, purchase_unit_id, sales_unit_id,
+			weight, volume, stock_quantity, tracking_mode, allow_negative_stock, template_id, category_id,
+			inventory_account_id, cogs_account_id, tax_code, costing_method, custom_fields)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, 0, $11, $12, $13, $14, $15, $16, $17, $18, $19)
 		RETURNING id, created_at, updated_at
 	`
 
-	return r.db.QueryRow(
+	product.StockQuantity = 0
+
+	customFields, err := marshalCustomFields(product.CustomFields)
+	if err != nil {
+		return err
+	}
+
+	err = q.QueryRow(
 		query,
 		product.TenantID,
 		product.Code,
 		product.Name,
 		product.Description,
 		product.UnitPrice,
-		product.StockQuantity,
+		nullString(product.BaseUnitID),
+		nullString(product.PurchaseUnitID),
+		nullString(product.SalesUnitID),
+		product.Weight,
+		product.Volume,
+		product.TrackingMode,
+		product.AllowNegativeStock,
+		nullString(product.TemplateID),
+		nullString(product.CategoryID),
+		nullString(product.InventoryAccountID),
+		nullString(product.COGSAccountID),
+		nullString(product.TaxCode),
+		nullString(product.CostingMethod),
+		customFields,
 	).Scan(
 		&product.ID,
 		&product.CreatedAt,
 		&product.UpdatedAt,
 	)
+	if err != nil {
+		return err
+	}
+
+	return loadProductDefaults(q, product)
 }
 
 // GetByID gets a product by ID
 func (r *ProductRepository) GetByID(tenantID, id string) (*models.Product, error) {
 	query := `
-		SELECT id, tenant_id, code, name, description, unit_price, stock_quantity, created_at, updated_at
+		SELECT ` + productColumns + `
 		FROM products
 		WHERE tenant_id = $1 AND id = $2
 	`
 
-	product := &models.Product{}
-	err := r.db.QueryRow(query, tenantID, id).Scan(
-		&product.ID,
-		&product.TenantID,
-		&product.Code,
-		&product.Name,
-		&product.Description,
-		&product.UnitPrice,
-		&product.StockQuantity,
-		&product.CreatedAt,
-		&product.UpdatedAt,
-	)
-
+	product, err := scanProduct(r.db.QueryRow(query, tenantID, id))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -71,24 +177,12 @@ func (r *ProductRepository) GetByID(tenantID, id string) (*models.Product, error
 // GetByCode gets a product by code
 func (r *ProductRepository) GetByCode(tenantID, code string) (*models.Product, error) {
 	query := `
-		SELECT id, tenant_id, code, name, description, unit_price, stock_quantity, created_at, updated_at
+		SELECT ` + productColumns + `
 		FROM products
 		WHERE tenant_id = $1 AND code = $2
 	`
 
-	product := &models.Product{}
-	err := r.db.QueryRow(query, tenantID, code).Scan(
-		&product.ID,
-		&product.TenantID,
-		&product.Code,
-		&product.Name,
-		&product.Description,
-		&product.UnitPrice,
-		&product.StockQuantity,
-		&product.CreatedAt,
-		&product.UpdatedAt,
-	)
-
+	product, err := scanProduct(r.db.QueryRow(query, tenantID, code))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -96,16 +190,22 @@ func (r *ProductRepository) GetByCode(tenantID, code string) (*models.Product, e
 	return product, err
 }
 
-// List lists all products for a tenant
-func (r *ProductRepository) List(tenantID string) ([]*models.Product, error) {
+// List lists the products of a tenant, optionally only those with the custom field
+// values of a filter
+func (r *ProductRepository) List(tenantID string, customFields models.CustomFieldFilter) ([]*models.Product, error) {
 	query := `
-		SELECT id, tenant_id, code, name, description, unit_price, stock_quantity, created_at, updated_at
+		SELECT ` + productColumns + `
 		FROM products
-		WHERE tenant_id = $1
+		WHERE tenant_id = $1 AND ` + customFieldFilterSQL("$2") + `
 		ORDER BY code
 	`
 
-	rows, err := r.db.Query(query, tenantID)
+	filter, err := marshalCustomFieldFilter(customFields)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(query, tenantID, filter)
 	if err != nil {
 		return nil, err
 	}
@@ -113,18 +213,7 @@ func (r *ProductRepository) List(tenantID string) ([]*models.Product, error) {
 
 	products := []*models.Product{}
 	for rows.Next() {
-		product := &models.Product{}
-		err := rows.Scan(
-			&product.ID,
-			&product.TenantID,
-			&product.Code,
-			&product.Name,
-			&product.Description,
-			&product.UnitPrice,
-			&product.StockQuantity,
-			&product.CreatedAt,
-			&product.UpdatedAt,
-		)
+		product, err := scanProduct(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -134,28 +223,86 @@ func (r *ProductRepository) List(tenantID string) ([]*models.Product, error) {
 	return products, nil
 }
 
-// Update updates a product
+// ListByCategory lists the products of a category and its subcategories, optionally
+// only those with the custom field values of a filter
+func (r *ProductRepository) ListByCategory(tenantID, categoryID string, customFields models.CustomFieldFilter) ([]*models.Product, error) {
+	query := categorySubtreeSQL + `
+		SELECT ` + productColumns + `
+		FROM products
+		WHERE tenant_id = $1 AND category_id IN (SELECT id FROM subtree) AND ` + customFieldFilterSQL("$3") + `
+		ORDER BY code
+	`
+
+	filter, err := marshalCustomFieldFilter(customFields)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(query, tenantID, categoryID, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	products := []*models.Product{}
+	for rows.Next() {
+		product, err := scanProduct(rows)
+		if err != nil {
+			return nil, err
+		}
+		products = append(products, product)
+	}
+
+	return products, nil
+}
+
+// Update updates a product. Its stock quantity is left alone since stock only
+// changes through inventory transactions.
 func (r *ProductRepository) Update(product *models.Product) error {
 	query := `
 		UPDATE products
-		SET code = $1, name = $2, description = $3, unit_price = $4, stock_quantity = $5, updated_at = $6
-		WHERE tenant_id = $7 AND id = $8
+		SET code = $1, name = $2, description = $3, unit_price = $4, base_unit_id = $5, purchase_unit_id = $6,
+			sales_unit_id = $7, weight = $8, volume = $9, tracking_mode = $10, allow_negative_stock = $11,
+			category_id = $12, inventory_account_id = $13, cogs_account_id = $14, tax_code = $15,
+			costing_method = $16, custom_fields = $17, updated_at = $18
+		WHERE tenant_id = $19 AND id = $20
 	`
 
+	customFields, err := marshalCustomFields(product.CustomFields)
+	if err != nil {
+		return err
+	}
+
 	now := time.Now()
-	_, err := r.db.Exec(
+	_, err = r.db.Exec(
 		query,
 		product.Code,
 		product.Name,
 		product.Description,
 		product.UnitPrice,
-		product.StockQuantity,
+		nullString(product.BaseUnitID),
+		nullString(product.PurchaseUnitID),
+		nullString(product.SalesUnitID),
+		product.Weight,
+		product.Volume,
+		product.TrackingMode,
+		product.AllowNegativeStock,
+		nullString(product.CategoryID),
+		nullString(product.InventoryAccountID),
+		nullString(product.COGSAccountID),
+		nullString(product.TaxCode),
+		nullString(product.CostingMethod),
+		customFields,
 		now,
 		product.TenantID,
 		product.ID,
 	)
+	if err != nil {
+		return err
+	}
 	product.UpdatedAt = now
-	return err
+
+	return loadProductDefaults(r.db, product)
 }
 
 // Delete deletes a product
@@ -169,34 +316,325 @@ func (r *ProductRepository) Delete(tenantID, id string) error {
 	return err
 }
 
-// InventoryTransactionRepository implements the InventoryTransactionService interface
+const inventoryTransactionColumns = `id, tenant_id, product_id, transaction_type, quantity,
+	COALESCE(unit_id::text, ''), unit_quantity, reason_code,
+	COALESCE(location_id::text, ''), COALESCE(destination_location_id::text, ''),
+	lot_number, serial_number, expiry_date, unit_cost, total_cost, COALESCE(journal_entry_id::text, ''),
+	reference, notes, created_by, created_at, updated_at`
+
+// scanInventoryTransaction scans a row selected with inventoryTransactionColumns
+func scanInventoryTransaction(row rowScanner) (*models.InventoryTransaction, error) {
+	transaction := &models.InventoryTransaction{}
+	err := row.Scan(
+		&transaction.ID,
+		&transaction.TenantID,
+		&transaction.ProductID,
+		&transaction.TransactionType,
+		&transaction.Quantity,
+		&transaction.UnitID,
+		&transaction.UnitQuantity,
+		&transaction.ReasonCode,
+		&transaction.LocationID,
+		&transaction.DestinationLocationID,
+		&transaction.LotNumber,
+		&transaction.SerialNumber,
+		&transaction.ExpiryDate,
+		&transaction.UnitCost,
+		&transaction.TotalCost,
+		&transaction.JournalEntryID,
+		&transaction.Reference,
+		&transaction.Notes,
+		&transaction.CreatedBy,
+		&transaction.CreatedAt,
+		&transaction.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return transaction, nil
+}
+
+// stockChange returns the signed change in stock caused by a transaction.
+// Transfers move stock between locations and leave the product total unchanged.
+func stockChange(transaction *models.InventoryTransaction) float64 {
+	switch transaction.TransactionType {
+	case models.TransactionTypeReceipt, models.TransactionTypeReturn, models.TransactionTypeAdjustment:
+		return transaction.Quantity
+	case models.TransactionTypeIssue:
+		return -transaction.Quantity
+	}
+	return 0
+}
+
+// stockChangeSQL is the SQL equivalent of stockChange for inventory_transactions rows
+const stockChangeSQL = `CASE transaction_type WHEN 'IN' THEN quantity WHEN 'RETURN' THEN quantity WHEN 'ADJUSTMENT' THEN quantity WHEN 'OUT' THEN -quantity ELSE 0 END`
+
+// validateTransaction checks the type, quantity and locations of a transaction
+func validateTransaction(transaction *models.InventoryTransaction) error {
+	switch transaction.TransactionType {
+	case models.TransactionTypeReceipt, models.TransactionTypeIssue, models.TransactionTypeReturn:
+		if transaction.Quantity <= 0 {
+			return models.ErrInvalidQuantity
+		}
+	case models.TransactionTypeAdjustment:
+		if transaction.Quantity == 0 {
+			return models.ErrZeroAdjustment
+		}
+		switch transaction.ReasonCode {
+		case models.ReasonDamaged, models.ReasonLost, models.ReasonFound, models.ReasonExpired,
+			models.ReasonCount, models.ReasonWriteOff, models.ReasonOther:
+		default:
+			return models.ErrInvalidReasonCode
+		}
+	case models.TransactionTypeTransfer:
+		if transaction.Quantity <= 0 {
+			return models.ErrInvalidQuantity
+		}
+		if transaction.LocationID == "" || transaction.DestinationLocationID == "" ||
+			transaction.LocationID == transaction.DestinationLocationID {
+			return models.ErrTransferLocations
+		}
+	default:
+		return models.ErrInvalidTransactionType
+	}
+	return nil
+}
+
+// productState is the stock and costing state of a product used when applying a transaction
+type productState struct {
+	trackingMode       string
+	stockQuantity      float64
+	averageCost        float64
+	costingMethod      string
+	allowNegativeStock bool
+	inventoryAccountID string
+	cogsAccountID      string
+	categoryID         string
+}
+
+// loadProductState locks a product and loads its state, the costing method and
+// GL accounts in effect for it, its category and its negative stock policy within tx
+func loadProductState(tx *sql.Tx, tenantID, productID string) (*productState, error) {
+	query := `
+		SELECT p.tracking_mode, p.stock_quantity, p.average_cost,
+			COALESCE(p.allow_negative_stock, t.allow_negative_stock),
+			COALESCE(p.inventory_account_id::text, ''), COALESCE(p.cogs_account_id::text, ''),
+			COALESCE(p.costing_method, ''), COALESCE(p.category_id::text, ''),
+			` + inheritedDefaultsSQL("p.category_id", "p.tenant_id") + `
+		FROM products p
+		JOIN tenants t ON t.id = p.tenant_id
+		WHERE p.tenant_id = $1 AND p.id = $2
+		FOR UPDATE OF p
+	`
+
+	state := &productState{}
+	product := &models.Product{}
+	var defaults []byte
+	err := tx.QueryRow(query, tenantID, productID).Scan(
+		&state.trackingMode,
+		&state.stockQuantity,
+		&state.averageCost,
+		&state.allowNegativeStock,
+		&product.InventoryAccountID,
+		&product.COGSAccountID,
+		&product.CostingMethod,
+		&state.categoryID,
+		&defaults,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(defaults, &product.Defaults); err != nil {
+		return nil, err
+	}
+	product.Defaults = overrideDefaults(product.Defaults, product)
+
+	state.costingMethod = product.Defaults.CostingMethod
+	state.inventoryAccountID = product.Defaults.InventoryAccountID
+	state.cogsAccountID = product.Defaults.COGSAccountID
+	return state, nil
+}
+
+// stockTx is a database transaction that moves stock. It collects the movements
+// recorded within it by createTx so that finishTx can check them against the reorder
+// rules once they are committed.
+type stockTx struct {
+	*sql.Tx
+	movements []*models.InventoryTransaction
+}
+
+// beginStockTx starts a database transaction that moves stock
+func beginStockTx(db *DB) (*stockTx, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	return &stockTx{Tx: tx}, nil
+}
+
+// InventoryTransactionRepository implements the InventoryTransactionService interface.
+// Every stock movement is recorded through createTx within a stockTx, which finishTx
+// ends by handing the committed movements to the low stock monitor.
 type InventoryTransactionRepository struct {
-	db *DB
+	db       *DB
+	lowStock *LowStockMonitor
 }
 
-// NewInventoryTransactionRepository creates a new inventory transaction repository
-func NewInventoryTransactionRepository(db *DB) *InventoryTransactionRepository {
-	return &InventoryTransactionRepository{db: db}
+// NewInventoryTransactionRepository creates a new inventory transaction repository.
+// lowStock may be nil to not check movements against the reorder rules.
+func NewInventoryTransactionRepository(db *DB, lowStock *LowStockMonitor) *InventoryTransactionRepository {
+	return &InventoryTransactionRepository{db: db, lowStock: lowStock}
 }
 
 // Create creates a new inventory transaction
-func (r *InventoryTransactionRepository) Create(transaction *models.InventoryTransaction) error {
-	tx, err := r.db.Begin()
+func (r *InventoryTransactionRepository) Create(transaction *models.InventoryTransaction) (err error) {
+	stock, err := beginStockTx(r.db)
+	if err != nil {
+		return err
+	}
+	defer func() { err = r.finishTx(stock, err) }()
+
+	return r.createTx(stock, transaction)
+}
+
+// finishTx commits stock, or rolls it back when err is set, and once committed hands
+// the movements recorded within it to the low stock monitor
+func (r *InventoryTransactionRepository) finishTx(stock *stockTx, err error) error {
 	if err != nil {
+		stock.Rollback()
 		return err
 	}
-	defer func() {
+
+	if err := stock.Commit(); err != nil {
+		return err
+	}
+
+	if r.lowStock != nil && len(stock.movements) > 0 {
+		r.lowStock.check(stock.movements)
+	}
+	return nil
+}
+
+// stockDecrease returns how much a transaction decreased stock across all
+// locations, or at locationID when it is set
+func stockDecrease(transaction *models.InventoryTransaction, locationID string) float64 {
+	if locationID != "" && transaction.LocationID != locationID {
+		return 0
+	}
+
+	switch transaction.TransactionType {
+	case models.TransactionTypeIssue:
+		return transaction.Quantity
+	case models.TransactionTypeAdjustment:
+		if transaction.Quantity < 0 {
+			return -transaction.Quantity
+		}
+	case models.TransactionTypeTransfer:
+		if locationID != "" {
+			return transaction.Quantity
+		}
+	}
+	return 0
+}
+
+// createTx records an inventory transaction and applies it to stock within stock, which
+// collects it as a movement. Callers end stock with finishTx so that the movement is
+// checked against the reorder rules.
+func (r *InventoryTransactionRepository) createTx(stock *stockTx, transaction *models.InventoryTransaction) error {
+	if err := validateTransaction(transaction); err != nil {
+		return err
+	}
+
+	tx := stock.Tx
+
+	state, err := loadProductState(tx, transaction.TenantID, transaction.ProductID)
+	if err != nil {
+		return err
+	}
+
+	// Quantities entered in another unit are stored in the base unit
+	transaction.UnitQuantity = transaction.Quantity
+	if transaction.UnitID != "" {
+		factor, err := unitFactor(tx, transaction.TenantID, transaction.ProductID, transaction.UnitID)
+		if err != nil {
+			return err
+		}
+		transaction.Quantity = roundQuantity(transaction.Quantity * factor)
+		transaction.UnitCost = transaction.UnitCost / factor
+	}
+
+	// Tracked products must carry their lot or serial number
+	switch state.trackingMode {
+	case models.TrackingLot:
+		if transaction.LotNumber == "" {
+			return models.ErrLotNumberRequired
+		}
+	case models.TrackingSerial:
+		if transaction.SerialNumber == "" {
+			return models.ErrSerialNumberRequired
+		}
+		if transaction.Quantity != 1 && transaction.Quantity != -1 {
+			return models.ErrSerialQuantity
+		}
+	}
+
+	// Guard against negative stock; the product row is locked until the transaction ends
+	change := stockChange(transaction)
+	if change < 0 && !state.allowNegativeStock && roundQuantity(state.stockQuantity+change) < 0 {
+		return models.ErrInsufficientStock
+	}
+
+	// Apply the movement to the locations involved
+	if transaction.TransactionType == models.TransactionTypeTransfer {
+		err := applyLocationMovement(tx, transaction, transaction.LocationID, -transaction.Quantity, state.allowNegativeStock)
 		if err != nil {
-			tx.Rollback()
-			return
+			return err
+		}
+		err = applyLocationMovement(tx, transaction, transaction.DestinationLocationID, transaction.Quantity, true)
+		if err != nil {
+			return err
+		}
+	} else if transaction.LocationID != "" && change != 0 {
+		if err := applyLocationMovement(tx, transaction, transaction.LocationID, change, state.allowNegativeStock); err != nil {
+			return err
 		}
-		err = tx.Commit()
-	}()
+	}
+
+	// Apply the movement to the lot or serial number first so that
+	// outbound movements record the expiry date of the lot they draw from
+	if change != 0 && (state.trackingMode == models.TrackingLot || state.trackingMode == models.TrackingSerial) {
+		if err := applyLotMovement(tx, transaction, state.trackingMode, change); err != nil {
+			return err
+		}
+	}
+
+	// Cost the movement: receipts carry their unit cost, other inbound
+	// movements default to the average cost, and issues are costed from
+	// the cost layers or the moving average cost
+	averageCost := state.averageCost
+	transaction.TotalCost = 0
+	if change > 0 {
+		if transaction.UnitCost == 0 && transaction.TransactionType != models.TransactionTypeReceipt {
+			transaction.UnitCost = state.averageCost
+		}
+		transaction.TotalCost = transaction.UnitCost * change
+		averageCost = movingAverageCost(state, change, transaction.UnitCost)
+	} else if change < 0 {
+		cost, err := issueCost(tx, transaction, state, -change)
+		if err != nil {
+			return err
+		}
+		transaction.UnitCost = cost / -change
+		transaction.TotalCost = -cost
+	}
 
 	// Insert inventory transaction
 	query := `
-		INSERT INTO inventory_transactions (tenant_id, product_id, transaction_type, quantity, reference, notes, created_by)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO inventory_transactions (tenant_id, product_id, transaction_type, quantity, unit_id, unit_quantity,
+			reason_code, location_id, destination_location_id, lot_number, serial_number, expiry_date, unit_cost,
+			total_cost, reference, notes, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
 		RETURNING id, created_at, updated_at
 	`
 
@@ -206,6 +644,16 @@ func (r *InventoryTransactionRepository) Create(transaction *models.InventoryTra
 		transaction.ProductID,
 		transaction.TransactionType,
 		transaction.Quantity,
+		nullString(transaction.UnitID),
+		transaction.UnitQuantity,
+		transaction.ReasonCode,
+		nullString(transaction.LocationID),
+		nullString(transaction.DestinationLocationID),
+		transaction.LotNumber,
+		transaction.SerialNumber,
+		transaction.ExpiryDate,
+		transaction.UnitCost,
+		transaction.TotalCost,
 		transaction.Reference,
 		transaction.Notes,
 		transaction.CreatedBy,
@@ -218,58 +666,51 @@ func (r *InventoryTransactionRepository) Create(transaction *models.InventoryTra
 		return err
 	}
 
-	// Update product stock quantity
-	var stockChange int
-	if transaction.TransactionType == "IN" {
-		stockChange = transaction.Quantity
-	} else if transaction.TransactionType == "OUT" {
-		stockChange = -transaction.Quantity
-	}
-
-	if stockChange != 0 {
-		query := `
-			UPDATE products
-			SET stock_quantity = stock_quantity + $1, updated_at = $2
-			WHERE tenant_id = $3 AND id = $4
-		`
-
-		_, err = tx.Exec(
-			query,
-			stockChange,
-			time.Now(),
-			transaction.TenantID,
-			transaction.ProductID,
-		)
-		if err != nil {
+	movement := *transaction
+	stock.movements = append(stock.movements, &movement)
+
+	if change == 0 {
+		return nil
+	}
+
+	// Post the change in inventory value to the general ledger
+	if err := postInventoryTransaction(tx, transaction, state); err != nil {
+		return err
+	}
+
+	if change > 0 && state.costingMethod == models.CostingFIFO {
+		if err := addCostLayer(tx, transaction, change); err != nil {
 			return err
 		}
 	}
 
-	return nil
+	// Update product stock quantity and average cost
+	query = `
+		UPDATE products
+		SET stock_quantity = stock_quantity + $1, average_cost = $2, updated_at = $3
+		WHERE tenant_id = $4 AND id = $5
+	`
+
+	_, err = tx.Exec(
+		query,
+		change,
+		averageCost,
+		time.Now(),
+		transaction.TenantID,
+		transaction.ProductID,
+	)
+	return err
 }
 
 // GetByID gets an inventory transaction by ID
 func (r *InventoryTransactionRepository) GetByID(tenantID, id string) (*models.InventoryTransaction, error) {
 	query := `
-		SELECT id, tenant_id, product_id, transaction_type, quantity, reference, notes, created_by, created_at, updated_at
+		SELECT ` + inventoryTransactionColumns + `
 		FROM inventory_transactions
 		WHERE tenant_id = $1 AND id = $2
 	`
 
-	transaction := &models.InventoryTransaction{}
-	err := r.db.QueryRow(query, tenantID, id).Scan(
-		&transaction.ID,
-		&transaction.TenantID,
-		&transaction.ProductID,
-		&transaction.TransactionType,
-		&transaction.Quantity,
-		&transaction.Reference,
-		&transaction.Notes,
-		&transaction.CreatedBy,
-		&transaction.CreatedAt,
-		&transaction.UpdatedAt,
-	)
-
+	transaction, err := scanInventoryTransaction(r.db.QueryRow(query, tenantID, id))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -280,52 +721,30 @@ func (r *InventoryTransactionRepository) GetByID(tenantID, id string) (*models.I
 // List lists all inventory transactions for a tenant
 func (r *InventoryTransactionRepository) List(tenantID string) ([]*models.InventoryTransaction, error) {
 	query := `
-		SELECT id, tenant_id, product_id, transaction_type, quantity, reference, notes, created_by, created_at, updated_at
+		SELECT ` + inventoryTransactionColumns + `
 		FROM inventory_transactions
 		WHERE tenant_id = $1
 		ORDER BY created_at DESC
 	`
 
-	rows, err := r.db.Query(query, tenantID)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	transactions := []*models.InventoryTransaction{}
-	for rows.Next() {
-		transaction := &models.InventoryTransaction{}
-		err := rows.Scan(
-			&transaction.ID,
-			&transaction.TenantID,
-			&transaction.ProductID,
-			&transaction.TransactionType,
-			&transaction.Quantity,
-			&transaction.Reference,
-			&transaction.Notes,
-			&transaction.CreatedBy,
-			&transaction.CreatedAt,
-			&transaction.UpdatedAt,
-		)
-		if err != nil {
-			return nil, err
-		}
-		transactions = append(transactions, transaction)
-	}
-
-	return transactions, nil
+	return queryInventoryTransactions(r.db, query, tenantID)
 }
 
 // ListByProduct lists all inventory transactions for a product
 func (r *InventoryTransactionRepository) ListByProduct(tenantID, productID string) ([]*models.InventoryTransaction, error) {
 	query := `
-		SELECT id, tenant_id, product_id, transaction_type, quantity, reference, notes, created_by, created_at, updated_at
+		SELECT ` + inventoryTransactionColumns + `
 		FROM inventory_transactions
 		WHERE tenant_id = $1 AND product_id = $2
 		ORDER BY created_at DESC
 	`
 
-	rows, err := r.db.Query(query, tenantID, productID)
+	return queryInventoryTransactions(r.db, query, tenantID, productID)
+}
+
+// queryInventoryTransactions runs a query selecting inventoryTransactionColumns
+func queryInventoryTransactions(db *DB, query string, args ...interface{}) ([]*models.InventoryTransaction, error) {
+	rows, err := db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -333,19 +752,7 @@ func (r *InventoryTransactionRepository) ListByProduct(tenantID, productID strin
 
 	transactions := []*models.InventoryTransaction{}
 	for rows.Next() {
-		transaction := &models.InventoryTransaction{}
-		err := rows.Scan(
-			&transaction.ID,
-			&transaction.TenantID,
-			&transaction.ProductID,
-			&transaction.TransactionType,
-			&transaction.Quantity,
-			&transaction.Reference,
-			&transaction.Notes,
-			&transaction.CreatedBy,
-			&transaction.CreatedAt,
-			&transaction.UpdatedAt,
-		)
+		transaction, err := scanInventoryTransaction(rows)
 		if err != nil {
 			return nil, err
 		}
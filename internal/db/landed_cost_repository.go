@@ -0,0 +1,744 @@
+package db
+
+import (
+	"database/sql"
+	"math"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/yookibooki/erp/internal/models"
+)
+
+const landedCostColumns = `id, tenant_id, number, reference, counter_account_id, status, notes,
+	COALESCE(journal_entry_id::text, ''), created_by, COALESCE(posted_by::text, ''), posted_at, created_at, updated_at`
+
+// scanLandedCost scans a row selected with landedCostColumns
+func scanLandedCost(row rowScanner) (*models.LandedCost, error) {
+	landedCost := &models.LandedCost{}
+	err := row.Scan(
+		&landedCost.ID,
+		&landedCost.TenantID,
+		&landedCost.Number,
+		&landedCost.Reference,
+		&landedCost.CounterAccountID,
+		&landedCost.Status,
+		&landedCost.Notes,
+		&landedCost.JournalEntryID,
+		&landedCost.CreatedBy,
+		&landedCost.PostedBy,
+		&landedCost.PostedAt,
+		&landedCost.CreatedAt,
+		&landedCost.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return landedCost, nil
+}
+
+// loadLandedCostLines loads the charges and receipts of a landed cost and totals its charges
+func loadLandedCostLines(q queryer, landedCost *models.LandedCost) error {
+	query := `
+		SELECT id, tenant_id, landed_cost_id, description, amount, allocation_method, created_at
+		FROM landed_cost_charges
+		WHERE tenant_id = $1 AND landed_cost_id = $2
+		ORDER BY created_at, id
+	`
+
+	rows, err := q.Query(query, landedCost.TenantID, landedCost.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	landedCost.Charges = []models.LandedCostCharge{}
+	landedCost.Total = 0
+	for rows.Next() {
+		charge := models.LandedCostCharge{}
+		err := rows.Scan(
+			&charge.ID,
+			&charge.TenantID,
+			&charge.LandedCostID,
+			&charge.Description,
+			&charge.Amount,
+			&charge.AllocationMethod,
+			&charge.CreatedAt,
+		)
+		if err != nil {
+			return err
+		}
+		landedCost.Total += charge.Amount
+		landedCost.Charges = append(landedCost.Charges, charge)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	query = `
+		SELECT id, tenant_id, landed_cost_id, transaction_id, product_id, quantity, value, weight, volume,
+			unit_cost, allocated_cost, capitalized_cost, expensed_cost,
+			COALESCE(adjustment_transaction_id::text, ''), created_at
+		FROM landed_cost_receipts
+		WHERE tenant_id = $1 AND landed_cost_id = $2
+		ORDER BY created_at, id
+	`
+
+	rows, err = q.Query(query, landedCost.TenantID, landedCost.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	landedCost.Receipts = []models.LandedCostReceipt{}
+	for rows.Next() {
+		receipt := models.LandedCostReceipt{}
+		err := rows.Scan(
+			&receipt.ID,
+			&receipt.TenantID,
+			&receipt.LandedCostID,
+			&receipt.TransactionID,
+			&receipt.ProductID,
+			&receipt.Quantity,
+			&receipt.Value,
+			&receipt.Weight,
+			&receipt.Volume,
+			&receipt.UnitCost,
+			&receipt.AllocatedCost,
+			&receipt.CapitalizedCost,
+			&receipt.ExpensedCost,
+			&receipt.AdjustmentTransactionID,
+			&receipt.CreatedAt,
+		)
+		if err != nil {
+			return err
+		}
+		receipt.LandedUnitCost = receipt.UnitCost + receipt.AllocatedCost/receipt.Quantity
+		landedCost.Receipts = append(landedCost.Receipts, receipt)
+	}
+
+	return rows.Err()
+}
+
+// allocationBasis returns the share of a receipt in a charge allocated by method
+func allocationBasis(receipt *models.LandedCostReceipt, method string) float64 {
+	switch method {
+	case models.AllocateByValue:
+		return receipt.Value
+	case models.AllocateByWeight:
+		return receipt.Weight
+	case models.AllocateByVolume:
+		return receipt.Volume
+	}
+	return receipt.Quantity
+}
+
+// allocateLandedCost splits each charge of a landed cost over its receipts in proportion
+// to their basis for the charge's allocation method. Allocations are rounded to cents and
+// the rounding difference goes to the receipt with the largest basis.
+func allocateLandedCost(landedCost *models.LandedCost) error {
+	for i := range landedCost.Receipts {
+		landedCost.Receipts[i].AllocatedCost = 0
+	}
+
+	for _, charge := range landedCost.Charges {
+		total := 0.0
+		largest := 0
+		for i := range landedCost.Receipts {
+			basis := allocationBasis(&landedCost.Receipts[i], charge.AllocationMethod)
+			total += basis
+			if basis > allocationBasis(&landedCost.Receipts[largest], charge.AllocationMethod) {
+				largest = i
+			}
+		}
+		if total <= 0 {
+			return models.ErrAllocationBasis
+		}
+
+		allocated := 0.0
+		for i := range landedCost.Receipts {
+			receipt := &landedCost.Receipts[i]
+			share := roundPrice(charge.Amount * allocationBasis(receipt, charge.AllocationMethod) / total)
+			receipt.AllocatedCost += share
+			allocated += share
+		}
+		landedCost.Receipts[largest].AllocatedCost += roundPrice(charge.Amount - allocated)
+	}
+
+	for i := range landedCost.Receipts {
+		receipt := &landedCost.Receipts[i]
+		receipt.AllocatedCost = roundPrice(receipt.AllocatedCost)
+		receipt.LandedUnitCost = receipt.UnitCost + receipt.AllocatedCost/receipt.Quantity
+	}
+	return nil
+}
+
+// loadLandedCostReceipt loads the allocation bases of a receipt transaction within tx
+func loadLandedCostReceipt(tx *sql.Tx, tenantID string, receipt *models.LandedCostReceipt) error {
+	query := `
+		SELECT t.transaction_type, t.product_id, t.quantity, t.total_cost, t.unit_cost,
+			t.quantity * p.weight, t.quantity * p.volume
+		FROM inventory_transactions t
+		JOIN products p ON p.id = t.product_id
+		WHERE t.tenant_id = $1 AND t.id = $2
+	`
+
+	var transactionType string
+	err := tx.QueryRow(query, tenantID, receipt.TransactionID).Scan(
+		&transactionType,
+		&receipt.ProductID,
+		&receipt.Quantity,
+		&receipt.Value,
+		&receipt.UnitCost,
+		&receipt.Weight,
+		&receipt.Volume,
+	)
+	if err == sql.ErrNoRows {
+		return models.ErrLandedCostReceipt
+	}
+	if err != nil {
+		return err
+	}
+
+	if transactionType != models.TransactionTypeReceipt || receipt.Quantity <= 0 {
+		return models.ErrLandedCostReceipt
+	}
+	return nil
+}
+
+// costLayerQuantity is the quantity a FIFO cost layer received and the quantity of it
+// still on hand
+type costLayerQuantity struct {
+	quantity  float64
+	remaining float64
+}
+
+// landedCostSplit is how the cost allocated to a receipt is capitalized: the quantity of
+// the receipt taken to be on hand, the share of the cost capitalized into inventory, the
+// addition to the unit cost of the receipt's FIFO cost layer and the addition to the
+// product's moving average cost
+type landedCostSplit struct {
+	quantity      float64
+	capitalized   float64
+	layerUnitCost float64
+	averageCost   float64
+}
+
+// splitLandedCost splits the cost allocated to a receipt between the stock still on hand
+// and the stock already issued. FIFO products are costed from their cost layers, so the
+// cost only goes to the receipt's layer, of which the remaining quantity is on hand, and
+// nothing is capitalized without a layer. Other products take the receipt to be on hand
+// up to available, the part of their stock quantity not already taken by other receipts
+// of the same landed cost, and spread the capitalized cost over their whole stock in
+// their average cost.
+func splitLandedCost(receipt *models.LandedCostReceipt, costingMethod string, layer *costLayerQuantity, stockQuantity, available float64) landedCostSplit {
+	split := landedCostSplit{}
+	if costingMethod == models.CostingFIFO {
+		if layer == nil || layer.quantity <= 0 {
+			return split
+		}
+		split.quantity = layer.remaining
+		split.capitalized = roundPrice(receipt.AllocatedCost * layer.remaining / receipt.Quantity)
+		split.layerUnitCost = receipt.AllocatedCost / layer.quantity
+		return split
+	}
+
+	split.quantity = math.Min(receipt.Quantity, math.Max(available, 0))
+	split.capitalized = roundPrice(receipt.AllocatedCost * split.quantity / receipt.Quantity)
+	if split.capitalized != 0 && stockQuantity > 0 {
+		split.averageCost = split.capitalized / stockQuantity
+	}
+	return split
+}
+
+// capitalizeLandedCost adds the cost allocated to a receipt to the stock it received
+// within tx, as split by splitLandedCost, and returns the split
+func capitalizeLandedCost(tx *sql.Tx, tenantID string, receipt *models.LandedCostReceipt, state *productState, available float64) (landedCostSplit, error) {
+	var layerID string
+	var layer *costLayerQuantity
+	if state.costingMethod == models.CostingFIFO {
+		layer = &costLayerQuantity{}
+		err := tx.QueryRow(
+			`SELECT id, quantity, remaining_quantity FROM cost_layers WHERE tenant_id = $1 AND transaction_id = $2 FOR UPDATE`,
+			tenantID,
+			receipt.TransactionID,
+		).Scan(&layerID, &layer.quantity, &layer.remaining)
+		if err == sql.ErrNoRows {
+			layer = nil
+		} else if err != nil {
+			return landedCostSplit{}, err
+		}
+	}
+
+	split := splitLandedCost(receipt, state.costingMethod, layer, state.stockQuantity, available)
+
+	if split.layerUnitCost != 0 {
+		_, err := tx.Exec(
+			`UPDATE cost_layers SET unit_cost = unit_cost + $1 WHERE tenant_id = $2 AND id = $3`,
+			split.layerUnitCost,
+			tenantID,
+			layerID,
+		)
+		if err != nil {
+			return landedCostSplit{}, err
+		}
+	}
+
+	if split.averageCost != 0 {
+		_, err := tx.Exec(
+			`UPDATE products SET average_cost = average_cost + $1, updated_at = $2 WHERE tenant_id = $3 AND id = $4`,
+			split.averageCost,
+			time.Now(),
+			tenantID,
+			receipt.ProductID,
+		)
+		if err != nil {
+			return landedCostSplit{}, err
+		}
+	}
+
+	return split, nil
+}
+
+// lockDraftLandedCost locks a draft landed cost within tx and loads its charges and receipts
+func lockDraftLandedCost(tx *sql.Tx, tenantID, id string) (*models.LandedCost, error) {
+	query := `
+		SELECT ` + landedCostColumns + `
+		FROM landed_costs
+		WHERE tenant_id = $1 AND id = $2
+		FOR UPDATE
+	`
+
+	landedCost, err := scanLandedCost(tx.QueryRow(query, tenantID, id))
+	if err != nil {
+		return nil, err
+	}
+
+	if landedCost.Status != models.LandedCostDraft {
+		return nil, models.ErrLandedCostStatus
+	}
+
+	if err := loadLandedCostLines(tx, landedCost); err != nil {
+		return nil, err
+	}
+	return landedCost, nil
+}
+
+// LandedCostRepository implements the LandedCostService interface
+type LandedCostRepository struct {
+	db *DB
+}
+
+// NewLandedCostRepository creates a new landed cost repository
+func NewLandedCostRepository(db *DB) *LandedCostRepository {
+	return &LandedCostRepository{db: db}
+}
+
+// Create creates a new draft landed cost and allocates its charges over its receipts
+func (r *LandedCostRepository) Create(landedCost *models.LandedCost) (err error) {
+	if len(landedCost.Charges) == 0 || len(landedCost.Receipts) == 0 {
+		return models.ErrLandedCostEmpty
+	}
+
+	for _, charge := range landedCost.Charges {
+		if charge.Amount <= 0 {
+			return models.ErrLandedCostAmount
+		}
+		switch charge.AllocationMethod {
+		case models.AllocateByQuantity, models.AllocateByValue, models.AllocateByWeight, models.AllocateByVolume:
+		default:
+			return models.ErrAllocationMethod
+		}
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	// Freeze the allocation bases of the receipts
+	seen := map[string]bool{}
+	for i := range landedCost.Receipts {
+		receipt := &landedCost.Receipts[i]
+		if seen[receipt.TransactionID] {
+			return models.ErrDuplicateLandedCost
+		}
+		seen[receipt.TransactionID] = true
+
+		if err = loadLandedCostReceipt(tx, landedCost.TenantID, receipt); err != nil {
+			return err
+		}
+	}
+
+	if err = allocateLandedCost(landedCost); err != nil {
+		return err
+	}
+
+	landedCost.Status = models.LandedCostDraft
+	query := `
+		INSERT INTO landed_costs (tenant_id, number, reference, counter_account_id, status, notes, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at, updated_at
+	`
+
+	err = tx.QueryRow(
+		query,
+		landedCost.TenantID,
+		landedCost.Number,
+		landedCost.Reference,
+		landedCost.CounterAccountID,
+		landedCost.Status,
+		landedCost.Notes,
+		landedCost.CreatedBy,
+	).Scan(
+		&landedCost.ID,
+		&landedCost.CreatedAt,
+		&landedCost.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	// Insert charges
+	landedCost.Total = 0
+	for i := range landedCost.Charges {
+		charge := &landedCost.Charges[i]
+		charge.TenantID = landedCost.TenantID
+		charge.LandedCostID = landedCost.ID
+		landedCost.Total += charge.Amount
+
+		err = tx.QueryRow(
+			`INSERT INTO landed_cost_charges (tenant_id, landed_cost_id, description, amount, allocation_method)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING id, created_at`,
+			charge.TenantID,
+			charge.LandedCostID,
+			charge.Description,
+			charge.Amount,
+			charge.AllocationMethod,
+		).Scan(&charge.ID, &charge.CreatedAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Insert receipts
+	for i := range landedCost.Receipts {
+		receipt := &landedCost.Receipts[i]
+		receipt.TenantID = landedCost.TenantID
+		receipt.LandedCostID = landedCost.ID
+
+		err = tx.QueryRow(
+			`INSERT INTO landed_cost_receipts (tenant_id, landed_cost_id, transaction_id, product_id, quantity, value,
+				weight, volume, unit_cost, allocated_cost)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			RETURNING id, created_at`,
+			receipt.TenantID,
+			receipt.LandedCostID,
+			receipt.TransactionID,
+			receipt.ProductID,
+			receipt.Quantity,
+			receipt.Value,
+			receipt.Weight,
+			receipt.Volume,
+			receipt.UnitCost,
+			receipt.AllocatedCost,
+		).Scan(&receipt.ID, &receipt.CreatedAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetByID gets a landed cost by ID
+func (r *LandedCostRepository) GetByID(tenantID, id string) (*models.LandedCost, error) {
+	query := `
+		SELECT ` + landedCostColumns + `
+		FROM landed_costs
+		WHERE tenant_id = $1 AND id = $2
+	`
+
+	return r.get(query, tenantID, id)
+}
+
+// GetByNumber gets a landed cost by number
+func (r *LandedCostRepository) GetByNumber(tenantID, number string) (*models.LandedCost, error) {
+	query := `
+		SELECT ` + landedCostColumns + `
+		FROM landed_costs
+		WHERE tenant_id = $1 AND number = $2
+	`
+
+	return r.get(query, tenantID, number)
+}
+
+// get gets a landed cost and its charges and receipts
+func (r *LandedCostRepository) get(query string, args ...interface{}) (*models.LandedCost, error) {
+	landedCost, err := scanLandedCost(r.db.QueryRow(query, args...))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := loadLandedCostLines(r.db, landedCost); err != nil {
+		return nil, err
+	}
+
+	return landedCost, nil
+}
+
+// List lists all landed costs for a tenant, optionally filtered by status
+func (r *LandedCostRepository) List(tenantID, status string) ([]*models.LandedCost, error) {
+	query := `
+		SELECT ` + landedCostColumns + `
+		FROM landed_costs
+		WHERE tenant_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY created_at DESC
+	`
+
+	return r.list(query, tenantID, status)
+}
+
+// ListByTransaction lists the landed costs allocated to a receipt transaction
+func (r *LandedCostRepository) ListByTransaction(tenantID, transactionID string) ([]*models.LandedCost, error) {
+	query := `
+		SELECT ` + landedCostColumns + `
+		FROM landed_costs
+		WHERE tenant_id = $1 AND id IN (
+			SELECT landed_cost_id FROM landed_cost_receipts WHERE tenant_id = $1 AND transaction_id = $2
+		)
+		ORDER BY created_at DESC
+	`
+
+	return r.list(query, tenantID, transactionID)
+}
+
+// list lists landed costs and their charges and receipts
+func (r *LandedCostRepository) list(query string, args ...interface{}) ([]*models.LandedCost, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	landedCosts := []*models.LandedCost{}
+	for rows.Next() {
+		landedCost, err := scanLandedCost(rows)
+		if err != nil {
+			return nil, err
+		}
+		landedCosts = append(landedCosts, landedCost)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Get charges and receipts for each landed cost
+	for _, landedCost := range landedCosts {
+		if err := loadLandedCostLines(r.db, landedCost); err != nil {
+			return nil, err
+		}
+	}
+
+	return landedCosts, nil
+}
+
+// Post adds the costs allocated to each receipt to the received stock and journals them:
+// the share for stock still on hand is debited to the product's inventory account and
+// recorded as a zero-quantity LANDED_COST adjustment so it is included in stock valuation,
+// the share for stock already issued is debited to the product's COGS account, and the
+// total is credited to the landed cost's counter account. Products without their own
+// accounts use the accounts of the IN and OUT posting rules that match their category.
+func (r *LandedCostRepository) Post(tenantID, id, userID string) (err error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	landedCost, err := lockDraftLandedCost(tx, tenantID, id)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	description := "Landed cost " + landedCost.Number
+	accountIDs := []string{}
+	debits := map[string]float64{}
+	debit := func(accountID string, amount float64) error {
+		if amount == 0 {
+			return nil
+		}
+		if accountID == "" {
+			return models.ErrLandedCostAccount
+		}
+		if _, ok := debits[accountID]; !ok {
+			accountIDs = append(accountIDs, accountID)
+		}
+		debits[accountID] += amount
+		return nil
+	}
+
+	// Receipts of the same product share its stock on hand, so no more of them is
+	// capitalized than is in stock
+	available := map[string]float64{}
+	adjustmentIDs := []string{}
+	for i := range landedCost.Receipts {
+		receipt := &landedCost.Receipts[i]
+
+		state, err := loadProductState(tx, tenantID, receipt.ProductID)
+		if err != nil {
+			return err
+		}
+		if _, ok := available[receipt.ProductID]; !ok {
+			available[receipt.ProductID] = state.stockQuantity
+		}
+
+		split, err := capitalizeLandedCost(tx, tenantID, receipt, state, available[receipt.ProductID])
+		if err != nil {
+			return err
+		}
+		available[receipt.ProductID] -= split.quantity
+		receipt.CapitalizedCost = split.capitalized
+		receipt.ExpensedCost = roundPrice(receipt.AllocatedCost - receipt.CapitalizedCost)
+
+		inventoryAccountID, cogsAccountID := state.inventoryAccountID, state.cogsAccountID
+		if inventoryAccountID == "" {
+			rule, err := matchPostingRule(tx, tenantID, models.TransactionTypeReceipt, state.categoryID, "")
+			if err != nil && err != sql.ErrNoRows {
+				return err
+			}
+			if rule != nil {
+				inventoryAccountID = rule.InventoryAccountID
+			}
+		}
+		if cogsAccountID == "" {
+			rule, err := matchPostingRule(tx, tenantID, models.TransactionTypeIssue, state.categoryID, "")
+			if err != nil && err != sql.ErrNoRows {
+				return err
+			}
+			if rule != nil {
+				cogsAccountID = rule.CounterAccountID
+			}
+		}
+		if err := debit(inventoryAccountID, receipt.CapitalizedCost); err != nil {
+			return err
+		}
+		if err := debit(cogsAccountID, receipt.ExpensedCost); err != nil {
+			return err
+		}
+
+		// Record the capitalized cost in the transaction ledger
+		if receipt.CapitalizedCost != 0 {
+			err := tx.QueryRow(
+				`INSERT INTO inventory_transactions (tenant_id, product_id, transaction_type, quantity, unit_quantity,
+					reason_code, total_cost, reference, notes, created_by)
+				VALUES ($1, $2, $3, 0, 0, $4, $5, $6, $7, $8)
+				RETURNING id`,
+				tenantID,
+				receipt.ProductID,
+				models.TransactionTypeAdjustment,
+				models.ReasonLandedCost,
+				receipt.CapitalizedCost,
+				landedCost.Number,
+				description,
+				userID,
+			).Scan(&receipt.AdjustmentTransactionID)
+			if err != nil {
+				return err
+			}
+			adjustmentIDs = append(adjustmentIDs, receipt.AdjustmentTransactionID)
+		}
+
+		_, err = tx.Exec(
+			`UPDATE landed_cost_receipts SET capitalized_cost = $1, expensed_cost = $2, adjustment_transaction_id = $3
+			WHERE tenant_id = $4 AND id = $5`,
+			receipt.CapitalizedCost,
+			receipt.ExpensedCost,
+			nullString(receipt.AdjustmentTransactionID),
+			tenantID,
+			receipt.ID,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	entry := &models.JournalEntry{
+		TenantID:    tenantID,
+		EntryDate:   now,
+		Reference:   landedCost.Number,
+		Description: description,
+		CreatedBy:   userID,
+		Lines:       []models.JournalEntryLine{},
+	}
+	for _, accountID := range accountIDs {
+		entry.Lines = append(entry.Lines, models.JournalEntryLine{
+			AccountID:   accountID,
+			Description: description,
+			Debit:       roundPrice(debits[accountID]),
+		})
+	}
+	entry.Lines = append(entry.Lines, models.JournalEntryLine{
+		AccountID:   landedCost.CounterAccountID,
+		Description: description,
+		Credit:      roundPrice(landedCost.Total),
+	})
+	if err = insertJournalEntry(tx, entry); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		`UPDATE inventory_transactions SET journal_entry_id = $1 WHERE tenant_id = $2 AND id = ANY($3)`,
+		entry.ID,
+		tenantID,
+		pq.Array(adjustmentIDs),
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		`UPDATE landed_costs SET status = $1, journal_entry_id = $2, posted_by = $3, posted_at = $4, updated_at = $4
+		WHERE tenant_id = $5 AND id = $6`,
+		models.LandedCostPosted,
+		entry.ID,
+		userID,
+		now,
+		tenantID,
+		id,
+	)
+	return err
+}
+
+// Cancel cancels a draft landed cost
+func (r *LandedCostRepository) Cancel(tenantID, id string) error {
+	query := `
+		UPDATE landed_costs
+		SET status = $1, updated_at = $2
+		WHERE tenant_id = $3 AND id = $4 AND status = $5
+	`
+
+	result, err := r.db.Exec(query, models.LandedCostCancelled, time.Now(), tenantID, id, models.LandedCostDraft)
+	if err != nil {
+		return err
+	}
+	return requireRowAffected(result, models.ErrLandedCostStatus)
+}
\ No newline at end of file
@@ -0,0 +1,227 @@
+package db
+
+import (
+	"math"
+	"testing"
+
+	"github.com/yookibooki/erp/internal/models"
+)
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestAllocateLandedCost(t *testing.T) {
+	tests := []struct {
+		name      string
+		charges   []models.LandedCostCharge
+		receipts  []models.LandedCostReceipt
+		allocated []float64
+	}{
+		{
+			name:      "by quantity with the rounding difference on the first largest receipt",
+			charges:   []models.LandedCostCharge{{Amount: 100, AllocationMethod: models.AllocateByQuantity}},
+			receipts:  []models.LandedCostReceipt{{Quantity: 1}, {Quantity: 1}, {Quantity: 1}},
+			allocated: []float64{33.34, 33.33, 33.33},
+		},
+		{
+			name:      "rounding up is taken back from the largest receipt",
+			charges:   []models.LandedCostCharge{{Amount: 1, AllocationMethod: models.AllocateByQuantity}},
+			receipts:  []models.LandedCostReceipt{{Quantity: 1}, {Quantity: 1}, {Quantity: 4}},
+			allocated: []float64{0.17, 0.17, 0.66},
+		},
+		{
+			name:      "by value",
+			charges:   []models.LandedCostCharge{{Amount: 50, AllocationMethod: models.AllocateByValue}},
+			receipts:  []models.LandedCostReceipt{{Quantity: 10, Value: 200}, {Quantity: 1, Value: 800}},
+			allocated: []float64{10, 40},
+		},
+		{
+			name:      "by volume",
+			charges:   []models.LandedCostCharge{{Amount: 9, AllocationMethod: models.AllocateByVolume}},
+			receipts:  []models.LandedCostReceipt{{Quantity: 1, Volume: 0.5}, {Quantity: 1, Volume: 0.25}},
+			allocated: []float64{6, 3},
+		},
+		{
+			name: "charges with different methods add up",
+			charges: []models.LandedCostCharge{
+				{Amount: 30, AllocationMethod: models.AllocateByQuantity},
+				{Amount: 10, AllocationMethod: models.AllocateByWeight},
+			},
+			receipts:  []models.LandedCostReceipt{{Quantity: 1, Weight: 3}, {Quantity: 2, Weight: 1}},
+			allocated: []float64{17.5, 22.5},
+		},
+		{
+			name:      "previous allocations are replaced",
+			charges:   []models.LandedCostCharge{{Amount: 10, AllocationMethod: models.AllocateByQuantity}},
+			receipts:  []models.LandedCostReceipt{{Quantity: 1, AllocatedCost: 99}, {Quantity: 1, AllocatedCost: 99}},
+			allocated: []float64{5, 5},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			landedCost := &models.LandedCost{Charges: test.charges, Receipts: test.receipts}
+			if err := allocateLandedCost(landedCost); err != nil {
+				t.Fatalf("allocateLandedCost returned error: %v", err)
+			}
+
+			total, charged := 0.0, 0.0
+			for _, charge := range test.charges {
+				charged += charge.Amount
+			}
+			for i, receipt := range landedCost.Receipts {
+				total += receipt.AllocatedCost
+				if !almostEqual(receipt.AllocatedCost, test.allocated[i]) {
+					t.Errorf("receipt %d allocated %v, want %v", i, receipt.AllocatedCost, test.allocated[i])
+				}
+			}
+			if !almostEqual(roundPrice(total), charged) {
+				t.Errorf("allocated %v in total, want %v", total, charged)
+			}
+		})
+	}
+}
+
+func TestAllocateLandedCostUnitCost(t *testing.T) {
+	landedCost := &models.LandedCost{
+		Charges:  []models.LandedCostCharge{{Amount: 20, AllocationMethod: models.AllocateByQuantity}},
+		Receipts: []models.LandedCostReceipt{{Quantity: 4, UnitCost: 10}, {Quantity: 6, UnitCost: 12}},
+	}
+	if err := allocateLandedCost(landedCost); err != nil {
+		t.Fatalf("allocateLandedCost returned error: %v", err)
+	}
+
+	for i, want := range []float64{12, 14} {
+		if got := landedCost.Receipts[i].LandedUnitCost; !almostEqual(got, want) {
+			t.Errorf("receipt %d landed unit cost = %v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestAllocateLandedCostWithoutBasis(t *testing.T) {
+	landedCost := &models.LandedCost{
+		Charges:  []models.LandedCostCharge{{Amount: 10, AllocationMethod: models.AllocateByWeight}},
+		Receipts: []models.LandedCostReceipt{{Quantity: 1}, {Quantity: 2}},
+	}
+	if err := allocateLandedCost(landedCost); err != models.ErrAllocationBasis {
+		t.Errorf("allocateLandedCost error = %v, want %v", err, models.ErrAllocationBasis)
+	}
+}
+
+func TestSplitLandedCost(t *testing.T) {
+	tests := []struct {
+		name          string
+		costingMethod string
+		layer         *costLayerQuantity
+		stockQuantity float64
+		available     float64
+		want          landedCostSplit
+	}{
+		{
+			name:          "FIFO receipt fully on hand",
+			costingMethod: models.CostingFIFO,
+			layer:         &costLayerQuantity{quantity: 10, remaining: 10},
+			stockQuantity: 25,
+			available:     25,
+			want:          landedCostSplit{quantity: 10, capitalized: 50, layerUnitCost: 5},
+		},
+		{
+			name:          "FIFO receipt partly issued",
+			costingMethod: models.CostingFIFO,
+			layer:         &costLayerQuantity{quantity: 10, remaining: 4},
+			stockQuantity: 25,
+			available:     25,
+			want:          landedCostSplit{quantity: 4, capitalized: 20, layerUnitCost: 5},
+		},
+		{
+			name:          "FIFO receipt fully issued",
+			costingMethod: models.CostingFIFO,
+			layer:         &costLayerQuantity{quantity: 10, remaining: 0},
+			stockQuantity: 25,
+			available:     25,
+			want:          landedCostSplit{layerUnitCost: 5},
+		},
+		{
+			name:          "FIFO receipt without a layer",
+			costingMethod: models.CostingFIFO,
+			stockQuantity: 25,
+			available:     25,
+			want:          landedCostSplit{},
+		},
+		{
+			name:          "average cost with more stock than received",
+			costingMethod: models.CostingAverage,
+			stockQuantity: 25,
+			available:     25,
+			want:          landedCostSplit{quantity: 10, capitalized: 50, averageCost: 2},
+		},
+		{
+			name:          "average cost with part of the stock taken by other receipts",
+			costingMethod: models.CostingAverage,
+			stockQuantity: 25,
+			available:     6,
+			want:          landedCostSplit{quantity: 6, capitalized: 30, averageCost: 1.2},
+		},
+		{
+			name:          "average cost with part of the receipt issued",
+			costingMethod: models.CostingAverage,
+			stockQuantity: 4,
+			available:     4,
+			want:          landedCostSplit{quantity: 4, capitalized: 20, averageCost: 5},
+		},
+		{
+			name:          "average cost without stock",
+			costingMethod: models.CostingAverage,
+			stockQuantity: -3,
+			available:     -3,
+			want:          landedCostSplit{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			receipt := &models.LandedCostReceipt{Quantity: 10, AllocatedCost: 50}
+			got := splitLandedCost(receipt, test.costingMethod, test.layer, test.stockQuantity, test.available)
+
+			if !almostEqual(got.quantity, test.want.quantity) ||
+				!almostEqual(got.capitalized, test.want.capitalized) ||
+				!almostEqual(got.layerUnitCost, test.want.layerUnitCost) ||
+				!almostEqual(got.averageCost, test.want.averageCost) {
+				t.Errorf("splitLandedCost = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSplitLandedCostSharesStock(t *testing.T) {
+	// Two 10-unit receipts of the same product with 10 units left on hand
+	receipts := []models.LandedCostReceipt{
+		{Quantity: 10, AllocatedCost: 50},
+		{Quantity: 10, AllocatedCost: 50},
+	}
+	stockQuantity := 10.0
+
+	available := stockQuantity
+	capitalized, expensed, averageCost := 0.0, 0.0, 0.0
+	for i := range receipts {
+		split := splitLandedCost(&receipts[i], models.CostingAverage, nil, stockQuantity, available)
+		available -= split.quantity
+		capitalized += split.capitalized
+		expensed += receipts[i].AllocatedCost - split.capitalized
+		averageCost += split.averageCost
+	}
+
+	if !almostEqual(capitalized, 50) {
+		t.Errorf("capitalized %v, want 50", capitalized)
+	}
+	if !almostEqual(expensed, 50) {
+		t.Errorf("expensed %v, want 50", expensed)
+	}
+	if !almostEqual(averageCost, 5) {
+		t.Errorf("average cost raised by %v, want 5", averageCost)
+	}
+	if !almostEqual(available, 0) {
+		t.Errorf("%v units left available, want 0", available)
+	}
+}
\ No newline at end of file
@@ -0,0 +1,232 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/yookibooki/erp/internal/models"
+)
+
+const locationColumns = `id, tenant_id, code, name, description, quarantine, created_at, updated_at`
+
+// scanLocation scans a row selected with locationColumns
+func scanLocation(row rowScanner) (*models.Location, error) {
+	location := &models.Location{}
+	err := row.Scan(
+		&location.ID,
+		&location.TenantID,
+		&location.Code,
+		&location.Name,
+		&location.Description,
+		&location.Quarantine,
+		&location.CreatedAt,
+		&location.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return location, nil
+}
+
+// applyLocationMovement changes the stock of a transaction's product at a location within tx.
+// The stock level row is locked so that concurrent movements cannot overdraw it.
+func applyLocationMovement(tx *sql.Tx, transaction *models.InventoryTransaction, locationID string, change float64, allowNegative bool) error {
+	_, err := tx.Exec(
+		`INSERT INTO stock_levels (tenant_id, product_id, location_id, quantity)
+		VALUES ($1, $2, $3, 0)
+		ON CONFLICT (tenant_id, product_id, location_id) DO NOTHING`,
+		transaction.TenantID,
+		transaction.ProductID,
+		locationID,
+	)
+	if err != nil {
+		return err
+	}
+
+	var quantity float64
+	err = tx.QueryRow(
+		`SELECT quantity FROM stock_levels
+		WHERE tenant_id = $1 AND product_id = $2 AND location_id = $3
+		FOR UPDATE`,
+		transaction.TenantID,
+		transaction.ProductID,
+		locationID,
+	).Scan(&quantity)
+	if err != nil {
+		return err
+	}
+
+	if change < 0 && !allowNegative && roundQuantity(quantity+change) < 0 {
+		return models.ErrInsufficientStock
+	}
+
+	_, err = tx.Exec(
+		`UPDATE stock_levels SET quantity = quantity + $1, updated_at = $2
+		WHERE tenant_id = $3 AND product_id = $4 AND location_id = $5`,
+		change,
+		time.Now(),
+		transaction.TenantID,
+		transaction.ProductID,
+		locationID,
+	)
+	return err
+}
+
+// LocationRepository implements the LocationService interface
+type LocationRepository struct {
+	db *DB
+}
+
+// NewLocationRepository creates a new location repository
+func NewLocationRepository(db *DB) *LocationRepository {
+	return &LocationRepository{db: db}
+}
+
+// Create creates a new location
+func (r *LocationRepository) Create(location *models.Location) error {
+	query := `
+		INSERT INTO locations (tenant_id, code, name, description, quarantine)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at
+	`
+
+	return r.db.QueryRow(
+		query,
+		location.TenantID,
+		location.Code,
+		location.Name,
+		location.Description,
+		location.Quarantine,
+	).Scan(
+		&location.ID,
+		&location.CreatedAt,
+		&location.UpdatedAt,
+	)
+}
+
+// GetByID gets a location by ID
+func (r *LocationRepository) GetByID(tenantID, id string) (*models.Location, error) {
+	query := `
+		SELECT ` + locationColumns + `
+		FROM locations
+		WHERE tenant_id = $1 AND id = $2
+	`
+
+	location, err := scanLocation(r.db.QueryRow(query, tenantID, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return location, err
+}
+
+// GetByCode gets a location by code
+func (r *LocationRepository) GetByCode(tenantID, code string) (*models.Location, error) {
+	query := `
+		SELECT ` + locationColumns + `
+		FROM locations
+		WHERE tenant_id = $1 AND code = $2
+	`
+
+	location, err := scanLocation(r.db.QueryRow(query, tenantID, code))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return location, err
+}
+
+// List lists all locations for a tenant
+func (r *LocationRepository) List(tenantID string) ([]*models.Location, error) {
+	query := `
+		SELECT ` + locationColumns + `
+		FROM locations
+		WHERE tenant_id = $1
+		ORDER BY code
+	`
+
+	rows, err := r.db.Query(query, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	locations := []*models.Location{}
+	for rows.Next() {
+		location, err := scanLocation(rows)
+		if err != nil {
+			return nil, err
+		}
+		locations = append(locations, location)
+	}
+
+	return locations, nil
+}
+
+// ListStockByProduct lists the stock of a product at each location
+func (r *LocationRepository) ListStockByProduct(tenantID, productID string) ([]*models.StockLevel, error) {
+	query := `
+		SELECT s.product_id, s.location_id, s.quantity, s.updated_at
+		FROM stock_levels s
+		JOIN locations l ON l.id = s.location_id
+		WHERE s.tenant_id = $1 AND s.product_id = $2
+		ORDER BY l.code
+	`
+
+	rows, err := r.db.Query(query, tenantID, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	levels := []*models.StockLevel{}
+	for rows.Next() {
+		level := &models.StockLevel{}
+		err := rows.Scan(
+			&level.ProductID,
+			&level.LocationID,
+			&level.Quantity,
+			&level.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		levels = append(levels, level)
+	}
+
+	return levels, nil
+}
+
+// Update updates a location
+func (r *LocationRepository) Update(location *models.Location) error {
+	query := `
+		UPDATE locations
+		SET code = $1, name = $2, description = $3, quarantine = $4, updated_at = $5
+		WHERE tenant_id = $6 AND id = $7
+	`
+
+	now := time.Now()
+	_, err := r.db.Exec(
+		query,
+		location.Code,
+		location.Name,
+		location.Description,
+		location.Quarantine,
+		now,
+		location.TenantID,
+		location.ID,
+	)
+	location.UpdatedAt = now
+	return err
+}
+
+// Delete deletes a location
+func (r *LocationRepository) Delete(tenantID, id string) error {
+	query := `
+		DELETE FROM locations
+		WHERE tenant_id = $1 AND id = $2
+	`
+
+	_, err := r.db.Exec(query, tenantID, id)
+	return err
+}
\ No newline at end of file
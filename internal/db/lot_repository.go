@@ -0,0 +1,228 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/yookibooki/erp/internal/models"
+)
+
+const lotColumns = `id, tenant_id, product_id, lot_number, serial_number, expiry_date, quantity, created_at, updated_at`
+
+// scanLot scans a row selected with lotColumns
+func scanLot(row rowScanner) (*models.Lot, error) {
+	lot := &models.Lot{}
+	err := row.Scan(
+		&lot.ID,
+		&lot.TenantID,
+		&lot.ProductID,
+		&lot.LotNumber,
+		&lot.SerialNumber,
+		&lot.ExpiryDate,
+		&lot.Quantity,
+		&lot.CreatedAt,
+		&lot.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return lot, nil
+}
+
+// applyLotMovement applies a stock change to the lot or serial number of a transaction within tx
+func applyLotMovement(tx *sql.Tx, transaction *models.InventoryTransaction, trackingMode string, change float64) error {
+	// Serial numbers are unique per product, lots are keyed by lot number
+	query := `
+		SELECT ` + lotColumns + `
+		FROM inventory_lots
+		WHERE tenant_id = $1 AND product_id = $2 AND lot_number = $3 AND serial_number = ''
+		FOR UPDATE
+	`
+	key := transaction.LotNumber
+	if trackingMode == models.TrackingSerial {
+		query = `
+			SELECT ` + lotColumns + `
+			FROM inventory_lots
+			WHERE tenant_id = $1 AND product_id = $2 AND serial_number = $3
+			FOR UPDATE
+		`
+		key = transaction.SerialNumber
+	}
+
+	lot, err := scanLot(tx.QueryRow(query, transaction.TenantID, transaction.ProductID, key))
+	if err == sql.ErrNoRows {
+		lot = nil
+	} else if err != nil {
+		return err
+	}
+
+	if change < 0 {
+		if lot == nil || roundQuantity(lot.Quantity+change) < 0 {
+			return models.ErrInsufficientLotQuantity
+		}
+		if transaction.ExpiryDate == nil {
+			transaction.ExpiryDate = lot.ExpiryDate
+		}
+	}
+
+	if lot == nil {
+		query := `
+			INSERT INTO inventory_lots (tenant_id, product_id, lot_number, serial_number, expiry_date, quantity)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`
+
+		_, err = tx.Exec(
+			query,
+			transaction.TenantID,
+			transaction.ProductID,
+			transaction.LotNumber,
+			transaction.SerialNumber,
+			transaction.ExpiryDate,
+			change,
+		)
+		return err
+	}
+
+	if trackingMode == models.TrackingSerial && change > 0 && lot.Quantity > 0 {
+		return models.ErrSerialNumberInStock
+	}
+
+	query = `
+		UPDATE inventory_lots
+		SET quantity = quantity + $1, expiry_date = COALESCE($2, expiry_date), updated_at = $3
+		WHERE tenant_id = $4 AND id = $5
+	`
+
+	_, err = tx.Exec(query, change, transaction.ExpiryDate, time.Now(), lot.TenantID, lot.ID)
+	return err
+}
+
+// LotRepository implements the LotService interface
+type LotRepository struct {
+	db *DB
+}
+
+// NewLotRepository creates a new lot repository
+func NewLotRepository(db *DB) *LotRepository {
+	return &LotRepository{db: db}
+}
+
+// ListByProduct lists all lots of a product, earliest expiry first
+func (r *LotRepository) ListByProduct(tenantID, productID string) ([]*models.Lot, error) {
+	query := `
+		SELECT ` + lotColumns + `
+		FROM inventory_lots
+		WHERE tenant_id = $1 AND product_id = $2
+		ORDER BY expiry_date NULLS LAST, created_at
+	`
+
+	return r.query(query, tenantID, productID)
+}
+
+// SuggestFEFO suggests the lots to pick a quantity of a product from, first expired first out.
+// Lots that have already expired are skipped.
+func (r *LotRepository) SuggestFEFO(tenantID, productID string, quantity float64) (*models.FEFOSuggestion, error) {
+	query := `
+		SELECT ` + lotColumns + `
+		FROM inventory_lots
+		WHERE tenant_id = $1 AND product_id = $2 AND quantity > 0
+			AND (expiry_date IS NULL OR expiry_date >= $3)
+		ORDER BY expiry_date NULLS LAST, created_at
+	`
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	lots, err := r.query(query, tenantID, productID, today)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestion := &models.FEFOSuggestion{
+		ProductID: productID,
+		Quantity:  quantity,
+		Picks:     []*models.LotPick{},
+	}
+
+	remaining := quantity
+	for _, lot := range lots {
+		if remaining <= 0 {
+			break
+		}
+		pick := lot.Quantity
+		if pick > remaining {
+			pick = remaining
+		}
+		suggestion.Picks = append(suggestion.Picks, &models.LotPick{Lot: lot, Quantity: pick})
+		remaining = roundQuantity(remaining - pick)
+	}
+	suggestion.Shortfall = remaining
+
+	return suggestion, nil
+}
+
+// Trace lists every lot record and movement of a lot or serial number
+func (r *LotRepository) Trace(tenantID, lotNumber, serialNumber string) (*models.LotTrace, error) {
+	filter := `lot_number = $2`
+	key := lotNumber
+	if serialNumber != "" {
+		filter = `serial_number = $2`
+		key = serialNumber
+	}
+
+	lots, err := r.query(`
+		SELECT `+lotColumns+`
+		FROM inventory_lots
+		WHERE tenant_id = $1 AND `+filter+`
+		ORDER BY product_id, created_at
+	`, tenantID, key)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions, err := queryInventoryTransactions(r.db, `
+		SELECT `+inventoryTransactionColumns+`
+		FROM inventory_transactions
+		WHERE tenant_id = $1 AND `+filter+`
+		ORDER BY created_at
+	`, tenantID, key)
+	if err != nil {
+		return nil, err
+	}
+
+	trace := &models.LotTrace{
+		LotNumber:    lotNumber,
+		SerialNumber: serialNumber,
+		Lots:         lots,
+		Backward:     []*models.InventoryTransaction{},
+		Forward:      []*models.InventoryTransaction{},
+	}
+	for _, transaction := range transactions {
+		if stockChange(transaction) > 0 {
+			trace.Backward = append(trace.Backward, transaction)
+		} else {
+			trace.Forward = append(trace.Forward, transaction)
+		}
+	}
+
+	return trace, nil
+}
+
+// query runs a query selecting lotColumns
+func (r *LotRepository) query(query string, args ...interface{}) ([]*models.Lot, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lots := []*models.Lot{}
+	for rows.Next() {
+		lot, err := scanLot(rows)
+		if err != nil {
+			return nil, err
+		}
+		lots = append(lots, lot)
+	}
+
+	return lots, nil
+}
\ No newline at end of file
@@ -0,0 +1,1087 @@
+package db
+
+import (
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/yookibooki/erp/internal/models"
+)
+
+const billOfMaterialsColumns = `id, tenant_id, product_id, quantity, notes, created_at, updated_at`
+
+// scanBillOfMaterials scans a row selected with billOfMaterialsColumns
+func scanBillOfMaterials(row rowScanner) (*models.BillOfMaterials, error) {
+	bom := &models.BillOfMaterials{}
+	err := row.Scan(
+		&bom.ID,
+		&bom.TenantID,
+		&bom.ProductID,
+		&bom.Quantity,
+		&bom.Notes,
+		&bom.CreatedAt,
+		&bom.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return bom, nil
+}
+
+// loadBOMLines loads the lines of a bill of materials
+func loadBOMLines(q queryer, bom *models.BillOfMaterials) error {
+	query := `
+		SELECT id, tenant_id, bom_id, line_number, component_id, quantity, scrap_percent, created_at, updated_at
+		FROM bom_lines
+		WHERE tenant_id = $1 AND bom_id = $2
+		ORDER BY line_number
+	`
+
+	rows, err := q.Query(query, bom.TenantID, bom.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	bom.Lines = []models.BOMLine{}
+	for rows.Next() {
+		line := models.BOMLine{}
+		err := rows.Scan(
+			&line.ID,
+			&line.TenantID,
+			&line.BOMID,
+			&line.LineNumber,
+			&line.ComponentID,
+			&line.Quantity,
+			&line.ScrapPercent,
+			&line.CreatedAt,
+			&line.UpdatedAt,
+		)
+		if err != nil {
+			return err
+		}
+		bom.Lines = append(bom.Lines, line)
+	}
+
+	return rows.Err()
+}
+
+// insertBOMLines inserts the lines of a bill of materials within tx
+func insertBOMLines(tx *sql.Tx, bom *models.BillOfMaterials) error {
+	for i := range bom.Lines {
+		line := &bom.Lines[i]
+		if line.Quantity <= 0 {
+			return models.ErrInvalidBOMQuantity
+		}
+		if line.ScrapPercent < 0 {
+			return models.ErrNegativeScrapPercent
+		}
+
+		line.TenantID = bom.TenantID
+		line.BOMID = bom.ID
+		line.LineNumber = i + 1
+
+		query := `
+			INSERT INTO bom_lines (tenant_id, bom_id, line_number, component_id, quantity, scrap_percent)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING id, created_at, updated_at
+		`
+
+		err := tx.QueryRow(
+			query,
+			line.TenantID,
+			line.BOMID,
+			line.LineNumber,
+			line.ComponentID,
+			line.Quantity,
+			line.ScrapPercent,
+		).Scan(
+			&line.ID,
+			&line.CreatedAt,
+			&line.UpdatedAt,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkBOMCycle returns ErrBOMCycle if the lines of a bill of materials would make the
+// product one of its own components, directly or through the bills of its components
+func checkBOMCycle(tx *sql.Tx, bom *models.BillOfMaterials) error {
+	rows, err := tx.Query(
+		`SELECT b.product_id, l.component_id
+		FROM bills_of_materials b
+		JOIN bom_lines l ON l.bom_id = b.id
+		WHERE b.tenant_id = $1 AND b.product_id <> $2`,
+		bom.TenantID,
+		bom.ProductID,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	components := map[string][]string{}
+	for rows.Next() {
+		var productID, componentID string
+		if err := rows.Scan(&productID, &componentID); err != nil {
+			return err
+		}
+		components[productID] = append(components[productID], componentID)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	// Walk the components of the bill looking for the product it makes
+	visited := map[string]bool{}
+	pending := []string{}
+	for _, line := range bom.Lines {
+		pending = append(pending, line.ComponentID)
+	}
+	for len(pending) > 0 {
+		productID := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if productID == bom.ProductID {
+			return models.ErrBOMCycle
+		}
+		if visited[productID] {
+			continue
+		}
+		visited[productID] = true
+		pending = append(pending, components[productID]...)
+	}
+
+	return nil
+}
+
+// BillOfMaterialsRepository implements the BillOfMaterialsService interface
+type BillOfMaterialsRepository struct {
+	db *DB
+}
+
+// NewBillOfMaterialsRepository creates a new bill of materials repository
+func NewBillOfMaterialsRepository(db *DB) *BillOfMaterialsRepository {
+	return &BillOfMaterialsRepository{db: db}
+}
+
+// Create creates a new bill of materials
+func (r *BillOfMaterialsRepository) Create(bom *models.BillOfMaterials) (err error) {
+	if bom.Quantity <= 0 {
+		return models.ErrInvalidBOMQuantity
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	if err = checkBOMCycle(tx, bom); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO bills_of_materials (tenant_id, product_id, quantity, notes)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at, updated_at
+	`
+
+	err = tx.QueryRow(
+		query,
+		bom.TenantID,
+		bom.ProductID,
+		bom.Quantity,
+		bom.Notes,
+	).Scan(
+		&bom.ID,
+		&bom.CreatedAt,
+		&bom.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	return insertBOMLines(tx, bom)
+}
+
+// GetByID gets a bill of materials by ID
+func (r *BillOfMaterialsRepository) GetByID(tenantID, id string) (*models.BillOfMaterials, error) {
+	query := `
+		SELECT ` + billOfMaterialsColumns + `
+		FROM bills_of_materials
+		WHERE tenant_id = $1 AND id = $2
+	`
+
+	return r.get(query, tenantID, id)
+}
+
+// GetByProduct gets the bill of materials of a product
+func (r *BillOfMaterialsRepository) GetByProduct(tenantID, productID string) (*models.BillOfMaterials, error) {
+	query := `
+		SELECT ` + billOfMaterialsColumns + `
+		FROM bills_of_materials
+		WHERE tenant_id = $1 AND product_id = $2
+	`
+
+	return r.get(query, tenantID, productID)
+}
+
+// get gets a bill of materials and its lines
+func (r *BillOfMaterialsRepository) get(query string, args ...interface{}) (*models.BillOfMaterials, error) {
+	bom, err := scanBillOfMaterials(r.db.QueryRow(query, args...))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := loadBOMLines(r.db, bom); err != nil {
+		return nil, err
+	}
+
+	return bom, nil
+}
+
+// List lists all bills of materials for a tenant
+func (r *BillOfMaterialsRepository) List(tenantID string) ([]*models.BillOfMaterials, error) {
+	query := `
+		SELECT ` + billOfMaterialsColumns + `
+		FROM bills_of_materials
+		WHERE tenant_id = $1
+		ORDER BY created_at
+	`
+
+	rows, err := r.db.Query(query, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	boms := []*models.BillOfMaterials{}
+	for rows.Next() {
+		bom, err := scanBillOfMaterials(rows)
+		if err != nil {
+			return nil, err
+		}
+		boms = append(boms, bom)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Get lines for each bill of materials
+	for _, bom := range boms {
+		if err := loadBOMLines(r.db, bom); err != nil {
+			return nil, err
+		}
+	}
+
+	return boms, nil
+}
+
+// Update updates a bill of materials and replaces its lines
+func (r *BillOfMaterialsRepository) Update(bom *models.BillOfMaterials) (err error) {
+	if bom.Quantity <= 0 {
+		return models.ErrInvalidBOMQuantity
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	if err = checkBOMCycle(tx, bom); err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE bills_of_materials
+		SET quantity = $1, notes = $2, updated_at = $3
+		WHERE tenant_id = $4 AND id = $5
+	`
+
+	now := time.Now()
+	_, err = tx.Exec(query, bom.Quantity, bom.Notes, now, bom.TenantID, bom.ID)
+	if err != nil {
+		return err
+	}
+	bom.UpdatedAt = now
+
+	_, err = tx.Exec(`DELETE FROM bom_lines WHERE tenant_id = $1 AND bom_id = $2`, bom.TenantID, bom.ID)
+	if err != nil {
+		return err
+	}
+
+	return insertBOMLines(tx, bom)
+}
+
+// Delete deletes a bill of materials
+func (r *BillOfMaterialsRepository) Delete(tenantID, id string) error {
+	query := `
+		DELETE FROM bills_of_materials
+		WHERE tenant_id = $1 AND id = $2
+	`
+
+	_, err := r.db.Exec(query, tenantID, id)
+	return err
+}
+
+const workOrderColumns = `id, tenant_id, number, product_id, COALESCE(bom_id::text, ''), COALESCE(location_id::text, ''),
+	quantity, produced_quantity, scrapped_quantity, status, planned_date, notes, COALESCE(output_transaction_id::text, ''),
+	created_by, released_at, completed_at, created_at, updated_at`
+
+// scanWorkOrder scans a row selected with workOrderColumns
+func scanWorkOrder(row rowScanner) (*models.WorkOrder, error) {
+	order := &models.WorkOrder{}
+	err := row.Scan(
+		&order.ID,
+		&order.TenantID,
+		&order.Number,
+		&order.ProductID,
+		&order.BOMID,
+		&order.LocationID,
+		&order.Quantity,
+		&order.ProducedQuantity,
+		&order.ScrappedQuantity,
+		&order.Status,
+		&order.PlannedDate,
+		&order.Notes,
+		&order.OutputTransactionID,
+		&order.CreatedBy,
+		&order.ReleasedAt,
+		&order.CompletedAt,
+		&order.CreatedAt,
+		&order.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if made := order.ProducedQuantity + order.ScrappedQuantity; made > 0 {
+		order.YieldPercent = order.ProducedQuantity / made * 100
+	}
+	return order, nil
+}
+
+// loadWorkOrderComponents loads the components of a work order with what is still reserved for them
+func loadWorkOrderComponents(q queryer, order *models.WorkOrder) error {
+	query := `
+		SELECT c.id, c.tenant_id, c.work_order_id, c.product_id, c.quantity, COALESCE(c.reservation_id::text, ''),
+			COALESCE((SELECT r.quantity FROM stock_reservations r WHERE r.id = c.reservation_id AND ` + activeReservationSQL + `), 0),
+			c.consumed_quantity, c.created_at, c.updated_at
+		FROM work_order_components c
+		WHERE c.tenant_id = $1 AND c.work_order_id = $2
+		ORDER BY c.created_at, c.id
+	`
+
+	rows, err := q.Query(query, order.TenantID, order.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	order.Components = []models.WorkOrderComponent{}
+	for rows.Next() {
+		component := models.WorkOrderComponent{}
+		err := rows.Scan(
+			&component.ID,
+			&component.TenantID,
+			&component.WorkOrderID,
+			&component.ProductID,
+			&component.Quantity,
+			&component.ReservationID,
+			&component.ReservedQuantity,
+			&component.ConsumedQuantity,
+			&component.CreatedAt,
+			&component.UpdatedAt,
+		)
+		if err != nil {
+			return err
+		}
+		order.Components = append(order.Components, component)
+	}
+
+	return rows.Err()
+}
+
+// insertWorkOrderComponents explodes the bill of materials of a work order one level
+// into the components it needs within tx. The bill defaults to the product's.
+func insertWorkOrderComponents(tx *sql.Tx, order *models.WorkOrder) error {
+	query := `
+		SELECT ` + billOfMaterialsColumns + `
+		FROM bills_of_materials
+		WHERE tenant_id = $1 AND (id::text = $2 OR ($2 = '' AND product_id = $3))
+	`
+
+	bom, err := scanBillOfMaterials(tx.QueryRow(query, order.TenantID, order.BOMID, order.ProductID))
+	if err == sql.ErrNoRows || (err == nil && bom.ProductID != order.ProductID) {
+		return models.ErrNoBOM
+	}
+	if err != nil {
+		return err
+	}
+	order.BOMID = bom.ID
+
+	if err := loadBOMLines(tx, bom); err != nil {
+		return err
+	}
+
+	// Components listed more than once are needed once in total
+	quantities := map[string]float64{}
+	productIDs := []string{}
+	for _, line := range bom.Lines {
+		if _, ok := quantities[line.ComponentID]; !ok {
+			productIDs = append(productIDs, line.ComponentID)
+		}
+		quantities[line.ComponentID] += line.Quantity * order.Quantity / bom.Quantity * (1 + line.ScrapPercent/100)
+	}
+
+	order.Components = []models.WorkOrderComponent{}
+	for _, productID := range productIDs {
+		component := models.WorkOrderComponent{
+			TenantID:    order.TenantID,
+			WorkOrderID: order.ID,
+			ProductID:   productID,
+			Quantity:    roundQuantity(quantities[productID]),
+		}
+
+		err := tx.QueryRow(
+			`INSERT INTO work_order_components (tenant_id, work_order_id, product_id, quantity)
+			VALUES ($1, $2, $3, $4)
+			RETURNING id, created_at, updated_at`,
+			component.TenantID,
+			component.WorkOrderID,
+			component.ProductID,
+			component.Quantity,
+		).Scan(
+			&component.ID,
+			&component.CreatedAt,
+			&component.UpdatedAt,
+		)
+		if err != nil {
+			return err
+		}
+		order.Components = append(order.Components, component)
+	}
+
+	_, err = tx.Exec(`UPDATE work_orders SET bom_id = $1 WHERE tenant_id = $2 AND id = $3`, order.BOMID, order.TenantID, order.ID)
+	return err
+}
+
+// lockWorkOrder locks a work order within tx and loads its components
+func lockWorkOrder(tx *sql.Tx, tenantID, id string) (*models.WorkOrder, error) {
+	query := `
+		SELECT ` + workOrderColumns + `
+		FROM work_orders
+		WHERE tenant_id = $1 AND id = $2
+		FOR UPDATE
+	`
+
+	order, err := scanWorkOrder(tx.QueryRow(query, tenantID, id))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := loadWorkOrderComponents(tx, order); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// WorkOrderRepository implements the WorkOrderService interface
+type WorkOrderRepository struct {
+	db           *DB
+	transactions *InventoryTransactionRepository
+}
+
+// NewWorkOrderRepository creates a new work order repository
+func NewWorkOrderRepository(db *DB, transactions *InventoryTransactionRepository) *WorkOrderRepository {
+	return &WorkOrderRepository{db: db, transactions: transactions}
+}
+
+// Create creates a new planned work order with the components of its bill of materials
+func (r *WorkOrderRepository) Create(order *models.WorkOrder) (err error) {
+	if order.Quantity <= 0 {
+		return models.ErrInvalidBOMQuantity
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	order.Status = models.WorkOrderPlanned
+	query := `
+		INSERT INTO work_orders (tenant_id, number, product_id, location_id, quantity, status, planned_date, notes, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at, updated_at
+	`
+
+	err = tx.QueryRow(
+		query,
+		order.TenantID,
+		order.Number,
+		order.ProductID,
+		nullString(order.LocationID),
+		order.Quantity,
+		order.Status,
+		order.PlannedDate,
+		order.Notes,
+		order.CreatedBy,
+	).Scan(
+		&order.ID,
+		&order.CreatedAt,
+		&order.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	return insertWorkOrderComponents(tx, order)
+}
+
+// GetByID gets a work order by ID
+func (r *WorkOrderRepository) GetByID(tenantID, id string) (*models.WorkOrder, error) {
+	query := `
+		SELECT ` + workOrderColumns + `
+		FROM work_orders
+		WHERE tenant_id = $1 AND id = $2
+	`
+
+	return r.get(query, tenantID, id)
+}
+
+// GetByNumber gets a work order by number
+func (r *WorkOrderRepository) GetByNumber(tenantID, number string) (*models.WorkOrder, error) {
+	query := `
+		SELECT ` + workOrderColumns + `
+		FROM work_orders
+		WHERE tenant_id = $1 AND number = $2
+	`
+
+	return r.get(query, tenantID, number)
+}
+
+// get gets a work order and its components
+func (r *WorkOrderRepository) get(query string, args ...interface{}) (*models.WorkOrder, error) {
+	order, err := scanWorkOrder(r.db.QueryRow(query, args...))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := loadWorkOrderComponents(r.db, order); err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// List lists all work orders for a tenant, optionally filtered by status
+func (r *WorkOrderRepository) List(tenantID, status string) ([]*models.WorkOrder, error) {
+	query := `
+		SELECT ` + workOrderColumns + `
+		FROM work_orders
+		WHERE tenant_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY planned_date NULLS LAST, number
+	`
+
+	rows, err := r.db.Query(query, tenantID, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orders := []*models.WorkOrder{}
+	for rows.Next() {
+		order, err := scanWorkOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Get components for each work order
+	for _, order := range orders {
+		if err := loadWorkOrderComponents(r.db, order); err != nil {
+			return nil, err
+		}
+	}
+
+	return orders, nil
+}
+
+// Update updates a planned work order and explodes its bill of materials again
+func (r *WorkOrderRepository) Update(order *models.WorkOrder) (err error) {
+	if order.Quantity <= 0 {
+		return models.ErrInvalidBOMQuantity
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	query := `
+		UPDATE work_orders
+		SET number = $1, product_id = $2, location_id = $3, quantity = $4, planned_date = $5, notes = $6, updated_at = $7
+		WHERE tenant_id = $8 AND id = $9 AND status = $10
+	`
+
+	now := time.Now()
+	result, err := tx.Exec(
+		query,
+		order.Number,
+		order.ProductID,
+		nullString(order.LocationID),
+		order.Quantity,
+		order.PlannedDate,
+		order.Notes,
+		now,
+		order.TenantID,
+		order.ID,
+		models.WorkOrderPlanned,
+	)
+	if err != nil {
+		return err
+	}
+	if err = requireRowAffected(result, models.ErrWorkOrderStatus); err != nil {
+		return err
+	}
+	order.Status = models.WorkOrderPlanned
+	order.UpdatedAt = now
+
+	_, err = tx.Exec(`DELETE FROM work_order_components WHERE tenant_id = $1 AND work_order_id = $2`, order.TenantID, order.ID)
+	if err != nil {
+		return err
+	}
+
+	return insertWorkOrderComponents(tx, order)
+}
+
+// Release releases a planned work order to production and reserves what stock of its components is available
+func (r *WorkOrderRepository) Release(tenantID, id, userID string) (err error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	order, err := lockWorkOrder(tx, tenantID, id)
+	if err != nil {
+		return err
+	}
+
+	if order.Status != models.WorkOrderPlanned {
+		return models.ErrWorkOrderStatus
+	}
+
+	now := time.Now()
+	for _, component := range order.Components {
+		available, err := availableTx(tx, tenantID, component.ProductID, order.LocationID)
+		if err != nil {
+			return err
+		}
+
+		quantity := component.Quantity
+		if available < quantity {
+			quantity = available
+		}
+		if quantity <= 0 {
+			continue
+		}
+
+		reservation := &models.Reservation{
+			TenantID:     tenantID,
+			ProductID:    component.ProductID,
+			LocationID:   order.LocationID,
+			Quantity:     quantity,
+			Reference:    order.Number,
+			RequiredDate: order.PlannedDate,
+			CreatedBy:    userID,
+		}
+		if err := createReservationTx(tx, reservation); err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(
+			`UPDATE work_order_components SET reservation_id = $1, updated_at = $2 WHERE tenant_id = $3 AND id = $4`,
+			reservation.ID,
+			now,
+			tenantID,
+			component.ID,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(
+		`UPDATE work_orders SET status = $1, released_at = $2, updated_at = $2 WHERE tenant_id = $3 AND id = $4`,
+		models.WorkOrderReleased,
+		now,
+		tenantID,
+		id,
+	)
+	return err
+}
+
+// Complete completes a released work order in one step: the components are issued,
+// their reservations settled, and the produced quantity is received at the cost of
+// the components consumed
+func (r *WorkOrderRepository) Complete(tenantID, id, userID string, completion *models.WorkOrderCompletion) (err error) {
+	if completion.ProducedQuantity <= 0 || completion.ScrappedQuantity < 0 {
+		return models.ErrInvalidProduction
+	}
+
+	stock, err := beginStockTx(r.db)
+	if err != nil {
+		return err
+	}
+	defer func() { err = r.transactions.finishTx(stock, err) }()
+	tx := stock.Tx
+
+	order, err := lockWorkOrder(tx, tenantID, id)
+	if err != nil {
+		return err
+	}
+
+	if order.Status != models.WorkOrderReleased {
+		return models.ErrWorkOrderStatus
+	}
+
+	lots := map[string][]models.ComponentLot{}
+	for _, lot := range completion.ComponentLots {
+		if lot.Quantity <= 0 {
+			return models.ErrComponentLots
+		}
+		lots[lot.ProductID] = append(lots[lot.ProductID], lot)
+	}
+
+	// Components are consumed in proportion to what was made, good or not
+	share := (completion.ProducedQuantity + completion.ScrappedQuantity) / order.Quantity
+	cost := 0.0
+	now := time.Now()
+	for _, component := range order.Components {
+		consumed := roundQuantity(component.Quantity * share)
+
+		issues := lots[component.ProductID]
+		delete(lots, component.ProductID)
+		remaining := consumed
+		for _, lot := range issues {
+			remaining = roundQuantity(remaining - lot.Quantity)
+		}
+		if remaining < 0 {
+			return models.ErrComponentLots
+		}
+		if remaining > 0 {
+			issues = append(issues, models.ComponentLot{ProductID: component.ProductID, Quantity: remaining})
+		}
+
+		for _, issue := range issues {
+			transaction := &models.InventoryTransaction{
+				TenantID:        tenantID,
+				ProductID:       component.ProductID,
+				TransactionType: models.TransactionTypeIssue,
+				Quantity:        issue.Quantity,
+				LocationID:      order.LocationID,
+				LotNumber:       issue.LotNumber,
+				SerialNumber:    issue.SerialNumber,
+				Reference:       order.Number,
+				Notes:           "Components for work order " + order.Number,
+				CreatedBy:       userID,
+			}
+			if err := r.transactions.createTx(stock, transaction); err != nil {
+				return err
+			}
+			cost -= transaction.TotalCost
+		}
+
+		// Settle the reservation, releasing whatever was not consumed
+		if component.ReservationID != "" {
+			if err := consumeReservationTx(tx, tenantID, component.ReservationID, consumed); err != nil {
+				return err
+			}
+			if err := releaseReservationTx(tx, tenantID, component.ReservationID); err != nil {
+				return err
+			}
+		}
+
+		_, err := tx.Exec(
+			`UPDATE work_order_components SET consumed_quantity = $1, updated_at = $2 WHERE tenant_id = $3 AND id = $4`,
+			consumed,
+			now,
+			tenantID,
+			component.ID,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Lots must belong to a component of the work order
+	if len(lots) > 0 {
+		return models.ErrComponentLots
+	}
+
+	output := &models.InventoryTransaction{
+		TenantID:        tenantID,
+		ProductID:       order.ProductID,
+		TransactionType: models.TransactionTypeReceipt,
+		Quantity:        completion.ProducedQuantity,
+		LocationID:      order.LocationID,
+		LotNumber:       completion.LotNumber,
+		SerialNumber:    completion.SerialNumber,
+		ExpiryDate:      completion.ExpiryDate,
+		UnitCost:        cost / completion.ProducedQuantity,
+		Reference:       order.Number,
+		Notes:           "Output of work order " + order.Number,
+		CreatedBy:       userID,
+	}
+	if err = r.transactions.createTx(stock, output); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		`UPDATE work_orders
+		SET status = $1, produced_quantity = $2, scrapped_quantity = $3, output_transaction_id = $4,
+			completed_at = $5, updated_at = $5
+		WHERE tenant_id = $6 AND id = $7`,
+		models.WorkOrderCompleted,
+		completion.ProducedQuantity,
+		completion.ScrappedQuantity,
+		output.ID,
+		now,
+		tenantID,
+		id,
+	)
+	return err
+}
+
+// Cancel cancels a planned or released work order and releases its reservations
+func (r *WorkOrderRepository) Cancel(tenantID, id string) (err error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	order, err := lockWorkOrder(tx, tenantID, id)
+	if err != nil {
+		return err
+	}
+
+	if order.Status != models.WorkOrderPlanned && order.Status != models.WorkOrderReleased {
+		return models.ErrWorkOrderStatus
+	}
+
+	for _, component := range order.Components {
+		if component.ReservationID != "" {
+			if err := releaseReservationTx(tx, tenantID, component.ReservationID); err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err = tx.Exec(
+		`UPDATE work_orders SET status = $1, updated_at = $2 WHERE tenant_id = $3 AND id = $4`,
+		models.WorkOrderCancelled,
+		time.Now(),
+		tenantID,
+		id,
+	)
+	return err
+}
+
+// Delete deletes a planned work order
+func (r *WorkOrderRepository) Delete(tenantID, id string) error {
+	query := `
+		DELETE FROM work_orders
+		WHERE tenant_id = $1 AND id = $2 AND status = $3
+	`
+
+	result, err := r.db.Exec(query, tenantID, id, models.WorkOrderPlanned)
+	if err != nil {
+		return err
+	}
+	return requireRowAffected(result, models.ErrWorkOrderStatus)
+}
+
+// bomComponent is a component of a bill of materials per unit of the product it makes
+type bomComponent struct {
+	productID string
+	quantity  float64
+}
+
+// maxBOMLevels bounds the explosion of multi-level bills of materials
+const maxBOMLevels = 50
+
+// MaterialRequirements computes the components planned work orders need. The
+// components of the work orders are netted against available stock, and the
+// shortage of components with a bill of materials is exploded a level further.
+func (r *WorkOrderRepository) MaterialRequirements(tenantID string) ([]*models.MaterialRequirement, error) {
+	// Components of the bills of materials per unit made
+	rows, err := r.db.Query(
+		`SELECT b.product_id, l.component_id, l.quantity / b.quantity * (1 + l.scrap_percent / 100)
+		FROM bills_of_materials b
+		JOIN bom_lines l ON l.bom_id = b.id
+		WHERE b.tenant_id = $1`,
+		tenantID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	boms := map[string][]bomComponent{}
+	for rows.Next() {
+		var productID string
+		component := bomComponent{}
+		if err := rows.Scan(&productID, &component.productID, &component.quantity); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		boms[productID] = append(boms[productID], component)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// First level requirements are the components of planned work orders
+	rows, err = r.db.Query(
+		`SELECT c.product_id, SUM(c.quantity)
+		FROM work_order_components c
+		JOIN work_orders w ON w.id = c.work_order_id
+		WHERE w.tenant_id = $1 AND w.status = $2
+		GROUP BY c.product_id`,
+		tenantID,
+		models.WorkOrderPlanned,
+	)
+	if err != nil {
+		return nil, err
+	}
+	needs := map[string]float64{}
+	for rows.Next() {
+		var productID string
+		var quantity float64
+		if err := rows.Scan(&productID, &quantity); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		needs[productID] = quantity
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	requirements := map[string]*models.MaterialRequirement{}
+	remaining := map[string]float64{}
+	for level := 1; len(needs) > 0 && level <= maxBOMLevels; level++ {
+		next := map[string]float64{}
+		for productID, need := range needs {
+			requirement, ok := requirements[productID]
+			if !ok {
+				product, err := scanProduct(r.db.QueryRow(
+					`SELECT `+productColumns+` FROM products WHERE tenant_id = $1 AND id = $2`,
+					tenantID,
+					productID,
+				))
+				if err != nil {
+					return nil, err
+				}
+
+				requirement = &models.MaterialRequirement{
+					ProductID:    product.ID,
+					ProductCode:  product.Code,
+					ProductName:  product.Name,
+					Manufactured: boms[productID] != nil,
+				}
+				if product.AvailableQuantity > 0 {
+					requirement.Available = product.AvailableQuantity
+				}
+				remaining[productID] = requirement.Available
+				requirements[productID] = requirement
+			}
+
+			requirement.Level = level
+			requirement.Required = roundQuantity(requirement.Required + need)
+
+			covered := need
+			if remaining[productID] < covered {
+				covered = remaining[productID]
+			}
+			remaining[productID] -= covered
+			shortage := roundQuantity(need - covered)
+			requirement.Shortage = roundQuantity(requirement.Shortage + shortage)
+
+			// Sub-assemblies that are short have to be made from their own components
+			if shortage > 0 {
+				for _, component := range boms[productID] {
+					next[component.productID] += shortage * component.quantity
+				}
+			}
+		}
+		needs = next
+	}
+
+	lines := []*models.MaterialRequirement{}
+	for _, requirement := range requirements {
+		lines = append(lines, requirement)
+	}
+	sort.Slice(lines, func(i, j int) bool {
+		if lines[i].Level != lines[j].Level {
+			return lines[i].Level < lines[j].Level
+		}
+		return lines[i].ProductCode < lines[j].ProductCode
+	})
+
+	return lines, nil
+}
\ No newline at end of file
@@ -0,0 +1,860 @@
+package db
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/yookibooki/erp/internal/models"
+)
+
+const leadColumns = `id, tenant_id, company, first_name, last_name, email, phone, source, status,
+	COALESCE(owner_id::text, ''), notes, COALESCE(customer_id::text, ''), COALESCE(contact_id::text, ''),
+	COALESCE(opportunity_id::text, ''), converted_at, created_at, updated_at`
+
+// scanLead scans a row selected with leadColumns
+func scanLead(row rowScanner) (*models.Lead, error) {
+	lead := &models.Lead{}
+	err := row.Scan(
+		&lead.ID,
+		&lead.TenantID,
+		&lead.Company,
+		&lead.FirstName,
+		&lead.LastName,
+		&lead.Email,
+		&lead.Phone,
+		&lead.Source,
+		&lead.Status,
+		&lead.OwnerID,
+		&lead.Notes,
+		&lead.CustomerID,
+		&lead.ContactID,
+		&lead.OpportunityID,
+		&lead.ConvertedAt,
+		&lead.CreatedAt,
+		&lead.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return lead, nil
+}
+
+// LeadRepository implements the LeadService interface
+type LeadRepository struct {
+	db *DB
+}
+
+// NewLeadRepository creates a new lead repository
+func NewLeadRepository(db *DB) *LeadRepository {
+	return &LeadRepository{db: db}
+}
+
+// Create creates a new lead
+func (r *LeadRepository) Create(lead *models.Lead) error {
+	query := `
+		INSERT INTO leads (tenant_id, company, first_name, last_name, email, phone, source, status, owner_id, notes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, created_at, updated_at
+	`
+
+	return r.db.QueryRow(
+		query,
+		lead.TenantID,
+		lead.Company,
+		lead.FirstName,
+		lead.LastName,
+		lead.Email,
+		lead.Phone,
+		lead.Source,
+		lead.Status,
+		nullString(lead.OwnerID),
+		lead.Notes,
+	).Scan(
+		&lead.ID,
+		&lead.CreatedAt,
+		&lead.UpdatedAt,
+	)
+}
+
+// GetByID gets a lead by ID
+func (r *LeadRepository) GetByID(tenantID, id string) (*models.Lead, error) {
+	query := `
+		SELECT ` + leadColumns + `
+		FROM leads
+		WHERE tenant_id = $1 AND id = $2
+	`
+
+	lead, err := scanLead(r.db.QueryRow(query, tenantID, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return lead, err
+}
+
+// List lists all leads for a tenant, newest first, optionally filtered by status
+func (r *LeadRepository) List(tenantID, status string) ([]*models.Lead, error) {
+	query := `
+		SELECT ` + leadColumns + `
+		FROM leads
+		WHERE tenant_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.Query(query, tenantID, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	leads := []*models.Lead{}
+	for rows.Next() {
+		lead, err := scanLead(rows)
+		if err != nil {
+			return nil, err
+		}
+		leads = append(leads, lead)
+	}
+
+	return leads, rows.Err()
+}
+
+// Update updates a lead that is not converted
+func (r *LeadRepository) Update(lead *models.Lead) error {
+	query := `
+		UPDATE leads
+		SET company = $1, first_name = $2, last_name = $3, email = $4, phone = $5, source = $6, status = $7,
+			owner_id = $8, notes = $9, updated_at = $10
+		WHERE tenant_id = $11 AND id = $12 AND status <> 'converted'
+	`
+
+	now := time.Now()
+	result, err := r.db.Exec(
+		query,
+		lead.Company,
+		lead.FirstName,
+		lead.LastName,
+		lead.Email,
+		lead.Phone,
+		lead.Source,
+		lead.Status,
+		nullString(lead.OwnerID),
+		lead.Notes,
+		now,
+		lead.TenantID,
+		lead.ID,
+	)
+	if err != nil {
+		return err
+	}
+	lead.UpdatedAt = now
+	return requireRowAffected(result, models.ErrLeadConverted)
+}
+
+// Delete deletes a lead. Customers, contacts and opportunities it was converted to are kept.
+func (r *LeadRepository) Delete(tenantID, id string) error {
+	query := `
+		DELETE FROM leads
+		WHERE tenant_id = $1 AND id = $2
+	`
+
+	_, err := r.db.Exec(query, tenantID, id)
+	return err
+}
+
+// Convert converts a lead in one database transaction. The lead becomes a new customer
+// named after its company, or after the lead when it has no company, unless the
+// conversion names an existing customer. A lead with a first and last name becomes a
+// contact of the customer, and an opportunity is opened for the customer when the
+// conversion has one.
+func (r *LeadRepository) Convert(tenantID, id, userID string, conversion *models.LeadConversion) (lead *models.Lead, err error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	lead, err = scanLead(tx.QueryRow(`SELECT `+leadColumns+` FROM leads WHERE tenant_id = $1 AND id = $2 FOR UPDATE`, tenantID, id))
+	if err != nil {
+		return nil, err
+	}
+
+	switch lead.Status {
+	case models.LeadConverted:
+		return nil, models.ErrLeadConverted
+	case models.LeadDisqualified:
+		return nil, models.ErrLeadDisqualified
+	}
+
+	hasName := lead.FirstName != "" && lead.LastName != ""
+
+	lead.CustomerID = conversion.CustomerID
+	if lead.CustomerID == "" {
+		name := lead.Company
+		if name == "" && hasName {
+			name = strings.TrimSpace(lead.FirstName + " " + lead.LastName)
+		}
+		if name == "" {
+			return nil, models.ErrLeadName
+		}
+
+		err = tx.QueryRow(
+			`INSERT INTO customers (tenant_id, name, email, phone, address)
+			VALUES ($1, $2, $3, $4, '')
+			RETURNING id`,
+			tenantID,
+			name,
+			lead.Email,
+			lead.Phone,
+		).Scan(&lead.CustomerID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if hasName {
+		err = tx.QueryRow(
+			`INSERT INTO contacts (tenant_id, customer_id, first_name, last_name, email, phone, position)
+			VALUES ($1, $2, $3, $4, $5, $6, '')
+			RETURNING id`,
+			tenantID,
+			lead.CustomerID,
+			lead.FirstName,
+			lead.LastName,
+			lead.Email,
+			lead.Phone,
+		).Scan(&lead.ContactID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if opportunity := conversion.Opportunity; opportunity != nil {
+		opportunity.TenantID = tenantID
+		opportunity.CustomerID = lead.CustomerID
+		opportunity.ContactID = lead.ContactID
+		opportunity.LeadID = lead.ID
+		opportunity.CreatedBy = userID
+		if opportunity.OwnerID == "" {
+			opportunity.OwnerID = lead.OwnerID
+		}
+
+		if err = insertOpportunity(tx, opportunity); err != nil {
+			return nil, err
+		}
+		lead.OpportunityID = opportunity.ID
+	}
+
+	now := time.Now()
+	_, err = tx.Exec(
+		`UPDATE leads
+		SET status = $1, customer_id = $2, contact_id = $3, opportunity_id = $4, converted_at = $5, updated_at = $5
+		WHERE tenant_id = $6 AND id = $7`,
+		models.LeadConverted,
+		lead.CustomerID,
+		nullString(lead.ContactID),
+		nullString(lead.OpportunityID),
+		now,
+		tenantID,
+		id,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	lead.Status = models.LeadConverted
+	lead.ConvertedAt = &now
+	lead.UpdatedAt = now
+	return lead, nil
+}
+
+const pipelineStageColumns = `id, tenant_id, name, position, probability, type, created_at, updated_at`
+
+// scanPipelineStage scans a row selected with pipelineStageColumns
+func scanPipelineStage(row rowScanner) (*models.PipelineStage, error) {
+	stage := &models.PipelineStage{}
+	err := row.Scan(
+		&stage.ID,
+		&stage.TenantID,
+		&stage.Name,
+		&stage.Position,
+		&stage.Probability,
+		&stage.Type,
+		&stage.CreatedAt,
+		&stage.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return stage, nil
+}
+
+// PipelineStageRepository implements the PipelineStageService interface
+type PipelineStageRepository struct {
+	db *DB
+}
+
+// NewPipelineStageRepository creates a new pipeline stage repository
+func NewPipelineStageRepository(db *DB) *PipelineStageRepository {
+	return &PipelineStageRepository{db: db}
+}
+
+// Create creates a new pipeline stage
+func (r *PipelineStageRepository) Create(stage *models.PipelineStage) error {
+	query := `
+		INSERT INTO pipeline_stages (tenant_id, name, position, probability, type)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at
+	`
+
+	return r.db.QueryRow(
+		query,
+		stage.TenantID,
+		stage.Name,
+		stage.Position,
+		stage.Probability,
+		stage.Type,
+	).Scan(
+		&stage.ID,
+		&stage.CreatedAt,
+		&stage.UpdatedAt,
+	)
+}
+
+// GetByID gets a pipeline stage by ID
+func (r *PipelineStageRepository) GetByID(tenantID, id string) (*models.PipelineStage, error) {
+	query := `
+		SELECT ` + pipelineStageColumns + `
+		FROM pipeline_stages
+		WHERE tenant_id = $1 AND id = $2
+	`
+
+	stage, err := scanPipelineStage(r.db.QueryRow(query, tenantID, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return stage, err
+}
+
+// GetByName gets a pipeline stage by name
+func (r *PipelineStageRepository) GetByName(tenantID, name string) (*models.PipelineStage, error) {
+	query := `
+		SELECT ` + pipelineStageColumns + `
+		FROM pipeline_stages
+		WHERE tenant_id = $1 AND name = $2
+	`
+
+	stage, err := scanPipelineStage(r.db.QueryRow(query, tenantID, name))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return stage, err
+}
+
+// List lists the pipeline stages of a tenant by position
+func (r *PipelineStageRepository) List(tenantID string) ([]*models.PipelineStage, error) {
+	query := `
+		SELECT ` + pipelineStageColumns + `
+		FROM pipeline_stages
+		WHERE tenant_id = $1
+		ORDER BY position, name
+	`
+
+	rows, err := r.db.Query(query, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stages := []*models.PipelineStage{}
+	for rows.Next() {
+		stage, err := scanPipelineStage(rows)
+		if err != nil {
+			return nil, err
+		}
+		stages = append(stages, stage)
+	}
+
+	return stages, rows.Err()
+}
+
+// Update updates a pipeline stage. Opportunities already in the stage keep their probability.
+func (r *PipelineStageRepository) Update(stage *models.PipelineStage) error {
+	query := `
+		UPDATE pipeline_stages
+		SET name = $1, position = $2, probability = $3, type = $4, updated_at = $5
+		WHERE tenant_id = $6 AND id = $7
+	`
+
+	now := time.Now()
+	_, err := r.db.Exec(
+		query,
+		stage.Name,
+		stage.Position,
+		stage.Probability,
+		stage.Type,
+		now,
+		stage.TenantID,
+		stage.ID,
+	)
+	stage.UpdatedAt = now
+	return err
+}
+
+// Delete deletes a pipeline stage that no opportunity is or has been in
+func (r *PipelineStageRepository) Delete(tenantID, id string) error {
+	query := `
+		DELETE FROM pipeline_stages
+		WHERE tenant_id = $1 AND id = $2
+			AND NOT EXISTS (SELECT 1 FROM opportunities WHERE tenant_id = $1 AND stage_id = $2)
+			AND NOT EXISTS (SELECT 1 FROM opportunity_stage_changes
+				WHERE tenant_id = $1 AND (from_stage_id = $2 OR to_stage_id = $2))
+	`
+
+	result, err := r.db.Exec(query, tenantID, id)
+	if err != nil {
+		return err
+	}
+	return requireRowAffected(result, models.ErrStageInUse)
+}
+
+// opportunityWeightedSQL is the weighted amount of an opportunity aliased as o
+const opportunityWeightedSQL = `ROUND(o.amount * o.probability / 100, 2)`
+
+const opportunityColumns = `o.id, o.tenant_id, o.name, o.customer_id, COALESCE(o.contact_id::text, ''),
+	COALESCE(o.lead_id::text, ''), o.stage_id, st.name, st.type, COALESCE(o.owner_id::text, ''), o.amount,
+	o.probability, ` + opportunityWeightedSQL + `, o.expected_close_date, o.closed_at, o.notes, o.created_by,
+	o.created_at, o.updated_at`
+
+// scanOpportunity scans a row selected with opportunityColumns
+func scanOpportunity(row rowScanner) (*models.Opportunity, error) {
+	opportunity := &models.Opportunity{}
+	err := row.Scan(
+		&opportunity.ID,
+		&opportunity.TenantID,
+		&opportunity.Name,
+		&opportunity.CustomerID,
+		&opportunity.ContactID,
+		&opportunity.LeadID,
+		&opportunity.StageID,
+		&opportunity.StageName,
+		&opportunity.StageType,
+		&opportunity.OwnerID,
+		&opportunity.Amount,
+		&opportunity.Probability,
+		&opportunity.WeightedAmount,
+		&opportunity.ExpectedCloseDate,
+		&opportunity.ClosedAt,
+		&opportunity.Notes,
+		&opportunity.CreatedBy,
+		&opportunity.CreatedAt,
+		&opportunity.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return opportunity, nil
+}
+
+// getOpportunity reads an opportunity within tx
+func getOpportunity(tx *sql.Tx, tenantID, id string) (*models.Opportunity, error) {
+	query := `
+		SELECT ` + opportunityColumns + `
+		FROM opportunities o
+		JOIN pipeline_stages st ON st.id = o.stage_id
+		WHERE o.tenant_id = $1 AND o.id = $2
+	`
+
+	return scanOpportunity(tx.QueryRow(query, tenantID, id))
+}
+
+// insertStageChange records an opportunity entering a stage within tx
+func insertStageChange(tx *sql.Tx, tenantID, opportunityID, fromStageID, toStageID, userID string, changedAt time.Time) error {
+	_, err := tx.Exec(
+		`INSERT INTO opportunity_stage_changes (tenant_id, opportunity_id, from_stage_id, to_stage_id, changed_by, changed_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		tenantID,
+		opportunityID,
+		nullString(fromStageID),
+		toStageID,
+		userID,
+		changedAt,
+	)
+	return err
+}
+
+// insertOpportunity opens an opportunity in its stage within tx and records the stage
+// in its history. An opportunity without a probability takes that of its stage.
+func insertOpportunity(tx *sql.Tx, opportunity *models.Opportunity) error {
+	var stageProbability float64
+	var stageType string
+	err := tx.QueryRow(
+		`SELECT probability, type FROM pipeline_stages WHERE tenant_id = $1 AND id = $2`,
+		opportunity.TenantID,
+		opportunity.StageID,
+	).Scan(&stageProbability, &stageType)
+	if err != nil {
+		return err
+	}
+
+	if opportunity.Probability == 0 {
+		opportunity.Probability = stageProbability
+	}
+
+	now := time.Now()
+	var closedAt *time.Time
+	if stageType != models.StageOpen {
+		closedAt = &now
+	}
+
+	query := `
+		INSERT INTO opportunities (tenant_id, name, customer_id, contact_id, lead_id, stage_id, owner_id, amount,
+			probability, expected_close_date, closed_at, notes, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $14)
+		RETURNING id
+	`
+
+	err = tx.QueryRow(
+		query,
+		opportunity.TenantID,
+		opportunity.Name,
+		opportunity.CustomerID,
+		nullString(opportunity.ContactID),
+		nullString(opportunity.LeadID),
+		opportunity.StageID,
+		nullString(opportunity.OwnerID),
+		opportunity.Amount,
+		opportunity.Probability,
+		opportunity.ExpectedCloseDate,
+		closedAt,
+		opportunity.Notes,
+		opportunity.CreatedBy,
+		now,
+	).Scan(&opportunity.ID)
+	if err != nil {
+		return err
+	}
+
+	err = insertStageChange(tx, opportunity.TenantID, opportunity.ID, "", opportunity.StageID, opportunity.CreatedBy, now)
+	if err != nil {
+		return err
+	}
+
+	loaded, err := getOpportunity(tx, opportunity.TenantID, opportunity.ID)
+	if err != nil {
+		return err
+	}
+	*opportunity = *loaded
+	return nil
+}
+
+// OpportunityRepository implements the OpportunityService interface
+type OpportunityRepository struct {
+	db *DB
+}
+
+// NewOpportunityRepository creates a new opportunity repository
+func NewOpportunityRepository(db *DB) *OpportunityRepository {
+	return &OpportunityRepository{db: db}
+}
+
+// Create opens an opportunity in its stage and records the stage in its history
+func (r *OpportunityRepository) Create(opportunity *models.Opportunity) (err error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	return insertOpportunity(tx, opportunity)
+}
+
+// GetByID gets an opportunity by ID
+func (r *OpportunityRepository) GetByID(tenantID, id string) (*models.Opportunity, error) {
+	query := `
+		SELECT ` + opportunityColumns + `
+		FROM opportunities o
+		JOIN pipeline_stages st ON st.id = o.stage_id
+		WHERE o.tenant_id = $1 AND o.id = $2
+	`
+
+	opportunity, err := scanOpportunity(r.db.QueryRow(query, tenantID, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return opportunity, err
+}
+
+// List lists the opportunities of a tenant matching a filter, by stage position and
+// expected close date
+func (r *OpportunityRepository) List(tenantID string, filter models.OpportunityFilter) ([]*models.Opportunity, error) {
+	query := `
+		SELECT ` + opportunityColumns + `
+		FROM opportunities o
+		JOIN pipeline_stages st ON st.id = o.stage_id
+		WHERE o.tenant_id = $1
+			AND ($2 = '' OR o.stage_id::text = $2)
+			AND ($3 = '' OR o.owner_id::text = $3)
+			AND ($4 = '' OR o.customer_id::text = $4)
+		ORDER BY st.position, o.expected_close_date NULLS LAST, o.name
+	`
+
+	rows, err := r.db.Query(query, tenantID, filter.StageID, filter.OwnerID, filter.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	opportunities := []*models.Opportunity{}
+	for rows.Next() {
+		opportunity, err := scanOpportunity(rows)
+		if err != nil {
+			return nil, err
+		}
+		opportunities = append(opportunities, opportunity)
+	}
+
+	return opportunities, rows.Err()
+}
+
+// Update updates an opportunity other than its stage
+func (r *OpportunityRepository) Update(opportunity *models.Opportunity) (err error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	query := `
+		UPDATE opportunities
+		SET name = $1, customer_id = $2, contact_id = $3, owner_id = $4, amount = $5, probability = $6,
+			expected_close_date = $7, notes = $8, updated_at = $9
+		WHERE tenant_id = $10 AND id = $11
+	`
+
+	_, err = tx.Exec(
+		query,
+		opportunity.Name,
+		opportunity.CustomerID,
+		nullString(opportunity.ContactID),
+		nullString(opportunity.OwnerID),
+		opportunity.Amount,
+		opportunity.Probability,
+		opportunity.ExpectedCloseDate,
+		opportunity.Notes,
+		time.Now(),
+		opportunity.TenantID,
+		opportunity.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	loaded, err := getOpportunity(tx, opportunity.TenantID, opportunity.ID)
+	if err != nil {
+		return err
+	}
+	*opportunity = *loaded
+	return nil
+}
+
+// Delete deletes an opportunity and its stage history
+func (r *OpportunityRepository) Delete(tenantID, id string) error {
+	query := `
+		DELETE FROM opportunities
+		WHERE tenant_id = $1 AND id = $2
+	`
+
+	_, err := r.db.Exec(query, tenantID, id)
+	return err
+}
+
+// ChangeStage moves an opportunity to another stage and records the change. The
+// opportunity takes the probability of the stage and is closed by a won or lost stage.
+func (r *OpportunityRepository) ChangeStage(tenantID, id, stageID, userID string) (err error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	var fromStageID string
+	err = tx.QueryRow(
+		`SELECT stage_id FROM opportunities WHERE tenant_id = $1 AND id = $2 FOR UPDATE`,
+		tenantID,
+		id,
+	).Scan(&fromStageID)
+	if err != nil {
+		return err
+	}
+
+	if fromStageID == stageID {
+		return models.ErrOpportunityStage
+	}
+
+	now := time.Now()
+	_, err = tx.Exec(
+		`UPDATE opportunities o
+		SET stage_id = st.id, probability = st.probability,
+			closed_at = CASE WHEN st.type = 'open' THEN NULL ELSE $1::timestamptz END, updated_at = $1
+		FROM pipeline_stages st
+		WHERE st.tenant_id = $2 AND st.id = $3 AND o.tenant_id = $2 AND o.id = $4`,
+		now,
+		tenantID,
+		stageID,
+		id,
+	)
+	if err != nil {
+		return err
+	}
+
+	return insertStageChange(tx, tenantID, id, fromStageID, stageID, userID, now)
+}
+
+// ListStageChanges lists the stage history of an opportunity, oldest first
+func (r *OpportunityRepository) ListStageChanges(tenantID, id string) ([]*models.OpportunityStageChange, error) {
+	query := `
+		SELECT c.id, c.tenant_id, c.opportunity_id, COALESCE(c.from_stage_id::text, ''), COALESCE(f.name, ''),
+			c.to_stage_id, t.name, c.changed_by, c.changed_at
+		FROM opportunity_stage_changes c
+		LEFT JOIN pipeline_stages f ON f.id = c.from_stage_id
+		JOIN pipeline_stages t ON t.id = c.to_stage_id
+		WHERE c.tenant_id = $1 AND c.opportunity_id = $2
+		ORDER BY c.changed_at, c.id
+	`
+
+	rows, err := r.db.Query(query, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := []*models.OpportunityStageChange{}
+	for rows.Next() {
+		change := &models.OpportunityStageChange{}
+		err := rows.Scan(
+			&change.ID,
+			&change.TenantID,
+			&change.OpportunityID,
+			&change.FromStageID,
+			&change.FromStageName,
+			&change.ToStageID,
+			&change.ToStageName,
+			&change.ChangedBy,
+			&change.ChangedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+
+	return changes, rows.Err()
+}
+
+// Summary sums the open opportunities of a tenant by open stage and by owner
+func (r *OpportunityRepository) Summary(tenantID string) (*models.PipelineSummary, error) {
+	summary := &models.PipelineSummary{
+		Stages: []*models.PipelineStageSummary{},
+		Owners: []*models.PipelineOwnerSummary{},
+	}
+
+	stageQuery := `
+		SELECT st.id, st.name, st.position, COUNT(o.id), COALESCE(SUM(o.amount), 0),
+			COALESCE(SUM(` + opportunityWeightedSQL + `), 0)
+		FROM pipeline_stages st
+		LEFT JOIN opportunities o ON o.tenant_id = st.tenant_id AND o.stage_id = st.id
+		WHERE st.tenant_id = $1 AND st.type = 'open'
+		GROUP BY st.id, st.name, st.position
+		ORDER BY st.position, st.name
+	`
+
+	rows, err := r.db.Query(stageQuery, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		stage := &models.PipelineStageSummary{}
+		err := rows.Scan(
+			&stage.StageID,
+			&stage.StageName,
+			&stage.Position,
+			&stage.Count,
+			&stage.Amount,
+			&stage.WeightedForecast,
+		)
+		if err != nil {
+			return nil, err
+		}
+		summary.Stages = append(summary.Stages, stage)
+		summary.Count += stage.Count
+		summary.Amount += stage.Amount
+		summary.WeightedForecast += stage.WeightedForecast
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	summary.Amount = roundPrice(summary.Amount)
+	summary.WeightedForecast = roundPrice(summary.WeightedForecast)
+
+	ownerQuery := `
+		SELECT COALESCE(o.owner_id::text, ''), COUNT(*), SUM(o.amount), SUM(` + opportunityWeightedSQL + `) AS weighted
+		FROM opportunities o
+		JOIN pipeline_stages st ON st.id = o.stage_id
+		WHERE o.tenant_id = $1 AND st.type = 'open'
+		GROUP BY o.owner_id
+		ORDER BY weighted DESC
+	`
+
+	ownerRows, err := r.db.Query(ownerQuery, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer ownerRows.Close()
+
+	for ownerRows.Next() {
+		owner := &models.PipelineOwnerSummary{}
+		err := ownerRows.Scan(
+			&owner.OwnerID,
+			&owner.Count,
+			&owner.Amount,
+			&owner.WeightedForecast,
+		)
+		if err != nil {
+			return nil, err
+		}
+		summary.Owners = append(summary.Owners, owner)
+	}
+
+	return summary, ownerRows.Err()
+}
\ No newline at end of file
@@ -0,0 +1,262 @@
+package db
+
+import (
+	"database/sql"
+	"math"
+	"time"
+
+	"github.com/yookibooki/erp/internal/models"
+)
+
+const postingRuleColumns = `id, tenant_id, transaction_type, COALESCE(category_id::text, ''), reason_code,
+	inventory_account_id, counter_account_id, description, created_at, updated_at`
+
+// scanPostingRule scans a row selected with postingRuleColumns
+func scanPostingRule(row rowScanner) (*models.PostingRule, error) {
+	rule := &models.PostingRule{}
+	err := row.Scan(
+		&rule.ID,
+		&rule.TenantID,
+		&rule.TransactionType,
+		&rule.CategoryID,
+		&rule.ReasonCode,
+		&rule.InventoryAccountID,
+		&rule.CounterAccountID,
+		&rule.Description,
+		&rule.CreatedAt,
+		&rule.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// matchPostingRule finds within tx the most specific posting rule of a tenant for a
+// transaction type, a product category and a reason code: a rule for the reason code
+// wins over one for any reason, then a rule for the category wins over one for its
+// parent categories, which wins over one for all products. It returns sql.ErrNoRows
+// when no rule matches.
+func matchPostingRule(tx *sql.Tx, tenantID, transactionType, categoryID, reasonCode string) (*models.PostingRule, error) {
+	query := `
+		WITH RECURSIVE chain AS (
+			SELECT id, parent_id, 0 AS depth
+			FROM product_categories
+			WHERE tenant_id = $1 AND id = $3
+			UNION ALL
+			SELECT c.id, c.parent_id, chain.depth + 1
+			FROM product_categories c
+			JOIN chain ON c.id = chain.parent_id
+			WHERE chain.depth < 100
+		)
+		SELECT ` + postingRuleColumns + `
+		FROM posting_rules
+		WHERE tenant_id = $1 AND transaction_type = $2
+			AND (category_id IS NULL OR category_id IN (SELECT id FROM chain))
+			AND (reason_code = '' OR reason_code = $4)
+		ORDER BY reason_code = '', (SELECT depth FROM chain WHERE chain.id = posting_rules.category_id) NULLS LAST
+		LIMIT 1
+	`
+
+	return scanPostingRule(tx.QueryRow(query, tenantID, transactionType, nullString(categoryID), reasonCode))
+}
+
+// postInventoryTransaction creates the journal entry for the change in inventory
+// value of a transaction within tx, using the posting rule that matches its type,
+// the product's category and its reason code. Transactions without a matching
+// posting rule or without a value are not posted. The inventory and COGS accounts
+// in effect for the product replace the rule's inventory account and, for issues,
+// its counter account.
+func postInventoryTransaction(tx *sql.Tx, transaction *models.InventoryTransaction, state *productState) error {
+	if transaction.TotalCost == 0 {
+		return nil
+	}
+
+	rule, err := matchPostingRule(tx, transaction.TenantID, transaction.TransactionType, state.categoryID, transaction.ReasonCode)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if state.inventoryAccountID != "" {
+		rule.InventoryAccountID = state.inventoryAccountID
+	}
+	if state.cogsAccountID != "" && transaction.TransactionType == models.TransactionTypeIssue {
+		rule.CounterAccountID = state.cogsAccountID
+	}
+
+	description := "Inventory " + transaction.TransactionType
+	if transaction.Notes != "" {
+		description += ": " + transaction.Notes
+	}
+
+	amount := math.Abs(transaction.TotalCost)
+	inventoryLine := models.JournalEntryLine{AccountID: rule.InventoryAccountID, Description: description}
+	counterLine := models.JournalEntryLine{AccountID: rule.CounterAccountID, Description: description}
+	if transaction.TotalCost > 0 {
+		inventoryLine.Debit = amount
+		counterLine.Credit = amount
+	} else {
+		counterLine.Debit = amount
+		inventoryLine.Credit = amount
+	}
+
+	entry := &models.JournalEntry{
+		TenantID:    transaction.TenantID,
+		EntryDate:   transaction.CreatedAt,
+		Reference:   transaction.Reference,
+		Description: description,
+		CreatedBy:   transaction.CreatedBy,
+		Lines:       []models.JournalEntryLine{inventoryLine, counterLine},
+	}
+	if err := insertJournalEntry(tx, entry); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		`UPDATE inventory_transactions SET journal_entry_id = $1 WHERE tenant_id = $2 AND id = $3`,
+		entry.ID,
+		transaction.TenantID,
+		transaction.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	transaction.JournalEntryID = entry.ID
+	return nil
+}
+
+// PostingRuleRepository implements the PostingRuleService interface
+type PostingRuleRepository struct {
+	db *DB
+}
+
+// NewPostingRuleRepository creates a new posting rule repository
+func NewPostingRuleRepository(db *DB) *PostingRuleRepository {
+	return &PostingRuleRepository{db: db}
+}
+
+// Create creates a new posting rule
+func (r *PostingRuleRepository) Create(rule *models.PostingRule) error {
+	query := `
+		INSERT INTO posting_rules (tenant_id, transaction_type, category_id, reason_code, inventory_account_id,
+			counter_account_id, description)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at, updated_at
+	`
+
+	return r.db.QueryRow(
+		query,
+		rule.TenantID,
+		rule.TransactionType,
+		nullString(rule.CategoryID),
+		rule.ReasonCode,
+		rule.InventoryAccountID,
+		rule.CounterAccountID,
+		rule.Description,
+	).Scan(
+		&rule.ID,
+		&rule.CreatedAt,
+		&rule.UpdatedAt,
+	)
+}
+
+// GetByID gets a posting rule by ID
+func (r *PostingRuleRepository) GetByID(tenantID, id string) (*models.PostingRule, error) {
+	query := `
+		SELECT ` + postingRuleColumns + `
+		FROM posting_rules
+		WHERE tenant_id = $1 AND id = $2
+	`
+
+	rule, err := scanPostingRule(r.db.QueryRow(query, tenantID, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return rule, err
+}
+
+// GetByScope gets the posting rule for exactly an inventory transaction type, a product
+// category and a reason code, either of which may be empty
+func (r *PostingRuleRepository) GetByScope(tenantID, transactionType, categoryID, reasonCode string) (*models.PostingRule, error) {
+	query := `
+		SELECT ` + postingRuleColumns + `
+		FROM posting_rules
+		WHERE tenant_id = $1 AND transaction_type = $2 AND category_id IS NOT DISTINCT FROM $3 AND reason_code = $4
+	`
+
+	rule, err := scanPostingRule(r.db.QueryRow(query, tenantID, transactionType, nullString(categoryID), reasonCode))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return rule, err
+}
+
+// List lists all posting rules for a tenant
+func (r *PostingRuleRepository) List(tenantID string) ([]*models.PostingRule, error) {
+	query := `
+		SELECT ` + postingRuleColumns + `
+		FROM posting_rules
+		WHERE tenant_id = $1
+		ORDER BY transaction_type, category_id NULLS FIRST, reason_code
+	`
+
+	rows, err := r.db.Query(query, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []*models.PostingRule{}
+	for rows.Next() {
+		rule, err := scanPostingRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+// Update updates a posting rule
+func (r *PostingRuleRepository) Update(rule *models.PostingRule) error {
+	query := `
+		UPDATE posting_rules
+		SET transaction_type = $1, category_id = $2, reason_code = $3, inventory_account_id = $4, counter_account_id = $5,
+			description = $6, updated_at = $7
+		WHERE tenant_id = $8 AND id = $9
+	`
+
+	now := time.Now()
+	_, err := r.db.Exec(
+		query,
+		rule.TransactionType,
+		nullString(rule.CategoryID),
+		rule.ReasonCode,
+		rule.InventoryAccountID,
+		rule.CounterAccountID,
+		rule.Description,
+		now,
+		rule.TenantID,
+		rule.ID,
+	)
+	rule.UpdatedAt = now
+	return err
+}
+
+// Delete deletes a posting rule
+func (r *PostingRuleRepository) Delete(tenantID, id string) error {
+	query := `
+		DELETE FROM posting_rules
+		WHERE tenant_id = $1 AND id = $2
+	`
+
+	_, err := r.db.Exec(query, tenantID, id)
+	return err
+}
\ No newline at end of file
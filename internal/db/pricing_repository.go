@@ -0,0 +1,537 @@
+package db
+
+import (
+	"database/sql"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/yookibooki/erp/internal/models"
+)
+
+// roundPrice rounds a price to cents
+func roundPrice(price float64) float64 {
+	return math.Round(price*100) / 100
+}
+
+const priceListColumns = `id, tenant_id, code, name, currency, valid_from, valid_to, discount_percent, active,
+	created_at, updated_at`
+
+// scanPriceList scans a row selected with priceListColumns
+func scanPriceList(row rowScanner) (*models.PriceList, error) {
+	priceList := &models.PriceList{}
+	err := row.Scan(
+		&priceList.ID,
+		&priceList.TenantID,
+		&priceList.Code,
+		&priceList.Name,
+		&priceList.Currency,
+		&priceList.ValidFrom,
+		&priceList.ValidTo,
+		&priceList.DiscountPercent,
+		&priceList.Active,
+		&priceList.CreatedAt,
+		&priceList.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return priceList, nil
+}
+
+// loadPriceListDetails loads the items and assignments of a price list
+func loadPriceListDetails(q queryer, priceList *models.PriceList) error {
+	query := `
+		SELECT id, tenant_id, price_list_id, product_id, min_quantity, unit_price, discount_percent, created_at, updated_at
+		FROM price_list_items
+		WHERE tenant_id = $1 AND price_list_id = $2
+		ORDER BY created_at, min_quantity
+	`
+
+	rows, err := q.Query(query, priceList.TenantID, priceList.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	priceList.Items = []models.PriceListItem{}
+	for rows.Next() {
+		item := models.PriceListItem{}
+		err := rows.Scan(
+			&item.ID,
+			&item.TenantID,
+			&item.PriceListID,
+			&item.ProductID,
+			&item.MinQuantity,
+			&item.UnitPrice,
+			&item.DiscountPercent,
+			&item.CreatedAt,
+			&item.UpdatedAt,
+		)
+		if err != nil {
+			return err
+		}
+		priceList.Items = append(priceList.Items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	priceList.CustomerIDs, err = queryIDs(q, `SELECT customer_id FROM price_list_customers WHERE price_list_id = $1 ORDER BY customer_id`, priceList.ID)
+	if err != nil {
+		return err
+	}
+
+	priceList.CustomerGroupIDs, err = queryIDs(q, `SELECT customer_group_id FROM price_list_customer_groups WHERE price_list_id = $1 ORDER BY customer_group_id`, priceList.ID)
+	return err
+}
+
+// queryIDs selects a single column of IDs
+func queryIDs(q queryer, query string, args ...interface{}) ([]string, error) {
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// validatePriceList checks the dates, discounts and items of a price list
+func validatePriceList(priceList *models.PriceList) error {
+	if priceList.ValidFrom != nil && priceList.ValidTo != nil && priceList.ValidFrom.After(*priceList.ValidTo) {
+		return models.ErrPriceListDates
+	}
+	if priceList.DiscountPercent < 0 || priceList.DiscountPercent > 100 {
+		return models.ErrInvalidDiscount
+	}
+
+	breaks := map[string]bool{}
+	for _, item := range priceList.Items {
+		if item.DiscountPercent < 0 || item.DiscountPercent > 100 {
+			return models.ErrInvalidDiscount
+		}
+		if item.UnitPrice < 0 || item.MinQuantity < 0 {
+			return models.ErrInvalidPriceListItem
+		}
+
+		key := item.ProductID + "/" + strconv.FormatFloat(item.MinQuantity, 'f', -1, 64)
+		if breaks[key] {
+			return models.ErrDuplicatePriceBreak
+		}
+		breaks[key] = true
+	}
+	return nil
+}
+
+// insertPriceListDetails inserts the items and assignments of a price list within tx
+func insertPriceListDetails(tx *sql.Tx, priceList *models.PriceList) error {
+	for i := range priceList.Items {
+		item := &priceList.Items[i]
+		item.TenantID = priceList.TenantID
+		item.PriceListID = priceList.ID
+
+		query := `
+			INSERT INTO price_list_items (tenant_id, price_list_id, product_id, min_quantity, unit_price, discount_percent)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING id, created_at, updated_at
+		`
+
+		err := tx.QueryRow(
+			query,
+			item.TenantID,
+			item.PriceListID,
+			item.ProductID,
+			item.MinQuantity,
+			item.UnitPrice,
+			item.DiscountPercent,
+		).Scan(
+			&item.ID,
+			&item.CreatedAt,
+			&item.UpdatedAt,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, customerID := range priceList.CustomerIDs {
+		_, err := tx.Exec(
+			`INSERT INTO price_list_customers (price_list_id, customer_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
+			priceList.ID,
+			customerID,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, groupID := range priceList.CustomerGroupIDs {
+		_, err := tx.Exec(
+			`INSERT INTO price_list_customer_groups (price_list_id, customer_group_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
+			priceList.ID,
+			groupID,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// PriceListRepository implements the PriceListService interface
+type PriceListRepository struct {
+	db *DB
+}
+
+// NewPriceListRepository creates a new price list repository
+func NewPriceListRepository(db *DB) *PriceListRepository {
+	return &PriceListRepository{db: db}
+}
+
+// Create creates a new price list with its items and assignments
+func (r *PriceListRepository) Create(priceList *models.PriceList) (err error) {
+	if err := validatePriceList(priceList); err != nil {
+		return err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	query := `
+		INSERT INTO price_lists (tenant_id, code, name, currency, valid_from, valid_to, discount_percent, active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at, updated_at
+	`
+
+	err = tx.QueryRow(
+		query,
+		priceList.TenantID,
+		priceList.Code,
+		priceList.Name,
+		priceList.Currency,
+		priceList.ValidFrom,
+		priceList.ValidTo,
+		priceList.DiscountPercent,
+		priceList.Active,
+	).Scan(
+		&priceList.ID,
+		&priceList.CreatedAt,
+		&priceList.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	return insertPriceListDetails(tx, priceList)
+}
+
+// GetByID gets a price list by ID
+func (r *PriceListRepository) GetByID(tenantID, id string) (*models.PriceList, error) {
+	query := `
+		SELECT ` + priceListColumns + `
+		FROM price_lists
+		WHERE tenant_id = $1 AND id = $2
+	`
+
+	return r.get(query, tenantID, id)
+}
+
+// GetByCode gets a price list by code
+func (r *PriceListRepository) GetByCode(tenantID, code string) (*models.PriceList, error) {
+	query := `
+		SELECT ` + priceListColumns + `
+		FROM price_lists
+		WHERE tenant_id = $1 AND code = $2
+	`
+
+	return r.get(query, tenantID, code)
+}
+
+// get gets a price list with its items and assignments
+func (r *PriceListRepository) get(query string, args ...interface{}) (*models.PriceList, error) {
+	priceList, err := scanPriceList(r.db.QueryRow(query, args...))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := loadPriceListDetails(r.db, priceList); err != nil {
+		return nil, err
+	}
+
+	return priceList, nil
+}
+
+// List lists all price lists for a tenant
+func (r *PriceListRepository) List(tenantID string) ([]*models.PriceList, error) {
+	query := `
+		SELECT ` + priceListColumns + `
+		FROM price_lists
+		WHERE tenant_id = $1
+		ORDER BY code
+	`
+
+	rows, err := r.db.Query(query, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	priceLists := []*models.PriceList{}
+	for rows.Next() {
+		priceList, err := scanPriceList(rows)
+		if err != nil {
+			return nil, err
+		}
+		priceLists = append(priceLists, priceList)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Get items and assignments for each price list
+	for _, priceList := range priceLists {
+		if err := loadPriceListDetails(r.db, priceList); err != nil {
+			return nil, err
+		}
+	}
+
+	return priceLists, nil
+}
+
+// Update updates a price list and replaces its items and assignments
+func (r *PriceListRepository) Update(priceList *models.PriceList) (err error) {
+	if err := validatePriceList(priceList); err != nil {
+		return err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	query := `
+		UPDATE price_lists
+		SET code = $1, name = $2, currency = $3, valid_from = $4, valid_to = $5, discount_percent = $6,
+			active = $7, updated_at = $8
+		WHERE tenant_id = $9 AND id = $10
+	`
+
+	now := time.Now()
+	_, err = tx.Exec(
+		query,
+		priceList.Code,
+		priceList.Name,
+		priceList.Currency,
+		priceList.ValidFrom,
+		priceList.ValidTo,
+		priceList.DiscountPercent,
+		priceList.Active,
+		now,
+		priceList.TenantID,
+		priceList.ID,
+	)
+	if err != nil {
+		return err
+	}
+	priceList.UpdatedAt = now
+
+	for _, table := range []string{"price_list_items", "price_list_customers", "price_list_customer_groups"} {
+		if _, err = tx.Exec(`DELETE FROM `+table+` WHERE price_list_id = $1`, priceList.ID); err != nil {
+			return err
+		}
+	}
+
+	return insertPriceListDetails(tx, priceList)
+}
+
+// Delete deletes a price list
+func (r *PriceListRepository) Delete(tenantID, id string) error {
+	query := `
+		DELETE FROM price_lists
+		WHERE tenant_id = $1 AND id = $2
+	`
+
+	_, err := r.db.Exec(query, tenantID, id)
+	return err
+}
+
+// priceCandidate is an active price list that applies to a price query
+type priceCandidate struct {
+	priceListID     string
+	currency        string
+	discountPercent float64
+	tier            int
+	itemID          string
+	itemPrice       float64
+	itemDiscount    float64
+}
+
+// priceTiers maps the precedence of a price list to its price source
+var priceTiers = map[int]string{
+	1: models.PriceSourceCustomer,
+	2: models.PriceSourceGroup,
+	3: models.PriceSourceGeneral,
+}
+
+// Resolve resolves the effective unit price of a product for a customer, quantity and date.
+// Each price list takes the item with the highest minimum quantity up to the quantity.
+// Prices are only compared within a currency, so a query without a currency fails with
+// ErrPriceCurrency when lists in more than one currency apply.
+func (r *PriceListRepository) Resolve(tenantID string, query *models.PriceQuery) (*models.PriceResolution, error) {
+	resolution := &models.PriceResolution{
+		CustomerID: query.CustomerID,
+		ProductID:  query.ProductID,
+		Quantity:   query.Quantity,
+		Date:       query.Date,
+		Currency:   query.Currency,
+		Source:     models.PriceSourceProduct,
+	}
+
+	err := r.db.QueryRow(
+		`SELECT unit_price FROM products WHERE tenant_id = $1 AND id = $2`,
+		tenantID,
+		query.ProductID,
+	).Scan(&resolution.ListPrice)
+	if err != nil {
+		return nil, err
+	}
+	productPrice := resolution.ListPrice
+	resolution.UnitPrice = productPrice
+
+	// Tier 1 lists are assigned to the customer, tier 2 lists to the customer's
+	// group and tier 3 lists to nobody; lists assigned to others are left out
+	candidatesQuery := `
+		SELECT pl.id, pl.currency, pl.discount_percent,
+			CASE
+				WHEN EXISTS (SELECT 1 FROM price_list_customers c
+					WHERE c.price_list_id = pl.id AND c.customer_id::text = $3) THEN 1
+				WHEN EXISTS (SELECT 1 FROM price_list_customer_groups g
+					JOIN customers cu ON cu.customer_group_id = g.customer_group_id
+					WHERE g.price_list_id = pl.id AND cu.tenant_id = pl.tenant_id AND cu.id::text = $3) THEN 2
+				WHEN NOT EXISTS (SELECT 1 FROM price_list_customers c WHERE c.price_list_id = pl.id)
+					AND NOT EXISTS (SELECT 1 FROM price_list_customer_groups g WHERE g.price_list_id = pl.id) THEN 3
+				ELSE 0
+			END AS tier,
+			COALESCE(i.id::text, ''), COALESCE(i.unit_price, 0), COALESCE(i.discount_percent, 0)
+		FROM price_lists pl
+		LEFT JOIN LATERAL (
+			SELECT id, unit_price, discount_percent
+			FROM price_list_items
+			WHERE price_list_id = pl.id AND product_id = $2 AND min_quantity <= $4
+			ORDER BY min_quantity DESC
+			LIMIT 1
+		) i ON TRUE
+		WHERE pl.tenant_id = $1 AND pl.active
+			AND ($5 = '' OR pl.currency = $5)
+			AND (pl.valid_from IS NULL OR pl.valid_from <= $6::date)
+			AND (pl.valid_to IS NULL OR pl.valid_to >= $6::date)
+		ORDER BY pl.code
+	`
+
+	rows, err := r.db.Query(
+		candidatesQuery,
+		tenantID,
+		query.ProductID,
+		query.CustomerID,
+		query.Quantity,
+		query.Currency,
+		query.Date,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	candidates := []priceCandidate{}
+	for rows.Next() {
+		candidate := priceCandidate{}
+		err := rows.Scan(
+			&candidate.priceListID,
+			&candidate.currency,
+			&candidate.discountPercent,
+			&candidate.tier,
+			&candidate.itemID,
+			&candidate.itemPrice,
+			&candidate.itemDiscount,
+		)
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, candidate)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Drop the lists that do not apply or have no price for the product
+	applicable := []priceCandidate{}
+	for _, candidate := range candidates {
+		if candidate.tier == 0 || (candidate.itemID == "" && candidate.discountPercent == 0) {
+			continue
+		}
+		if len(applicable) > 0 && candidate.currency != applicable[0].currency {
+			return nil, models.ErrPriceCurrency
+		}
+		applicable = append(applicable, candidate)
+	}
+
+	// The most specific list with a price for the product wins, then the lowest price
+	bestTier := len(priceTiers) + 1
+	for _, candidate := range applicable {
+		listPrice := productPrice
+		discount := candidate.discountPercent
+		if candidate.itemID != "" {
+			if candidate.itemPrice > 0 {
+				listPrice = candidate.itemPrice
+			}
+			discount = candidate.itemDiscount
+		}
+		unitPrice := roundPrice(listPrice * (1 - discount/100))
+
+		if candidate.tier > bestTier || (candidate.tier == bestTier && unitPrice >= resolution.UnitPrice) {
+			continue
+		}
+
+		bestTier = candidate.tier
+		resolution.Source = priceTiers[candidate.tier]
+		resolution.PriceListID = candidate.priceListID
+		resolution.PriceListItemID = candidate.itemID
+		resolution.Currency = candidate.currency
+		resolution.ListPrice = listPrice
+		resolution.DiscountPercent = discount
+		resolution.UnitPrice = unitPrice
+	}
+
+	resolution.Total = roundPrice(resolution.UnitPrice * resolution.Quantity)
+	return resolution, nil
+}
\ No newline at end of file
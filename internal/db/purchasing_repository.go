@@ -0,0 +1,1159 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/yookibooki/erp/internal/models"
+)
+
+const supplierColumns = `id, tenant_id, code, name, email, phone, address, created_at, updated_at`
+
+// scanSupplier scans a row selected with supplierColumns
+func scanSupplier(row rowScanner) (*models.Supplier, error) {
+	supplier := &models.Supplier{}
+	err := row.Scan(
+		&supplier.ID,
+		&supplier.TenantID,
+		&supplier.Code,
+		&supplier.Name,
+		&supplier.Email,
+		&supplier.Phone,
+		&supplier.Address,
+		&supplier.CreatedAt,
+		&supplier.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return supplier, nil
+}
+
+// SupplierRepository implements the SupplierService interface
+type SupplierRepository struct {
+	db *DB
+}
+
+// NewSupplierRepository creates a new supplier repository
+func NewSupplierRepository(db *DB) *SupplierRepository {
+	return &SupplierRepository{db: db}
+}
+
+// Create creates a new supplier
+func (r *SupplierRepository) Create(supplier *models.Supplier) error {
+	query := `
+		INSERT INTO suppliers (tenant_id, code, name, email, phone, address)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at
+	`
+
+	return r.db.QueryRow(
+		query,
+		supplier.TenantID,
+		supplier.Code,
+		supplier.Name,
+		supplier.Email,
+		supplier.Phone,
+		supplier.Address,
+	).Scan(
+		&supplier.ID,
+		&supplier.CreatedAt,
+		&supplier.UpdatedAt,
+	)
+}
+
+// GetByID gets a supplier by ID
+func (r *SupplierRepository) GetByID(tenantID, id string) (*models.Supplier, error) {
+	query := `
+		SELECT ` + supplierColumns + `
+		FROM suppliers
+		WHERE tenant_id = $1 AND id = $2
+	`
+
+	supplier, err := scanSupplier(r.db.QueryRow(query, tenantID, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return supplier, err
+}
+
+// GetByCode gets a supplier by code
+func (r *SupplierRepository) GetByCode(tenantID, code string) (*models.Supplier, error) {
+	query := `
+		SELECT ` + supplierColumns + `
+		FROM suppliers
+		WHERE tenant_id = $1 AND code = $2
+	`
+
+	supplier, err := scanSupplier(r.db.QueryRow(query, tenantID, code))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return supplier, err
+}
+
+// List lists all suppliers for a tenant
+func (r *SupplierRepository) List(tenantID string) ([]*models.Supplier, error) {
+	query := `
+		SELECT ` + supplierColumns + `
+		FROM suppliers
+		WHERE tenant_id = $1
+		ORDER BY code
+	`
+
+	rows, err := r.db.Query(query, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	suppliers := []*models.Supplier{}
+	for rows.Next() {
+		supplier, err := scanSupplier(rows)
+		if err != nil {
+			return nil, err
+		}
+		suppliers = append(suppliers, supplier)
+	}
+
+	return suppliers, nil
+}
+
+// Update updates a supplier
+func (r *SupplierRepository) Update(supplier *models.Supplier) error {
+	query := `
+		UPDATE suppliers
+		SET code = $1, name = $2, email = $3, phone = $4, address = $5, updated_at = $6
+		WHERE tenant_id = $7 AND id = $8
+	`
+
+	now := time.Now()
+	_, err := r.db.Exec(
+		query,
+		supplier.Code,
+		supplier.Name,
+		supplier.Email,
+		supplier.Phone,
+		supplier.Address,
+		now,
+		supplier.TenantID,
+		supplier.ID,
+	)
+	supplier.UpdatedAt = now
+	return err
+}
+
+// Delete deletes a supplier
+func (r *SupplierRepository) Delete(tenantID, id string) error {
+	query := `
+		DELETE FROM suppliers
+		WHERE tenant_id = $1 AND id = $2
+	`
+
+	_, err := r.db.Exec(query, tenantID, id)
+	return err
+}
+
+var productSupplierColumns = `ps.id, ps.tenant_id, ps.product_id, ps.supplier_id, s.code, s.name, ps.supplier_item_code,
+	ps.purchase_price, ps.currency, COALESCE(ps.unit_id::text, ''), ps.purchase_price / ` + unitFactorSQL("ps") + `,
+	ps.lead_time_days, ps.minimum_order_quantity, ps.preferred, ps.created_at, ps.updated_at`
+
+// productSupplierRankSQL orders the suppliers of a product aliased as ps, joined to suppliers as s, by rank
+var productSupplierRankSQL = `ps.preferred DESC, ps.purchase_price / ` + unitFactorSQL("ps") + `, ps.lead_time_days, s.code`
+
+// scanProductSupplier scans a row selected with productSupplierColumns
+func scanProductSupplier(row rowScanner) (*models.ProductSupplier, error) {
+	productSupplier := &models.ProductSupplier{}
+	err := row.Scan(
+		&productSupplier.ID,
+		&productSupplier.TenantID,
+		&productSupplier.ProductID,
+		&productSupplier.SupplierID,
+		&productSupplier.SupplierCode,
+		&productSupplier.SupplierName,
+		&productSupplier.SupplierItemCode,
+		&productSupplier.PurchasePrice,
+		&productSupplier.Currency,
+		&productSupplier.UnitID,
+		&productSupplier.BaseUnitPrice,
+		&productSupplier.LeadTimeDays,
+		&productSupplier.MinimumOrderQuantity,
+		&productSupplier.Preferred,
+		&productSupplier.CreatedAt,
+		&productSupplier.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return productSupplier, nil
+}
+
+// queryProductSuppliers runs a query selecting productSupplierColumns
+func queryProductSuppliers(db *DB, query string, args ...interface{}) ([]*models.ProductSupplier, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	productSuppliers := []*models.ProductSupplier{}
+	for rows.Next() {
+		productSupplier, err := scanProductSupplier(rows)
+		if err != nil {
+			return nil, err
+		}
+		productSuppliers = append(productSuppliers, productSupplier)
+	}
+
+	return productSuppliers, rows.Err()
+}
+
+// clearPreferredSupplier unsets the preferred supplier of the product of productSupplier
+// within tx, unless it is productSupplier itself
+func clearPreferredSupplier(tx *sql.Tx, productSupplier *models.ProductSupplier) error {
+	_, err := tx.Exec(
+		`UPDATE product_suppliers SET preferred = FALSE, updated_at = $1
+		WHERE tenant_id = $2 AND product_id = $3 AND preferred AND id::text <> $4`,
+		time.Now(),
+		productSupplier.TenantID,
+		productSupplier.ProductID,
+		productSupplier.ID,
+	)
+	return err
+}
+
+// ProductSupplierRepository implements the ProductSupplierService interface
+type ProductSupplierRepository struct {
+	db *DB
+}
+
+// NewProductSupplierRepository creates a new product supplier repository
+func NewProductSupplierRepository(db *DB) *ProductSupplierRepository {
+	return &ProductSupplierRepository{db: db}
+}
+
+// Create adds a supplier to a product. A preferred supplier replaces the product's
+// previously preferred supplier.
+func (r *ProductSupplierRepository) Create(productSupplier *models.ProductSupplier) (err error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	if productSupplier.Preferred {
+		if err = clearPreferredSupplier(tx, productSupplier); err != nil {
+			return err
+		}
+	}
+
+	query := `
+		INSERT INTO product_suppliers (tenant_id, product_id, supplier_id, supplier_item_code, purchase_price, currency,
+			unit_id, lead_time_days, minimum_order_quantity, preferred)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id
+	`
+
+	err = tx.QueryRow(
+		query,
+		productSupplier.TenantID,
+		productSupplier.ProductID,
+		productSupplier.SupplierID,
+		productSupplier.SupplierItemCode,
+		productSupplier.PurchasePrice,
+		productSupplier.Currency,
+		nullString(productSupplier.UnitID),
+		productSupplier.LeadTimeDays,
+		productSupplier.MinimumOrderQuantity,
+		productSupplier.Preferred,
+	).Scan(&productSupplier.ID)
+	if err != nil {
+		return err
+	}
+
+	return r.reload(tx, productSupplier)
+}
+
+// reload reads back a product supplier within tx to fill in its supplier and base unit price
+func (r *ProductSupplierRepository) reload(tx *sql.Tx, productSupplier *models.ProductSupplier) error {
+	query := `
+		SELECT ` + productSupplierColumns + `
+		FROM product_suppliers ps
+		JOIN suppliers s ON s.id = ps.supplier_id
+		WHERE ps.tenant_id = $1 AND ps.id = $2
+	`
+
+	loaded, err := scanProductSupplier(tx.QueryRow(query, productSupplier.TenantID, productSupplier.ID))
+	if err != nil {
+		return err
+	}
+	*productSupplier = *loaded
+	return nil
+}
+
+// GetByID gets a product supplier by ID
+func (r *ProductSupplierRepository) GetByID(tenantID, id string) (*models.ProductSupplier, error) {
+	query := `
+		SELECT ` + productSupplierColumns + `
+		FROM product_suppliers ps
+		JOIN suppliers s ON s.id = ps.supplier_id
+		WHERE ps.tenant_id = $1 AND ps.id = $2
+	`
+
+	productSupplier, err := scanProductSupplier(r.db.QueryRow(query, tenantID, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return productSupplier, err
+}
+
+// GetByProductAndSupplier gets the terms of a supplier for a product
+func (r *ProductSupplierRepository) GetByProductAndSupplier(tenantID, productID, supplierID string) (*models.ProductSupplier, error) {
+	query := `
+		SELECT ` + productSupplierColumns + `
+		FROM product_suppliers ps
+		JOIN suppliers s ON s.id = ps.supplier_id
+		WHERE ps.tenant_id = $1 AND ps.product_id = $2 AND ps.supplier_id = $3
+	`
+
+	productSupplier, err := scanProductSupplier(r.db.QueryRow(query, tenantID, productID, supplierID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return productSupplier, err
+}
+
+// ListByProduct lists the suppliers of a product in rank order
+func (r *ProductSupplierRepository) ListByProduct(tenantID, productID string) ([]*models.ProductSupplier, error) {
+	query := `
+		SELECT ` + productSupplierColumns + `
+		FROM product_suppliers ps
+		JOIN suppliers s ON s.id = ps.supplier_id
+		WHERE ps.tenant_id = $1 AND ps.product_id = $2
+		ORDER BY ` + productSupplierRankSQL
+
+	return queryProductSuppliers(r.db, query, tenantID, productID)
+}
+
+// ListBySupplier lists the products a supplier supplies, which is its catalogue
+func (r *ProductSupplierRepository) ListBySupplier(tenantID, supplierID string) ([]*models.ProductSupplier, error) {
+	query := `
+		SELECT ` + productSupplierColumns + `
+		FROM product_suppliers ps
+		JOIN suppliers s ON s.id = ps.supplier_id
+		JOIN products p ON p.id = ps.product_id
+		WHERE ps.tenant_id = $1 AND ps.supplier_id = $2
+		ORDER BY p.code
+	`
+
+	return queryProductSuppliers(r.db, query, tenantID, supplierID)
+}
+
+// Rank lists the suppliers to buy a quantity of a product from, in rank order. When a
+// quantity in base units is given, suppliers whose minimum order quantity exceeds it are
+// left out, and when a currency is given, suppliers pricing in another currency are.
+// Prices are never compared across currencies, so without a currency it returns
+// ErrSupplierCurrency when the suppliers left price in more than one.
+func (r *ProductSupplierRepository) Rank(tenantID, productID string, quantity float64, currency string) ([]*models.ProductSupplier, error) {
+	query := `
+		SELECT ` + productSupplierColumns + `
+		FROM product_suppliers ps
+		JOIN suppliers s ON s.id = ps.supplier_id
+		WHERE ps.tenant_id = $1 AND ps.product_id = $2
+			AND ($3::numeric <= 0 OR ps.minimum_order_quantity * ` + unitFactorSQL("ps") + ` <= $3::numeric)
+			AND ($4::text = '' OR ps.currency = $4)
+		ORDER BY ` + productSupplierRankSQL
+
+	productSuppliers, err := queryProductSuppliers(r.db, query, tenantID, productID, quantity, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, productSupplier := range productSuppliers {
+		if productSupplier.Currency != productSuppliers[0].Currency {
+			return nil, models.ErrSupplierCurrency
+		}
+	}
+
+	return productSuppliers, nil
+}
+
+// Update updates the terms of a product supplier. Making it preferred replaces the
+// product's previously preferred supplier.
+func (r *ProductSupplierRepository) Update(productSupplier *models.ProductSupplier) (err error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	if productSupplier.Preferred {
+		if err = clearPreferredSupplier(tx, productSupplier); err != nil {
+			return err
+		}
+	}
+
+	query := `
+		UPDATE product_suppliers
+		SET supplier_item_code = $1, purchase_price = $2, currency = $3, unit_id = $4, lead_time_days = $5,
+			minimum_order_quantity = $6, preferred = $7, updated_at = $8
+		WHERE tenant_id = $9 AND id = $10
+	`
+
+	_, err = tx.Exec(
+		query,
+		productSupplier.SupplierItemCode,
+		productSupplier.PurchasePrice,
+		productSupplier.Currency,
+		nullString(productSupplier.UnitID),
+		productSupplier.LeadTimeDays,
+		productSupplier.MinimumOrderQuantity,
+		productSupplier.Preferred,
+		time.Now(),
+		productSupplier.TenantID,
+		productSupplier.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	return r.reload(tx, productSupplier)
+}
+
+// Delete removes a supplier from a product
+func (r *ProductSupplierRepository) Delete(tenantID, id string) error {
+	query := `
+		DELETE FROM product_suppliers
+		WHERE tenant_id = $1 AND id = $2
+	`
+
+	_, err := r.db.Exec(query, tenantID, id)
+	return err
+}
+
+// openPurchaseOrderSQL matches purchase orders aliased as po that are still expected to be received
+const openPurchaseOrderSQL = `po.status IN ('approved', 'sent', 'partially_received')`
+
+const purchaseOrderColumns = `id, tenant_id, supplier_id, number, status, order_date, expected_date, over_receipt_tolerance,
+	notes, created_by, COALESCE(approved_by::text, ''), approved_at, sent_at, created_at, updated_at`
+
+// scanPurchaseOrder scans a row selected with purchaseOrderColumns
+func scanPurchaseOrder(row rowScanner) (*models.PurchaseOrder, error) {
+	order := &models.PurchaseOrder{}
+	err := row.Scan(
+		&order.ID,
+		&order.TenantID,
+		&order.SupplierID,
+		&order.Number,
+		&order.Status,
+		&order.OrderDate,
+		&order.ExpectedDate,
+		&order.OverReceiptTolerance,
+		&order.Notes,
+		&order.CreatedBy,
+		&order.ApprovedBy,
+		&order.ApprovedAt,
+		&order.SentAt,
+		&order.CreatedAt,
+		&order.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// loadPurchaseOrderLines loads the lines and total of a purchase order
+func loadPurchaseOrderLines(q queryer, order *models.PurchaseOrder) error {
+	query := `
+		SELECT id, tenant_id, purchase_order_id, line_number, product_id, COALESCE(location_id::text, ''),
+			COALESCE(unit_id::text, ''), quantity, unit_price, received_quantity, created_at, updated_at
+		FROM purchase_order_lines
+		WHERE tenant_id = $1 AND purchase_order_id = $2
+		ORDER BY line_number
+	`
+
+	rows, err := q.Query(query, order.TenantID, order.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	order.Lines = []models.PurchaseOrderLine{}
+	order.Total = 0
+	for rows.Next() {
+		line := models.PurchaseOrderLine{}
+		err := rows.Scan(
+			&line.ID,
+			&line.TenantID,
+			&line.PurchaseOrderID,
+			&line.LineNumber,
+			&line.ProductID,
+			&line.LocationID,
+			&line.UnitID,
+			&line.Quantity,
+			&line.UnitPrice,
+			&line.ReceivedQuantity,
+			&line.CreatedAt,
+			&line.UpdatedAt,
+		)
+		if err != nil {
+			return err
+		}
+		if line.ReceivedQuantity < line.Quantity {
+			line.OutstandingQuantity = line.Quantity - line.ReceivedQuantity
+		}
+		order.Total += line.Quantity * line.UnitPrice
+		order.Lines = append(order.Lines, line)
+	}
+
+	return rows.Err()
+}
+
+// insertPurchaseOrderLines inserts the lines of a purchase order within tx
+func insertPurchaseOrderLines(tx *sql.Tx, order *models.PurchaseOrder) error {
+	order.Total = 0
+	for i := range order.Lines {
+		line := &order.Lines[i]
+		if line.Quantity <= 0 {
+			return models.ErrInvalidOrderQuantity
+		}
+		if line.UnitPrice < 0 {
+			return models.ErrNegativeOrderPrice
+		}
+		if line.UnitID != "" {
+			if _, err := unitFactor(tx, order.TenantID, line.ProductID, line.UnitID); err != nil {
+				return err
+			}
+		}
+
+		line.TenantID = order.TenantID
+		line.PurchaseOrderID = order.ID
+		line.LineNumber = i + 1
+		line.ReceivedQuantity = 0
+		line.OutstandingQuantity = line.Quantity
+
+		query := `
+			INSERT INTO purchase_order_lines (tenant_id, purchase_order_id, line_number, product_id, location_id, unit_id,
+				quantity, unit_price)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			RETURNING id, created_at, updated_at
+		`
+
+		err := tx.QueryRow(
+			query,
+			line.TenantID,
+			line.PurchaseOrderID,
+			line.LineNumber,
+			line.ProductID,
+			nullString(line.LocationID),
+			nullString(line.UnitID),
+			line.Quantity,
+			line.UnitPrice,
+		).Scan(
+			&line.ID,
+			&line.CreatedAt,
+			&line.UpdatedAt,
+		)
+		if err != nil {
+			return err
+		}
+		order.Total += line.Quantity * line.UnitPrice
+	}
+
+	return nil
+}
+
+// PurchaseOrderRepository implements the PurchaseOrderService interface
+type PurchaseOrderRepository struct {
+	db *DB
+}
+
+// NewPurchaseOrderRepository creates a new purchase order repository
+func NewPurchaseOrderRepository(db *DB) *PurchaseOrderRepository {
+	return &PurchaseOrderRepository{db: db}
+}
+
+// Create creates a new draft purchase order
+func (r *PurchaseOrderRepository) Create(order *models.PurchaseOrder) (err error) {
+	if order.OverReceiptTolerance < 0 {
+		return models.ErrNegativeOverReceipt
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	order.Status = models.PurchaseOrderDraft
+	query := `
+		INSERT INTO purchase_orders (tenant_id, supplier_id, number, status, order_date, expected_date,
+			over_receipt_tolerance, notes, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at, updated_at
+	`
+
+	err = tx.QueryRow(
+		query,
+		order.TenantID,
+		order.SupplierID,
+		order.Number,
+		order.Status,
+		order.OrderDate,
+		order.ExpectedDate,
+		order.OverReceiptTolerance,
+		order.Notes,
+		order.CreatedBy,
+	).Scan(
+		&order.ID,
+		&order.CreatedAt,
+		&order.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	return insertPurchaseOrderLines(tx, order)
+}
+
+// GetByID gets a purchase order by ID
+func (r *PurchaseOrderRepository) GetByID(tenantID, id string) (*models.PurchaseOrder, error) {
+	query := `
+		SELECT ` + purchaseOrderColumns + `
+		FROM purchase_orders
+		WHERE tenant_id = $1 AND id = $2
+	`
+
+	return r.get(query, tenantID, id)
+}
+
+// GetByNumber gets a purchase order by number
+func (r *PurchaseOrderRepository) GetByNumber(tenantID, number string) (*models.PurchaseOrder, error) {
+	query := `
+		SELECT ` + purchaseOrderColumns + `
+		FROM purchase_orders
+		WHERE tenant_id = $1 AND number = $2
+	`
+
+	return r.get(query, tenantID, number)
+}
+
+// get gets a purchase order and its lines
+func (r *PurchaseOrderRepository) get(query string, args ...interface{}) (*models.PurchaseOrder, error) {
+	order, err := scanPurchaseOrder(r.db.QueryRow(query, args...))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := loadPurchaseOrderLines(r.db, order); err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// List lists all purchase orders for a tenant, optionally filtered by status
+func (r *PurchaseOrderRepository) List(tenantID, status string) ([]*models.PurchaseOrder, error) {
+	query := `
+		SELECT ` + purchaseOrderColumns + `
+		FROM purchase_orders
+		WHERE tenant_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY order_date DESC, number DESC
+	`
+
+	rows, err := r.db.Query(query, tenantID, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orders := []*models.PurchaseOrder{}
+	for rows.Next() {
+		order, err := scanPurchaseOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Get lines for each purchase order
+	for _, order := range orders {
+		if err := loadPurchaseOrderLines(r.db, order); err != nil {
+			return nil, err
+		}
+	}
+
+	return orders, nil
+}
+
+// Update updates a draft purchase order and replaces its lines
+func (r *PurchaseOrderRepository) Update(order *models.PurchaseOrder) (err error) {
+	if order.OverReceiptTolerance < 0 {
+		return models.ErrNegativeOverReceipt
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	query := `
+		UPDATE purchase_orders
+		SET supplier_id = $1, number = $2, order_date = $3, expected_date = $4, over_receipt_tolerance = $5,
+			notes = $6, updated_at = $7
+		WHERE tenant_id = $8 AND id = $9 AND status = $10
+	`
+
+	now := time.Now()
+	result, err := tx.Exec(
+		query,
+		order.SupplierID,
+		order.Number,
+		order.OrderDate,
+		order.ExpectedDate,
+		order.OverReceiptTolerance,
+		order.Notes,
+		now,
+		order.TenantID,
+		order.ID,
+		models.PurchaseOrderDraft,
+	)
+	if err != nil {
+		return err
+	}
+	if err = requireRowAffected(result, models.ErrPurchaseOrderStatus); err != nil {
+		return err
+	}
+	order.Status = models.PurchaseOrderDraft
+	order.UpdatedAt = now
+
+	_, err = tx.Exec(
+		`DELETE FROM purchase_order_lines WHERE tenant_id = $1 AND purchase_order_id = $2`,
+		order.TenantID,
+		order.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	return insertPurchaseOrderLines(tx, order)
+}
+
+// Approve approves a draft purchase order
+func (r *PurchaseOrderRepository) Approve(tenantID, id, userID string) error {
+	query := `
+		UPDATE purchase_orders
+		SET status = $1, approved_by = $2, approved_at = $3, updated_at = $3
+		WHERE tenant_id = $4 AND id = $5 AND status = $6
+	`
+
+	result, err := r.db.Exec(query, models.PurchaseOrderApproved, userID, time.Now(), tenantID, id, models.PurchaseOrderDraft)
+	if err != nil {
+		return err
+	}
+	return requireRowAffected(result, models.ErrPurchaseOrderStatus)
+}
+
+// Send marks an approved purchase order as sent to the supplier
+func (r *PurchaseOrderRepository) Send(tenantID, id string) error {
+	query := `
+		UPDATE purchase_orders
+		SET status = $1, sent_at = $2, updated_at = $2
+		WHERE tenant_id = $3 AND id = $4 AND status = $5
+	`
+
+	result, err := r.db.Exec(query, models.PurchaseOrderSent, time.Now(), tenantID, id, models.PurchaseOrderApproved)
+	if err != nil {
+		return err
+	}
+	return requireRowAffected(result, models.ErrPurchaseOrderStatus)
+}
+
+// Cancel cancels a purchase order nothing has been received against
+func (r *PurchaseOrderRepository) Cancel(tenantID, id string) error {
+	query := `
+		UPDATE purchase_orders
+		SET status = $1, updated_at = $2
+		WHERE tenant_id = $3 AND id = $4 AND status IN ($5, $6, $7)
+	`
+
+	result, err := r.db.Exec(
+		query,
+		models.PurchaseOrderCancelled,
+		time.Now(),
+		tenantID,
+		id,
+		models.PurchaseOrderDraft,
+		models.PurchaseOrderApproved,
+		models.PurchaseOrderSent,
+	)
+	if err != nil {
+		return err
+	}
+	return requireRowAffected(result, models.ErrPurchaseOrderStatus)
+}
+
+// Delete deletes a draft purchase order
+func (r *PurchaseOrderRepository) Delete(tenantID, id string) error {
+	query := `
+		DELETE FROM purchase_orders
+		WHERE tenant_id = $1 AND id = $2 AND status = $3
+	`
+
+	result, err := r.db.Exec(query, tenantID, id, models.PurchaseOrderDraft)
+	if err != nil {
+		return err
+	}
+	return requireRowAffected(result, models.ErrPurchaseOrderStatus)
+}
+
+// requireRowAffected returns errNone if a statement changed no rows
+func requireRowAffected(result sql.Result, errNone error) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return errNone
+	}
+	return nil
+}
+
+const goodsReceiptLineColumns = `id, tenant_id, goods_receipt_id, purchase_order_line_id, product_id,
+	COALESCE(location_id::text, ''), quantity, lot_number, serial_number, expiry_date, inventory_transaction_id,
+	created_at, updated_at`
+
+// GoodsReceiptRepository implements the GoodsReceiptService interface
+type GoodsReceiptRepository struct {
+	db           *DB
+	transactions *InventoryTransactionRepository
+}
+
+// NewGoodsReceiptRepository creates a new goods receipt repository
+func NewGoodsReceiptRepository(db *DB, transactions *InventoryTransactionRepository) *GoodsReceiptRepository {
+	return &GoodsReceiptRepository{db: db, transactions: transactions}
+}
+
+// Create receives goods against the lines of a sent purchase order. Each line
+// creates an IN inventory transaction at the order price, and the order becomes
+// partially received or received, all within one database transaction.
+func (r *GoodsReceiptRepository) Create(receipt *models.GoodsReceipt) (err error) {
+	stock, err := beginStockTx(r.db)
+	if err != nil {
+		return err
+	}
+	defer func() { err = r.transactions.finishTx(stock, err) }()
+	tx := stock.Tx
+
+	// Lock the purchase order so that concurrent receipts are applied in turn
+	var number, status string
+	var tolerance float64
+	err = tx.QueryRow(
+		`SELECT number, status, over_receipt_tolerance FROM purchase_orders
+		WHERE tenant_id = $1 AND id = $2
+		FOR UPDATE`,
+		receipt.TenantID,
+		receipt.PurchaseOrderID,
+	).Scan(&number, &status, &tolerance)
+	if err != nil {
+		return err
+	}
+
+	if status != models.PurchaseOrderSent && status != models.PurchaseOrderPartiallyReceived {
+		return models.ErrPurchaseOrderStatus
+	}
+
+	if receipt.Reference == "" {
+		receipt.Reference = number
+	}
+
+	query := `
+		INSERT INTO goods_receipts (tenant_id, purchase_order_id, reference, notes, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at
+	`
+
+	err = tx.QueryRow(
+		query,
+		receipt.TenantID,
+		receipt.PurchaseOrderID,
+		receipt.Reference,
+		receipt.Notes,
+		receipt.CreatedBy,
+	).Scan(
+		&receipt.ID,
+		&receipt.CreatedAt,
+		&receipt.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	for i := range receipt.Lines {
+		line := &receipt.Lines[i]
+		line.TenantID = receipt.TenantID
+		line.GoodsReceiptID = receipt.ID
+		if err = r.receiveLine(stock, receipt, line, tolerance); err != nil {
+			return err
+		}
+	}
+
+	// The order is received once no line is outstanding
+	var outstanding int
+	err = tx.QueryRow(
+		`SELECT COUNT(*) FROM purchase_order_lines
+		WHERE tenant_id = $1 AND purchase_order_id = $2 AND received_quantity < quantity`,
+		receipt.TenantID,
+		receipt.PurchaseOrderID,
+	).Scan(&outstanding)
+	if err != nil {
+		return err
+	}
+
+	status = models.PurchaseOrderPartiallyReceived
+	if outstanding == 0 {
+		status = models.PurchaseOrderReceived
+	}
+
+	_, err = tx.Exec(
+		`UPDATE purchase_orders SET status = $1, updated_at = $2 WHERE tenant_id = $3 AND id = $4`,
+		status,
+		time.Now(),
+		receipt.TenantID,
+		receipt.PurchaseOrderID,
+	)
+	return err
+}
+
+// receiveLine receives a goods receipt line against its purchase order line within tx
+func (r *GoodsReceiptRepository) receiveLine(tx *stockTx, receipt *models.GoodsReceipt, line *models.GoodsReceiptLine, tolerance float64) error {
+	if line.Quantity <= 0 {
+		return models.ErrInvalidOrderQuantity
+	}
+
+	var ordered, received, unitPrice float64
+	var locationID, unitID string
+	err := tx.QueryRow(
+		`SELECT product_id, COALESCE(location_id::text, ''), COALESCE(unit_id::text, ''), quantity, received_quantity, unit_price
+		FROM purchase_order_lines
+		WHERE tenant_id = $1 AND id = $2 AND purchase_order_id = $3
+		FOR UPDATE`,
+		receipt.TenantID,
+		line.PurchaseOrderLineID,
+		receipt.PurchaseOrderID,
+	).Scan(&line.ProductID, &locationID, &unitID, &ordered, &received, &unitPrice)
+	if err == sql.ErrNoRows {
+		return models.ErrPurchaseOrderLine
+	}
+	if err != nil {
+		return err
+	}
+
+	allowed := roundQuantity(ordered + ordered*tolerance/100)
+	if roundQuantity(received+line.Quantity) > allowed {
+		return models.ErrOverReceipt
+	}
+
+	if line.LocationID == "" {
+		line.LocationID = locationID
+	}
+
+	transaction := &models.InventoryTransaction{
+		TenantID:        receipt.TenantID,
+		ProductID:       line.ProductID,
+		TransactionType: models.TransactionTypeReceipt,
+		Quantity:        line.Quantity,
+		UnitID:          unitID,
+		LocationID:      line.LocationID,
+		LotNumber:       line.LotNumber,
+		SerialNumber:    line.SerialNumber,
+		ExpiryDate:      line.ExpiryDate,
+		UnitCost:        unitPrice,
+		Reference:       receipt.Reference,
+		Notes:           "Goods receipt",
+		CreatedBy:       receipt.CreatedBy,
+	}
+	if err := r.transactions.createTx(tx, transaction); err != nil {
+		return err
+	}
+	line.InventoryTransactionID = transaction.ID
+
+	_, err = tx.Exec(
+		`UPDATE purchase_order_lines SET received_quantity = received_quantity + $1, updated_at = $2
+		WHERE tenant_id = $3 AND id = $4`,
+		line.Quantity,
+		time.Now(),
+		receipt.TenantID,
+		line.PurchaseOrderLineID,
+	)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO goods_receipt_lines (tenant_id, goods_receipt_id, purchase_order_line_id, product_id, location_id,
+			quantity, lot_number, serial_number, expiry_date, inventory_transaction_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, created_at, updated_at
+	`
+
+	return tx.QueryRow(
+		query,
+		line.TenantID,
+		line.GoodsReceiptID,
+		line.PurchaseOrderLineID,
+		line.ProductID,
+		nullString(line.LocationID),
+		line.Quantity,
+		line.LotNumber,
+		line.SerialNumber,
+		line.ExpiryDate,
+		line.InventoryTransactionID,
+	).Scan(
+		&line.ID,
+		&line.CreatedAt,
+		&line.UpdatedAt,
+	)
+}
+
+// GetByID gets a goods receipt by ID
+func (r *GoodsReceiptRepository) GetByID(tenantID, id string) (*models.GoodsReceipt, error) {
+	receipts, err := r.query(`WHERE tenant_id = $1 AND id = $2`, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(receipts) == 0 {
+		return nil, nil
+	}
+	return receipts[0], nil
+}
+
+// ListByPurchaseOrder lists all goods receipts of a purchase order
+func (r *GoodsReceiptRepository) ListByPurchaseOrder(tenantID, purchaseOrderID string) ([]*models.GoodsReceipt, error) {
+	return r.query(`WHERE tenant_id = $1 AND purchase_order_id = $2`, tenantID, purchaseOrderID)
+}
+
+// query lists the goods receipts matching a where clause with their lines
+func (r *GoodsReceiptRepository) query(where string, args ...interface{}) ([]*models.GoodsReceipt, error) {
+	query := `
+		SELECT id, tenant_id, purchase_order_id, reference, notes, created_by, created_at, updated_at
+		FROM goods_receipts
+		` + where + `
+		ORDER BY created_at
+	`
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	receipts := []*models.GoodsReceipt{}
+	for rows.Next() {
+		receipt := &models.GoodsReceipt{}
+		err := rows.Scan(
+			&receipt.ID,
+			&receipt.TenantID,
+			&receipt.PurchaseOrderID,
+			&receipt.Reference,
+			&receipt.Notes,
+			&receipt.CreatedBy,
+			&receipt.CreatedAt,
+			&receipt.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		receipts = append(receipts, receipt)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Get lines for each goods receipt
+	for _, receipt := range receipts {
+		if err := r.loadLines(receipt); err != nil {
+			return nil, err
+		}
+	}
+
+	return receipts, nil
+}
+
+// loadLines loads the lines of a goods receipt
+func (r *GoodsReceiptRepository) loadLines(receipt *models.GoodsReceipt) error {
+	query := `
+		SELECT ` + goodsReceiptLineColumns + `
+		FROM goods_receipt_lines
+		WHERE tenant_id = $1 AND goods_receipt_id = $2
+		ORDER BY created_at, id
+	`
+
+	rows, err := r.db.Query(query, receipt.TenantID, receipt.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	receipt.Lines = []models.GoodsReceiptLine{}
+	for rows.Next() {
+		line := models.GoodsReceiptLine{}
+		err := rows.Scan(
+			&line.ID,
+			&line.TenantID,
+			&line.GoodsReceiptID,
+			&line.PurchaseOrderLineID,
+			&line.ProductID,
+			&line.LocationID,
+			&line.Quantity,
+			&line.LotNumber,
+			&line.SerialNumber,
+			&line.ExpiryDate,
+			&line.InventoryTransactionID,
+			&line.CreatedAt,
+			&line.UpdatedAt,
+		)
+		if err != nil {
+			return err
+		}
+		receipt.Lines = append(receipt.Lines, line)
+	}
+
+	return rows.Err()
+}
\ No newline at end of file
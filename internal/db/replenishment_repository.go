@@ -0,0 +1,391 @@
+package db
+
+import (
+	"database/sql"
+	"log"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/yookibooki/erp/internal/models"
+)
+
+const reorderRuleColumns = `rr.id, rr.tenant_id, rr.product_id, COALESCE(rr.location_id::text, ''), rr.policy,
+	rr.reorder_point, rr.reorder_quantity, rr.max_quantity, rr.created_at, rr.updated_at`
+
+// scanReorderRule scans a row selected with reorderRuleColumns
+func scanReorderRule(row rowScanner) (*models.ReorderRule, error) {
+	rule := &models.ReorderRule{}
+	err := row.Scan(
+		&rule.ID,
+		&rule.TenantID,
+		&rule.ProductID,
+		&rule.LocationID,
+		&rule.Policy,
+		&rule.ReorderPoint,
+		&rule.ReorderQuantity,
+		&rule.MaxQuantity,
+		&rule.CreatedAt,
+		&rule.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// ReorderRuleRepository implements the ReorderRuleService interface
+type ReorderRuleRepository struct {
+	db *DB
+}
+
+// NewReorderRuleRepository creates a new reorder rule repository
+func NewReorderRuleRepository(db *DB) *ReorderRuleRepository {
+	return &ReorderRuleRepository{db: db}
+}
+
+// Create creates a new reorder rule
+func (r *ReorderRuleRepository) Create(rule *models.ReorderRule) error {
+	query := `
+		INSERT INTO reorder_rules (tenant_id, product_id, location_id, policy, reorder_point, reorder_quantity, max_quantity)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at, updated_at
+	`
+
+	return r.db.QueryRow(
+		query,
+		rule.TenantID,
+		rule.ProductID,
+		nullString(rule.LocationID),
+		rule.Policy,
+		rule.ReorderPoint,
+		rule.ReorderQuantity,
+		rule.MaxQuantity,
+	).Scan(
+		&rule.ID,
+		&rule.CreatedAt,
+		&rule.UpdatedAt,
+	)
+}
+
+// GetByID gets a reorder rule by ID
+func (r *ReorderRuleRepository) GetByID(tenantID, id string) (*models.ReorderRule, error) {
+	query := `
+		SELECT ` + reorderRuleColumns + `
+		FROM reorder_rules rr
+		WHERE rr.tenant_id = $1 AND rr.id = $2
+	`
+
+	rule, err := scanReorderRule(r.db.QueryRow(query, tenantID, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return rule, err
+}
+
+// GetByProductAndLocation gets the reorder rule of a product at a location,
+// or across all locations when locationID is empty
+func (r *ReorderRuleRepository) GetByProductAndLocation(tenantID, productID, locationID string) (*models.ReorderRule, error) {
+	query := `
+		SELECT ` + reorderRuleColumns + `
+		FROM reorder_rules rr
+		WHERE rr.tenant_id = $1 AND rr.product_id = $2 AND COALESCE(rr.location_id::text, '') = $3
+	`
+
+	rule, err := scanReorderRule(r.db.QueryRow(query, tenantID, productID, locationID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return rule, err
+}
+
+// List lists all reorder rules for a tenant
+func (r *ReorderRuleRepository) List(tenantID string) ([]*models.ReorderRule, error) {
+	query := `
+		SELECT ` + reorderRuleColumns + `
+		FROM reorder_rules rr
+		WHERE rr.tenant_id = $1
+		ORDER BY rr.created_at
+	`
+
+	rows, err := r.db.Query(query, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []*models.ReorderRule{}
+	for rows.Next() {
+		rule, err := scanReorderRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+// Update updates a reorder rule
+func (r *ReorderRuleRepository) Update(rule *models.ReorderRule) error {
+	query := `
+		UPDATE reorder_rules
+		SET product_id = $1, location_id = $2, policy = $3, reorder_point = $4, reorder_quantity = $5,
+			max_quantity = $6, updated_at = $7
+		WHERE tenant_id = $8 AND id = $9
+	`
+
+	now := time.Now()
+	_, err := r.db.Exec(
+		query,
+		rule.ProductID,
+		nullString(rule.LocationID),
+		rule.Policy,
+		rule.ReorderPoint,
+		rule.ReorderQuantity,
+		rule.MaxQuantity,
+		now,
+		rule.TenantID,
+		rule.ID,
+	)
+	rule.UpdatedAt = now
+	return err
+}
+
+// Delete deletes a reorder rule
+func (r *ReorderRuleRepository) Delete(tenantID, id string) error {
+	query := `
+		DELETE FROM reorder_rules
+		WHERE tenant_id = $1 AND id = $2
+	`
+
+	_, err := r.db.Exec(query, tenantID, id)
+	return err
+}
+
+// ReplenishmentRepository implements the ReplenishmentService interface
+type ReplenishmentRepository struct {
+	db *DB
+}
+
+// NewReplenishmentRepository creates a new replenishment repository
+func NewReplenishmentRepository(db *DB) *ReplenishmentRepository {
+	return &ReplenishmentRepository{db: db}
+}
+
+// Report computes a replenishment line for every reorder rule of a tenant
+func (r *ReplenishmentRepository) Report(tenantID string, days int) ([]*models.ReplenishmentLine, error) {
+	return r.report(tenantID, "", days)
+}
+
+// ReportByProduct computes a replenishment line for every reorder rule of a product
+func (r *ReplenishmentRepository) ReportByProduct(tenantID, productID string, days int) ([]*models.ReplenishmentLine, error) {
+	return r.report(tenantID, productID, days)
+}
+
+// report computes replenishment lines for the reorder rules of a tenant, limited
+// to a product unless productID is empty. Rules with a location only count stock,
+// reservations, receipts and issues at that location.
+func (r *ReplenishmentRepository) report(tenantID, productID string, days int) ([]*models.ReplenishmentLine, error) {
+	query := `
+		SELECT ` + reorderRuleColumns + `, p.code, p.name,
+			CASE WHEN rr.location_id IS NULL THEN p.stock_quantity ELSE COALESCE(sl.quantity, 0) END,
+			(SELECT COALESCE(SUM(r.quantity), 0) FROM stock_reservations r
+				WHERE r.tenant_id = rr.tenant_id AND r.product_id = rr.product_id
+				AND (rr.location_id IS NULL OR r.location_id = rr.location_id) AND ` + activeReservationSQL + `),
+			(SELECT COALESCE(SUM(s.quantity), 0) FROM scheduled_receipts s
+				WHERE s.tenant_id = rr.tenant_id AND s.product_id = rr.product_id
+				AND (rr.location_id IS NULL OR s.location_id = rr.location_id)) +
+			(SELECT COALESCE(SUM((pol.quantity - pol.received_quantity) * ` + unitFactorSQL("pol") + `), 0) FROM purchase_order_lines pol
+				JOIN purchase_orders po ON po.id = pol.purchase_order_id
+				WHERE pol.tenant_id = rr.tenant_id AND pol.product_id = rr.product_id AND pol.received_quantity < pol.quantity
+				AND (rr.location_id IS NULL OR pol.location_id = rr.location_id) AND ` + openPurchaseOrderSQL + `),
+			(SELECT COALESCE(SUM(t.quantity), 0) FROM inventory_transactions t
+				WHERE t.tenant_id = rr.tenant_id AND t.product_id = rr.product_id AND t.transaction_type = 'OUT'
+				AND (rr.location_id IS NULL OR t.location_id = rr.location_id)
+				AND t.created_at >= NOW() - make_interval(days => $3::int))
+		FROM reorder_rules rr
+		JOIN products p ON p.id = rr.product_id
+		LEFT JOIN stock_levels sl ON sl.tenant_id = rr.tenant_id AND sl.product_id = rr.product_id AND sl.location_id = rr.location_id
+		WHERE rr.tenant_id = $1 AND ($2 = '' OR rr.product_id::text = $2)
+		ORDER BY p.code
+	`
+
+	rows, err := r.db.Query(query, tenantID, productID, days)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lines := []*models.ReplenishmentLine{}
+	for rows.Next() {
+		rule := &models.ReorderRule{}
+		line := &models.ReplenishmentLine{Rule: rule}
+		var consumed float64
+		err := rows.Scan(
+			&rule.ID,
+			&rule.TenantID,
+			&rule.ProductID,
+			&rule.LocationID,
+			&rule.Policy,
+			&rule.ReorderPoint,
+			&rule.ReorderQuantity,
+			&rule.MaxQuantity,
+			&rule.CreatedAt,
+			&rule.UpdatedAt,
+			&line.ProductCode,
+			&line.ProductName,
+			&line.OnHand,
+			&line.Reserved,
+			&line.Inbound,
+			&consumed,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		line.Position = line.OnHand - line.Reserved + line.Inbound
+		line.AverageDailyUsage = consumed / float64(days)
+		if line.AverageDailyUsage > 0 {
+			cover := line.Position / line.AverageDailyUsage
+			line.DaysOfCover = &cover
+		}
+		line.SuggestedQuantity = suggestedQuantity(rule, line.Position)
+		line.NeedsReorder = line.SuggestedQuantity > 0
+		lines = append(lines, line)
+	}
+
+	return lines, nil
+}
+
+// suggestedQuantity returns the quantity to order for a rule at a stock position
+func suggestedQuantity(rule *models.ReorderRule, position float64) float64 {
+	if position > rule.ReorderPoint {
+		return 0
+	}
+
+	if rule.Policy == models.ReorderMinMax {
+		return rule.MaxQuantity - position
+	}
+
+	// Order enough multiples of the reorder quantity to rise above the reorder point
+	multiples := math.Floor((rule.ReorderPoint-position)/rule.ReorderQuantity) + 1
+	return multiples * rule.ReorderQuantity
+}
+
+// lowStockQueueSize is how many committed database transactions of stock movements
+// wait to be checked before further ones are dropped
+const lowStockQueueSize = 256
+
+// LowStockMonitor checks committed stock movements against the reorder rules and
+// notifies of every product they took to or below its reorder point. A single worker
+// started with Run checks them in the background, so that notifying never holds up a
+// request; when its queue is full, movements are logged and dropped.
+type LowStockMonitor struct {
+	replenishment *ReplenishmentRepository
+	notifier      models.LowStockNotifier
+	queue         chan []*models.InventoryTransaction
+	done          chan struct{}
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// NewLowStockMonitor creates a new low stock monitor
+func NewLowStockMonitor(db *DB, notifier models.LowStockNotifier) *LowStockMonitor {
+	return &LowStockMonitor{
+		replenishment: NewReplenishmentRepository(db),
+		notifier:      notifier,
+		queue:         make(chan []*models.InventoryTransaction, lowStockQueueSize),
+		done:          make(chan struct{}),
+	}
+}
+
+// Run checks queued movements until the monitor is closed and its queue is drained
+func (m *LowStockMonitor) Run() {
+	defer close(m.done)
+	for movements := range m.queue {
+		m.notify(movements)
+	}
+}
+
+// Close stops accepting movements and waits for Run to check those already queued
+func (m *LowStockMonitor) Close() {
+	m.mu.Lock()
+	if !m.closed {
+		m.closed = true
+		close(m.queue)
+	}
+	m.mu.Unlock()
+
+	<-m.done
+}
+
+// check queues the movements of a committed database transaction without waiting
+func (m *LowStockMonitor) check(movements []*models.InventoryTransaction) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		log.Printf("Low stock monitor is closed; not checking %d stock movements", len(movements))
+		return
+	}
+
+	select {
+	case m.queue <- movements:
+	default:
+		log.Printf("Low stock queue is full; not checking %d stock movements", len(movements))
+	}
+}
+
+// notify notifies of every reorder rule whose position the movements took to or below
+// its reorder point. Errors are logged since the movements have already been recorded.
+func (m *LowStockMonitor) notify(movements []*models.InventoryTransaction) {
+	if m.notifier == nil {
+		return
+	}
+
+	checked := make(map[string]bool)
+	for _, movement := range movements {
+		if checked[movement.ProductID] {
+			continue
+		}
+		checked[movement.ProductID] = true
+
+		lines, err := m.replenishment.ReportByProduct(movement.TenantID, movement.ProductID, models.DefaultConsumptionDays)
+		if err != nil {
+			log.Printf("Error checking low stock for product %s: %v", movement.ProductID, err)
+			continue
+		}
+
+		for _, line := range lines {
+			// Sum the decrease of every movement of the product at the rule's location
+			// and attribute the alert to the last of them
+			decrease := 0.0
+			transactionID := ""
+			for _, other := range movements {
+				if other.ProductID != movement.ProductID {
+					continue
+				}
+				if amount := stockDecrease(other, line.Rule.LocationID); amount > 0 {
+					decrease += amount
+					transactionID = other.ID
+				}
+			}
+			if decrease == 0 || !line.NeedsReorder || line.Position+decrease <= line.Rule.ReorderPoint {
+				continue
+			}
+
+			alert := &models.LowStockAlert{
+				TenantID:      movement.TenantID,
+				TransactionID: transactionID,
+				Line:          line,
+			}
+			if err := m.notifier.NotifyLowStock(alert); err != nil {
+				log.Printf("Error notifying low stock for product %s: %v", movement.ProductID, err)
+			}
+		}
+	}
+}
\ No newline at end of file
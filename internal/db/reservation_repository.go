@@ -0,0 +1,452 @@
+package db
+
+import (
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/yookibooki/erp/internal/models"
+)
+
+// activeReservationSQL matches reservations aliased as r that still hold stock
+const activeReservationSQL = `r.status = 'active' AND (r.expires_at IS NULL OR r.expires_at > NOW())`
+
+const reservationColumns = `r.id, r.tenant_id, r.product_id, COALESCE(r.location_id::text, ''), r.quantity, r.reference,
+	r.status, r.required_date, r.expires_at, r.created_by, r.created_at, r.updated_at`
+
+// scanReservation scans a row selected with reservationColumns
+func scanReservation(row rowScanner) (*models.Reservation, error) {
+	reservation := &models.Reservation{}
+	err := row.Scan(
+		&reservation.ID,
+		&reservation.TenantID,
+		&reservation.ProductID,
+		&reservation.LocationID,
+		&reservation.Quantity,
+		&reservation.Reference,
+		&reservation.Status,
+		&reservation.RequiredDate,
+		&reservation.ExpiresAt,
+		&reservation.CreatedBy,
+		&reservation.CreatedAt,
+		&reservation.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return reservation, nil
+}
+
+// availableTx returns the unreserved stock of a product outside quarantine within tx,
+// limited to the unreserved stock at a location when locationID is set. The product
+// row is locked so that concurrent reservations cannot promise the same stock twice.
+func availableTx(tx *sql.Tx, tenantID, productID, locationID string) (float64, error) {
+	var onHand, reserved, quarantined float64
+	err := tx.QueryRow(
+		`SELECT stock_quantity FROM products WHERE tenant_id = $1 AND id = $2 FOR UPDATE`,
+		tenantID,
+		productID,
+	).Scan(&onHand)
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.QueryRow(
+		`SELECT COALESCE(SUM(r.quantity), 0) FROM stock_reservations r
+		WHERE r.tenant_id = $1 AND r.product_id = $2 AND `+activeReservationSQL,
+		tenantID,
+		productID,
+	).Scan(&reserved)
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.QueryRow(
+		`SELECT COALESCE(SUM(s.quantity), 0) FROM stock_levels s
+		JOIN locations l ON l.id = s.location_id
+		WHERE s.tenant_id = $1 AND s.product_id = $2 AND l.quarantine`,
+		tenantID,
+		productID,
+	).Scan(&quarantined)
+	if err != nil {
+		return 0, err
+	}
+
+	available := onHand - reserved - quarantined
+	if locationID == "" {
+		return available, nil
+	}
+
+	err = tx.QueryRow(
+		`SELECT COALESCE((SELECT s.quantity FROM stock_levels s
+			JOIN locations l ON l.id = s.location_id
+			WHERE s.tenant_id = $1 AND s.product_id = $2 AND s.location_id = $3 AND NOT l.quarantine), 0)`,
+		tenantID,
+		productID,
+		locationID,
+	).Scan(&onHand)
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.QueryRow(
+		`SELECT COALESCE(SUM(r.quantity), 0) FROM stock_reservations r
+		WHERE r.tenant_id = $1 AND r.product_id = $2 AND r.location_id = $3 AND `+activeReservationSQL,
+		tenantID,
+		productID,
+		locationID,
+	).Scan(&reserved)
+	if err != nil {
+		return 0, err
+	}
+
+	if onHand-reserved < available {
+		available = onHand - reserved
+	}
+	return available, nil
+}
+
+// createReservationTx reserves available stock within tx
+func createReservationTx(tx *sql.Tx, reservation *models.Reservation) error {
+	available, err := availableTx(tx, reservation.TenantID, reservation.ProductID, reservation.LocationID)
+	if err != nil {
+		return err
+	}
+
+	if reservation.Quantity > available {
+		return models.ErrInsufficientAvailable
+	}
+
+	reservation.Status = models.ReservationActive
+	query := `
+		INSERT INTO stock_reservations (tenant_id, product_id, location_id, quantity, reference, status, required_date, expires_at, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at, updated_at
+	`
+
+	return tx.QueryRow(
+		query,
+		reservation.TenantID,
+		reservation.ProductID,
+		nullString(reservation.LocationID),
+		reservation.Quantity,
+		reservation.Reference,
+		reservation.Status,
+		reservation.RequiredDate,
+		reservation.ExpiresAt,
+		reservation.CreatedBy,
+	).Scan(
+		&reservation.ID,
+		&reservation.CreatedAt,
+		&reservation.UpdatedAt,
+	)
+}
+
+// consumeReservationTx draws quantity from an active reservation within tx and
+// fulfills the reservation once it is used up
+func consumeReservationTx(tx *sql.Tx, tenantID, id string, quantity float64) error {
+	query := `
+		UPDATE stock_reservations
+		SET status = CASE WHEN quantity <= $1 THEN $2 ELSE status END,
+			quantity = CASE WHEN quantity <= $1 THEN quantity ELSE quantity - $1 END,
+			updated_at = $3
+		WHERE tenant_id = $4 AND id = $5 AND status = $6
+	`
+
+	_, err := tx.Exec(query, quantity, models.ReservationFulfilled, time.Now(), tenantID, id, models.ReservationActive)
+	return err
+}
+
+// releaseReservationTx releases an active reservation within tx
+func releaseReservationTx(tx *sql.Tx, tenantID, id string) error {
+	query := `
+		UPDATE stock_reservations
+		SET status = $1, updated_at = $2
+		WHERE tenant_id = $3 AND id = $4 AND status = $5
+	`
+
+	_, err := tx.Exec(query, models.ReservationReleased, time.Now(), tenantID, id, models.ReservationActive)
+	return err
+}
+
+// ReservationRepository implements the ReservationService interface
+type ReservationRepository struct {
+	db *DB
+}
+
+// NewReservationRepository creates a new reservation repository
+func NewReservationRepository(db *DB) *ReservationRepository {
+	return &ReservationRepository{db: db}
+}
+
+// Create creates a new reservation
+func (r *ReservationRepository) Create(reservation *models.Reservation) (err error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	return createReservationTx(tx, reservation)
+}
+
+// GetByID gets a reservation by ID
+func (r *ReservationRepository) GetByID(tenantID, id string) (*models.Reservation, error) {
+	query := `
+		SELECT ` + reservationColumns + `
+		FROM stock_reservations r
+		WHERE r.tenant_id = $1 AND r.id = $2
+	`
+
+	reservation, err := scanReservation(r.db.QueryRow(query, tenantID, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return reservation, err
+}
+
+// ListByProduct lists all reservations for a product
+func (r *ReservationRepository) ListByProduct(tenantID, productID string) ([]*models.Reservation, error) {
+	query := `
+		SELECT ` + reservationColumns + `
+		FROM stock_reservations r
+		WHERE r.tenant_id = $1 AND r.product_id = $2
+		ORDER BY r.created_at DESC
+	`
+
+	rows, err := r.db.Query(query, tenantID, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reservations := []*models.Reservation{}
+	for rows.Next() {
+		reservation, err := scanReservation(rows)
+		if err != nil {
+			return nil, err
+		}
+		reservations = append(reservations, reservation)
+	}
+
+	return reservations, nil
+}
+
+// UpdateStatus updates the status of a reservation
+func (r *ReservationRepository) UpdateStatus(tenantID, id, status string) error {
+	query := `
+		UPDATE stock_reservations
+		SET status = $1, updated_at = $2
+		WHERE tenant_id = $3 AND id = $4
+	`
+
+	_, err := r.db.Exec(query, status, time.Now(), tenantID, id)
+	return err
+}
+
+// ProjectAvailability projects the availability of a product by date from its
+// on hand stock outside quarantine, active reservations, scheduled receipts and
+// outstanding purchase order lines. Overdue receipts and reservations without a
+// required date count from today.
+func (r *ReservationRepository) ProjectAvailability(tenantID, productID string) (*models.AvailabilityProjection, error) {
+	projection := &models.AvailabilityProjection{ProductID: productID}
+	err := r.db.QueryRow(
+		`SELECT p.stock_quantity,
+			(SELECT COALESCE(SUM(s.quantity), 0) FROM stock_levels s
+				JOIN locations l ON l.id = s.location_id
+				WHERE s.tenant_id = p.tenant_id AND s.product_id = p.id AND l.quarantine)
+		FROM products p WHERE p.tenant_id = $1 AND p.id = $2`,
+		tenantID,
+		productID,
+	).Scan(&projection.OnHand, &projection.Quarantined)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT GREATEST(COALESCE(r.required_date, CURRENT_DATE), CURRENT_DATE), 0, SUM(r.quantity)
+		FROM stock_reservations r
+		WHERE r.tenant_id = $1 AND r.product_id = $2 AND ` + activeReservationSQL + `
+		GROUP BY 1
+		UNION ALL
+		SELECT GREATEST(s.expected_date, CURRENT_DATE), SUM(s.quantity), 0
+		FROM scheduled_receipts s
+		WHERE s.tenant_id = $1 AND s.product_id = $2
+		GROUP BY 1
+		UNION ALL
+		SELECT GREATEST(COALESCE(po.expected_date, po.order_date), CURRENT_DATE), SUM((pol.quantity - pol.received_quantity) * ` + unitFactorSQL("pol") + `), 0
+		FROM purchase_order_lines pol
+		JOIN purchase_orders po ON po.id = pol.purchase_order_id
+		WHERE pol.tenant_id = $1 AND pol.product_id = $2 AND pol.received_quantity < pol.quantity
+			AND ` + openPurchaseOrderSQL + `
+		GROUP BY 1
+	`
+
+	rows, err := r.db.Query(query, tenantID, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lines := map[string]*models.AvailabilityLine{}
+	for rows.Next() {
+		var date time.Time
+		var inbound, reserved float64
+		if err := rows.Scan(&date, &inbound, &reserved); err != nil {
+			return nil, err
+		}
+
+		key := date.Format("2006-01-02")
+		line, ok := lines[key]
+		if !ok {
+			line = &models.AvailabilityLine{Date: date}
+			lines[key] = line
+		}
+		line.Inbound += inbound
+		line.Reserved += reserved
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	projection.Lines = []*models.AvailabilityLine{}
+	for _, line := range lines {
+		projection.Lines = append(projection.Lines, line)
+	}
+	sort.Slice(projection.Lines, func(i, j int) bool {
+		return projection.Lines[i].Date.Before(projection.Lines[j].Date)
+	})
+
+	projected := projection.OnHand - projection.Quarantined
+	for _, line := range projection.Lines {
+		projected += line.Inbound - line.Reserved
+		line.Projected = projected
+	}
+
+	// Stock can only be promised on a date if no later date depends on it
+	for i := len(projection.Lines) - 1; i >= 0; i-- {
+		line := projection.Lines[i]
+		line.AvailableToPromise = line.Projected
+		if i < len(projection.Lines)-1 && projection.Lines[i+1].AvailableToPromise < line.AvailableToPromise {
+			line.AvailableToPromise = projection.Lines[i+1].AvailableToPromise
+		}
+	}
+
+	return projection, nil
+}
+
+const scheduledReceiptColumns = `id, tenant_id, product_id, COALESCE(location_id::text, ''), quantity, expected_date, reference, created_at, updated_at`
+
+// scanScheduledReceipt scans a row selected with scheduledReceiptColumns
+func scanScheduledReceipt(row rowScanner) (*models.ScheduledReceipt, error) {
+	receipt := &models.ScheduledReceipt{}
+	err := row.Scan(
+		&receipt.ID,
+		&receipt.TenantID,
+		&receipt.ProductID,
+		&receipt.LocationID,
+		&receipt.Quantity,
+		&receipt.ExpectedDate,
+		&receipt.Reference,
+		&receipt.CreatedAt,
+		&receipt.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return receipt, nil
+}
+
+// ScheduledReceiptRepository implements the ScheduledReceiptService interface
+type ScheduledReceiptRepository struct {
+	db *DB
+}
+
+// NewScheduledReceiptRepository creates a new scheduled receipt repository
+func NewScheduledReceiptRepository(db *DB) *ScheduledReceiptRepository {
+	return &ScheduledReceiptRepository{db: db}
+}
+
+// Create creates a new scheduled receipt
+func (r *ScheduledReceiptRepository) Create(receipt *models.ScheduledReceipt) error {
+	query := `
+		INSERT INTO scheduled_receipts (tenant_id, product_id, location_id, quantity, expected_date, reference)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at
+	`
+
+	return r.db.QueryRow(
+		query,
+		receipt.TenantID,
+		receipt.ProductID,
+		nullString(receipt.LocationID),
+		receipt.Quantity,
+		receipt.ExpectedDate,
+		receipt.Reference,
+	).Scan(
+		&receipt.ID,
+		&receipt.CreatedAt,
+		&receipt.UpdatedAt,
+	)
+}
+
+// GetByID gets a scheduled receipt by ID
+func (r *ScheduledReceiptRepository) GetByID(tenantID, id string) (*models.ScheduledReceipt, error) {
+	query := `
+		SELECT ` + scheduledReceiptColumns + `
+		FROM scheduled_receipts
+		WHERE tenant_id = $1 AND id = $2
+	`
+
+	receipt, err := scanScheduledReceipt(r.db.QueryRow(query, tenantID, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return receipt, err
+}
+
+// ListByProduct lists all scheduled receipts for a product
+func (r *ScheduledReceiptRepository) ListByProduct(tenantID, productID string) ([]*models.ScheduledReceipt, error) {
+	query := `
+		SELECT ` + scheduledReceiptColumns + `
+		FROM scheduled_receipts
+		WHERE tenant_id = $1 AND product_id = $2
+		ORDER BY expected_date
+	`
+
+	rows, err := r.db.Query(query, tenantID, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	receipts := []*models.ScheduledReceipt{}
+	for rows.Next() {
+		receipt, err := scanScheduledReceipt(rows)
+		if err != nil {
+			return nil, err
+		}
+		receipts = append(receipts, receipt)
+	}
+
+	return receipts, nil
+}
+
+// Delete deletes a scheduled receipt
+func (r *ScheduledReceiptRepository) Delete(tenantID, id string) error {
+	query := `
+		DELETE FROM scheduled_receipts
+		WHERE tenant_id = $1 AND id = $2
+	`
+
+	_, err := r.db.Exec(query, tenantID, id)
+	return err
+}
\ No newline at end of file
@@ -0,0 +1,611 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/yookibooki/erp/internal/models"
+)
+
+const returnAuthorizationColumns = `id, tenant_id, customer_id, COALESCE(shipment_id::text, ''), number, status, reason, notes,
+	COALESCE(credit_note_id::text, ''), created_by, received_at, credited_at, created_at, updated_at`
+
+// scanReturnAuthorization scans a row selected with returnAuthorizationColumns
+func scanReturnAuthorization(row rowScanner) (*models.ReturnAuthorization, error) {
+	rma := &models.ReturnAuthorization{}
+	err := row.Scan(
+		&rma.ID,
+		&rma.TenantID,
+		&rma.CustomerID,
+		&rma.ShipmentID,
+		&rma.Number,
+		&rma.Status,
+		&rma.Reason,
+		&rma.Notes,
+		&rma.CreditNoteID,
+		&rma.CreatedBy,
+		&rma.ReceivedAt,
+		&rma.CreditedAt,
+		&rma.CreatedAt,
+		&rma.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return rma, nil
+}
+
+const returnLineColumns = `id, tenant_id, return_authorization_id, line_number, COALESCE(shipment_line_id::text, ''),
+	product_id, COALESCE(unit_id::text, ''), quantity, unit_price, lot_number, serial_number, outcome, COALESCE(location_id::text, ''),
+	COALESCE(inventory_transaction_id::text, ''), COALESCE(scrap_transaction_id::text, ''), created_at, updated_at`
+
+// scanReturnLine scans a row selected with returnLineColumns
+func scanReturnLine(row rowScanner) (*models.ReturnLine, error) {
+	line := &models.ReturnLine{}
+	err := row.Scan(
+		&line.ID,
+		&line.TenantID,
+		&line.ReturnAuthorizationID,
+		&line.LineNumber,
+		&line.ShipmentLineID,
+		&line.ProductID,
+		&line.UnitID,
+		&line.Quantity,
+		&line.UnitPrice,
+		&line.LotNumber,
+		&line.SerialNumber,
+		&line.Outcome,
+		&line.LocationID,
+		&line.InventoryTransactionID,
+		&line.ScrapTransactionID,
+		&line.CreatedAt,
+		&line.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return line, nil
+}
+
+// loadReturnLines loads the lines and total of a return authorization
+func loadReturnLines(q queryer, rma *models.ReturnAuthorization) error {
+	query := `
+		SELECT ` + returnLineColumns + `
+		FROM return_lines
+		WHERE tenant_id = $1 AND return_authorization_id = $2
+		ORDER BY line_number
+	`
+
+	rows, err := q.Query(query, rma.TenantID, rma.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	rma.Lines = []models.ReturnLine{}
+	rma.Total = 0
+	for rows.Next() {
+		line, err := scanReturnLine(rows)
+		if err != nil {
+			return err
+		}
+		rma.Total += line.Quantity * line.UnitPrice
+		rma.Lines = append(rma.Lines, *line)
+	}
+
+	return rows.Err()
+}
+
+// lockReturnAuthorization locks a return authorization within tx and returns its number and status
+func lockReturnAuthorization(tx *sql.Tx, tenantID, id string) (number, status string, err error) {
+	err = tx.QueryRow(
+		`SELECT number, status FROM return_authorizations
+		WHERE tenant_id = $1 AND id = $2
+		FOR UPDATE`,
+		tenantID,
+		id,
+	).Scan(&number, &status)
+	return number, status, err
+}
+
+// lockReturnShipmentLine locks the shipment line a return line returns within tx,
+// checks that the quantity returned does not exceed the quantity shipped, sets
+// the product and unit of the return line and defaults its lot, serial number
+// and unit price to those shipped
+func lockReturnShipmentLine(tx *sql.Tx, tenantID, shipmentID string, line *models.ReturnLine) error {
+	var productID, unitID, lotNumber, serialNumber string
+	var shipped, returned float64
+	var unitPrice float64
+	err := tx.QueryRow(
+		`SELECT sl.product_id, COALESCE(sol.unit_id::text, ''), sl.quantity, sl.lot_number, sl.serial_number, sol.unit_price
+		FROM shipment_lines sl
+		JOIN sales_order_lines sol ON sol.id = sl.sales_order_line_id
+		WHERE sl.tenant_id = $1 AND sl.id = $2 AND sl.shipment_id = $3
+		FOR UPDATE OF sl`,
+		tenantID,
+		line.ShipmentLineID,
+		shipmentID,
+	).Scan(&productID, &unitID, &shipped, &lotNumber, &serialNumber, &unitPrice)
+	if err == sql.ErrNoRows {
+		return models.ErrReturnShipmentLine
+	}
+	if err != nil {
+		return err
+	}
+
+	err = tx.QueryRow(
+		`SELECT COALESCE(SUM(rl.quantity), 0)
+		FROM return_lines rl
+		JOIN return_authorizations ra ON ra.id = rl.return_authorization_id
+		WHERE rl.tenant_id = $1 AND rl.shipment_line_id = $2 AND ra.status <> $3`,
+		tenantID,
+		line.ShipmentLineID,
+		models.ReturnCancelled,
+	).Scan(&returned)
+	if err != nil {
+		return err
+	}
+
+	if roundQuantity(returned+line.Quantity) > shipped {
+		return models.ErrOverReturn
+	}
+
+	line.ProductID = productID
+	line.UnitID = unitID
+	if line.LotNumber == "" {
+		line.LotNumber = lotNumber
+	}
+	if line.SerialNumber == "" {
+		line.SerialNumber = serialNumber
+	}
+	if line.UnitPrice == 0 {
+		line.UnitPrice = unitPrice
+	}
+	return nil
+}
+
+// ReturnAuthorizationRepository implements the ReturnAuthorizationService interface
+type ReturnAuthorizationRepository struct {
+	db           *DB
+	transactions *InventoryTransactionRepository
+}
+
+// NewReturnAuthorizationRepository creates a new return authorization repository
+func NewReturnAuthorizationRepository(db *DB, transactions *InventoryTransactionRepository) *ReturnAuthorizationRepository {
+	return &ReturnAuthorizationRepository{db: db, transactions: transactions}
+}
+
+// Create creates a new return authorization. Returns against a shipment must be
+// for goods shipped to the customer and cannot exceed the quantity shipped.
+func (r *ReturnAuthorizationRepository) Create(rma *models.ReturnAuthorization) (err error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	if rma.ShipmentID != "" {
+		var status, customerID string
+		err = tx.QueryRow(
+			`SELECT s.status, so.customer_id
+			FROM shipments s
+			JOIN sales_orders so ON so.id = s.sales_order_id
+			WHERE s.tenant_id = $1 AND s.id = $2`,
+			rma.TenantID,
+			rma.ShipmentID,
+		).Scan(&status, &customerID)
+		if err == sql.ErrNoRows {
+			return models.ErrReturnShipment
+		}
+		if err != nil {
+			return err
+		}
+
+		if status != models.ShipmentShipped || customerID != rma.CustomerID {
+			return models.ErrReturnShipment
+		}
+	}
+
+	rma.Status = models.ReturnAuthorized
+	query := `
+		INSERT INTO return_authorizations (tenant_id, customer_id, shipment_id, number, status, reason, notes, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at, updated_at
+	`
+
+	err = tx.QueryRow(
+		query,
+		rma.TenantID,
+		rma.CustomerID,
+		nullString(rma.ShipmentID),
+		rma.Number,
+		rma.Status,
+		rma.Reason,
+		rma.Notes,
+		rma.CreatedBy,
+	).Scan(
+		&rma.ID,
+		&rma.CreatedAt,
+		&rma.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	rma.Total = 0
+	for i := range rma.Lines {
+		line := &rma.Lines[i]
+		if line.Quantity <= 0 {
+			return models.ErrInvalidSalesQuantity
+		}
+		if line.UnitPrice < 0 {
+			return models.ErrNegativeSalesPrice
+		}
+
+		line.TenantID = rma.TenantID
+		line.ReturnAuthorizationID = rma.ID
+		line.LineNumber = i + 1
+		line.Outcome = ""
+		line.LocationID = ""
+		line.InventoryTransactionID = ""
+		line.ScrapTransactionID = ""
+
+		if line.ShipmentLineID != "" {
+			if rma.ShipmentID == "" {
+				return models.ErrReturnShipmentLine
+			}
+			if err := lockReturnShipmentLine(tx, rma.TenantID, rma.ShipmentID, line); err != nil {
+				return err
+			}
+		} else if line.UnitID != "" {
+			if _, err := unitFactor(tx, rma.TenantID, line.ProductID, line.UnitID); err != nil {
+				return err
+			}
+		}
+
+		query := `
+			INSERT INTO return_lines (tenant_id, return_authorization_id, line_number, shipment_line_id, product_id,
+				unit_id, quantity, unit_price, lot_number, serial_number)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			RETURNING id, created_at, updated_at
+		`
+
+		err := tx.QueryRow(
+			query,
+			line.TenantID,
+			line.ReturnAuthorizationID,
+			line.LineNumber,
+			nullString(line.ShipmentLineID),
+			line.ProductID,
+			nullString(line.UnitID),
+			line.Quantity,
+			line.UnitPrice,
+			line.LotNumber,
+			line.SerialNumber,
+		).Scan(
+			&line.ID,
+			&line.CreatedAt,
+			&line.UpdatedAt,
+		)
+		if err != nil {
+			return err
+		}
+		rma.Total += line.Quantity * line.UnitPrice
+	}
+
+	return nil
+}
+
+// GetByID gets a return authorization by ID
+func (r *ReturnAuthorizationRepository) GetByID(tenantID, id string) (*models.ReturnAuthorization, error) {
+	query := `
+		SELECT ` + returnAuthorizationColumns + `
+		FROM return_authorizations
+		WHERE tenant_id = $1 AND id = $2
+	`
+
+	return r.get(query, tenantID, id)
+}
+
+// GetByNumber gets a return authorization by number
+func (r *ReturnAuthorizationRepository) GetByNumber(tenantID, number string) (*models.ReturnAuthorization, error) {
+	query := `
+		SELECT ` + returnAuthorizationColumns + `
+		FROM return_authorizations
+		WHERE tenant_id = $1 AND number = $2
+	`
+
+	return r.get(query, tenantID, number)
+}
+
+// get gets a return authorization and its lines
+func (r *ReturnAuthorizationRepository) get(query string, args ...interface{}) (*models.ReturnAuthorization, error) {
+	rma, err := scanReturnAuthorization(r.db.QueryRow(query, args...))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := loadReturnLines(r.db, rma); err != nil {
+		return nil, err
+	}
+
+	return rma, nil
+}
+
+// List lists all return authorizations for a tenant, optionally filtered by customer
+func (r *ReturnAuthorizationRepository) List(tenantID, customerID string) ([]*models.ReturnAuthorization, error) {
+	query := `
+		SELECT ` + returnAuthorizationColumns + `
+		FROM return_authorizations
+		WHERE tenant_id = $1 AND ($2 = '' OR customer_id::text = $2)
+		ORDER BY created_at DESC, number DESC
+	`
+
+	rows, err := r.db.Query(query, tenantID, customerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rmas := []*models.ReturnAuthorization{}
+	for rows.Next() {
+		rma, err := scanReturnAuthorization(rows)
+		if err != nil {
+			return nil, err
+		}
+		rmas = append(rmas, rma)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Get lines for each return authorization
+	for _, rma := range rmas {
+		if err := loadReturnLines(r.db, rma); err != nil {
+			return nil, err
+		}
+	}
+
+	return rmas, nil
+}
+
+// Receive books the goods of an authorized return back into stock according to
+// the outcome of their inspection. Each line creates a RETURN inventory transaction
+// at the cost it was shipped at, into sellable stock when restocked or quarantine
+// stock when scrapped or repaired, and scrapped goods are written off again with
+// an ADJUSTMENT, all within one database transaction.
+func (r *ReturnAuthorizationRepository) Receive(tenantID, id, userID string, inspections []models.ReturnInspection) (err error) {
+	stock, err := beginStockTx(r.db)
+	if err != nil {
+		return err
+	}
+	defer func() { err = r.transactions.finishTx(stock, err) }()
+	tx := stock.Tx
+
+	number, status, err := lockReturnAuthorization(tx, tenantID, id)
+	if err != nil {
+		return err
+	}
+
+	if status != models.ReturnAuthorized {
+		return models.ErrReturnStatus
+	}
+
+	rma := &models.ReturnAuthorization{ID: id, TenantID: tenantID, Number: number}
+	if err = loadReturnLines(tx, rma); err != nil {
+		return err
+	}
+
+	// Every line must be inspected exactly once
+	byLine := map[string]models.ReturnInspection{}
+	for _, inspection := range inspections {
+		byLine[inspection.ReturnLineID] = inspection
+	}
+	if len(byLine) != len(inspections) || len(byLine) != len(rma.Lines) {
+		return models.ErrIncompleteInspection
+	}
+
+	now := time.Now()
+	for i := range rma.Lines {
+		line := &rma.Lines[i]
+		inspection, ok := byLine[line.ID]
+		if !ok {
+			return models.ErrReturnLine
+		}
+
+		var quarantine bool
+		err := tx.QueryRow(
+			`SELECT quarantine FROM locations WHERE tenant_id = $1 AND id = $2`,
+			tenantID,
+			inspection.LocationID,
+		).Scan(&quarantine)
+		if err == sql.ErrNoRows {
+			return models.ErrReturnLocation
+		}
+		if err != nil {
+			return err
+		}
+
+		switch inspection.Outcome {
+		case models.ReturnRestock:
+			if quarantine {
+				return models.ErrReturnLocation
+			}
+		case models.ReturnScrap, models.ReturnRepair:
+			if !quarantine {
+				return models.ErrReturnLocation
+			}
+		default:
+			return models.ErrReturnOutcome
+		}
+
+		// Returned goods go back into stock at the cost they were shipped at,
+		// per unit of the shipment
+		var unitCost float64
+		if line.ShipmentLineID != "" {
+			err := tx.QueryRow(
+				`SELECT COALESCE(t.unit_cost * t.quantity / NULLIF(t.unit_quantity, 0), 0)
+				FROM shipment_lines sl
+				LEFT JOIN inventory_transactions t ON t.id = sl.inventory_transaction_id
+				WHERE sl.tenant_id = $1 AND sl.id = $2`,
+				tenantID,
+				line.ShipmentLineID,
+			).Scan(&unitCost)
+			if err != nil {
+				return err
+			}
+		}
+
+		transaction := &models.InventoryTransaction{
+			TenantID:        tenantID,
+			ProductID:       line.ProductID,
+			TransactionType: models.TransactionTypeReturn,
+			Quantity:        line.Quantity,
+			UnitID:          line.UnitID,
+			LocationID:      inspection.LocationID,
+			LotNumber:       line.LotNumber,
+			SerialNumber:    line.SerialNumber,
+			UnitCost:        unitCost,
+			Reference:       number,
+			Notes:           "Customer return (" + inspection.Outcome + ")",
+			CreatedBy:       userID,
+		}
+		if err := r.transactions.createTx(stock, transaction); err != nil {
+			return err
+		}
+
+		scrapTransactionID := ""
+		if inspection.Outcome == models.ReturnScrap {
+			scrap := &models.InventoryTransaction{
+				TenantID:        tenantID,
+				ProductID:       line.ProductID,
+				TransactionType: models.TransactionTypeAdjustment,
+				Quantity:        -line.Quantity,
+				UnitID:          line.UnitID,
+				ReasonCode:      models.ReasonWriteOff,
+				LocationID:      inspection.LocationID,
+				LotNumber:       line.LotNumber,
+				SerialNumber:    line.SerialNumber,
+				Reference:       number,
+				Notes:           "Scrapped customer return",
+				CreatedBy:       userID,
+			}
+			if err := r.transactions.createTx(stock, scrap); err != nil {
+				return err
+			}
+			scrapTransactionID = scrap.ID
+		}
+
+		_, err = tx.Exec(
+			`UPDATE return_lines
+			SET outcome = $1, location_id = $2, inventory_transaction_id = $3, scrap_transaction_id = $4, updated_at = $5
+			WHERE tenant_id = $6 AND id = $7`,
+			inspection.Outcome,
+			inspection.LocationID,
+			transaction.ID,
+			nullString(scrapTransactionID),
+			now,
+			tenantID,
+			line.ID,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(
+		`UPDATE return_authorizations SET status = $1, received_at = $2, updated_at = $2 WHERE tenant_id = $3 AND id = $4`,
+		models.ReturnReceived,
+		now,
+		tenantID,
+		id,
+	)
+	return err
+}
+
+// Credit raises a credit note for a received return as a journal entry debiting
+// the sales returns account and crediting the receivable account with the value
+// of the returned goods
+func (r *ReturnAuthorizationRepository) Credit(tenantID, id, userID string, creditNote *models.CreditNote) (err error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	number, status, err := lockReturnAuthorization(tx, tenantID, id)
+	if err != nil {
+		return err
+	}
+
+	if status != models.ReturnReceived {
+		return models.ErrReturnStatus
+	}
+
+	rma := &models.ReturnAuthorization{ID: id, TenantID: tenantID, Number: number}
+	if err = loadReturnLines(tx, rma); err != nil {
+		return err
+	}
+
+	if rma.Total <= 0 {
+		return models.ErrNothingToCredit
+	}
+
+	now := time.Now()
+	description := "Credit note for return " + number
+	entry := &models.JournalEntry{
+		TenantID:    tenantID,
+		EntryDate:   now,
+		Reference:   number,
+		Description: description,
+		CreatedBy:   userID,
+		Lines: []models.JournalEntryLine{
+			{AccountID: creditNote.SalesReturnsAccountID, Description: description, Debit: rma.Total},
+			{AccountID: creditNote.ReceivableAccountID, Description: description, Credit: rma.Total},
+		},
+	}
+	if err = insertJournalEntry(tx, entry); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		`UPDATE return_authorizations SET status = $1, credit_note_id = $2, credited_at = $3, updated_at = $3
+		WHERE tenant_id = $4 AND id = $5`,
+		models.ReturnCredited,
+		entry.ID,
+		now,
+		tenantID,
+		id,
+	)
+	return err
+}
+
+// Cancel cancels a return authorization whose goods have not been received
+func (r *ReturnAuthorizationRepository) Cancel(tenantID, id string) error {
+	query := `
+		UPDATE return_authorizations
+		SET status = $1, updated_at = $2
+		WHERE tenant_id = $3 AND id = $4 AND status = $5
+	`
+
+	result, err := r.db.Exec(query, models.ReturnCancelled, time.Now(), tenantID, id, models.ReturnAuthorized)
+	if err != nil {
+		return err
+	}
+	return requireRowAffected(result, models.ErrReturnStatus)
+}
\ No newline at end of file
@@ -0,0 +1,1126 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/yookibooki/erp/internal/models"
+)
+
+const salesOrderColumns = `id, tenant_id, customer_id, number, status, order_date, requested_date, currency, notes,
+	created_by, confirmed_at, invoiced_at, created_at, updated_at`
+
+// scanSalesOrder scans a row selected with salesOrderColumns
+func scanSalesOrder(row rowScanner) (*models.SalesOrder, error) {
+	order := &models.SalesOrder{}
+	err := row.Scan(
+		&order.ID,
+		&order.TenantID,
+		&order.CustomerID,
+		&order.Number,
+		&order.Status,
+		&order.OrderDate,
+		&order.RequestedDate,
+		&order.Currency,
+		&order.Notes,
+		&order.CreatedBy,
+		&order.ConfirmedAt,
+		&order.InvoicedAt,
+		&order.CreatedAt,
+		&order.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+var salesOrderLineColumns = `sol.id, sol.tenant_id, sol.sales_order_id, sol.line_number, sol.product_id,
+	COALESCE(sol.location_id::text, ''), COALESCE(sol.unit_id::text, ''), sol.quantity, sol.unit_price,
+	COALESCE(sol.reservation_id::text, ''),
+	COALESCE((SELECT r.quantity FROM stock_reservations r WHERE r.id = sol.reservation_id AND ` + activeReservationSQL + `), 0) /
+		` + unitFactorSQL("sol") + `,
+	sol.shipped_quantity, sol.created_at, sol.updated_at`
+
+// scanSalesOrderLine scans a row selected with salesOrderLineColumns and computes
+// the backordered quantity of a line of an order with status
+func scanSalesOrderLine(row rowScanner, status string) (*models.SalesOrderLine, error) {
+	line := &models.SalesOrderLine{}
+	err := row.Scan(
+		&line.ID,
+		&line.TenantID,
+		&line.SalesOrderID,
+		&line.LineNumber,
+		&line.ProductID,
+		&line.LocationID,
+		&line.UnitID,
+		&line.Quantity,
+		&line.UnitPrice,
+		&line.ReservationID,
+		&line.ReservedQuantity,
+		&line.ShippedQuantity,
+		&line.CreatedAt,
+		&line.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	line.ReservedQuantity = roundQuantity(line.ReservedQuantity)
+	switch status {
+	case models.SalesOrderConfirmed, models.SalesOrderPicked, models.SalesOrderPartiallyShipped:
+		if backorder := roundQuantity(line.Quantity - line.ShippedQuantity - line.ReservedQuantity); backorder > 0 {
+			line.BackorderQuantity = backorder
+		}
+	}
+	return line, nil
+}
+
+// loadSalesOrderLines loads the lines and total of a sales order
+func loadSalesOrderLines(q queryer, order *models.SalesOrder) error {
+	query := `
+		SELECT ` + salesOrderLineColumns + `
+		FROM sales_order_lines sol
+		WHERE sol.tenant_id = $1 AND sol.sales_order_id = $2
+		ORDER BY sol.line_number
+	`
+
+	rows, err := q.Query(query, order.TenantID, order.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	order.Lines = []models.SalesOrderLine{}
+	order.Total = 0
+	for rows.Next() {
+		line, err := scanSalesOrderLine(rows, order.Status)
+		if err != nil {
+			return err
+		}
+		order.Total += line.Quantity * line.UnitPrice
+		order.Lines = append(order.Lines, *line)
+	}
+
+	return rows.Err()
+}
+
+// insertSalesOrderLines inserts the lines of a sales order within tx
+func insertSalesOrderLines(tx *sql.Tx, order *models.SalesOrder) error {
+	order.Total = 0
+	for i := range order.Lines {
+		line := &order.Lines[i]
+		if line.Quantity <= 0 {
+			return models.ErrInvalidSalesQuantity
+		}
+		if line.UnitPrice < 0 {
+			return models.ErrNegativeSalesPrice
+		}
+		if line.UnitID != "" {
+			if _, err := unitFactor(tx, order.TenantID, line.ProductID, line.UnitID); err != nil {
+				return err
+			}
+		}
+
+		line.TenantID = order.TenantID
+		line.SalesOrderID = order.ID
+		line.LineNumber = i + 1
+		line.ReservationID = ""
+		line.ReservedQuantity = 0
+		line.ShippedQuantity = 0
+		line.BackorderQuantity = 0
+
+		query := `
+			INSERT INTO sales_order_lines (tenant_id, sales_order_id, line_number, product_id, location_id, unit_id,
+				quantity, unit_price)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			RETURNING id, created_at, updated_at
+		`
+
+		err := tx.QueryRow(
+			query,
+			line.TenantID,
+			line.SalesOrderID,
+			line.LineNumber,
+			line.ProductID,
+			nullString(line.LocationID),
+			nullString(line.UnitID),
+			line.Quantity,
+			line.UnitPrice,
+		).Scan(
+			&line.ID,
+			&line.CreatedAt,
+			&line.UpdatedAt,
+		)
+		if err != nil {
+			return err
+		}
+		order.Total += line.Quantity * line.UnitPrice
+	}
+
+	return nil
+}
+
+// lockSalesOrder locks a sales order within tx and returns its number, status and requested date
+func lockSalesOrder(tx *sql.Tx, tenantID, id string) (number, status string, requestedDate *time.Time, err error) {
+	err = tx.QueryRow(
+		`SELECT number, status, requested_date FROM sales_orders
+		WHERE tenant_id = $1 AND id = $2
+		FOR UPDATE`,
+		tenantID,
+		id,
+	).Scan(&number, &status, &requestedDate)
+	return number, status, requestedDate, err
+}
+
+// salesLineAllocation is the state of a sales order line needed to reserve its stock.
+// The outstanding quantity is in the product's base unit.
+type salesLineAllocation struct {
+	id            string
+	productID     string
+	locationID    string
+	outstanding   float64
+	reservationID string
+}
+
+// allocateSalesOrderTx replaces the reservations of the lines of a sales order
+// within tx with reservations for as much of their unshipped quantity as is
+// available. The remainder is backordered.
+func allocateSalesOrderTx(tx *sql.Tx, tenantID, orderID, number string, requestedDate *time.Time, userID string) error {
+	rows, err := tx.Query(
+		`SELECT sol.id, sol.product_id, COALESCE(sol.location_id::text, ''),
+			(sol.quantity - sol.shipped_quantity) * `+unitFactorSQL("sol")+`, COALESCE(sol.reservation_id::text, '')
+		FROM sales_order_lines sol
+		WHERE sol.tenant_id = $1 AND sol.sales_order_id = $2
+		ORDER BY sol.line_number
+		FOR UPDATE OF sol`,
+		tenantID,
+		orderID,
+	)
+	if err != nil {
+		return err
+	}
+
+	lines := []*salesLineAllocation{}
+	for rows.Next() {
+		line := &salesLineAllocation{}
+		if err := rows.Scan(&line.id, &line.productID, &line.locationID, &line.outstanding, &line.reservationID); err != nil {
+			rows.Close()
+			return err
+		}
+		lines = append(lines, line)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, line := range lines {
+		if line.reservationID != "" {
+			if err := releaseReservationTx(tx, tenantID, line.reservationID); err != nil {
+				return err
+			}
+		}
+
+		reservationID := ""
+		if line.outstanding > 0 {
+			available, err := availableTx(tx, tenantID, line.productID, line.locationID)
+			if err != nil {
+				return err
+			}
+
+			quantity := line.outstanding
+			if available < quantity {
+				quantity = available
+			}
+
+			if quantity > 0 {
+				reservation := &models.Reservation{
+					TenantID:     tenantID,
+					ProductID:    line.productID,
+					LocationID:   line.locationID,
+					Quantity:     quantity,
+					Reference:    number,
+					RequiredDate: requestedDate,
+					CreatedBy:    userID,
+				}
+				if err := createReservationTx(tx, reservation); err != nil {
+					return err
+				}
+				reservationID = reservation.ID
+			}
+		}
+
+		_, err := tx.Exec(
+			`UPDATE sales_order_lines SET reservation_id = $1, updated_at = $2 WHERE tenant_id = $3 AND id = $4`,
+			nullString(reservationID),
+			time.Now(),
+			tenantID,
+			line.id,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SalesOrderRepository implements the SalesOrderService interface
+type SalesOrderRepository struct {
+	db *DB
+}
+
+// NewSalesOrderRepository creates a new sales order repository
+func NewSalesOrderRepository(db *DB) *SalesOrderRepository {
+	return &SalesOrderRepository{db: db}
+}
+
+// Create creates a new draft sales order
+func (r *SalesOrderRepository) Create(order *models.SalesOrder) (err error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	order.Status = models.SalesOrderDraft
+	query := `
+		INSERT INTO sales_orders (tenant_id, customer_id, number, status, order_date, requested_date, currency, notes,
+			created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at, updated_at
+	`
+
+	err = tx.QueryRow(
+		query,
+		order.TenantID,
+		order.CustomerID,
+		order.Number,
+		order.Status,
+		order.OrderDate,
+		order.RequestedDate,
+		order.Currency,
+		order.Notes,
+		order.CreatedBy,
+	).Scan(
+		&order.ID,
+		&order.CreatedAt,
+		&order.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	return insertSalesOrderLines(tx, order)
+}
+
+// GetByID gets a sales order by ID
+func (r *SalesOrderRepository) GetByID(tenantID, id string) (*models.SalesOrder, error) {
+	query := `
+		SELECT ` + salesOrderColumns + `
+		FROM sales_orders
+		WHERE tenant_id = $1 AND id = $2
+	`
+
+	return r.get(query, tenantID, id)
+}
+
+// GetByNumber gets a sales order by number
+func (r *SalesOrderRepository) GetByNumber(tenantID, number string) (*models.SalesOrder, error) {
+	query := `
+		SELECT ` + salesOrderColumns + `
+		FROM sales_orders
+		WHERE tenant_id = $1 AND number = $2
+	`
+
+	return r.get(query, tenantID, number)
+}
+
+// get gets a sales order and its lines
+func (r *SalesOrderRepository) get(query string, args ...interface{}) (*models.SalesOrder, error) {
+	order, err := scanSalesOrder(r.db.QueryRow(query, args...))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := loadSalesOrderLines(r.db, order); err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// List lists all sales orders for a tenant, optionally filtered by status
+func (r *SalesOrderRepository) List(tenantID, status string) ([]*models.SalesOrder, error) {
+	query := `
+		SELECT ` + salesOrderColumns + `
+		FROM sales_orders
+		WHERE tenant_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY order_date DESC, number DESC
+	`
+
+	rows, err := r.db.Query(query, tenantID, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orders := []*models.SalesOrder{}
+	for rows.Next() {
+		order, err := scanSalesOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Get lines for each sales order
+	for _, order := range orders {
+		if err := loadSalesOrderLines(r.db, order); err != nil {
+			return nil, err
+		}
+	}
+
+	return orders, nil
+}
+
+// ListBackorders lists the lines of open sales orders waiting for stock
+func (r *SalesOrderRepository) ListBackorders(tenantID string) ([]*models.Backorder, error) {
+	query := `
+		SELECT so.number, so.customer_id, so.requested_date, so.status, ` + salesOrderLineColumns + `
+		FROM sales_order_lines sol
+		JOIN sales_orders so ON so.id = sol.sales_order_id
+		WHERE sol.tenant_id = $1 AND so.status IN ($2, $3, $4) AND sol.shipped_quantity < sol.quantity
+		ORDER BY COALESCE(so.requested_date, so.order_date), so.number, sol.line_number
+	`
+
+	rows, err := r.db.Query(
+		query,
+		tenantID,
+		models.SalesOrderConfirmed,
+		models.SalesOrderPicked,
+		models.SalesOrderPartiallyShipped,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	backorders := []*models.Backorder{}
+	for rows.Next() {
+		backorder := &models.Backorder{}
+		var status string
+		line := &models.SalesOrderLine{}
+		err := rows.Scan(
+			&backorder.SalesOrderNumber,
+			&backorder.CustomerID,
+			&backorder.RequestedDate,
+			&status,
+			&line.ID,
+			&line.TenantID,
+			&line.SalesOrderID,
+			&line.LineNumber,
+			&line.ProductID,
+			&line.LocationID,
+			&line.UnitID,
+			&line.Quantity,
+			&line.UnitPrice,
+			&line.ReservationID,
+			&line.ReservedQuantity,
+			&line.ShippedQuantity,
+			&line.CreatedAt,
+			&line.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		line.ReservedQuantity = roundQuantity(line.ReservedQuantity)
+		line.BackorderQuantity = roundQuantity(line.Quantity - line.ShippedQuantity - line.ReservedQuantity)
+		if line.BackorderQuantity <= 0 {
+			continue
+		}
+		backorder.Line = line
+		backorders = append(backorders, backorder)
+	}
+
+	return backorders, nil
+}
+
+// Update updates a draft sales order and replaces its lines
+func (r *SalesOrderRepository) Update(order *models.SalesOrder) (err error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	query := `
+		UPDATE sales_orders
+		SET customer_id = $1, number = $2, order_date = $3, requested_date = $4, currency = $5, notes = $6,
+			updated_at = $7
+		WHERE tenant_id = $8 AND id = $9 AND status = $10
+	`
+
+	now := time.Now()
+	result, err := tx.Exec(
+		query,
+		order.CustomerID,
+		order.Number,
+		order.OrderDate,
+		order.RequestedDate,
+		order.Currency,
+		order.Notes,
+		now,
+		order.TenantID,
+		order.ID,
+		models.SalesOrderDraft,
+	)
+	if err != nil {
+		return err
+	}
+	if err = requireRowAffected(result, models.ErrSalesOrderStatus); err != nil {
+		return err
+	}
+	order.Status = models.SalesOrderDraft
+	order.UpdatedAt = now
+
+	_, err = tx.Exec(
+		`DELETE FROM sales_order_lines WHERE tenant_id = $1 AND sales_order_id = $2`,
+		order.TenantID,
+		order.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	return insertSalesOrderLines(tx, order)
+}
+
+// Confirm confirms a draft sales order and reserves the available stock for its lines
+func (r *SalesOrderRepository) Confirm(tenantID, id, userID string) (err error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	number, status, requestedDate, err := lockSalesOrder(tx, tenantID, id)
+	if err != nil {
+		return err
+	}
+
+	if status != models.SalesOrderDraft {
+		return models.ErrSalesOrderStatus
+	}
+
+	if err = allocateSalesOrderTx(tx, tenantID, id, number, requestedDate, userID); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		`UPDATE sales_orders SET status = $1, confirmed_at = $2, updated_at = $2 WHERE tenant_id = $3 AND id = $4`,
+		models.SalesOrderConfirmed,
+		time.Now(),
+		tenantID,
+		id,
+	)
+	return err
+}
+
+// Allocate reserves stock that has become available for the backordered lines of an open sales order
+func (r *SalesOrderRepository) Allocate(tenantID, id, userID string) (err error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	number, status, requestedDate, err := lockSalesOrder(tx, tenantID, id)
+	if err != nil {
+		return err
+	}
+
+	switch status {
+	case models.SalesOrderConfirmed, models.SalesOrderPicked, models.SalesOrderPartiallyShipped:
+	default:
+		return models.ErrSalesOrderStatus
+	}
+
+	return allocateSalesOrderTx(tx, tenantID, id, number, requestedDate, userID)
+}
+
+// Pick marks a confirmed sales order as picked
+func (r *SalesOrderRepository) Pick(tenantID, id string) error {
+	return r.changeStatus(tenantID, id, models.SalesOrderPicked, models.SalesOrderConfirmed)
+}
+
+// Invoice marks a shipped sales order as invoiced
+func (r *SalesOrderRepository) Invoice(tenantID, id string) error {
+	query := `
+		UPDATE sales_orders
+		SET status = $1, invoiced_at = $2, updated_at = $2
+		WHERE tenant_id = $3 AND id = $4 AND status = $5
+	`
+
+	result, err := r.db.Exec(query, models.SalesOrderInvoiced, time.Now(), tenantID, id, models.SalesOrderShipped)
+	if err != nil {
+		return err
+	}
+	return requireRowAffected(result, models.ErrSalesOrderStatus)
+}
+
+// changeStatus moves a sales order from status from to status to
+func (r *SalesOrderRepository) changeStatus(tenantID, id, to, from string) error {
+	query := `
+		UPDATE sales_orders
+		SET status = $1, updated_at = $2
+		WHERE tenant_id = $3 AND id = $4 AND status = $5
+	`
+
+	result, err := r.db.Exec(query, to, time.Now(), tenantID, id, from)
+	if err != nil {
+		return err
+	}
+	return requireRowAffected(result, models.ErrSalesOrderStatus)
+}
+
+// Cancel cancels a sales order nothing has been shipped against and releases its reservations
+func (r *SalesOrderRepository) Cancel(tenantID, id string) (err error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	_, status, _, err := lockSalesOrder(tx, tenantID, id)
+	if err != nil {
+		return err
+	}
+
+	switch status {
+	case models.SalesOrderDraft, models.SalesOrderConfirmed, models.SalesOrderPicked:
+	default:
+		return models.ErrSalesOrderStatus
+	}
+
+	query := `
+		UPDATE stock_reservations
+		SET status = $1, updated_at = $2
+		WHERE tenant_id = $3 AND status = $4
+			AND id IN (SELECT reservation_id FROM sales_order_lines WHERE tenant_id = $3 AND sales_order_id = $5)
+	`
+
+	now := time.Now()
+	_, err = tx.Exec(query, models.ReservationReleased, now, tenantID, models.ReservationActive, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		`UPDATE sales_orders SET status = $1, updated_at = $2 WHERE tenant_id = $3 AND id = $4`,
+		models.SalesOrderCancelled,
+		now,
+		tenantID,
+		id,
+	)
+	return err
+}
+
+// Delete deletes a draft sales order
+func (r *SalesOrderRepository) Delete(tenantID, id string) error {
+	query := `
+		DELETE FROM sales_orders
+		WHERE tenant_id = $1 AND id = $2 AND status = $3
+	`
+
+	result, err := r.db.Exec(query, tenantID, id, models.SalesOrderDraft)
+	if err != nil {
+		return err
+	}
+	return requireRowAffected(result, models.ErrSalesOrderStatus)
+}
+
+const shipmentLineColumns = `id, tenant_id, shipment_id, sales_order_line_id, product_id, COALESCE(location_id::text, ''),
+	quantity, lot_number, serial_number, COALESCE(inventory_transaction_id::text, ''), created_at, updated_at`
+
+// scanShipmentLine scans a row selected with shipmentLineColumns
+func scanShipmentLine(row rowScanner) (*models.ShipmentLine, error) {
+	line := &models.ShipmentLine{}
+	err := row.Scan(
+		&line.ID,
+		&line.TenantID,
+		&line.ShipmentID,
+		&line.SalesOrderLineID,
+		&line.ProductID,
+		&line.LocationID,
+		&line.Quantity,
+		&line.LotNumber,
+		&line.SerialNumber,
+		&line.InventoryTransactionID,
+		&line.CreatedAt,
+		&line.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return line, nil
+}
+
+// loadShipmentLines loads the lines of a shipment
+func loadShipmentLines(q queryer, shipment *models.Shipment) error {
+	query := `
+		SELECT ` + shipmentLineColumns + `
+		FROM shipment_lines
+		WHERE tenant_id = $1 AND shipment_id = $2
+		ORDER BY created_at, id
+	`
+
+	rows, err := q.Query(query, shipment.TenantID, shipment.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	shipment.Lines = []models.ShipmentLine{}
+	for rows.Next() {
+		line, err := scanShipmentLine(rows)
+		if err != nil {
+			return err
+		}
+		shipment.Lines = append(shipment.Lines, *line)
+	}
+
+	return rows.Err()
+}
+
+// ShipmentRepository implements the ShipmentService interface
+type ShipmentRepository struct {
+	db           *DB
+	transactions *InventoryTransactionRepository
+}
+
+// NewShipmentRepository creates a new shipment repository
+func NewShipmentRepository(db *DB, transactions *InventoryTransactionRepository) *ShipmentRepository {
+	return &ShipmentRepository{db: db, transactions: transactions}
+}
+
+// Create creates a draft shipment of goods packed against the lines of an open sales order
+func (r *ShipmentRepository) Create(shipment *models.Shipment) (err error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	number, status, _, err := lockSalesOrder(tx, shipment.TenantID, shipment.SalesOrderID)
+	if err != nil {
+		return err
+	}
+
+	switch status {
+	case models.SalesOrderConfirmed, models.SalesOrderPicked, models.SalesOrderPartiallyShipped:
+	default:
+		return models.ErrSalesOrderStatus
+	}
+
+	// Shipments are numbered after their sales order
+	var count int
+	err = tx.QueryRow(
+		`SELECT COUNT(*) FROM shipments WHERE tenant_id = $1 AND sales_order_id = $2`,
+		shipment.TenantID,
+		shipment.SalesOrderID,
+	).Scan(&count)
+	if err != nil {
+		return err
+	}
+
+	shipment.Number = fmt.Sprintf("%s-%d", number, count+1)
+	shipment.Status = models.ShipmentDraft
+	query := `
+		INSERT INTO shipments (tenant_id, sales_order_id, number, status, carrier, tracking_number, notes, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at, updated_at
+	`
+
+	err = tx.QueryRow(
+		query,
+		shipment.TenantID,
+		shipment.SalesOrderID,
+		shipment.Number,
+		shipment.Status,
+		shipment.Carrier,
+		shipment.TrackingNumber,
+		shipment.Notes,
+		shipment.CreatedBy,
+	).Scan(
+		&shipment.ID,
+		&shipment.CreatedAt,
+		&shipment.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	for i := range shipment.Lines {
+		line := &shipment.Lines[i]
+		line.TenantID = shipment.TenantID
+		line.ShipmentID = shipment.ID
+		line.InventoryTransactionID = ""
+
+		orderLine, err := lockSalesOrderLine(tx, shipment.TenantID, shipment.SalesOrderID, line)
+		if err != nil {
+			return err
+		}
+		if line.LocationID == "" {
+			line.LocationID = orderLine.LocationID
+		}
+		if err := checkSellableLocation(tx, shipment.TenantID, line.LocationID); err != nil {
+			return err
+		}
+
+		query := `
+			INSERT INTO shipment_lines (tenant_id, shipment_id, sales_order_line_id, product_id, location_id,
+				quantity, lot_number, serial_number)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			RETURNING id, created_at, updated_at
+		`
+
+		err = tx.QueryRow(
+			query,
+			line.TenantID,
+			line.ShipmentID,
+			line.SalesOrderLineID,
+			line.ProductID,
+			nullString(line.LocationID),
+			line.Quantity,
+			line.LotNumber,
+			line.SerialNumber,
+		).Scan(
+			&line.ID,
+			&line.CreatedAt,
+			&line.UpdatedAt,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// lockSalesOrderLine locks the sales order line a shipment line ships against
+// within tx, sets the product of the shipment line and checks that its quantity
+// does not exceed the quantity left to ship
+func lockSalesOrderLine(tx *sql.Tx, tenantID, salesOrderID string, line *models.ShipmentLine) (*models.SalesOrderLine, error) {
+	if line.Quantity <= 0 {
+		return nil, models.ErrInvalidSalesQuantity
+	}
+
+	orderLine := &models.SalesOrderLine{}
+	err := tx.QueryRow(
+		`SELECT id, product_id, COALESCE(location_id::text, ''), COALESCE(unit_id::text, ''), quantity, shipped_quantity,
+			COALESCE(reservation_id::text, '')
+		FROM sales_order_lines
+		WHERE tenant_id = $1 AND id = $2 AND sales_order_id = $3
+		FOR UPDATE`,
+		tenantID,
+		line.SalesOrderLineID,
+		salesOrderID,
+	).Scan(
+		&orderLine.ID,
+		&orderLine.ProductID,
+		&orderLine.LocationID,
+		&orderLine.UnitID,
+		&orderLine.Quantity,
+		&orderLine.ShippedQuantity,
+		&orderLine.ReservationID,
+	)
+	if err == sql.ErrNoRows {
+		return nil, models.ErrSalesOrderLine
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if roundQuantity(orderLine.ShippedQuantity+line.Quantity) > orderLine.Quantity {
+		return nil, models.ErrOverShipment
+	}
+
+	line.ProductID = orderLine.ProductID
+	return orderLine, nil
+}
+
+// GetByID gets a shipment by ID
+func (r *ShipmentRepository) GetByID(tenantID, id string) (*models.Shipment, error) {
+	shipments, err := r.query(`WHERE tenant_id = $1 AND id = $2`, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(shipments) == 0 {
+		return nil, nil
+	}
+	return shipments[0], nil
+}
+
+// ListBySalesOrder lists all shipments of a sales order
+func (r *ShipmentRepository) ListBySalesOrder(tenantID, salesOrderID string) ([]*models.Shipment, error) {
+	return r.query(`WHERE tenant_id = $1 AND sales_order_id = $2`, tenantID, salesOrderID)
+}
+
+// query lists the shipments matching a where clause with their lines
+func (r *ShipmentRepository) query(where string, args ...interface{}) ([]*models.Shipment, error) {
+	query := `
+		SELECT id, tenant_id, sales_order_id, number, status, carrier, tracking_number, notes, shipped_at,
+			created_by, created_at, updated_at
+		FROM shipments
+		` + where + `
+		ORDER BY number
+	`
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	shipments := []*models.Shipment{}
+	for rows.Next() {
+		shipment := &models.Shipment{}
+		err := rows.Scan(
+			&shipment.ID,
+			&shipment.TenantID,
+			&shipment.SalesOrderID,
+			&shipment.Number,
+			&shipment.Status,
+			&shipment.Carrier,
+			&shipment.TrackingNumber,
+			&shipment.Notes,
+			&shipment.ShippedAt,
+			&shipment.CreatedBy,
+			&shipment.CreatedAt,
+			&shipment.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		shipments = append(shipments, shipment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Get lines for each shipment
+	for _, shipment := range shipments {
+		if err := loadShipmentLines(r.db, shipment); err != nil {
+			return nil, err
+		}
+	}
+
+	return shipments, nil
+}
+
+// checkSellableLocation returns ErrQuarantineLocation within tx if a location holds
+// quarantined stock, which is not available to sell. An empty location is sellable.
+func checkSellableLocation(tx *sql.Tx, tenantID, locationID string) error {
+	if locationID == "" {
+		return nil
+	}
+
+	var quarantine bool
+	err := tx.QueryRow(
+		`SELECT quarantine FROM locations WHERE tenant_id = $1 AND id = $2`,
+		tenantID,
+		locationID,
+	).Scan(&quarantine)
+	if err != nil {
+		return err
+	}
+
+	if quarantine {
+		return models.ErrQuarantineLocation
+	}
+	return nil
+}
+
+// Ship confirms a draft shipment. Each line creates an OUT inventory transaction,
+// draws on the reservation of its sales order line and counts as shipped, and the
+// order becomes partially shipped or shipped, all within one database transaction.
+func (r *ShipmentRepository) Ship(tenantID, id, userID string) (err error) {
+	stock, err := beginStockTx(r.db)
+	if err != nil {
+		return err
+	}
+	defer func() { err = r.transactions.finishTx(stock, err) }()
+	tx := stock.Tx
+
+	shipment := &models.Shipment{ID: id, TenantID: tenantID}
+	err = tx.QueryRow(
+		`SELECT sales_order_id, number, status FROM shipments
+		WHERE tenant_id = $1 AND id = $2
+		FOR UPDATE`,
+		tenantID,
+		id,
+	).Scan(&shipment.SalesOrderID, &shipment.Number, &shipment.Status)
+	if err != nil {
+		return err
+	}
+
+	if shipment.Status != models.ShipmentDraft {
+		return models.ErrShipmentStatus
+	}
+
+	number, status, _, err := lockSalesOrder(tx, tenantID, shipment.SalesOrderID)
+	if err != nil {
+		return err
+	}
+
+	switch status {
+	case models.SalesOrderConfirmed, models.SalesOrderPicked, models.SalesOrderPartiallyShipped:
+	default:
+		return models.ErrSalesOrderStatus
+	}
+
+	if err = loadShipmentLines(tx, shipment); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for i := range shipment.Lines {
+		line := &shipment.Lines[i]
+		orderLine, err := lockSalesOrderLine(tx, tenantID, shipment.SalesOrderID, line)
+		if err != nil {
+			return err
+		}
+		if err := checkSellableLocation(tx, tenantID, line.LocationID); err != nil {
+			return err
+		}
+
+		transaction := &models.InventoryTransaction{
+			TenantID:        tenantID,
+			ProductID:       line.ProductID,
+			TransactionType: models.TransactionTypeIssue,
+			Quantity:        line.Quantity,
+			UnitID:          orderLine.UnitID,
+			LocationID:      line.LocationID,
+			LotNumber:       line.LotNumber,
+			SerialNumber:    line.SerialNumber,
+			Reference:       shipment.Number,
+			Notes:           "Shipment for sales order " + number,
+			CreatedBy:       userID,
+		}
+		if err := r.transactions.createTx(stock, transaction); err != nil {
+			return err
+		}
+
+		if orderLine.ReservationID != "" {
+			if err := consumeReservationTx(tx, tenantID, orderLine.ReservationID, transaction.Quantity); err != nil {
+				return err
+			}
+		}
+
+		_, err = tx.Exec(
+			`UPDATE sales_order_lines SET shipped_quantity = shipped_quantity + $1, updated_at = $2
+			WHERE tenant_id = $3 AND id = $4`,
+			line.Quantity,
+			now,
+			tenantID,
+			orderLine.ID,
+		)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(
+			`UPDATE shipment_lines SET inventory_transaction_id = $1, updated_at = $2 WHERE tenant_id = $3 AND id = $4`,
+			transaction.ID,
+			now,
+			tenantID,
+			line.ID,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	// The order is shipped once no line is left to ship
+	var outstanding int
+	err = tx.QueryRow(
+		`SELECT COUNT(*) FROM sales_order_lines
+		WHERE tenant_id = $1 AND sales_order_id = $2 AND shipped_quantity < quantity`,
+		tenantID,
+		shipment.SalesOrderID,
+	).Scan(&outstanding)
+	if err != nil {
+		return err
+	}
+
+	status = models.SalesOrderPartiallyShipped
+	if outstanding == 0 {
+		status = models.SalesOrderShipped
+	}
+
+	_, err = tx.Exec(
+		`UPDATE sales_orders SET status = $1, updated_at = $2 WHERE tenant_id = $3 AND id = $4`,
+		status,
+		now,
+		tenantID,
+		shipment.SalesOrderID,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		`UPDATE shipments SET status = $1, shipped_at = $2, updated_at = $2 WHERE tenant_id = $3 AND id = $4`,
+		models.ShipmentShipped,
+		now,
+		tenantID,
+		id,
+	)
+	return err
+}
\ No newline at end of file
@@ -0,0 +1,172 @@
+package db
+
+import (
+	"html"
+	"strings"
+
+	"github.com/yookibooki/erp/internal/models"
+)
+
+// Control characters ts_headline marks the matched words with, chr(2) and chr(3) in
+// SQL. They are removed from the record text first, so snippetHTML only turns
+// ts_headline's own marks into markup.
+const (
+	headlineStart = "\x02"
+	headlineStop  = "\x03"
+)
+
+// snippetHTML turns the marks in an HTML-escaped snippet into <mark> tags
+var snippetHTML = strings.NewReplacer(headlineStart, "<mark>", headlineStop, "</mark>")
+
+// searchSource is a table searched for one type of hit. document is the searched text,
+// written exactly as the expressions indexed by the search migration so that the
+// indexes can be used.
+type searchSource struct {
+	hitType    string
+	from       string
+	id         string
+	customerID string
+	title      string
+	document   string
+}
+
+// searchSources are the tables searched, each aliased t
+var searchSources = []searchSource{
+	{
+		hitType:    models.SearchCustomer,
+		from:       `customers t`,
+		id:         `t.id`,
+		customerID: `t.id`,
+		title:      `t.name`,
+		document:   `t.name || ' ' || t.email || ' ' || t.phone`,
+	},
+	{
+		hitType:    models.SearchContact,
+		from:       `contacts t`,
+		id:         `t.id`,
+		customerID: `t.customer_id`,
+		title:      `t.first_name || ' ' || t.last_name`,
+		document:   `t.first_name || ' ' || t.last_name || ' ' || t.email || ' ' || t.phone`,
+	},
+	{
+		hitType:    models.SearchInteraction,
+		from:       `interactions t JOIN customers c ON c.id = t.customer_id`,
+		id:         `t.id`,
+		customerID: `t.customer_id`,
+		title:      `c.name || ' - ' || t.interaction_type`,
+		document:   `t.description`,
+	},
+}
+
+// searchSourceSQL selects the hits of a source for the query text $3 of a tenant $1
+// with the text search configuration config. Snippets mark the matched words with
+// headlineStart and headlineStop.
+func searchSourceSQL(source searchSource, config string) string {
+	query := `websearch_to_tsquery(` + config + `, $3)`
+	vector := `to_tsvector(` + config + `, ` + source.document + `)`
+	text := `translate(` + source.document + `, chr(2) || chr(3), '')`
+
+	return `
+		SELECT '` + source.hitType + `' AS type, ` + source.id + `::text AS id, ` + source.customerID + `::text AS customer_id,
+			` + source.title + ` AS title,
+			CASE WHEN ` + vector + ` @@ ` + query + `
+				THEN ts_headline(` + config + `, ` + text + `, ` + query + `,
+					'StartSel=' || chr(2) || ', StopSel=' || chr(3) || ', MaxFragments=2')
+				ELSE LEFT(` + text + `, 200)
+			END AS snippet,
+			CASE WHEN ` + vector + ` @@ ` + query + `
+				THEN 1 + ts_rank(` + vector + `, ` + query + `)
+				ELSE word_similarity($3, ` + source.document + `)
+			END AS rank,
+			` + vector + ` @@ ` + query + ` AS full_text
+		FROM ` + source.from + `
+		WHERE t.tenant_id = $1 AND (` + vector + ` @@ ` + query + ` OR $3 <% (` + source.document + `))`
+}
+
+// SearchRepository implements the SearchService interface
+type SearchRepository struct {
+	db *DB
+}
+
+// NewSearchRepository creates a new search repository
+func NewSearchRepository(db *DB) *SearchRepository {
+	return &SearchRepository{db: db}
+}
+
+// Search finds the customers, contacts and interactions of a tenant matching the query
+// text, best match first. Words are matched with the text search configuration of the
+// query language; records whose text is only similar to the query, such as misspelt
+// names, follow the full-text matches.
+func (r *SearchRepository) Search(tenantID string, query models.SearchQuery) ([]*models.SearchHit, error) {
+	language := query.Language
+	if language == "" {
+		language = models.DefaultSearchLanguage
+	}
+
+	var exists bool
+	err := r.db.QueryRow(`SELECT EXISTS(SELECT 1 FROM pg_ts_config WHERE cfgname = $1)`, language).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists {
+		return nil, models.ErrSearchLanguage
+	}
+
+	types := map[string]bool{}
+	for _, hitType := range query.Types {
+		types[hitType] = true
+	}
+
+	// The indexes are built for the default configuration, which is written as a
+	// constant so that the planner can match them
+	config := `'` + models.DefaultSearchLanguage + `'`
+	args := []interface{}{tenantID, query.Limit, query.Text}
+	if language != models.DefaultSearchLanguage {
+		config = `$4::regconfig`
+		args = append(args, language)
+	}
+
+	sqlQuery := ``
+	for _, source := range searchSources {
+		if len(types) > 0 && !types[source.hitType] {
+			continue
+		}
+		if sqlQuery != "" {
+			sqlQuery += `
+		UNION ALL`
+		}
+		sqlQuery += searchSourceSQL(source, config)
+	}
+	sqlQuery += `
+		ORDER BY rank DESC, title
+		LIMIT $2
+	`
+
+	rows, err := r.db.Query(sqlQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hits := []*models.SearchHit{}
+	for rows.Next() {
+		hit := &models.SearchHit{}
+		err := rows.Scan(
+			&hit.Type,
+			&hit.ID,
+			&hit.CustomerID,
+			&hit.Title,
+			&hit.Snippet,
+			&hit.Rank,
+			&hit.FullText,
+		)
+		if err != nil {
+			return nil, err
+		}
+		hit.Snippet = snippetHTML.Replace(html.EscapeString(hit.Snippet))
+		hits = append(hits, hit)
+	}
+
+	return hits, rows.Err()
+}
\ No newline at end of file
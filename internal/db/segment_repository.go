@@ -0,0 +1,351 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yookibooki/erp/internal/models"
+)
+
+// segmentComparisons are the SQL operators of the segment rule comparisons
+var segmentComparisons = map[string]string{
+	models.SegmentOpEqual:          "=",
+	models.SegmentOpNotEqual:       "<>",
+	models.SegmentOpGreater:        ">",
+	models.SegmentOpGreaterOrEqual: ">=",
+	models.SegmentOpLess:           "<",
+	models.SegmentOpLessOrEqual:    "<=",
+}
+
+// customerRevenueSQL is the revenue of a customer from its sales orders that are neither
+// draft nor cancelled, over the number of days given by the parameter or all time when
+// it is 0
+func customerRevenueSQL(days string) string {
+	return `(SELECT COALESCE(SUM(l.quantity * l.unit_price), 0)
+		FROM sales_orders o
+		JOIN sales_order_lines l ON l.sales_order_id = o.id
+		WHERE o.tenant_id = customers.tenant_id AND o.customer_id = customers.id
+			AND o.status NOT IN ('draft', 'cancelled')
+			AND (` + days + `::integer = 0 OR o.order_date > CURRENT_DATE - ` + days + `::integer))`
+}
+
+// lastInteractionSQL is the date of the last interaction with a customer
+const lastInteractionSQL = `(SELECT MAX(i.interaction_date) FROM interactions i
+	WHERE i.tenant_id = customers.tenant_id AND i.customer_id = customers.id)`
+
+// segmentQuery builds the conditions of segment rules on the customers table, adding
+// their values to the query arguments
+type segmentQuery struct {
+	args       []interface{}
+	fieldTypes map[string]string
+}
+
+// arg adds a query argument and returns its parameter
+func (q *segmentQuery) arg(value interface{}) string {
+	q.args = append(q.args, value)
+	return "$" + strconv.Itoa(len(q.args))
+}
+
+// condition returns the condition of a rule
+func (q *segmentQuery) condition(rule models.SegmentRule) (string, error) {
+	switch rule.Type {
+	case models.SegmentRuleTag:
+		has := `tags @> ARRAY[` + q.arg(rule.Value) + `::text]`
+		switch rule.Operator {
+		case models.SegmentOpHas:
+			return has, nil
+		case models.SegmentOpNotHas:
+			return `NOT ` + has, nil
+		}
+	case models.SegmentRuleCustomField:
+		comparison, ok := segmentComparisons[rule.Operator]
+		if !ok {
+			break
+		}
+		key := q.arg(rule.Key) + "::text"
+		if q.fieldTypes[rule.Key] == models.CustomFieldNumber {
+			return `(CASE WHEN jsonb_typeof(custom_fields -> ` + key + `) = 'number'
+				THEN (custom_fields ->> ` + key + `)::numeric END) ` + comparison + ` ` + q.arg(rule.Value) + `::numeric`, nil
+		}
+		if rule.Operator == models.SegmentOpNotEqual {
+			return `(custom_fields ->> ` + key + `) IS DISTINCT FROM ` + q.arg(rule.Value), nil
+		}
+		return `(custom_fields ->> ` + key + `) ` + comparison + ` ` + q.arg(rule.Value), nil
+	case models.SegmentRuleLastInteraction:
+		recent := `EXISTS (SELECT 1 FROM interactions i
+			WHERE i.tenant_id = customers.tenant_id AND i.customer_id = customers.id
+				AND i.interaction_date >= NOW() - MAKE_INTERVAL(days => ` + q.arg(rule.Days) + `::integer))`
+		switch rule.Operator {
+		case models.SegmentOpWithin:
+			return recent, nil
+		case models.SegmentOpNotWithin:
+			return `NOT ` + recent, nil
+		}
+	case models.SegmentRuleRevenue:
+		comparison, ok := segmentComparisons[rule.Operator]
+		if !ok {
+			break
+		}
+		return customerRevenueSQL(q.arg(rule.Days)) + ` ` + comparison + ` ` + q.arg(rule.Value) + `::numeric`, nil
+	}
+
+	return "", models.ErrSegmentRule
+}
+
+const segmentColumns = `id, tenant_id, name, description, match, rules, created_by, created_at, updated_at`
+
+// scanSegment scans a row selected with segmentColumns
+func scanSegment(row rowScanner) (*models.Segment, error) {
+	segment := &models.Segment{}
+	var rules []byte
+	err := row.Scan(
+		&segment.ID,
+		&segment.TenantID,
+		&segment.Name,
+		&segment.Description,
+		&segment.Match,
+		&rules,
+		&segment.CreatedBy,
+		&segment.CreatedAt,
+		&segment.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(rules, &segment.Rules); err != nil {
+		return nil, err
+	}
+	return segment, nil
+}
+
+// SegmentRepository implements the SegmentService interface
+type SegmentRepository struct {
+	db *DB
+}
+
+// NewSegmentRepository creates a new segment repository
+func NewSegmentRepository(db *DB) *SegmentRepository {
+	return &SegmentRepository{db: db}
+}
+
+// Create creates a new segment
+func (r *SegmentRepository) Create(segment *models.Segment) error {
+	query := `
+		INSERT INTO segments (tenant_id, name, description, match, rules, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at
+	`
+
+	rules, err := json.Marshal(segment.Rules)
+	if err != nil {
+		return err
+	}
+
+	return r.db.QueryRow(
+		query,
+		segment.TenantID,
+		segment.Name,
+		segment.Description,
+		segment.Match,
+		string(rules),
+		segment.CreatedBy,
+	).Scan(
+		&segment.ID,
+		&segment.CreatedAt,
+		&segment.UpdatedAt,
+	)
+}
+
+// GetByID gets a segment by ID
+func (r *SegmentRepository) GetByID(tenantID, id string) (*models.Segment, error) {
+	query := `
+		SELECT ` + segmentColumns + `
+		FROM segments
+		WHERE tenant_id = $1 AND id = $2
+	`
+
+	segment, err := scanSegment(r.db.QueryRow(query, tenantID, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return segment, err
+}
+
+// GetByName gets a segment by name
+func (r *SegmentRepository) GetByName(tenantID, name string) (*models.Segment, error) {
+	query := `
+		SELECT ` + segmentColumns + `
+		FROM segments
+		WHERE tenant_id = $1 AND name = $2
+	`
+
+	segment, err := scanSegment(r.db.QueryRow(query, tenantID, name))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return segment, err
+}
+
+// List lists all segments for a tenant
+func (r *SegmentRepository) List(tenantID string) ([]*models.Segment, error) {
+	query := `
+		SELECT ` + segmentColumns + `
+		FROM segments
+		WHERE tenant_id = $1
+		ORDER BY name
+	`
+
+	rows, err := r.db.Query(query, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	segments := []*models.Segment{}
+	for rows.Next() {
+		segment, err := scanSegment(rows)
+		if err != nil {
+			return nil, err
+		}
+		segments = append(segments, segment)
+	}
+
+	return segments, rows.Err()
+}
+
+// Update updates a segment
+func (r *SegmentRepository) Update(segment *models.Segment) error {
+	query := `
+		UPDATE segments
+		SET name = $1, description = $2, match = $3, rules = $4, updated_at = $5
+		WHERE tenant_id = $6 AND id = $7
+	`
+
+	rules, err := json.Marshal(segment.Rules)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	_, err = r.db.Exec(
+		query,
+		segment.Name,
+		segment.Description,
+		segment.Match,
+		string(rules),
+		now,
+		segment.TenantID,
+		segment.ID,
+	)
+	segment.UpdatedAt = now
+	return err
+}
+
+// Delete deletes a segment
+func (r *SegmentRepository) Delete(tenantID, id string) error {
+	query := `
+		DELETE FROM segments
+		WHERE tenant_id = $1 AND id = $2
+	`
+
+	_, err := r.db.Exec(query, tenantID, id)
+	return err
+}
+
+// ListMembers lists the customers matching the rules of a segment by name, with their
+// revenue and last interaction. Custom field rules compare number fields as numbers
+// and other fields as text.
+func (r *SegmentRepository) ListMembers(tenantID string, segment *models.Segment) ([]*models.SegmentMember, error) {
+	fieldTypes, err := r.customerFieldTypes(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	q := &segmentQuery{fieldTypes: fieldTypes}
+	tenant := q.arg(tenantID)
+
+	conditions := make([]string, 0, len(segment.Rules))
+	for _, rule := range segment.Rules {
+		condition, err := q.condition(rule)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, "("+condition+")")
+	}
+
+	match := "TRUE"
+	if len(conditions) > 0 {
+		separator := " AND "
+		if segment.Match == models.SegmentMatchAny {
+			separator = " OR "
+		}
+		match = strings.Join(conditions, separator)
+	}
+
+	query := `
+		SELECT ` + customerColumns + `, ` + customerRevenueSQL("0") + `, ` + lastInteractionSQL + `
+		FROM customers
+		WHERE tenant_id = ` + tenant + ` AND (` + match + `)
+		ORDER BY name
+	`
+
+	rows, err := r.db.Query(query, q.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []*models.SegmentMember{}
+	for rows.Next() {
+		member := &models.SegmentMember{}
+		customer, err := scanCustomer(scanPrefix{rows, []interface{}{&member.Revenue, &member.LastInteractionAt}})
+		if err != nil {
+			return nil, err
+		}
+		member.Customer = *customer
+		members = append(members, member)
+	}
+
+	return members, rows.Err()
+}
+
+// customerFieldTypes gets the field types of the tenant's customer custom fields by key
+func (r *SegmentRepository) customerFieldTypes(tenantID string) (map[string]string, error) {
+	rows, err := r.db.Query(
+		`SELECT key, field_type FROM custom_field_definitions WHERE tenant_id = $1 AND entity_type = $2`,
+		tenantID,
+		models.CustomFieldCustomer,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	fieldTypes := map[string]string{}
+	for rows.Next() {
+		var key, fieldType string
+		if err := rows.Scan(&key, &fieldType); err != nil {
+			return nil, err
+		}
+		fieldTypes[key] = fieldType
+	}
+
+	return fieldTypes, rows.Err()
+}
+
+// scanPrefix scans a row whose leading columns are read by another scan function and
+// whose remaining columns go to extra
+type scanPrefix struct {
+	row   rowScanner
+	extra []interface{}
+}
+
+// Scan scans the row into dest followed by the extra destinations
+func (s scanPrefix) Scan(dest ...interface{}) error {
+	return s.row.Scan(append(dest, s.extra...)...)
+}
\ No newline at end of file
@@ -0,0 +1,221 @@
+package db
+
+import (
+	"time"
+
+	"github.com/yookibooki/erp/internal/models"
+)
+
+// locationMovementsSQL selects the stock movements of each location from the ledger.
+// Transfers move stock out of their location and into their destination location.
+const locationMovementsSQL = `
+	SELECT product_id, location_id, lot_number, serial_number, created_at,
+		CASE WHEN transaction_type = 'TRANSFER' THEN -quantity ELSE ` + stockChangeSQL + ` END AS quantity
+	FROM inventory_transactions
+	WHERE tenant_id = $1 AND location_id IS NOT NULL
+	UNION ALL
+	SELECT product_id, destination_location_id, lot_number, serial_number, created_at, quantity
+	FROM inventory_transactions
+	WHERE tenant_id = $1 AND destination_location_id IS NOT NULL AND transaction_type = 'TRANSFER'
+`
+
+// StockLedgerRepository implements the StockLedgerService interface
+type StockLedgerRepository struct {
+	db *DB
+}
+
+// NewStockLedgerRepository creates a new stock ledger repository
+func NewStockLedgerRepository(db *DB) *StockLedgerRepository {
+	return &StockLedgerRepository{db: db}
+}
+
+// StockOnHand recomputes the stock on hand as of a point in time from the transactions
+// recorded until then, at a location or in total when locationID is empty
+func (r *StockLedgerRepository) StockOnHand(tenantID string, asOf time.Time, locationID string) (*models.StockOnHand, error) {
+	query := `
+		SELECT p.id, p.code, p.name, SUM(` + stockChangeSQL + `)
+		FROM products p
+		JOIN inventory_transactions t ON t.tenant_id = p.tenant_id AND t.product_id = p.id
+		WHERE p.tenant_id = $1 AND t.created_at <= $2
+		GROUP BY p.id, p.code, p.name
+		HAVING ROUND(SUM(` + stockChangeSQL + `), 4) <> 0
+		ORDER BY p.code
+	`
+	args := []interface{}{tenantID, asOf}
+	if locationID != "" {
+		query = `
+			SELECT p.id, p.code, p.name, SUM(m.quantity)
+			FROM (` + locationMovementsSQL + `) m
+			JOIN products p ON p.id = m.product_id
+			WHERE m.location_id = $3 AND m.created_at <= $2
+			GROUP BY p.id, p.code, p.name
+			HAVING ROUND(SUM(m.quantity), 4) <> 0
+			ORDER BY p.code
+		`
+		args = append(args, locationID)
+	}
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stock := &models.StockOnHand{
+		AsOf:       asOf,
+		LocationID: locationID,
+		Lines:      []*models.StockOnHandLine{},
+	}
+	for rows.Next() {
+		line := &models.StockOnHandLine{}
+		err := rows.Scan(
+			&line.ProductID,
+			&line.ProductCode,
+			&line.ProductName,
+			&line.Quantity,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		line.Quantity = roundQuantity(line.Quantity)
+		stock.Lines = append(stock.Lines, line)
+	}
+
+	return stock, rows.Err()
+}
+
+// CheckConsistency compares the stored stock of products, stock levels and lots with
+// the quantities recomputed from the ledger and reports every difference
+func (r *StockLedgerRepository) CheckConsistency(tenantID string) (*models.StockConsistencyReport, error) {
+	report := &models.StockConsistencyReport{
+		TenantID:  tenantID,
+		CheckedAt: time.Now(),
+		Drifts:    []*models.StockDrift{},
+	}
+
+	checks := []func(string) ([]*models.StockDrift, error){
+		r.productDrift,
+		r.locationDrift,
+		r.lotDrift,
+	}
+	for _, check := range checks {
+		drifts, err := check(tenantID)
+		if err != nil {
+			return nil, err
+		}
+		report.Drifts = append(report.Drifts, drifts...)
+	}
+
+	report.Consistent = len(report.Drifts) == 0
+	return report, nil
+}
+
+// productDrift compares the stock quantity of products with their ledger
+func (r *StockLedgerRepository) productDrift(tenantID string) ([]*models.StockDrift, error) {
+	query := `
+		SELECT p.id, p.code, p.name, '', '', '', '', p.stock_quantity, COALESCE(l.quantity, 0)
+		FROM products p
+		LEFT JOIN (
+			SELECT product_id, SUM(` + stockChangeSQL + `) AS quantity
+			FROM inventory_transactions
+			WHERE tenant_id = $1
+			GROUP BY product_id
+		) l ON l.product_id = p.id
+		WHERE p.tenant_id = $1 AND ROUND(p.stock_quantity - COALESCE(l.quantity, 0), 4) <> 0
+		ORDER BY p.code
+	`
+
+	return r.queryDrifts(models.StockDriftProduct, query, tenantID)
+}
+
+// locationDrift compares the stock levels of products at locations with their ledger
+func (r *StockLedgerRepository) locationDrift(tenantID string) ([]*models.StockDrift, error) {
+	query := `
+		SELECT p.id, p.code, p.name, loc.id, loc.code, '', '', COALESCE(s.quantity, 0), COALESCE(l.quantity, 0)
+		FROM (
+			SELECT product_id, location_id, quantity
+			FROM stock_levels
+			WHERE tenant_id = $1
+		) s
+		FULL JOIN (
+			SELECT product_id, location_id, SUM(quantity) AS quantity
+			FROM (` + locationMovementsSQL + `) m
+			GROUP BY product_id, location_id
+		) l ON l.product_id = s.product_id AND l.location_id = s.location_id
+		JOIN products p ON p.id = COALESCE(s.product_id, l.product_id)
+		JOIN locations loc ON loc.id = COALESCE(s.location_id, l.location_id)
+		WHERE ROUND(COALESCE(s.quantity, 0) - COALESCE(l.quantity, 0), 4) <> 0
+		ORDER BY p.code, loc.code
+	`
+
+	return r.queryDrifts(models.StockDriftLocation, query, tenantID)
+}
+
+// lotDrift compares the quantity of lots and serial numbers with their ledger. Lots are
+// keyed by lot number and serial numbers by serial number, as movements apply them.
+func (r *StockLedgerRepository) lotDrift(tenantID string) ([]*models.StockDrift, error) {
+	query := `
+		SELECT p.id, p.code, p.name, '', '',
+			CASE WHEN p.tracking_mode = 'serial' THEN '' ELSE COALESCE(s.lot_number, l.lot_number) END,
+			CASE WHEN p.tracking_mode = 'serial' THEN COALESCE(s.serial_number, l.serial_number) ELSE '' END,
+			COALESCE(s.quantity, 0), COALESCE(l.quantity, 0)
+		FROM (
+			SELECT product_id, lot_number, serial_number, quantity
+			FROM inventory_lots
+			WHERE tenant_id = $1
+		) s
+		FULL JOIN (
+			SELECT t.product_id,
+				CASE WHEN pt.tracking_mode = 'serial' THEN '' ELSE t.lot_number END AS lot_number,
+				CASE WHEN pt.tracking_mode = 'serial' THEN t.serial_number ELSE '' END AS serial_number,
+				SUM(` + stockChangeSQL + `) AS quantity
+			FROM inventory_transactions t
+			JOIN products pt ON pt.id = t.product_id
+			WHERE t.tenant_id = $1 AND pt.tracking_mode <> 'none'
+			GROUP BY 1, 2, 3
+		) l ON l.product_id = s.product_id
+			AND (l.serial_number = s.serial_number AND l.serial_number <> ''
+				OR l.lot_number = s.lot_number AND l.serial_number = '' AND s.serial_number = '')
+		JOIN products p ON p.id = COALESCE(s.product_id, l.product_id)
+		WHERE ROUND(COALESCE(s.quantity, 0) - COALESCE(l.quantity, 0), 4) <> 0
+		ORDER BY p.code, 6, 7
+	`
+
+	return r.queryDrifts(models.StockDriftLot, query, tenantID)
+}
+
+// queryDrifts runs a drift query selecting the product, location, lot and serial number
+// with the stored and ledger quantities
+func (r *StockLedgerRepository) queryDrifts(kind, query string, tenantID string) ([]*models.StockDrift, error) {
+	rows, err := r.db.Query(query, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	drifts := []*models.StockDrift{}
+	for rows.Next() {
+		drift := &models.StockDrift{Kind: kind}
+		err := rows.Scan(
+			&drift.ProductID,
+			&drift.ProductCode,
+			&drift.ProductName,
+			&drift.LocationID,
+			&drift.LocationCode,
+			&drift.LotNumber,
+			&drift.SerialNumber,
+			&drift.StoredQuantity,
+			&drift.LedgerQuantity,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		drift.LedgerQuantity = roundQuantity(drift.LedgerQuantity)
+		drift.Difference = roundQuantity(drift.StoredQuantity - drift.LedgerQuantity)
+		drifts = append(drifts, drift)
+	}
+
+	return drifts, rows.Err()
+}
\ No newline at end of file
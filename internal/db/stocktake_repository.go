@@ -0,0 +1,732 @@
+package db
+
+import (
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/yookibooki/erp/internal/models"
+)
+
+const stocktakeColumns = `id, tenant_id, number, COALESCE(location_id::text, ''), product_ids, status, notes,
+	created_by, COALESCE(approved_by::text, ''), approved_at, created_at, updated_at`
+
+// scanStocktake scans a row selected with stocktakeColumns
+func scanStocktake(row rowScanner) (*models.Stocktake, error) {
+	stocktake := &models.Stocktake{}
+	err := row.Scan(
+		&stocktake.ID,
+		&stocktake.TenantID,
+		&stocktake.Number,
+		&stocktake.LocationID,
+		pq.Array(&stocktake.ProductIDs),
+		&stocktake.Status,
+		&stocktake.Notes,
+		&stocktake.CreatedBy,
+		&stocktake.ApprovedBy,
+		&stocktake.ApprovedAt,
+		&stocktake.CreatedAt,
+		&stocktake.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return stocktake, nil
+}
+
+// loadStocktakeLines loads the lines of a stocktake and totals their variances
+func loadStocktakeLines(q queryer, stocktake *models.Stocktake) error {
+	query := `
+		SELECT id, tenant_id, stocktake_id, line_number, product_id, lot_number, serial_number,
+			expected_quantity, counted_quantity, unit_cost, COALESCE(transaction_id::text, ''),
+			COALESCE(counted_by::text, ''), counted_at, created_at, updated_at
+		FROM stocktake_lines
+		WHERE tenant_id = $1 AND stocktake_id = $2
+		ORDER BY line_number
+	`
+
+	rows, err := q.Query(query, stocktake.TenantID, stocktake.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	stocktake.Lines = []models.StocktakeLine{}
+	stocktake.CountedLines = 0
+	stocktake.VarianceValue = 0
+	for rows.Next() {
+		line := models.StocktakeLine{}
+		err := rows.Scan(
+			&line.ID,
+			&line.TenantID,
+			&line.StocktakeID,
+			&line.LineNumber,
+			&line.ProductID,
+			&line.LotNumber,
+			&line.SerialNumber,
+			&line.ExpectedQuantity,
+			&line.CountedQuantity,
+			&line.UnitCost,
+			&line.TransactionID,
+			&line.CountedBy,
+			&line.CountedAt,
+			&line.CreatedAt,
+			&line.UpdatedAt,
+		)
+		if err != nil {
+			return err
+		}
+
+		if line.CountedQuantity != nil {
+			line.Variance = roundQuantity(*line.CountedQuantity - line.ExpectedQuantity)
+			line.VarianceValue = line.Variance * line.UnitCost
+			stocktake.CountedLines++
+			stocktake.VarianceValue += line.VarianceValue
+		}
+		stocktake.Lines = append(stocktake.Lines, line)
+	}
+
+	return rows.Err()
+}
+
+// allStockQuery selects the stock on the books across all locations: untracked
+// products by product and tracked products by lot or serial number
+const allStockQuery = `
+	SELECT id AS product_id, '' AS lot_number, '' AS serial_number, stock_quantity AS quantity
+	FROM products
+	WHERE tenant_id = $1 AND tracking_mode = 'none' AND stock_quantity <> 0
+	UNION ALL
+	SELECT product_id, lot_number, serial_number, quantity
+	FROM inventory_lots
+	WHERE tenant_id = $1 AND quantity <> 0
+`
+
+// locationStockQuery selects the stock on the books at a location. Lots are not kept
+// per location, so the stock of tracked products is summed from their movements.
+const locationStockQuery = `
+	SELECT s.product_id, '' AS lot_number, '' AS serial_number, s.quantity
+	FROM stock_levels s
+	JOIN products p ON p.id = s.product_id
+	WHERE s.tenant_id = $1 AND s.location_id = $2 AND p.tracking_mode = 'none' AND s.quantity <> 0
+	UNION ALL
+	SELECT m.product_id, m.lot_number, m.serial_number, SUM(m.quantity)
+	FROM (` + locationMovementsSQL + `) m
+	JOIN products p ON p.id = m.product_id
+	WHERE m.location_id = $2 AND p.tracking_mode <> 'none'
+	GROUP BY m.product_id, m.lot_number, m.serial_number
+	HAVING SUM(m.quantity) <> 0
+`
+
+// freezeStocktakeLines builds the lines of a new stocktake from the stock on the books
+// within tx. Untracked products of a product subset are counted even when out of stock.
+func freezeStocktakeLines(tx *sql.Tx, stocktake *models.Stocktake) error {
+	stockQuery := allStockQuery
+	args := []interface{}{stocktake.TenantID}
+	if stocktake.LocationID != "" {
+		stockQuery = locationStockQuery
+		args = append(args, stocktake.LocationID)
+	}
+
+	query := `
+		SELECT e.product_id, e.lot_number, e.serial_number, e.quantity, p.average_cost
+		FROM (` + stockQuery + `) e
+		JOIN products p ON p.id = e.product_id
+		ORDER BY p.code, e.lot_number, e.serial_number
+	`
+
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	subset := map[string]bool{}
+	for _, productID := range stocktake.ProductIDs {
+		subset[productID] = true
+	}
+
+	frozen := map[string]bool{}
+	stocktake.Lines = []models.StocktakeLine{}
+	for rows.Next() {
+		line := models.StocktakeLine{}
+		err := rows.Scan(
+			&line.ProductID,
+			&line.LotNumber,
+			&line.SerialNumber,
+			&line.ExpectedQuantity,
+			&line.UnitCost,
+		)
+		if err != nil {
+			return err
+		}
+		if len(subset) > 0 && !subset[line.ProductID] {
+			continue
+		}
+		frozen[line.ProductID] = true
+		stocktake.Lines = append(stocktake.Lines, line)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	for _, productID := range stocktake.ProductIDs {
+		if frozen[productID] {
+			continue
+		}
+
+		var trackingMode string
+		var averageCost float64
+		err := tx.QueryRow(
+			`SELECT tracking_mode, average_cost FROM products WHERE tenant_id = $1 AND id = $2`,
+			stocktake.TenantID,
+			productID,
+		).Scan(&trackingMode, &averageCost)
+		if err != nil {
+			return err
+		}
+
+		// Tracked products without stock get lines as their lots are counted
+		if trackingMode == models.TrackingNone {
+			stocktake.Lines = append(stocktake.Lines, models.StocktakeLine{ProductID: productID, UnitCost: averageCost})
+		}
+	}
+
+	if len(stocktake.Lines) == 0 {
+		return models.ErrStocktakeEmpty
+	}
+
+	for i := range stocktake.Lines {
+		stocktake.Lines[i].LineNumber = i + 1
+		if err := insertStocktakeLine(tx, stocktake, &stocktake.Lines[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// insertStocktakeLine inserts a line of a stocktake within tx
+func insertStocktakeLine(tx *sql.Tx, stocktake *models.Stocktake, line *models.StocktakeLine) error {
+	line.TenantID = stocktake.TenantID
+	line.StocktakeID = stocktake.ID
+
+	query := `
+		INSERT INTO stocktake_lines (tenant_id, stocktake_id, line_number, product_id, lot_number, serial_number,
+			expected_quantity, counted_quantity, unit_cost, counted_by, counted_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id, created_at, updated_at
+	`
+
+	return tx.QueryRow(
+		query,
+		line.TenantID,
+		line.StocktakeID,
+		line.LineNumber,
+		line.ProductID,
+		line.LotNumber,
+		line.SerialNumber,
+		line.ExpectedQuantity,
+		line.CountedQuantity,
+		line.UnitCost,
+		nullString(line.CountedBy),
+		line.CountedAt,
+	).Scan(
+		&line.ID,
+		&line.CreatedAt,
+		&line.UpdatedAt,
+	)
+}
+
+// lockStocktake locks an open stocktake within tx and loads its lines
+func lockStocktake(tx *sql.Tx, tenantID, id string) (*models.Stocktake, error) {
+	query := `
+		SELECT ` + stocktakeColumns + `
+		FROM stocktakes
+		WHERE tenant_id = $1 AND id = $2
+		FOR UPDATE
+	`
+
+	stocktake, err := scanStocktake(tx.QueryRow(query, tenantID, id))
+	if err != nil {
+		return nil, err
+	}
+
+	if stocktake.Status != models.StocktakeOpen {
+		return nil, models.ErrStocktakeStatus
+	}
+
+	if err := loadStocktakeLines(tx, stocktake); err != nil {
+		return nil, err
+	}
+	return stocktake, nil
+}
+
+// recordLineCount sets the counted quantity of a stocktake line within tx
+func recordLineCount(tx *sql.Tx, line *models.StocktakeLine, quantity float64, userID string, now time.Time) error {
+	if quantity < 0 {
+		return models.ErrNegativeCount
+	}
+	if line.SerialNumber != "" && quantity != 0 && quantity != 1 {
+		return models.ErrSerialCount
+	}
+
+	query := `
+		UPDATE stocktake_lines
+		SET counted_quantity = $1, counted_by = $2, counted_at = $3, updated_at = $3
+		WHERE tenant_id = $4 AND id = $5
+	`
+
+	_, err := tx.Exec(query, quantity, nullString(userID), now, line.TenantID, line.ID)
+	if err != nil {
+		return err
+	}
+
+	line.CountedQuantity = &quantity
+	line.CountedBy = userID
+	line.CountedAt = &now
+	return nil
+}
+
+// StocktakeRepository implements the StocktakeService interface
+type StocktakeRepository struct {
+	db           *DB
+	transactions *InventoryTransactionRepository
+}
+
+// NewStocktakeRepository creates a new stocktake repository
+func NewStocktakeRepository(db *DB, transactions *InventoryTransactionRepository) *StocktakeRepository {
+	return &StocktakeRepository{db: db, transactions: transactions}
+}
+
+// Create creates a new open stocktake and freezes the quantities expected to be counted
+func (r *StocktakeRepository) Create(stocktake *models.Stocktake) (err error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	if stocktake.ProductIDs == nil {
+		stocktake.ProductIDs = []string{}
+	}
+	stocktake.Status = models.StocktakeOpen
+
+	query := `
+		INSERT INTO stocktakes (tenant_id, number, location_id, product_ids, status, notes, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at, updated_at
+	`
+
+	err = tx.QueryRow(
+		query,
+		stocktake.TenantID,
+		stocktake.Number,
+		nullString(stocktake.LocationID),
+		pq.Array(stocktake.ProductIDs),
+		stocktake.Status,
+		stocktake.Notes,
+		stocktake.CreatedBy,
+	).Scan(
+		&stocktake.ID,
+		&stocktake.CreatedAt,
+		&stocktake.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	return freezeStocktakeLines(tx, stocktake)
+}
+
+// GetByID gets a stocktake by ID
+func (r *StocktakeRepository) GetByID(tenantID, id string) (*models.Stocktake, error) {
+	query := `
+		SELECT ` + stocktakeColumns + `
+		FROM stocktakes
+		WHERE tenant_id = $1 AND id = $2
+	`
+
+	return r.get(query, tenantID, id)
+}
+
+// GetByNumber gets a stocktake by number
+func (r *StocktakeRepository) GetByNumber(tenantID, number string) (*models.Stocktake, error) {
+	query := `
+		SELECT ` + stocktakeColumns + `
+		FROM stocktakes
+		WHERE tenant_id = $1 AND number = $2
+	`
+
+	return r.get(query, tenantID, number)
+}
+
+// get gets a stocktake and its lines
+func (r *StocktakeRepository) get(query string, args ...interface{}) (*models.Stocktake, error) {
+	stocktake, err := scanStocktake(r.db.QueryRow(query, args...))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := loadStocktakeLines(r.db, stocktake); err != nil {
+		return nil, err
+	}
+
+	return stocktake, nil
+}
+
+// List lists all stocktakes for a tenant, optionally filtered by status
+func (r *StocktakeRepository) List(tenantID, status string) ([]*models.Stocktake, error) {
+	query := `
+		SELECT ` + stocktakeColumns + `
+		FROM stocktakes
+		WHERE tenant_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.Query(query, tenantID, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stocktakes := []*models.Stocktake{}
+	for rows.Next() {
+		stocktake, err := scanStocktake(rows)
+		if err != nil {
+			return nil, err
+		}
+		stocktakes = append(stocktakes, stocktake)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Get lines for each stocktake
+	for _, stocktake := range stocktakes {
+		if err := loadStocktakeLines(r.db, stocktake); err != nil {
+			return nil, err
+		}
+	}
+
+	return stocktakes, nil
+}
+
+// RecordCounts records counted quantities on an open stocktake. Counts of a lot or
+// serial number, or of a product of the stocktake's scope, that was not in stock when
+// the stocktake was created add a line with nothing expected.
+func (r *StocktakeRepository) RecordCounts(tenantID, id, userID string, counts []models.StocktakeCount) (err error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	stocktake, err := lockStocktake(tx, tenantID, id)
+	if err != nil {
+		return err
+	}
+
+	subset := map[string]bool{}
+	for _, productID := range stocktake.ProductIDs {
+		subset[productID] = true
+	}
+
+	now := time.Now()
+	for _, count := range counts {
+		var productID, trackingMode string
+		var averageCost float64
+		err := tx.QueryRow(
+			`SELECT id, tracking_mode, average_cost FROM products
+			WHERE tenant_id = $1 AND (id::text = $2 OR ($2 = '' AND code = $3))`,
+			tenantID,
+			count.ProductID,
+			count.ProductCode,
+		).Scan(&productID, &trackingMode, &averageCost)
+		if err == sql.ErrNoRows {
+			return models.ErrStocktakeProduct
+		}
+		if err != nil {
+			return err
+		}
+
+		// Lots are keyed by lot number and serial numbers by serial number alone
+		switch trackingMode {
+		case models.TrackingLot:
+			if count.LotNumber == "" {
+				return models.ErrLotNumberRequired
+			}
+			count.SerialNumber = ""
+		case models.TrackingSerial:
+			if count.SerialNumber == "" {
+				return models.ErrSerialNumberRequired
+			}
+		default:
+			count.LotNumber = ""
+			count.SerialNumber = ""
+		}
+
+		var line *models.StocktakeLine
+		for i := range stocktake.Lines {
+			candidate := &stocktake.Lines[i]
+			if candidate.ProductID != productID {
+				continue
+			}
+			if (trackingMode == models.TrackingSerial && candidate.SerialNumber == count.SerialNumber) ||
+				(trackingMode == models.TrackingLot && candidate.LotNumber == count.LotNumber) ||
+				trackingMode == models.TrackingNone {
+				line = candidate
+				break
+			}
+		}
+
+		if line != nil {
+			if err := recordLineCount(tx, line, count.CountedQuantity, userID, now); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if len(subset) > 0 && !subset[productID] {
+			return models.ErrStocktakeProduct
+		}
+
+		newLine := models.StocktakeLine{
+			LineNumber:   len(stocktake.Lines) + 1,
+			ProductID:    productID,
+			LotNumber:    count.LotNumber,
+			SerialNumber: count.SerialNumber,
+			UnitCost:     averageCost,
+		}
+		if count.CountedQuantity < 0 {
+			return models.ErrNegativeCount
+		}
+		if newLine.SerialNumber != "" && count.CountedQuantity != 0 && count.CountedQuantity != 1 {
+			return models.ErrSerialCount
+		}
+		quantity := count.CountedQuantity
+		newLine.CountedQuantity = &quantity
+		newLine.CountedBy = userID
+		newLine.CountedAt = &now
+
+		if err := insertStocktakeLine(tx, stocktake, &newLine); err != nil {
+			return err
+		}
+		stocktake.Lines = append(stocktake.Lines, newLine)
+	}
+
+	_, err = tx.Exec(`UPDATE stocktakes SET updated_at = $1 WHERE tenant_id = $2 AND id = $3`, now, tenantID, id)
+	return err
+}
+
+// CountLine records the counted quantity of a line of an open stocktake
+func (r *StocktakeRepository) CountLine(tenantID, id, lineID, userID string, quantity float64) (err error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	stocktake, err := lockStocktake(tx, tenantID, id)
+	if err != nil {
+		return err
+	}
+
+	for i := range stocktake.Lines {
+		if stocktake.Lines[i].ID != lineID {
+			continue
+		}
+
+		now := time.Now()
+		if err := recordLineCount(tx, &stocktake.Lines[i], quantity, userID, now); err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(`UPDATE stocktakes SET updated_at = $1 WHERE tenant_id = $2 AND id = $3`, now, tenantID, id)
+		return err
+	}
+
+	return sql.ErrNoRows
+}
+
+// Approve approves a fully counted stocktake and adjusts stock by the variance of
+// each line with a COUNT adjustment
+func (r *StocktakeRepository) Approve(tenantID, id, userID string) (err error) {
+	stock, err := beginStockTx(r.db)
+	if err != nil {
+		return err
+	}
+	defer func() { err = r.transactions.finishTx(stock, err) }()
+	tx := stock.Tx
+
+	stocktake, err := lockStocktake(tx, tenantID, id)
+	if err != nil {
+		return err
+	}
+
+	for _, line := range stocktake.Lines {
+		if line.CountedQuantity == nil {
+			return models.ErrStocktakeNotCounted
+		}
+	}
+
+	now := time.Now()
+	for _, line := range stocktake.Lines {
+		if line.Variance == 0 {
+			continue
+		}
+
+		adjustment := &models.InventoryTransaction{
+			TenantID:        tenantID,
+			ProductID:       line.ProductID,
+			TransactionType: models.TransactionTypeAdjustment,
+			Quantity:        line.Variance,
+			ReasonCode:      models.ReasonCount,
+			LocationID:      stocktake.LocationID,
+			LotNumber:       line.LotNumber,
+			SerialNumber:    line.SerialNumber,
+			Reference:       stocktake.Number,
+			Notes:           "Stocktake " + stocktake.Number,
+			CreatedBy:       userID,
+		}
+		if err := r.transactions.createTx(stock, adjustment); err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(
+			`UPDATE stocktake_lines SET transaction_id = $1, updated_at = $2 WHERE tenant_id = $3 AND id = $4`,
+			adjustment.ID,
+			now,
+			tenantID,
+			line.ID,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	query := `
+		UPDATE stocktakes
+		SET status = $1, approved_by = $2, approved_at = $3, updated_at = $3
+		WHERE tenant_id = $4 AND id = $5
+	`
+
+	_, err = tx.Exec(query, models.StocktakeApproved, userID, now, tenantID, id)
+	return err
+}
+
+// Cancel cancels an open stocktake without adjusting stock
+func (r *StocktakeRepository) Cancel(tenantID, id string) error {
+	query := `
+		UPDATE stocktakes
+		SET status = $1, updated_at = $2
+		WHERE tenant_id = $3 AND id = $4 AND status = $5
+	`
+
+	result, err := r.db.Exec(query, models.StocktakeCancelled, time.Now(), tenantID, id, models.StocktakeOpen)
+	if err != nil {
+		return err
+	}
+	return requireRowAffected(result, models.ErrStocktakeStatus)
+}
+
+// CycleCountSchedule classifies products by the value issued over the policy's period
+// and schedules their next count from the last approved stocktake that counted them.
+// Products are listed in the order they are due.
+func (r *StocktakeRepository) CycleCountSchedule(tenantID string, policy *models.CycleCountPolicy) ([]*models.CycleCountItem, error) {
+	query := `
+		SELECT p.id, p.code, p.name,
+			COALESCE((SELECT -SUM(t.total_cost) FROM inventory_transactions t
+				WHERE t.tenant_id = p.tenant_id AND t.product_id = p.id AND t.transaction_type = 'OUT'
+				AND t.created_at >= NOW() - make_interval(days => $2::int)), 0) AS consumption_value,
+			(SELECT MAX(s.approved_at) FROM stocktake_lines l
+				JOIN stocktakes s ON s.id = l.stocktake_id
+				WHERE l.tenant_id = p.tenant_id AND l.product_id = p.id AND s.status = 'approved')
+		FROM products p
+		WHERE p.tenant_id = $1
+		ORDER BY consumption_value DESC, p.code
+	`
+
+	rows, err := r.db.Query(query, tenantID, policy.Days)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []*models.CycleCountItem{}
+	total := 0.0
+	for rows.Next() {
+		item := &models.CycleCountItem{}
+		err := rows.Scan(
+			&item.ProductID,
+			&item.ProductCode,
+			&item.ProductName,
+			&item.ConsumptionValue,
+			&item.LastCountedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		total += item.ConsumptionValue
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Classes follow the share of value issued before each product in the ranking
+	now := time.Now()
+	cumulative := 0.0
+	for _, item := range items {
+		share := 100.0
+		if total > 0 {
+			share = cumulative / total * 100
+		}
+		cumulative += item.ConsumptionValue
+
+		switch {
+		case item.ConsumptionValue > 0 && share < 80:
+			item.Class = models.ABCClassA
+			item.IntervalDays = policy.ClassAInterval
+		case item.ConsumptionValue > 0 && share < 95:
+			item.Class = models.ABCClassB
+			item.IntervalDays = policy.ClassBInterval
+		default:
+			item.Class = models.ABCClassC
+			item.IntervalDays = policy.ClassCInterval
+		}
+
+		item.NextCountDate = now
+		if item.LastCountedAt != nil {
+			item.NextCountDate = item.LastCountedAt.AddDate(0, 0, item.IntervalDays)
+		}
+		item.Due = !item.NextCountDate.After(now)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].NextCountDate.Before(items[j].NextCountDate)
+	})
+
+	return items, nil
+}
\ No newline at end of file
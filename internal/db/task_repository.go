@@ -0,0 +1,260 @@
+package db
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/yookibooki/erp/internal/models"
+)
+
+// taskOverdueSQL is true for open tasks past their due date
+const taskOverdueSQL = `(status IN ('open', 'in_progress') AND due_date < NOW())`
+
+const taskColumns = `id, tenant_id, title, description, customer_id, COALESCE(contact_id::text, ''),
+	COALESCE(opportunity_id::text, ''), assignee_id, due_date, priority, status, ` + taskOverdueSQL + `,
+	COALESCE(completed_by::text, ''), completed_at, COALESCE(interaction_id::text, ''), created_by,
+	created_at, updated_at`
+
+// scanTask scans a row selected with taskColumns
+func scanTask(row rowScanner) (*models.Task, error) {
+	task := &models.Task{}
+	err := row.Scan(
+		&task.ID,
+		&task.TenantID,
+		&task.Title,
+		&task.Description,
+		&task.CustomerID,
+		&task.ContactID,
+		&task.OpportunityID,
+		&task.AssigneeID,
+		&task.DueDate,
+		&task.Priority,
+		&task.Status,
+		&task.Overdue,
+		&task.CompletedBy,
+		&task.CompletedAt,
+		&task.InteractionID,
+		&task.CreatedBy,
+		&task.CreatedAt,
+		&task.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// TaskRepository implements the TaskService interface
+type TaskRepository struct {
+	db *DB
+}
+
+// NewTaskRepository creates a new task repository
+func NewTaskRepository(db *DB) *TaskRepository {
+	return &TaskRepository{db: db}
+}
+
+// Create creates a new task
+func (r *TaskRepository) Create(task *models.Task) error {
+	query := `
+		INSERT INTO tasks (tenant_id, title, description, customer_id, contact_id, opportunity_id, assignee_id,
+			due_date, priority, status, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id, ` + taskOverdueSQL + `, created_at, updated_at
+	`
+
+	return r.db.QueryRow(
+		query,
+		task.TenantID,
+		task.Title,
+		task.Description,
+		task.CustomerID,
+		nullString(task.ContactID),
+		nullString(task.OpportunityID),
+		task.AssigneeID,
+		task.DueDate,
+		task.Priority,
+		task.Status,
+		task.CreatedBy,
+	).Scan(
+		&task.ID,
+		&task.Overdue,
+		&task.CreatedAt,
+		&task.UpdatedAt,
+	)
+}
+
+// GetByID gets a task by ID
+func (r *TaskRepository) GetByID(tenantID, id string) (*models.Task, error) {
+	query := `
+		SELECT ` + taskColumns + `
+		FROM tasks
+		WHERE tenant_id = $1 AND id = $2
+	`
+
+	task, err := scanTask(r.db.QueryRow(query, tenantID, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return task, err
+}
+
+// List lists the tasks of a tenant matching a filter by due date, tasks without one last
+func (r *TaskRepository) List(tenantID string, filter models.TaskFilter) ([]*models.Task, error) {
+	conditions := []string{
+		"tenant_id = $1",
+		"($2 = '' OR assignee_id::text = $2)",
+		"($3 = '' OR customer_id::text = $3)",
+		"($4 = '' OR opportunity_id::text = $4)",
+		"($5 = '' OR status = $5)",
+	}
+	if filter.Overdue {
+		conditions = append(conditions, taskOverdueSQL)
+	}
+
+	query := `
+		SELECT ` + taskColumns + `
+		FROM tasks
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY due_date NULLS LAST, CASE priority WHEN 'high' THEN 0 WHEN 'normal' THEN 1 ELSE 2 END, created_at
+	`
+
+	rows, err := r.db.Query(query, tenantID, filter.AssigneeID, filter.CustomerID, filter.OpportunityID, filter.Status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tasks := []*models.Task{}
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+
+	return tasks, rows.Err()
+}
+
+// Update updates an open or in progress task
+func (r *TaskRepository) Update(task *models.Task) error {
+	query := `
+		UPDATE tasks
+		SET title = $1, description = $2, customer_id = $3, contact_id = $4, opportunity_id = $5, assignee_id = $6,
+			due_date = $7, priority = $8, status = $9, updated_at = $10
+		WHERE tenant_id = $11 AND id = $12 AND status IN ('open', 'in_progress')
+		RETURNING ` + taskOverdueSQL + `
+	`
+
+	now := time.Now()
+	err := r.db.QueryRow(
+		query,
+		task.Title,
+		task.Description,
+		task.CustomerID,
+		nullString(task.ContactID),
+		nullString(task.OpportunityID),
+		task.AssigneeID,
+		task.DueDate,
+		task.Priority,
+		task.Status,
+		now,
+		task.TenantID,
+		task.ID,
+	).Scan(&task.Overdue)
+	if err == sql.ErrNoRows {
+		return models.ErrTaskStatus
+	}
+	task.UpdatedAt = now
+	return err
+}
+
+// Delete deletes a task. An interaction logged by completing it is kept.
+func (r *TaskRepository) Delete(tenantID, id string) error {
+	query := `
+		DELETE FROM tasks
+		WHERE tenant_id = $1 AND id = $2
+	`
+
+	_, err := r.db.Exec(query, tenantID, id)
+	return err
+}
+
+// Complete completes an open or in progress task and logs it as an interaction with its
+// customer and contact, described by the task title followed by the notes
+func (r *TaskRepository) Complete(tenantID, id, userID, notes string) (err error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	task, err := scanTask(tx.QueryRow(`SELECT `+taskColumns+` FROM tasks WHERE tenant_id = $1 AND id = $2 FOR UPDATE`, tenantID, id))
+	if err != nil {
+		return err
+	}
+
+	if task.Status != models.TaskOpen && task.Status != models.TaskInProgress {
+		return models.ErrTaskStatus
+	}
+
+	description := task.Title
+	if notes != "" {
+		description += "\n\n" + notes
+	}
+
+	now := time.Now()
+	var interactionID string
+	err = tx.QueryRow(
+		`INSERT INTO interactions (tenant_id, customer_id, contact_id, interaction_type, description, interaction_date, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id`,
+		tenantID,
+		task.CustomerID,
+		nullString(task.ContactID),
+		models.InteractionTask,
+		description,
+		now,
+		userID,
+	).Scan(&interactionID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		`UPDATE tasks
+		SET status = $1, completed_by = $2, completed_at = $3, interaction_id = $4, updated_at = $3
+		WHERE tenant_id = $5 AND id = $6`,
+		models.TaskCompleted,
+		userID,
+		now,
+		interactionID,
+		tenantID,
+		id,
+	)
+	return err
+}
+
+// Cancel cancels an open or in progress task
+func (r *TaskRepository) Cancel(tenantID, id string) error {
+	query := `
+		UPDATE tasks
+		SET status = $1, updated_at = $2
+		WHERE tenant_id = $3 AND id = $4 AND status IN ('open', 'in_progress')
+	`
+
+	result, err := r.db.Exec(query, models.TaskCancelled, time.Now(), tenantID, id)
+	if err != nil {
+		return err
+	}
+	return requireRowAffected(result, models.ErrTaskStatus)
+}
\ No newline at end of file
@@ -20,12 +20,12 @@ func NewTenantRepository(db *DB) *TenantRepository {
 // Create creates a new tenant
 func (r *TenantRepository) Create(tenant *models.Tenant) error {
 	query := `
-		INSERT INTO tenants (name, subdomain)
-		VALUES ($1, $2)
+		INSERT INTO tenants (name, subdomain, costing_method, allow_negative_stock)
+		VALUES ($1, $2, $3, $4)
 		RETURNING id, created_at, updated_at
 	`
 
-	return r.db.QueryRow(query, tenant.Name, tenant.Subdomain).Scan(
+	return r.db.QueryRow(query, tenant.Name, tenant.Subdomain, tenant.CostingMethod, tenant.AllowNegativeStock).Scan(
 		&tenant.ID,
 		&tenant.CreatedAt,
 		&tenant.UpdatedAt,
@@ -35,7 +35,7 @@ func (r *TenantRepository) Create(tenant *models.Tenant) error {
 // GetByID gets a tenant by ID
 func (r *TenantRepository) GetByID(id string) (*models.Tenant, error) {
 	query := `
-		SELECT id, name, subdomain, created_at, updated_at
+		SELECT id, name, subdomain, costing_method, allow_negative_stock, created_at, updated_at
 		FROM tenants
 		WHERE id = $1
 	`
@@ -45,6 +45,8 @@ func (r *TenantRepository) GetByID(id string) (*models.Tenant, error) {
 		&tenant.ID,
 		&tenant.Name,
 		&tenant.Subdomain,
+		&tenant.CostingMethod,
+		&tenant.AllowNegativeStock,
 		&tenant.CreatedAt,
 		&tenant.UpdatedAt,
 	)
@@ -59,7 +61,7 @@ func (r *TenantRepository) GetByID(id string) (*models.Tenant, error) {
 // GetBySubdomain gets a tenant by subdomain
 func (r *TenantRepository) GetBySubdomain(subdomain string) (*models.Tenant, error) {
 	query := `
-		SELECT id, name, subdomain, created_at, updated_at
+		SELECT id, name, subdomain, costing_method, allow_negative_stock, created_at, updated_at
 		FROM tenants
 		WHERE subdomain = $1
 	`
@@ -69,6 +71,8 @@ func (r *TenantRepository) GetBySubdomain(subdomain string) (*models.Tenant, err
 		&tenant.ID,
 		&tenant.Name,
 		&tenant.Subdomain,
+		&tenant.CostingMethod,
+		&tenant.AllowNegativeStock,
 		&tenant.CreatedAt,
 		&tenant.UpdatedAt,
 	)
@@ -83,7 +87,7 @@ func (r *TenantRepository) GetBySubdomain(subdomain string) (*models.Tenant, err
 // List lists all tenants
 func (r *TenantRepository) List() ([]*models.Tenant, error) {
 	query := `
-		SELECT id, name, subdomain, created_at, updated_at
+		SELECT id, name, subdomain, costing_method, allow_negative_stock, created_at, updated_at
 		FROM tenants
 		ORDER BY name
 	`
@@ -101,6 +105,8 @@ func (r *TenantRepository) List() ([]*models.Tenant, error) {
 			&tenant.ID,
 			&tenant.Name,
 			&tenant.Subdomain,
+			&tenant.CostingMethod,
+			&tenant.AllowNegativeStock,
 			&tenant.CreatedAt,
 			&tenant.UpdatedAt,
 		)
@@ -117,12 +123,12 @@ func (r *TenantRepository) List() ([]*models.Tenant, error) {
 func (r *TenantRepository) Update(tenant *models.Tenant) error {
 	query := `
 		UPDATE tenants
-		SET name = $1, subdomain = $2, updated_at = $3
-		WHERE id = $4
+		SET name = $1, subdomain = $2, costing_method = $3, allow_negative_stock = $4, updated_at = $5
+		WHERE id = $6
 	`
 
 	now := time.Now()
-	_, err := r.db.Exec(query, tenant.Name, tenant.Subdomain, now, tenant.ID)
+	_, err := r.db.Exec(query, tenant.Name, tenant.Subdomain, tenant.CostingMethod, tenant.AllowNegativeStock, now, tenant.ID)
 	tenant.UpdatedAt = now
 	return err
 }
@@ -136,4 +142,15 @@ func (r *TenantRepository) Delete(id string) error {
 
 	_, err := r.db.Exec(query, id)
 	return err
+}
+
+// HasStock reports whether any product of a tenant has stock on hand
+func (r *TenantRepository) HasStock(id string) (bool, error) {
+	query := `
+		SELECT EXISTS(SELECT 1 FROM products WHERE tenant_id = $1 AND stock_quantity <> 0)
+	`
+
+	var hasStock bool
+	err := r.db.QueryRow(query, id).Scan(&hasStock)
+	return hasStock, err
 }
\ No newline at end of file
@@ -0,0 +1,328 @@
+package db
+
+import (
+	"database/sql"
+	"math"
+	"time"
+
+	"github.com/yookibooki/erp/internal/models"
+)
+
+// roundQuantity rounds a quantity to the four decimal places quantities are stored with
+func roundQuantity(quantity float64) float64 {
+	return math.Round(quantity*10000) / 10000
+}
+
+// unitFactor returns the number of base units in one unit of a product within tx.
+// The base unit converts with a factor of 1; units without a conversion are rejected.
+func unitFactor(tx *sql.Tx, tenantID, productID, unitID string) (float64, error) {
+	var factor sql.NullFloat64
+	err := tx.QueryRow(
+		`SELECT CASE WHEN p.base_unit_id = $3 THEN 1 ELSE pu.factor END
+		FROM products p
+		LEFT JOIN product_units pu ON pu.product_id = p.id AND pu.unit_id = $3
+		WHERE p.tenant_id = $1 AND p.id = $2`,
+		tenantID,
+		productID,
+		unitID,
+	).Scan(&factor)
+	if err != nil {
+		return 0, err
+	}
+
+	if !factor.Valid {
+		return 0, models.ErrUnitConversion
+	}
+	return factor.Float64, nil
+}
+
+// unitFactorSQL is the SQL equivalent of unitFactor for the unit of a line aliased as alias.
+// Lines without a unit are in the base unit.
+func unitFactorSQL(alias string) string {
+	return `COALESCE((SELECT pu.factor FROM product_units pu
+		WHERE pu.product_id = ` + alias + `.product_id AND pu.unit_id = ` + alias + `.unit_id), 1)`
+}
+
+const unitOfMeasureColumns = `id, tenant_id, code, name, description, created_at, updated_at`
+
+// scanUnitOfMeasure scans a row selected with unitOfMeasureColumns
+func scanUnitOfMeasure(row rowScanner) (*models.UnitOfMeasure, error) {
+	unit := &models.UnitOfMeasure{}
+	err := row.Scan(
+		&unit.ID,
+		&unit.TenantID,
+		&unit.Code,
+		&unit.Name,
+		&unit.Description,
+		&unit.CreatedAt,
+		&unit.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return unit, nil
+}
+
+// UnitOfMeasureRepository implements the UnitOfMeasureService interface
+type UnitOfMeasureRepository struct {
+	db *DB
+}
+
+// NewUnitOfMeasureRepository creates a new unit of measure repository
+func NewUnitOfMeasureRepository(db *DB) *UnitOfMeasureRepository {
+	return &UnitOfMeasureRepository{db: db}
+}
+
+// Create creates a new unit of measure
+func (r *UnitOfMeasureRepository) Create(unit *models.UnitOfMeasure) error {
+	query := `
+		INSERT INTO units_of_measure (tenant_id, code, name, description)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at, updated_at
+	`
+
+	return r.db.QueryRow(
+		query,
+		unit.TenantID,
+		unit.Code,
+		unit.Name,
+		unit.Description,
+	).Scan(
+		&unit.ID,
+		&unit.CreatedAt,
+		&unit.UpdatedAt,
+	)
+}
+
+// GetByID gets a unit of measure by ID
+func (r *UnitOfMeasureRepository) GetByID(tenantID, id string) (*models.UnitOfMeasure, error) {
+	query := `
+		SELECT ` + unitOfMeasureColumns + `
+		FROM units_of_measure
+		WHERE tenant_id = $1 AND id = $2
+	`
+
+	unit, err := scanUnitOfMeasure(r.db.QueryRow(query, tenantID, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return unit, err
+}
+
+// GetByCode gets a unit of measure by code
+func (r *UnitOfMeasureRepository) GetByCode(tenantID, code string) (*models.UnitOfMeasure, error) {
+	query := `
+		SELECT ` + unitOfMeasureColumns + `
+		FROM units_of_measure
+		WHERE tenant_id = $1 AND code = $2
+	`
+
+	unit, err := scanUnitOfMeasure(r.db.QueryRow(query, tenantID, code))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return unit, err
+}
+
+// List lists all units of measure for a tenant
+func (r *UnitOfMeasureRepository) List(tenantID string) ([]*models.UnitOfMeasure, error) {
+	query := `
+		SELECT ` + unitOfMeasureColumns + `
+		FROM units_of_measure
+		WHERE tenant_id = $1
+		ORDER BY code
+	`
+
+	rows, err := r.db.Query(query, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	units := []*models.UnitOfMeasure{}
+	for rows.Next() {
+		unit, err := scanUnitOfMeasure(rows)
+		if err != nil {
+			return nil, err
+		}
+		units = append(units, unit)
+	}
+
+	return units, nil
+}
+
+// Update updates a unit of measure
+func (r *UnitOfMeasureRepository) Update(unit *models.UnitOfMeasure) error {
+	query := `
+		UPDATE units_of_measure
+		SET code = $1, name = $2, description = $3, updated_at = $4
+		WHERE tenant_id = $5 AND id = $6
+	`
+
+	now := time.Now()
+	_, err := r.db.Exec(
+		query,
+		unit.Code,
+		unit.Name,
+		unit.Description,
+		now,
+		unit.TenantID,
+		unit.ID,
+	)
+	unit.UpdatedAt = now
+	return err
+}
+
+// Delete deletes a unit of measure
+func (r *UnitOfMeasureRepository) Delete(tenantID, id string) error {
+	query := `
+		DELETE FROM units_of_measure
+		WHERE tenant_id = $1 AND id = $2
+	`
+
+	_, err := r.db.Exec(query, tenantID, id)
+	return err
+}
+
+const productUnitColumns = `id, tenant_id, product_id, unit_id, factor, created_at, updated_at`
+
+// scanProductUnit scans a row selected with productUnitColumns
+func scanProductUnit(row rowScanner) (*models.ProductUnit, error) {
+	productUnit := &models.ProductUnit{}
+	err := row.Scan(
+		&productUnit.ID,
+		&productUnit.TenantID,
+		&productUnit.ProductID,
+		&productUnit.UnitID,
+		&productUnit.Factor,
+		&productUnit.CreatedAt,
+		&productUnit.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return productUnit, nil
+}
+
+// ProductUnitRepository implements the ProductUnitService interface
+type ProductUnitRepository struct {
+	db *DB
+}
+
+// NewProductUnitRepository creates a new product unit repository
+func NewProductUnitRepository(db *DB) *ProductUnitRepository {
+	return &ProductUnitRepository{db: db}
+}
+
+// Create creates a new product unit conversion
+func (r *ProductUnitRepository) Create(productUnit *models.ProductUnit) error {
+	query := `
+		INSERT INTO product_units (tenant_id, product_id, unit_id, factor)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at, updated_at
+	`
+
+	return r.db.QueryRow(
+		query,
+		productUnit.TenantID,
+		productUnit.ProductID,
+		productUnit.UnitID,
+		productUnit.Factor,
+	).Scan(
+		&productUnit.ID,
+		&productUnit.CreatedAt,
+		&productUnit.UpdatedAt,
+	)
+}
+
+// GetByID gets a product unit conversion by ID
+func (r *ProductUnitRepository) GetByID(tenantID, id string) (*models.ProductUnit, error) {
+	query := `
+		SELECT ` + productUnitColumns + `
+		FROM product_units
+		WHERE tenant_id = $1 AND id = $2
+	`
+
+	productUnit, err := scanProductUnit(r.db.QueryRow(query, tenantID, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return productUnit, err
+}
+
+// GetByProductAndUnit gets the conversion of a unit of a product
+func (r *ProductUnitRepository) GetByProductAndUnit(tenantID, productID, unitID string) (*models.ProductUnit, error) {
+	query := `
+		SELECT ` + productUnitColumns + `
+		FROM product_units
+		WHERE tenant_id = $1 AND product_id = $2 AND unit_id = $3
+	`
+
+	productUnit, err := scanProductUnit(r.db.QueryRow(query, tenantID, productID, unitID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return productUnit, err
+}
+
+// ListByProduct lists the unit conversions of a product
+func (r *ProductUnitRepository) ListByProduct(tenantID, productID string) ([]*models.ProductUnit, error) {
+	query := `
+		SELECT ` + productUnitColumns + `
+		FROM product_units
+		WHERE tenant_id = $1 AND product_id = $2
+		ORDER BY factor
+	`
+
+	rows, err := r.db.Query(query, tenantID, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	productUnits := []*models.ProductUnit{}
+	for rows.Next() {
+		productUnit, err := scanProductUnit(rows)
+		if err != nil {
+			return nil, err
+		}
+		productUnits = append(productUnits, productUnit)
+	}
+
+	return productUnits, nil
+}
+
+// Update updates the factor of a product unit conversion
+func (r *ProductUnitRepository) Update(productUnit *models.ProductUnit) error {
+	query := `
+		UPDATE product_units
+		SET factor = $1, updated_at = $2
+		WHERE tenant_id = $3 AND id = $4
+	`
+
+	now := time.Now()
+	_, err := r.db.Exec(
+		query,
+		productUnit.Factor,
+		now,
+		productUnit.TenantID,
+		productUnit.ID,
+	)
+	productUnit.UpdatedAt = now
+	return err
+}
+
+// Delete deletes a product unit conversion
+func (r *ProductUnitRepository) Delete(tenantID, id string) error {
+	query := `
+		DELETE FROM product_units
+		WHERE tenant_id = $1 AND id = $2
+	`
+
+	_, err := r.db.Exec(query, tenantID, id)
+	return err
+}
\ No newline at end of file
@@ -0,0 +1,782 @@
+package db
+
+import (
+	"database/sql"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yookibooki/erp/internal/models"
+)
+
+const productTemplateColumns = `id, tenant_id, code, name, description, unit_price,
+	COALESCE(base_unit_id::text, ''), tracking_mode, created_at, updated_at`
+
+// scanProductTemplate scans a row selected with productTemplateColumns
+func scanProductTemplate(row rowScanner) (*models.ProductTemplate, error) {
+	template := &models.ProductTemplate{}
+	err := row.Scan(
+		&template.ID,
+		&template.TenantID,
+		&template.Code,
+		&template.Name,
+		&template.Description,
+		&template.UnitPrice,
+		&template.BaseUnitID,
+		&template.TrackingMode,
+		&template.CreatedAt,
+		&template.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+// loadProductAttributes loads the attributes of a template and their values in position order
+func loadProductAttributes(q queryer, tenantID, templateID string) ([]models.ProductAttribute, error) {
+	query := `
+		SELECT a.id, a.tenant_id, a.template_id, a.name, a.position, v.value
+		FROM product_attributes a
+		LEFT JOIN product_attribute_values v ON v.attribute_id = a.id
+		WHERE a.tenant_id = $1 AND a.template_id = $2
+		ORDER BY a.position, v.position
+	`
+
+	rows, err := q.Query(query, tenantID, templateID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	attributes := []models.ProductAttribute{}
+	for rows.Next() {
+		attribute := models.ProductAttribute{}
+		var value sql.NullString
+		err := rows.Scan(
+			&attribute.ID,
+			&attribute.TenantID,
+			&attribute.TemplateID,
+			&attribute.Name,
+			&attribute.Position,
+			&value,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(attributes) == 0 || attributes[len(attributes)-1].ID != attribute.ID {
+			attribute.Values = []string{}
+			attributes = append(attributes, attribute)
+		}
+		if value.Valid {
+			last := &attributes[len(attributes)-1]
+			last.Values = append(last.Values, value.String)
+		}
+	}
+
+	return attributes, nil
+}
+
+// listVariants lists the variants of a template whose attribute values match filter
+func listVariants(q queryer, tenantID, templateID string, filter map[string]string) ([]*models.Product, error) {
+	query := `
+		SELECT ` + productColumns + `
+		FROM products
+		WHERE tenant_id = $1 AND template_id = $2
+		ORDER BY code
+	`
+
+	rows, err := q.Query(query, tenantID, templateID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	variants := []*models.Product{}
+	for rows.Next() {
+		variant, err := scanProduct(rows)
+		if err != nil {
+			return nil, err
+		}
+		if matchesAttributes(variant, filter) {
+			variants = append(variants, variant)
+		}
+	}
+
+	return variants, nil
+}
+
+// matchesAttributes reports whether a variant has every attribute value in filter
+func matchesAttributes(variant *models.Product, filter map[string]string) bool {
+	for name, value := range filter {
+		if !strings.EqualFold(variant.Attributes[name], value) {
+			return false
+		}
+	}
+	return true
+}
+
+// variantKey identifies a combination of attribute values regardless of order
+func variantKey(valueIDs []string) string {
+	sorted := append([]string{}, valueIDs...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ",")
+}
+
+// templateValueIDs maps the attribute values of a template within tx by attribute name and value
+func templateValueIDs(tx *sql.Tx, tenantID, templateID string) (map[string]map[string]string, error) {
+	query := `
+		SELECT a.name, v.value, v.id
+		FROM product_attributes a
+		JOIN product_attribute_values v ON v.attribute_id = a.id
+		WHERE a.tenant_id = $1 AND a.template_id = $2
+	`
+
+	rows, err := tx.Query(query, tenantID, templateID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	valueIDs := map[string]map[string]string{}
+	for rows.Next() {
+		var name, value, id string
+		if err := rows.Scan(&name, &value, &id); err != nil {
+			return nil, err
+		}
+		if valueIDs[name] == nil {
+			valueIDs[name] = map[string]string{}
+		}
+		valueIDs[name][value] = id
+	}
+
+	return valueIDs, nil
+}
+
+// existingVariantKeys returns the keys of the attribute value combinations a template already has variants for
+func existingVariantKeys(tx *sql.Tx, tenantID, templateID string) (map[string]bool, error) {
+	query := `
+		SELECT pvv.product_id, pvv.attribute_value_id
+		FROM product_variant_values pvv
+		JOIN products p ON p.id = pvv.product_id
+		WHERE p.tenant_id = $1 AND p.template_id = $2
+	`
+
+	rows, err := tx.Query(query, tenantID, templateID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	combinations := map[string][]string{}
+	for rows.Next() {
+		var productID, valueID string
+		if err := rows.Scan(&productID, &valueID); err != nil {
+			return nil, err
+		}
+		combinations[productID] = append(combinations[productID], valueID)
+	}
+
+	keys := map[string]bool{}
+	for _, valueIDs := range combinations {
+		keys[variantKey(valueIDs)] = true
+	}
+	return keys, nil
+}
+
+// ProductTemplateRepository implements the ProductTemplateService interface
+type ProductTemplateRepository struct {
+	db *DB
+}
+
+// NewProductTemplateRepository creates a new product template repository
+func NewProductTemplateRepository(db *DB) *ProductTemplateRepository {
+	return &ProductTemplateRepository{db: db}
+}
+
+// Create creates a new product template with its attributes
+func (r *ProductTemplateRepository) Create(template *models.ProductTemplate) (err error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	query := `
+		INSERT INTO product_templates (tenant_id, code, name, description, unit_price, base_unit_id, tracking_mode)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at, updated_at
+	`
+
+	err = tx.QueryRow(
+		query,
+		template.TenantID,
+		template.Code,
+		template.Name,
+		template.Description,
+		template.UnitPrice,
+		nullString(template.BaseUnitID),
+		template.TrackingMode,
+	).Scan(
+		&template.ID,
+		&template.CreatedAt,
+		&template.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	for i := range template.Attributes {
+		if err = insertProductAttribute(tx, template, i); err != nil {
+			return err
+		}
+	}
+
+	template.Variants = []*models.Product{}
+	return nil
+}
+
+// insertProductAttribute inserts the attribute of a template at position i with its values within tx
+func insertProductAttribute(tx *sql.Tx, template *models.ProductTemplate, i int) error {
+	attribute := &template.Attributes[i]
+	attribute.TenantID = template.TenantID
+	attribute.TemplateID = template.ID
+	attribute.Position = i
+
+	err := tx.QueryRow(
+		`INSERT INTO product_attributes (tenant_id, template_id, name, position)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id`,
+		attribute.TenantID,
+		attribute.TemplateID,
+		attribute.Name,
+		attribute.Position,
+	).Scan(&attribute.ID)
+	if err != nil {
+		return err
+	}
+
+	for j, value := range attribute.Values {
+		_, err := tx.Exec(
+			`INSERT INTO product_attribute_values (tenant_id, attribute_id, value, position)
+			VALUES ($1, $2, $3, $4)`,
+			attribute.TenantID,
+			attribute.ID,
+			value,
+			j,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetByID gets a product template by ID with its attributes and variants
+func (r *ProductTemplateRepository) GetByID(tenantID, id string) (*models.ProductTemplate, error) {
+	query := `
+		SELECT ` + productTemplateColumns + `
+		FROM product_templates
+		WHERE tenant_id = $1 AND id = $2
+	`
+
+	template, err := scanProductTemplate(r.db.QueryRow(query, tenantID, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return template, r.loadDetails(template, nil)
+}
+
+// GetByCode gets a product template by code with its attributes and variants
+func (r *ProductTemplateRepository) GetByCode(tenantID, code string) (*models.ProductTemplate, error) {
+	query := `
+		SELECT ` + productTemplateColumns + `
+		FROM product_templates
+		WHERE tenant_id = $1 AND code = $2
+	`
+
+	template, err := scanProductTemplate(r.db.QueryRow(query, tenantID, code))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return template, r.loadDetails(template, nil)
+}
+
+// List lists the product templates of a tenant with their attributes and the variants matching filter
+func (r *ProductTemplateRepository) List(tenantID string, filter map[string]string) ([]*models.ProductTemplate, error) {
+	query := `
+		SELECT ` + productTemplateColumns + `
+		FROM product_templates
+		WHERE tenant_id = $1
+		ORDER BY code
+	`
+
+	rows, err := r.db.Query(query, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	all := []*models.ProductTemplate{}
+	for rows.Next() {
+		template, err := scanProductTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, template)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	templates := []*models.ProductTemplate{}
+	for _, template := range all {
+		if err := r.loadDetails(template, filter); err != nil {
+			return nil, err
+		}
+		if len(filter) > 0 && len(template.Variants) == 0 {
+			continue
+		}
+		templates = append(templates, template)
+	}
+
+	return templates, nil
+}
+
+// loadDetails loads the attributes of a template and its variants matching filter
+func (r *ProductTemplateRepository) loadDetails(template *models.ProductTemplate, filter map[string]string) error {
+	attributes, err := loadProductAttributes(r.db, template.TenantID, template.ID)
+	if err != nil {
+		return err
+	}
+	template.Attributes = attributes
+
+	variants, err := listVariants(r.db, template.TenantID, template.ID, filter)
+	if err != nil {
+		return err
+	}
+	template.Variants = variants
+
+	template.StockQuantity = 0
+	for _, variant := range variants {
+		template.StockQuantity += variant.StockQuantity
+	}
+	return nil
+}
+
+// Update updates a product template and its attributes. Attributes and values left out
+// are removed unless a variant uses them; attributes can only be added before there are variants.
+func (r *ProductTemplateRepository) Update(template *models.ProductTemplate) (err error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	query := `
+		UPDATE product_templates
+		SET code = $1, name = $2, description = $3, unit_price = $4, base_unit_id = $5, tracking_mode = $6, updated_at = $7
+		WHERE tenant_id = $8 AND id = $9
+	`
+
+	now := time.Now()
+	_, err = tx.Exec(
+		query,
+		template.Code,
+		template.Name,
+		template.Description,
+		template.UnitPrice,
+		nullString(template.BaseUnitID),
+		template.TrackingMode,
+		now,
+		template.TenantID,
+		template.ID,
+	)
+	if err != nil {
+		return err
+	}
+	template.UpdatedAt = now
+
+	if err = syncProductAttributes(tx, template); err != nil {
+		return err
+	}
+
+	template.Attributes, err = loadProductAttributes(tx, template.TenantID, template.ID)
+	return err
+}
+
+// syncProductAttributes brings the stored attributes of a template in line with template.Attributes within tx
+func syncProductAttributes(tx *sql.Tx, template *models.ProductTemplate) error {
+	existing, err := loadProductAttributes(tx, template.TenantID, template.ID)
+	if err != nil {
+		return err
+	}
+
+	var hasVariants bool
+	err = tx.QueryRow(
+		`SELECT EXISTS (SELECT 1 FROM products WHERE tenant_id = $1 AND template_id = $2)`,
+		template.TenantID,
+		template.ID,
+	).Scan(&hasVariants)
+	if err != nil {
+		return err
+	}
+
+	requested := map[string]*models.ProductAttribute{}
+	for i := range template.Attributes {
+		requested[template.Attributes[i].Name] = &template.Attributes[i]
+	}
+
+	// Remove attributes and values left out
+	current := map[string]string{}
+	for _, attribute := range existing {
+		wanted := requested[attribute.Name]
+		keep := map[string]bool{}
+		if wanted != nil {
+			current[attribute.Name] = attribute.ID
+			for _, value := range wanted.Values {
+				keep[value] = true
+			}
+		}
+
+		for _, value := range attribute.Values {
+			if keep[value] {
+				continue
+			}
+			if err := deleteAttributeValue(tx, attribute.ID, value); err != nil {
+				return err
+			}
+		}
+
+		if wanted == nil {
+			if _, err := tx.Exec(`DELETE FROM product_attributes WHERE id = $1`, attribute.ID); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Add new attributes and values and reorder the rest
+	for i := range template.Attributes {
+		attribute := &template.Attributes[i]
+		attributeID, ok := current[attribute.Name]
+		if !ok {
+			if hasVariants {
+				return models.ErrTemplateAttributes
+			}
+			if err := insertProductAttribute(tx, template, i); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if _, err := tx.Exec(`UPDATE product_attributes SET position = $1 WHERE id = $2`, i, attributeID); err != nil {
+			return err
+		}
+
+		for j, value := range attribute.Values {
+			_, err := tx.Exec(
+				`INSERT INTO product_attribute_values (tenant_id, attribute_id, value, position)
+				VALUES ($1, $2, $3, $4)
+				ON CONFLICT (attribute_id, value) DO UPDATE SET position = EXCLUDED.position`,
+				template.TenantID,
+				attributeID,
+				value,
+				j,
+			)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// deleteAttributeValue deletes a value of an attribute within tx unless a variant uses it
+func deleteAttributeValue(tx *sql.Tx, attributeID, value string) error {
+	var inUse bool
+	err := tx.QueryRow(
+		`SELECT EXISTS (
+			SELECT 1 FROM product_variant_values pvv
+			JOIN product_attribute_values v ON v.id = pvv.attribute_value_id
+			WHERE v.attribute_id = $1 AND v.value = $2
+		)`,
+		attributeID,
+		value,
+	).Scan(&inUse)
+	if err != nil {
+		return err
+	}
+
+	if inUse {
+		return models.ErrAttributeValueInUse
+	}
+
+	_, err = tx.Exec(`DELETE FROM product_attribute_values WHERE attribute_id = $1 AND value = $2`, attributeID, value)
+	return err
+}
+
+// Delete deletes a product template that has no variants
+func (r *ProductTemplateRepository) Delete(tenantID, id string) error {
+	query := `
+		DELETE FROM product_templates
+		WHERE tenant_id = $1 AND id = $2
+			AND NOT EXISTS (SELECT 1 FROM products WHERE tenant_id = $1 AND template_id = $2)
+	`
+
+	result, err := r.db.Exec(query, tenantID, id)
+	if err != nil {
+		return err
+	}
+	return requireRowAffected(result, models.ErrTemplateHasVariants)
+}
+
+// lockProductTemplate locks a template within tx so its variants are created one at a time
+func lockProductTemplate(tx *sql.Tx, tenantID, id string) (*models.ProductTemplate, error) {
+	query := `
+		SELECT ` + productTemplateColumns + `
+		FROM product_templates
+		WHERE tenant_id = $1 AND id = $2
+		FOR UPDATE
+	`
+
+	return scanProductTemplate(tx.QueryRow(query, tenantID, id))
+}
+
+// insertVariant inserts a variant of a template with its attribute values within tx.
+// Fields left empty default to the template.
+func insertVariant(tx *sql.Tx, template *models.ProductTemplate, variant *models.Product, valueIDs []string) error {
+	var exists bool
+	err := tx.QueryRow(
+		`SELECT EXISTS (SELECT 1 FROM products WHERE tenant_id = $1 AND code = $2)`,
+		template.TenantID,
+		variant.Code,
+	).Scan(&exists)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return models.ErrVariantCode
+	}
+
+	variant.TenantID = template.TenantID
+	variant.TemplateID = template.ID
+	if variant.Description == "" {
+		variant.Description = template.Description
+	}
+	if variant.UnitPrice == 0 {
+		variant.UnitPrice = template.UnitPrice
+	}
+	if variant.BaseUnitID == "" {
+		variant.BaseUnitID = template.BaseUnitID
+	}
+	if variant.TrackingMode == "" {
+		variant.TrackingMode = template.TrackingMode
+	}
+
+	if err := insertProduct(tx, variant); err != nil {
+		return err
+	}
+
+	for _, valueID := range valueIDs {
+		_, err := tx.Exec(
+			`INSERT INTO product_variant_values (tenant_id, product_id, attribute_value_id) VALUES ($1, $2, $3)`,
+			variant.TenantID,
+			variant.ID,
+			valueID,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	variant.AvailableQuantity = variant.StockQuantity
+	return nil
+}
+
+// CreateVariant creates a variant of a template from the attribute values of product.
+// The code and name default to the template's followed by the attribute values.
+func (r *ProductTemplateRepository) CreateVariant(product *models.Product) (err error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	template, err := lockProductTemplate(tx, product.TenantID, product.TemplateID)
+	if err != nil {
+		return err
+	}
+
+	attributes, err := loadProductAttributes(tx, template.TenantID, template.ID)
+	if err != nil {
+		return err
+	}
+
+	valueIDs, err := templateValueIDs(tx, template.TenantID, template.ID)
+	if err != nil {
+		return err
+	}
+
+	if len(attributes) == 0 || len(product.Attributes) != len(attributes) {
+		return models.ErrVariantAttributes
+	}
+
+	ids := []string{}
+	values := []string{}
+	for _, attribute := range attributes {
+		value := product.Attributes[attribute.Name]
+		id, ok := valueIDs[attribute.Name][value]
+		if !ok {
+			return models.ErrVariantAttributes
+		}
+		ids = append(ids, id)
+		values = append(values, value)
+	}
+
+	existing, err := existingVariantKeys(tx, template.TenantID, template.ID)
+	if err != nil {
+		return err
+	}
+
+	if existing[variantKey(ids)] {
+		return models.ErrDuplicateVariant
+	}
+
+	if product.Code == "" {
+		product.Code = variantCode(template, values)
+	}
+	if product.Name == "" {
+		product.Name = variantName(template, values)
+	}
+
+	return insertVariant(tx, template, product, ids)
+}
+
+// GenerateVariants creates a variant of a template for every combination of attribute
+// values it does not have a variant for yet and returns the variants created
+func (r *ProductTemplateRepository) GenerateVariants(tenantID, id string) (variants []*models.Product, err error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	template, err := lockProductTemplate(tx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	attributes, err := loadProductAttributes(tx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(attributes) == 0 {
+		return nil, models.ErrVariantAttributes
+	}
+
+	valueIDs, err := templateValueIDs(tx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := existingVariantKeys(tx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Build every combination of values, varying the last attribute fastest
+	combinations := [][]string{{}}
+	for _, attribute := range attributes {
+		next := [][]string{}
+		for _, combination := range combinations {
+			for _, value := range attribute.Values {
+				next = append(next, append(append([]string{}, combination...), value))
+			}
+		}
+		combinations = next
+	}
+
+	variants = []*models.Product{}
+	for _, values := range combinations {
+		ids := []string{}
+		for i, attribute := range attributes {
+			ids = append(ids, valueIDs[attribute.Name][values[i]])
+		}
+
+		if existing[variantKey(ids)] {
+			continue
+		}
+
+		variant := &models.Product{
+			Code:       variantCode(template, values),
+			Name:       variantName(template, values),
+			Attributes: map[string]string{},
+		}
+		for i, attribute := range attributes {
+			variant.Attributes[attribute.Name] = values[i]
+		}
+
+		if err = insertVariant(tx, template, variant, ids); err != nil {
+			return nil, err
+		}
+		variants = append(variants, variant)
+	}
+
+	return variants, nil
+}
+
+// ListVariants lists the variants of a template whose attribute values match filter
+func (r *ProductTemplateRepository) ListVariants(tenantID, id string, filter map[string]string) ([]*models.Product, error) {
+	return listVariants(r.db, tenantID, id, filter)
+}
+
+// variantCode is the default code of a variant, e.g. TSHIRT-M-RED
+func variantCode(template *models.ProductTemplate, values []string) string {
+	parts := []string{template.Code}
+	for _, value := range values {
+		parts = append(parts, strings.ToUpper(strings.Join(strings.Fields(value), "-")))
+	}
+	return strings.Join(parts, "-")
+}
+
+// variantName is the default name of a variant, e.g. T-Shirt (M, Red)
+func variantName(template *models.ProductTemplate, values []string) string {
+	return template.Name + " (" + strings.Join(values, ", ") + ")"
+}
\ No newline at end of file
@@ -42,6 +42,23 @@ type JournalEntryLine struct {
 	UpdatedAt      time.Time `json:"updated_at"`
 }
 
+// PostingRule maps inventory transactions of a type to the accounts their journal entries post to.
+// Stock increases debit the inventory account and credit the counter account, decreases the reverse.
+// A rule can be limited to products of a category and its subcategories and, for adjustments, to a
+// reason code; a transaction posts with the most specific rule that matches it.
+type PostingRule struct {
+	ID                 string    `json:"id"`
+	TenantID           string    `json:"tenant_id"`
+	TransactionType    string    `json:"transaction_type"`
+	CategoryID         string    `json:"category_id,omitempty"`
+	ReasonCode         string    `json:"reason_code,omitempty"`
+	InventoryAccountID string    `json:"inventory_account_id"`
+	CounterAccountID   string    `json:"counter_account_id"`
+	Description        string    `json:"description"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
 // AccountService provides methods to interact with accounts
 type AccountService interface {
 	Create(account *Account) error
@@ -59,4 +76,14 @@ type JournalEntryService interface {
 	List(tenantID string) ([]*JournalEntry, error)
 	Update(entry *JournalEntry) error
 	Delete(tenantID, id string) error
+}
+
+// PostingRuleService provides methods to interact with posting rules
+type PostingRuleService interface {
+	Create(rule *PostingRule) error
+	GetByID(tenantID, id string) (*PostingRule, error)
+	GetByScope(tenantID, transactionType, categoryID, reasonCode string) (*PostingRule, error)
+	List(tenantID string) ([]*PostingRule, error)
+	Update(rule *PostingRule) error
+	Delete(tenantID, id string) error
 }
\ No newline at end of file
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"time"
+)
+
+// Barcode types. EAN-13, EAN-8, UPC-A and GTIN-14 barcodes are GTINs and must carry a
+// valid check digit; internal barcodes are any printable ASCII text.
+const (
+	BarcodeEAN13    = "ean13"
+	BarcodeEAN8     = "ean8"
+	BarcodeUPCA     = "upca"
+	BarcodeGTIN14   = "gtin14"
+	BarcodeInternal = "internal"
+)
+
+// ProductBarcode is a barcode identifying a product. A barcode with a unit identifies a
+// pack of the product in that unit, such as the GTIN printed on a case.
+type ProductBarcode struct {
+	ID        string    `json:"id"`
+	TenantID  string    `json:"tenant_id"`
+	ProductID string    `json:"product_id"`
+	Code      string    `json:"code"`
+	Type      string    `json:"type"`
+	UnitID    string    `json:"unit_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// GS1Data is the data of a GS1-128 or GS1 DataMatrix barcode. Elements holds every
+// application identifier read with its raw value; Quantity is the count of items
+// (AI 30 or 37) and NetWeight the net weight in kilograms (AI 310n).
+type GS1Data struct {
+	SSCC           string            `json:"sscc,omitempty"`
+	GTIN           string            `json:"gtin,omitempty"`
+	LotNumber      string            `json:"lot_number,omitempty"`
+	SerialNumber   string            `json:"serial_number,omitempty"`
+	ProductionDate *time.Time        `json:"production_date,omitempty"`
+	BestBefore     *time.Time        `json:"best_before,omitempty"`
+	ExpiryDate     *time.Time        `json:"expiry_date,omitempty"`
+	Quantity       float64           `json:"quantity,omitempty"`
+	NetWeight      float64           `json:"net_weight,omitempty"`
+	Elements       map[string]string `json:"elements"`
+}
+
+// ScanRequest is scanned barcode data, either a product barcode or a GS1 element string,
+// with the transaction to draft from it
+type ScanRequest struct {
+	Data            string `json:"data"`
+	TransactionType string `json:"transaction_type"`
+	LocationID      string `json:"location_id"`
+}
+
+// ScanResult is the product identified by a barcode. For a scan it includes a draft
+// inventory transaction, which is created by posting it to the transactions endpoint
+// once confirmed.
+type ScanResult struct {
+	Barcode     *ProductBarcode       `json:"barcode"`
+	GS1         *GS1Data              `json:"gs1,omitempty"`
+	Product     *Product              `json:"product"`
+	Transaction *InventoryTransaction `json:"transaction,omitempty"`
+}
+
+// Barcode errors
+var (
+	ErrInvalidBarcode    = &InventoryError{"Barcode must be printable ASCII text"}
+	ErrBarcodeDigits     = &InventoryError{"Barcode has the wrong number of digits for its type"}
+	ErrBarcodeCheckDigit = &InventoryError{"Barcode check digit is not valid"}
+	ErrBarcodeType       = &InventoryError{"Barcode type must be ean13, ean8, upca, gtin14 or internal"}
+	ErrGS1Data           = &InventoryError{"Barcode data is not a valid GS1 element string"}
+	ErrGS1Identifier     = &InventoryError{"Barcode data has an unsupported GS1 application identifier"}
+	ErrGS1Date           = &InventoryError{"Barcode data has an invalid GS1 date"}
+)
+
+// BarcodeService provides methods to interact with product barcodes
+type BarcodeService interface {
+	Create(barcode *ProductBarcode) error
+	GetByID(tenantID, id string) (*ProductBarcode, error)
+	GetByCode(tenantID, code string) (*ProductBarcode, error)
+	GetByGTIN(tenantID, gtin string) (*ProductBarcode, error)
+	ListByProduct(tenantID, productID string) ([]*ProductBarcode, error)
+	Update(barcode *ProductBarcode) error
+	Delete(tenantID, id string) error
+}
\ No newline at end of file
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"time"
+)
+
+// ProductCategory groups products in a tree. The GL accounts, tax code, costing method
+// and unit a category sets are defaults for the products in it and in its subcategories;
+// those it leaves empty are inherited from its parent. Defaults holds the values in effect.
+type ProductCategory struct {
+	ID                 string          `json:"id"`
+	TenantID           string          `json:"tenant_id"`
+	ParentID           string          `json:"parent_id,omitempty"`
+	Code               string          `json:"code"`
+	Name               string          `json:"name"`
+	Description        string          `json:"description"`
+	InventoryAccountID string          `json:"inventory_account_id,omitempty"`
+	COGSAccountID      string          `json:"cogs_account_id,omitempty"`
+	TaxCode            string          `json:"tax_code,omitempty"`
+	CostingMethod      string          `json:"costing_method,omitempty"`
+	UnitID             string          `json:"unit_id,omitempty"`
+	Defaults           ProductDefaults `json:"defaults"`
+	CreatedAt          time.Time       `json:"created_at"`
+	UpdatedAt          time.Time       `json:"updated_at"`
+}
+
+// ProductDefaults are the defaults in effect for a product or category. Stock movements
+// post to the inventory account instead of the posting rule's, and issues post to the
+// COGS account instead of the rule's counter account. The costing method falls back to
+// the tenant's, and the unit is the base unit of new products that do not give one.
+type ProductDefaults struct {
+	InventoryAccountID string `json:"inventory_account_id,omitempty"`
+	COGSAccountID      string `json:"cogs_account_id,omitempty"`
+	TaxCode            string `json:"tax_code,omitempty"`
+	CostingMethod      string `json:"costing_method"`
+	UnitID             string `json:"unit_id,omitempty"`
+}
+
+// CategoryStock is the stock on hand of the products in a category as of a date.
+// Quantity and Value cover the products in the category itself, and TotalQuantity
+// and TotalValue add those of its subcategories. Products without a category are
+// reported on a line without a category ID.
+type CategoryStock struct {
+	CategoryID    string  `json:"category_id,omitempty"`
+	CategoryCode  string  `json:"category_code,omitempty"`
+	CategoryName  string  `json:"category_name,omitempty"`
+	ParentID      string  `json:"parent_id,omitempty"`
+	Quantity      float64 `json:"quantity"`
+	Value         float64 `json:"value"`
+	TotalQuantity float64 `json:"total_quantity"`
+	TotalValue    float64 `json:"total_value"`
+}
+
+// CategoryStockReport is the stock on hand grouped by category as of a date
+type CategoryStockReport struct {
+	AsOf       time.Time        `json:"as_of"`
+	Lines      []*CategoryStock `json:"lines"`
+	TotalValue float64          `json:"total_value"`
+}
+
+// Product category errors
+var (
+	ErrCategoryCycle       = &InventoryError{"Category cannot be its own parent or the parent of an ancestor"}
+	ErrCategoryHasChildren = &InventoryError{"Category has subcategories"}
+)
+
+// ProductCategoryService provides methods to interact with product categories
+type ProductCategoryService interface {
+	Create(category *ProductCategory) error
+	GetByID(tenantID, id string) (*ProductCategory, error)
+	GetByCode(tenantID, code string) (*ProductCategory, error)
+	List(tenantID string) ([]*ProductCategory, error)
+	Update(category *ProductCategory) error
+	Delete(tenantID, id string) error
+	StockByCategory(tenantID string, asOf time.Time) (*CategoryStockReport, error)
+}
\ No newline at end of file
@@ -6,59 +6,106 @@ import (
 
 // Customer represents a customer in the CRM
 type Customer struct {
-	ID        string    `json:"id"`
-	TenantID  string    `json:"tenant_id"`
-	Name      string    `json:"name"`
-	Email     string    `json:"email"`
-	Phone     string    `json:"phone"`
-	Address   string    `json:"address"`
-	Contacts  []Contact `json:"contacts,omitempty"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID           string       `json:"id"`
+	TenantID     string       `json:"tenant_id"`
+	GroupID      string       `json:"group_id,omitempty"`
+	Name         string       `json:"name"`
+	Email        string       `json:"email"`
+	Phone        string       `json:"phone"`
+	Address      string       `json:"address"`
+	Tags         []string     `json:"tags"`
+	CustomFields CustomFields `json:"custom_fields"`
+	Contacts     []Contact    `json:"contacts,omitempty"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+}
+
+// CustomerGroup groups customers that share commercial terms such as a price list
+type CustomerGroup struct {
+	ID          string    `json:"id"`
+	TenantID    string    `json:"tenant_id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
 }
 
 // Contact represents a contact person for a customer
 type Contact struct {
-	ID         string    `json:"id"`
-	TenantID   string    `json:"tenant_id"`
-	CustomerID string    `json:"customer_id"`
-	FirstName  string    `json:"first_name"`
-	LastName   string    `json:"last_name"`
-	Email      string    `json:"email"`
-	Phone      string    `json:"phone"`
-	Position   string    `json:"position"`
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
+	ID           string       `json:"id"`
+	TenantID     string       `json:"tenant_id"`
+	CustomerID   string       `json:"customer_id"`
+	FirstName    string       `json:"first_name"`
+	LastName     string       `json:"last_name"`
+	Email        string       `json:"email"`
+	Phone        string       `json:"phone"`
+	Position     string       `json:"position"`
+	Tags         []string     `json:"tags"`
+	CustomFields CustomFields `json:"custom_fields"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
 }
 
 // Interaction represents an interaction with a customer
 type Interaction struct {
-	ID              string    `json:"id"`
-	TenantID        string    `json:"tenant_id"`
-	CustomerID      string    `json:"customer_id"`
-	ContactID       string    `json:"contact_id,omitempty"`
-	InteractionType string    `json:"interaction_type"`
-	Description     string    `json:"description"`
-	InteractionDate time.Time `json:"interaction_date"`
-	CreatedBy       string    `json:"created_by"`
-	CreatedAt       time.Time `json:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at"`
+	ID              string       `json:"id"`
+	TenantID        string       `json:"tenant_id"`
+	CustomerID      string       `json:"customer_id"`
+	ContactID       string       `json:"contact_id,omitempty"`
+	InteractionType string       `json:"interaction_type"`
+	Description     string       `json:"description"`
+	InteractionDate time.Time    `json:"interaction_date"`
+	CustomFields    CustomFields `json:"custom_fields"`
+	CreatedBy       string       `json:"created_by"`
+	CreatedAt       time.Time    `json:"created_at"`
+	UpdatedAt       time.Time    `json:"updated_at"`
+}
+
+// CRMError is a CRM rule violated by a request
+type CRMError struct {
+	Message string
+}
+
+func (e *CRMError) Error() string {
+	return e.Message
+}
+
+// CustomerFilter selects customers by tag and custom field values
+type CustomerFilter struct {
+	Tag          string
+	CustomFields CustomFieldFilter
+}
+
+// ContactFilter selects contacts by tag and custom field values
+type ContactFilter struct {
+	Tag          string
+	CustomFields CustomFieldFilter
 }
 
 // CustomerService provides methods to interact with customers
 type CustomerService interface {
 	Create(customer *Customer) error
 	GetByID(tenantID, id string) (*Customer, error)
-	List(tenantID string) ([]*Customer, error)
+	List(tenantID string, filter CustomerFilter) ([]*Customer, error)
 	Update(customer *Customer) error
 	Delete(tenantID, id string) error
 }
 
+// CustomerGroupService provides methods to interact with customer groups
+type CustomerGroupService interface {
+	Create(group *CustomerGroup) error
+	GetByID(tenantID, id string) (*CustomerGroup, error)
+	GetByName(tenantID, name string) (*CustomerGroup, error)
+	List(tenantID string) ([]*CustomerGroup, error)
+	Update(group *CustomerGroup) error
+	Delete(tenantID, id string) error
+}
+
 // ContactService provides methods to interact with contacts
 type ContactService interface {
 	Create(contact *Contact) error
 	GetByID(tenantID, id string) (*Contact, error)
-	ListByCustomer(tenantID, customerID string) ([]*Contact, error)
+	ListByCustomer(tenantID, customerID string, filter ContactFilter) ([]*Contact, error)
 	Update(contact *Contact) error
 	Delete(tenantID, id string) error
 }
@@ -67,7 +114,163 @@ type ContactService interface {
 type InteractionService interface {
 	Create(interaction *Interaction) error
 	GetByID(tenantID, id string) (*Interaction, error)
-	ListByCustomer(tenantID, customerID string) ([]*Interaction, error)
+	ListByCustomer(tenantID, customerID string, customFields CustomFieldFilter) ([]*Interaction, error)
 	Update(interaction *Interaction) error
 	Delete(tenantID, id string) error
+}
+
+// Task statuses. Completed and cancelled tasks are closed.
+const (
+	TaskOpen       = "open"
+	TaskInProgress = "in_progress"
+	TaskCompleted  = "completed"
+	TaskCancelled  = "cancelled"
+)
+
+// Task priorities
+const (
+	TaskPriorityLow    = "low"
+	TaskPriorityNormal = "normal"
+	TaskPriorityHigh   = "high"
+)
+
+// InteractionTask is the interaction type logged when a task is completed
+const InteractionTask = "task"
+
+// Task is a follow-up assigned to a user about a customer, optionally about one of its
+// contacts or opportunities. A task is overdue when it is not closed after its due date.
+// Completing it logs an interaction with the customer.
+type Task struct {
+	ID            string     `json:"id"`
+	TenantID      string     `json:"tenant_id"`
+	Title         string     `json:"title"`
+	Description   string     `json:"description"`
+	CustomerID    string     `json:"customer_id"`
+	ContactID     string     `json:"contact_id,omitempty"`
+	OpportunityID string     `json:"opportunity_id,omitempty"`
+	AssigneeID    string     `json:"assignee_id"`
+	DueDate       *time.Time `json:"due_date,omitempty"`
+	Priority      string     `json:"priority"`
+	Status        string     `json:"status"`
+	Overdue       bool       `json:"overdue"`
+	CompletedBy   string     `json:"completed_by,omitempty"`
+	CompletedAt   *time.Time `json:"completed_at,omitempty"`
+	InteractionID string     `json:"interaction_id,omitempty"`
+	CreatedBy     string     `json:"created_by"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// TaskFilter limits the tasks listed. Empty fields match any task, and Overdue limits
+// them to overdue tasks.
+type TaskFilter struct {
+	AssigneeID    string
+	CustomerID    string
+	OpportunityID string
+	Status        string
+	Overdue       bool
+}
+
+// Task errors
+var (
+	ErrTaskStatus   = &CRMError{"Task status does not allow this action"}
+	ErrTaskPriority = &CRMError{"Priority must be low, normal or high"}
+)
+
+// TaskService provides methods to interact with tasks. Only open and in progress tasks
+// can be updated, completed or cancelled.
+type TaskService interface {
+	Create(task *Task) error
+	GetByID(tenantID, id string) (*Task, error)
+	List(tenantID string, filter TaskFilter) ([]*Task, error)
+	Update(task *Task) error
+	Delete(tenantID, id string) error
+	Complete(tenantID, id, userID, notes string) error
+	Cancel(tenantID, id string) error
+}
+
+// Duplicate match reasons
+const (
+	MatchEmail = "email"
+	MatchPhone = "phone"
+	MatchName  = "name"
+)
+
+// DuplicatePair is a pair of customers, or of contacts of the same customer, that may be
+// the same. Emails match ignoring case and surrounding spaces, phones match on their
+// digits, and names match by trigram similarity. Score is 1 for a matching email, 0.9 for
+// a matching phone and otherwise the name similarity between 0 and 1.
+type DuplicatePair struct {
+	ID             string   `json:"id"`
+	Name           string   `json:"name"`
+	Email          string   `json:"email"`
+	Phone          string   `json:"phone"`
+	DuplicateID    string   `json:"duplicate_id"`
+	DuplicateName  string   `json:"duplicate_name"`
+	DuplicateEmail string   `json:"duplicate_email"`
+	DuplicatePhone string   `json:"duplicate_phone"`
+	CustomerID     string   `json:"customer_id,omitempty"`
+	Score          float64  `json:"score"`
+	MatchedOn      []string `json:"matched_on"`
+}
+
+// Merge errors
+var (
+	ErrMergeSelf          = &CRMError{"A record cannot be merged into itself"}
+	ErrMergeContactOwners = &CRMError{"Contacts of different customers cannot be merged; merge the customers first"}
+)
+
+// DuplicateService finds and merges duplicate customers and contacts. Merging moves
+// everything that refers to the duplicate to the surviving record, fills the survivor's
+// empty fields from the duplicate and deletes the duplicate, in one database transaction.
+type DuplicateService interface {
+	FindCustomerDuplicates(tenantID string, minScore float64) ([]*DuplicatePair, error)
+	FindContactDuplicates(tenantID string, minScore float64) ([]*DuplicatePair, error)
+	MergeCustomers(tenantID, survivorID, duplicateID string) error
+	MergeContacts(tenantID, survivorID, duplicateID string) error
+}
+
+// Search hit types
+const (
+	SearchCustomer    = "customer"
+	SearchContact     = "contact"
+	SearchInteraction = "interaction"
+)
+
+// DefaultSearchLanguage is the text search configuration used when a search names none.
+// It matches words as written, without language-specific stemming or stop words.
+const DefaultSearchLanguage = "simple"
+
+// SearchQuery is a full-text search of a tenant's customers, contacts and interactions.
+// Language names a PostgreSQL text search configuration such as english or german, and
+// Types limits the hits to some of customer, contact and interaction.
+type SearchQuery struct {
+	Text     string
+	Language string
+	Types    []string
+	Limit    int
+}
+
+// SearchHit is a customer, contact or interaction matching a search. Snippet is HTML:
+// the escaped record text around the matched words, which are highlighted with <mark>.
+// Full-text hits rank above 1 and come before hits found only by trigram similarity,
+// which rank by it.
+type SearchHit struct {
+	Type       string  `json:"type"`
+	ID         string  `json:"id"`
+	CustomerID string  `json:"customer_id"`
+	Title      string  `json:"title"`
+	Snippet    string  `json:"snippet"`
+	Rank       float64 `json:"rank"`
+	FullText   bool    `json:"full_text"`
+}
+
+// Search errors
+var (
+	ErrSearchLanguage = &CRMError{"Language is not a text search configuration"}
+)
+
+// SearchService searches a tenant's customers, contacts and interactions
+type SearchService interface {
+	Search(tenantID string, query SearchQuery) ([]*SearchHit, error)
 }
\ No newline at end of file
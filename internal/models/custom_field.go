@@ -0,0 +1,81 @@
+package models
+
+import (
+	"time"
+)
+
+// Record types that take custom fields
+const (
+	CustomFieldCustomer    = "customer"
+	CustomFieldContact     = "contact"
+	CustomFieldProduct     = "product"
+	CustomFieldInteraction = "interaction"
+)
+
+// Custom field types. Date values are written as YYYY-MM-DD and select values are one
+// of the options of their field.
+const (
+	CustomFieldText    = "text"
+	CustomFieldNumber  = "number"
+	CustomFieldBoolean = "boolean"
+	CustomFieldDate    = "date"
+	CustomFieldSelect  = "select"
+)
+
+// CustomFields are the custom field values of a record by field key
+type CustomFields map[string]interface{}
+
+// CustomFieldFilter selects records whose custom fields have the given values, written
+// as text, by field key
+type CustomFieldFilter map[string]string
+
+// CustomFieldDefinition is a field a tenant adds to its customers, contacts, products
+// or interactions. Pattern is a regular expression text values must match; MinValue
+// and MaxValue bound number values and MaxLength bounds the length of text values.
+type CustomFieldDefinition struct {
+	ID         string    `json:"id"`
+	TenantID   string    `json:"tenant_id"`
+	EntityType string    `json:"entity_type"`
+	Key        string    `json:"key"`
+	Label      string    `json:"label"`
+	FieldType  string    `json:"field_type"`
+	Required   bool      `json:"required"`
+	Options    []string  `json:"options,omitempty"`
+	Pattern    string    `json:"pattern,omitempty"`
+	MinValue   *float64  `json:"min_value,omitempty"`
+	MaxValue   *float64  `json:"max_value,omitempty"`
+	MaxLength  int       `json:"max_length,omitempty"`
+	Position   int       `json:"position"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// CustomFieldError is returned when a custom field definition or value is invalid
+type CustomFieldError struct {
+	Message string
+}
+
+// Error returns the error message
+func (e *CustomFieldError) Error() string {
+	return e.Message
+}
+
+// Custom field definition errors
+var (
+	ErrCustomFieldEntity  = &CustomFieldError{"Entity type must be customer, contact, product or interaction"}
+	ErrCustomFieldType    = &CustomFieldError{"Field type must be text, number, boolean, date or select"}
+	ErrCustomFieldKey     = &CustomFieldError{"Key must start with a lowercase letter and contain only lowercase letters, digits and underscores"}
+	ErrCustomFieldOptions = &CustomFieldError{"Select fields need at least one option, and options must be unique"}
+	ErrCustomFieldPattern = &CustomFieldError{"Pattern is not a valid regular expression"}
+	ErrCustomFieldRange   = &CustomFieldError{"Minimum value must not be above the maximum value"}
+)
+
+// CustomFieldService provides methods to interact with custom field definitions
+type CustomFieldService interface {
+	Create(definition *CustomFieldDefinition) error
+	GetByID(tenantID, id string) (*CustomFieldDefinition, error)
+	GetByKey(tenantID, entityType, key string) (*CustomFieldDefinition, error)
+	List(tenantID, entityType string) ([]*CustomFieldDefinition, error)
+	Update(definition *CustomFieldDefinition) error
+	Delete(tenantID, id string) error
+}
\ No newline at end of file
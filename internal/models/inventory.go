@@ -4,39 +4,264 @@ import (
 	"time"
 )
 
-// Product represents a product in inventory
+// Inventory transaction types
+const (
+	TransactionTypeReceipt    = "IN"
+	TransactionTypeIssue      = "OUT"
+	TransactionTypeAdjustment = "ADJUSTMENT"
+	TransactionTypeTransfer   = "TRANSFER"
+	TransactionTypeReturn     = "RETURN"
+)
+
+// Adjustment reason codes
+const (
+	ReasonDamaged  = "DAMAGED"
+	ReasonLost     = "LOST"
+	ReasonFound    = "FOUND"
+	ReasonExpired  = "EXPIRED"
+	ReasonCount    = "COUNT"
+	ReasonWriteOff = "WRITE_OFF"
+	ReasonOther    = "OTHER"
+)
+
+// ReasonLandedCost marks the zero-quantity adjustments that add a posted landed cost
+// to the value of stock. They cannot be entered as transactions.
+const ReasonLandedCost = "LANDED_COST"
+
+// Product tracking modes
+const (
+	TrackingNone   = "none"
+	TrackingLot    = "lot"
+	TrackingSerial = "serial"
+)
+
+// Product represents a product in inventory.
+// StockQuantity is on hand; AvailableQuantity is on hand less active reservations
+// and stock held at quarantine locations.
+// AllowNegativeStock overrides the tenant's negative stock policy when set.
+// Quantities are in the base unit; purchases and sales default to the purchase and sales units.
+// Variants belong to a product template and carry their attribute values by attribute name.
+// The GL accounts, tax code and costing method override the defaults of the product's
+// category when set; Defaults holds the values in effect.
+// Weight and Volume are per base unit and allocate landed costs by weight or volume.
 type Product struct {
-	ID            string    `json:"id"`
-	TenantID      string    `json:"tenant_id"`
-	Code          string    `json:"code"`
-	Name          string    `json:"name"`
-	Description   string    `json:"description"`
-	UnitPrice     float64   `json:"unit_price"`
-	StockQuantity int       `json:"stock_quantity"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
-}
-
-// InventoryTransaction represents a transaction affecting inventory
+	ID                 string            `json:"id"`
+	TenantID           string            `json:"tenant_id"`
+	Code               string            `json:"code"`
+	Name               string            `json:"name"`
+	Description        string            `json:"description"`
+	UnitPrice          float64           `json:"unit_price"`
+	BaseUnitID         string            `json:"base_unit_id,omitempty"`
+	PurchaseUnitID     string            `json:"purchase_unit_id,omitempty"`
+	SalesUnitID        string            `json:"sales_unit_id,omitempty"`
+	Weight             float64           `json:"weight"`
+	Volume             float64           `json:"volume"`
+	StockQuantity      float64           `json:"stock_quantity"`
+	ReservedQuantity   float64           `json:"reserved_quantity"`
+	QuarantineQuantity float64           `json:"quarantine_quantity"`
+	AvailableQuantity  float64           `json:"available_quantity"`
+	TrackingMode       string            `json:"tracking_mode"`
+	AverageCost        float64           `json:"average_cost"`
+	AllowNegativeStock *bool             `json:"allow_negative_stock"`
+	TemplateID         string            `json:"template_id,omitempty"`
+	Attributes         map[string]string `json:"attributes,omitempty"`
+	CategoryID         string            `json:"category_id,omitempty"`
+	InventoryAccountID string            `json:"inventory_account_id,omitempty"`
+	COGSAccountID      string            `json:"cogs_account_id,omitempty"`
+	TaxCode            string            `json:"tax_code,omitempty"`
+	CostingMethod      string            `json:"costing_method,omitempty"`
+	Defaults           ProductDefaults   `json:"defaults"`
+	CustomFields       CustomFields      `json:"custom_fields"`
+	CreatedAt          time.Time         `json:"created_at"`
+	UpdatedAt          time.Time         `json:"updated_at"`
+}
+
+// InventoryTransaction represents a transaction affecting inventory.
+// Quantity is positive except for adjustments, where its sign gives the direction.
+// Transfers move stock from LocationID to DestinationLocationID.
+// UnitCost is given on receipts and computed for issues from the product's costing method.
+// TotalCost is the resulting change in inventory value, negative for issues.
+// A quantity entered in UnitID is converted to the product's base unit; the entered
+// quantity is kept as UnitQuantity and a unit cost entered with it is converted too.
 type InventoryTransaction struct {
-	ID              string    `json:"id"`
-	TenantID        string    `json:"tenant_id"`
-	ProductID       string    `json:"product_id"`
-	TransactionType string    `json:"transaction_type"`
-	Quantity        int       `json:"quantity"`
-	Reference       string    `json:"reference"`
-	Notes           string    `json:"notes"`
-	CreatedBy       string    `json:"created_by"`
-	CreatedAt       time.Time `json:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at"`
+	ID                    string     `json:"id"`
+	TenantID              string     `json:"tenant_id"`
+	ProductID             string     `json:"product_id"`
+	TransactionType       string     `json:"transaction_type"`
+	Quantity              float64    `json:"quantity"`
+	UnitID                string     `json:"unit_id,omitempty"`
+	UnitQuantity          float64    `json:"unit_quantity"`
+	ReasonCode            string     `json:"reason_code,omitempty"`
+	LocationID            string     `json:"location_id,omitempty"`
+	DestinationLocationID string     `json:"destination_location_id,omitempty"`
+	LotNumber             string     `json:"lot_number,omitempty"`
+	SerialNumber          string     `json:"serial_number,omitempty"`
+	ExpiryDate            *time.Time `json:"expiry_date,omitempty"`
+	UnitCost              float64    `json:"unit_cost"`
+	TotalCost             float64    `json:"total_cost"`
+	JournalEntryID        string     `json:"journal_entry_id,omitempty"`
+	Reference             string     `json:"reference"`
+	Notes                 string     `json:"notes"`
+	CreatedBy             string     `json:"created_by"`
+	CreatedAt             time.Time  `json:"created_at"`
+	UpdatedAt             time.Time  `json:"updated_at"`
+}
+
+// Location represents a stock location such as a warehouse.
+// Stock held at a quarantine location is on hand but not available to sell.
+type Location struct {
+	ID          string    `json:"id"`
+	TenantID    string    `json:"tenant_id"`
+	Code        string    `json:"code"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Quarantine  bool      `json:"quarantine"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// StockLevel is the quantity of a product held at a location
+type StockLevel struct {
+	ProductID  string    `json:"product_id"`
+	LocationID string    `json:"location_id"`
+	Quantity   float64   `json:"quantity"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// Lot represents a lot or serial number of a tracked product.
+// Serial-tracked products hold one lot per serial number.
+type Lot struct {
+	ID           string     `json:"id"`
+	TenantID     string     `json:"tenant_id"`
+	ProductID    string     `json:"product_id"`
+	LotNumber    string     `json:"lot_number,omitempty"`
+	SerialNumber string     `json:"serial_number,omitempty"`
+	ExpiryDate   *time.Time `json:"expiry_date,omitempty"`
+	Quantity     float64    `json:"quantity"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// LotPick is a suggested quantity to pick from a lot
+type LotPick struct {
+	Lot      *Lot    `json:"lot"`
+	Quantity float64 `json:"quantity"`
+}
+
+// FEFOSuggestion lists the lots to pick from, first expired first out
+type FEFOSuggestion struct {
+	ProductID string     `json:"product_id"`
+	Quantity  float64    `json:"quantity"`
+	Picks     []*LotPick `json:"picks"`
+	Shortfall float64    `json:"shortfall"`
+}
+
+// LotTrace lists every movement of a lot or serial number.
+// Backward movements show where the stock came from, forward movements where it went.
+type LotTrace struct {
+	LotNumber    string                  `json:"lot_number,omitempty"`
+	SerialNumber string                  `json:"serial_number,omitempty"`
+	Lots         []*Lot                  `json:"lots"`
+	Backward     []*InventoryTransaction `json:"backward"`
+	Forward      []*InventoryTransaction `json:"forward"`
+}
+
+// StockValuationLine is the quantity and value of a product in a stock valuation
+type StockValuationLine struct {
+	ProductID   string  `json:"product_id"`
+	ProductCode string  `json:"product_code"`
+	ProductName string  `json:"product_name"`
+	Quantity    float64 `json:"quantity"`
+	Value       float64 `json:"value"`
+	UnitCost    float64 `json:"unit_cost"`
+}
+
+// StockValuation is the value of stock on hand as of a date
+type StockValuation struct {
+	AsOf       time.Time             `json:"as_of"`
+	Lines      []*StockValuationLine `json:"lines"`
+	TotalValue float64               `json:"total_value"`
 }
 
+// StockOnHandLine is the quantity of a product on hand recomputed from the ledger
+type StockOnHandLine struct {
+	ProductID   string  `json:"product_id"`
+	ProductCode string  `json:"product_code"`
+	ProductName string  `json:"product_name"`
+	Quantity    float64 `json:"quantity"`
+}
+
+// StockOnHand is the stock on hand as of a point in time, in total or at a location
+type StockOnHand struct {
+	AsOf       time.Time          `json:"as_of"`
+	LocationID string             `json:"location_id,omitempty"`
+	Lines      []*StockOnHandLine `json:"lines"`
+}
+
+// Stock drift kinds, naming the stored quantity that differs from the ledger
+const (
+	StockDriftProduct  = "product"
+	StockDriftLocation = "location"
+	StockDriftLot      = "lot"
+)
+
+// StockDrift is a stored stock quantity of a product, location or lot that differs
+// from the quantity recomputed from its inventory transactions
+type StockDrift struct {
+	Kind           string  `json:"kind"`
+	ProductID      string  `json:"product_id"`
+	ProductCode    string  `json:"product_code"`
+	ProductName    string  `json:"product_name"`
+	LocationID     string  `json:"location_id,omitempty"`
+	LocationCode   string  `json:"location_code,omitempty"`
+	LotNumber      string  `json:"lot_number,omitempty"`
+	SerialNumber   string  `json:"serial_number,omitempty"`
+	StoredQuantity float64 `json:"stored_quantity"`
+	LedgerQuantity float64 `json:"ledger_quantity"`
+	Difference     float64 `json:"difference"`
+}
+
+// StockConsistencyReport lists the drift between stored stock quantities and the ledger
+type StockConsistencyReport struct {
+	TenantID   string        `json:"tenant_id"`
+	CheckedAt  time.Time     `json:"checked_at"`
+	Consistent bool          `json:"consistent"`
+	Drifts     []*StockDrift `json:"drifts"`
+}
+
+// InventoryError is returned when an inventory movement violates a business rule
+type InventoryError struct {
+	Message string
+}
+
+// Error returns the error message
+func (e *InventoryError) Error() string {
+	return e.Message
+}
+
+// Inventory business rule errors
+var (
+	ErrInvalidTransactionType  = &InventoryError{"Transaction type must be IN, OUT, ADJUSTMENT, TRANSFER or RETURN"}
+	ErrInvalidQuantity         = &InventoryError{"Quantity must be positive"}
+	ErrZeroAdjustment          = &InventoryError{"Adjustment quantity cannot be zero"}
+	ErrInvalidReasonCode       = &InventoryError{"Adjustments require a reason code of DAMAGED, LOST, FOUND, EXPIRED, COUNT, WRITE_OFF or OTHER"}
+	ErrTransferLocations       = &InventoryError{"Transfers require different source and destination locations"}
+	ErrInsufficientStock       = &InventoryError{"Insufficient stock"}
+	ErrLotNumberRequired       = &InventoryError{"Lot number is required for lot-tracked products"}
+	ErrSerialNumberRequired    = &InventoryError{"Serial number is required for serial-tracked products"}
+	ErrSerialQuantity          = &InventoryError{"Serial-tracked movements must have a quantity of 1"}
+	ErrInsufficientLotQuantity = &InventoryError{"Insufficient quantity in lot"}
+	ErrSerialNumberInStock     = &InventoryError{"Serial number is already in stock"}
+	ErrUnitConversion          = &InventoryError{"Unit has no conversion to the product's base unit"}
+)
+
 // ProductService provides methods to interact with products
 type ProductService interface {
 	Create(product *Product) error
 	GetByID(tenantID, id string) (*Product, error)
 	GetByCode(tenantID, code string) (*Product, error)
-	List(tenantID string) ([]*Product, error)
+	List(tenantID string, customFields CustomFieldFilter) ([]*Product, error)
+	ListByCategory(tenantID, categoryID string, customFields CustomFieldFilter) ([]*Product, error)
 	Update(product *Product) error
 	Delete(tenantID, id string) error
 }
@@ -47,4 +272,33 @@ type InventoryTransactionService interface {
 	GetByID(tenantID, id string) (*InventoryTransaction, error)
 	List(tenantID string) ([]*InventoryTransaction, error)
 	ListByProduct(tenantID, productID string) ([]*InventoryTransaction, error)
+}
+
+// LocationService provides methods to interact with stock locations
+type LocationService interface {
+	Create(location *Location) error
+	GetByID(tenantID, id string) (*Location, error)
+	GetByCode(tenantID, code string) (*Location, error)
+	List(tenantID string) ([]*Location, error)
+	ListStockByProduct(tenantID, productID string) ([]*StockLevel, error)
+	Update(location *Location) error
+	Delete(tenantID, id string) error
+}
+
+// LotService provides methods to interact with lots and serial numbers
+type LotService interface {
+	ListByProduct(tenantID, productID string) ([]*Lot, error)
+	SuggestFEFO(tenantID, productID string, quantity float64) (*FEFOSuggestion, error)
+	Trace(tenantID, lotNumber, serialNumber string) (*LotTrace, error)
+}
+
+// StockValuationService provides stock valuation reports
+type StockValuationService interface {
+	Valuation(tenantID string, asOf time.Time) (*StockValuation, error)
+}
+
+// StockLedgerService recomputes stock from the inventory transaction ledger
+type StockLedgerService interface {
+	StockOnHand(tenantID string, asOf time.Time, locationID string) (*StockOnHand, error)
+	CheckConsistency(tenantID string) (*StockConsistencyReport, error)
 }
\ No newline at end of file
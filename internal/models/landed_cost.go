@@ -0,0 +1,103 @@
+package models
+
+import (
+	"time"
+)
+
+// Landed cost statuses
+const (
+	LandedCostDraft     = "draft"
+	LandedCostPosted    = "posted"
+	LandedCostCancelled = "cancelled"
+)
+
+// Landed cost allocation methods. Weight and volume are the received quantity times
+// the product's weight or volume per base unit.
+const (
+	AllocateByQuantity = "quantity"
+	AllocateByValue    = "value"
+	AllocateByWeight   = "weight"
+	AllocateByVolume   = "volume"
+)
+
+// LandedCost adds charges such as freight, duty and insurance to the cost of goods
+// received. Each charge is allocated over the receipts by its allocation method when
+// the landed cost is created. Posting it adds the allocated costs to the received
+// stock and credits the total to CounterAccountID.
+type LandedCost struct {
+	ID               string              `json:"id"`
+	TenantID         string              `json:"tenant_id"`
+	Number           string              `json:"number"`
+	Reference        string              `json:"reference"`
+	CounterAccountID string              `json:"counter_account_id"`
+	Status           string              `json:"status"`
+	Notes            string              `json:"notes"`
+	Charges          []LandedCostCharge  `json:"charges"`
+	Receipts         []LandedCostReceipt `json:"receipts"`
+	Total            float64             `json:"total"`
+	JournalEntryID   string              `json:"journal_entry_id,omitempty"`
+	CreatedBy        string              `json:"created_by"`
+	PostedBy         string              `json:"posted_by,omitempty"`
+	PostedAt         *time.Time          `json:"posted_at,omitempty"`
+	CreatedAt        time.Time           `json:"created_at"`
+	UpdatedAt        time.Time           `json:"updated_at"`
+}
+
+// LandedCostCharge is an extra cost of a landed cost and how it is allocated
+type LandedCostCharge struct {
+	ID               string    `json:"id"`
+	TenantID         string    `json:"tenant_id"`
+	LandedCostID     string    `json:"landed_cost_id"`
+	Description      string    `json:"description"`
+	Amount           float64   `json:"amount"`
+	AllocationMethod string    `json:"allocation_method"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// LandedCostReceipt is a receipt transaction a landed cost is allocated to, with the
+// bases it is allocated by. LandedUnitCost is the receipt's unit cost including its
+// allocated cost. On posting, the share of the allocated cost for stock still on hand
+// is capitalized into inventory and the share for stock already issued is expensed to
+// cost of goods sold.
+type LandedCostReceipt struct {
+	ID                      string    `json:"id"`
+	TenantID                string    `json:"tenant_id"`
+	LandedCostID            string    `json:"landed_cost_id"`
+	TransactionID           string    `json:"transaction_id"`
+	ProductID               string    `json:"product_id"`
+	Quantity                float64   `json:"quantity"`
+	Value                   float64   `json:"value"`
+	Weight                  float64   `json:"weight"`
+	Volume                  float64   `json:"volume"`
+	UnitCost                float64   `json:"unit_cost"`
+	AllocatedCost           float64   `json:"allocated_cost"`
+	LandedUnitCost          float64   `json:"landed_unit_cost"`
+	CapitalizedCost         float64   `json:"capitalized_cost"`
+	ExpensedCost            float64   `json:"expensed_cost"`
+	AdjustmentTransactionID string    `json:"adjustment_transaction_id,omitempty"`
+	CreatedAt               time.Time `json:"created_at"`
+}
+
+// Landed cost errors
+var (
+	ErrLandedCostStatus    = &PurchasingError{"Landed cost status does not allow this action"}
+	ErrLandedCostReceipt   = &PurchasingError{"Landed costs can only be allocated to receipt transactions"}
+	ErrLandedCostEmpty     = &PurchasingError{"Landed cost needs at least one charge and one receipt"}
+	ErrLandedCostAmount    = &PurchasingError{"Charge amount must be positive"}
+	ErrAllocationMethod    = &PurchasingError{"Allocation method must be quantity, value, weight or volume"}
+	ErrAllocationBasis     = &PurchasingError{"Receipts have no quantity, value, weight or volume to allocate the charge by"}
+	ErrLandedCostAccount   = &PurchasingError{"No inventory or COGS account for a product; set a posting rule for IN and OUT transactions"}
+	ErrDuplicateLandedCost = &PurchasingError{"Receipt is listed more than once"}
+)
+
+// LandedCostService provides methods to interact with landed costs.
+// Only draft landed costs can be posted or cancelled.
+type LandedCostService interface {
+	Create(landedCost *LandedCost) error
+	GetByID(tenantID, id string) (*LandedCost, error)
+	GetByNumber(tenantID, number string) (*LandedCost, error)
+	List(tenantID, status string) ([]*LandedCost, error)
+	ListByTransaction(tenantID, transactionID string) ([]*LandedCost, error)
+	Post(tenantID, id, userID string) error
+	Cancel(tenantID, id string) error
+}
\ No newline at end of file
@@ -0,0 +1,162 @@
+package models
+
+import (
+	"time"
+)
+
+// Work order statuses
+const (
+	WorkOrderPlanned   = "planned"
+	WorkOrderReleased  = "released"
+	WorkOrderCompleted = "completed"
+	WorkOrderCancelled = "cancelled"
+)
+
+// BillOfMaterials lists the components needed to make Quantity of a product.
+// Components may have bills of materials of their own, making the bill multi-level.
+type BillOfMaterials struct {
+	ID        string    `json:"id"`
+	TenantID  string    `json:"tenant_id"`
+	ProductID string    `json:"product_id"`
+	Quantity  float64   `json:"quantity"`
+	Notes     string    `json:"notes"`
+	Lines     []BOMLine `json:"lines"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BOMLine is a component of a bill of materials. ScrapPercent is the share of the
+// component expected to be lost in production, added on top of Quantity.
+type BOMLine struct {
+	ID           string    `json:"id"`
+	TenantID     string    `json:"tenant_id"`
+	BOMID        string    `json:"bom_id"`
+	LineNumber   int       `json:"line_number"`
+	ComponentID  string    `json:"component_id"`
+	Quantity     float64   `json:"quantity"`
+	ScrapPercent float64   `json:"scrap_percent"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// WorkOrder makes a quantity of a product from the components of its bill of materials.
+// Releasing it reserves the components; completing it consumes them and receives the
+// finished goods in one step. Components and output move at LocationID when it is set.
+type WorkOrder struct {
+	ID                  string               `json:"id"`
+	TenantID            string               `json:"tenant_id"`
+	Number              string               `json:"number"`
+	ProductID           string               `json:"product_id"`
+	BOMID               string               `json:"bom_id"`
+	LocationID          string               `json:"location_id,omitempty"`
+	Quantity            float64              `json:"quantity"`
+	ProducedQuantity    float64              `json:"produced_quantity"`
+	ScrappedQuantity    float64              `json:"scrapped_quantity"`
+	YieldPercent        float64              `json:"yield_percent"`
+	Status              string               `json:"status"`
+	PlannedDate         *time.Time           `json:"planned_date,omitempty"`
+	Notes               string               `json:"notes"`
+	Components          []WorkOrderComponent `json:"components"`
+	OutputTransactionID string               `json:"output_transaction_id,omitempty"`
+	CreatedBy           string               `json:"created_by"`
+	ReleasedAt          *time.Time           `json:"released_at,omitempty"`
+	CompletedAt         *time.Time           `json:"completed_at,omitempty"`
+	CreatedAt           time.Time            `json:"created_at"`
+	UpdatedAt           time.Time            `json:"updated_at"`
+}
+
+// WorkOrderComponent is the quantity of a component a work order needs, including expected scrap
+type WorkOrderComponent struct {
+	ID               string    `json:"id"`
+	TenantID         string    `json:"tenant_id"`
+	WorkOrderID      string    `json:"work_order_id"`
+	ProductID        string    `json:"product_id"`
+	Quantity         float64   `json:"quantity"`
+	ReservationID    string    `json:"reservation_id,omitempty"`
+	ReservedQuantity float64   `json:"reserved_quantity"`
+	ConsumedQuantity float64   `json:"consumed_quantity"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// WorkOrderCompletion reports the outcome of a work order. Scrapped units were made
+// but are not usable; components are consumed in proportion to produced plus scrapped
+// units and their cost is carried by the produced units. LotNumber, SerialNumber and
+// ExpiryDate apply to the finished goods, and ComponentLots name the lots components are
+// issued from, with anything left over issued without a lot.
+type WorkOrderCompletion struct {
+	ProducedQuantity float64        `json:"produced_quantity"`
+	ScrappedQuantity float64        `json:"scrapped_quantity"`
+	LotNumber        string         `json:"lot_number,omitempty"`
+	SerialNumber     string         `json:"serial_number,omitempty"`
+	ExpiryDate       *time.Time     `json:"expiry_date,omitempty"`
+	ComponentLots    []ComponentLot `json:"component_lots,omitempty"`
+}
+
+// ComponentLot is a quantity of a component issued from a lot or serial number
+type ComponentLot struct {
+	ProductID    string  `json:"product_id"`
+	LotNumber    string  `json:"lot_number,omitempty"`
+	SerialNumber string  `json:"serial_number,omitempty"`
+	Quantity     float64 `json:"quantity"`
+}
+
+// MaterialRequirement is the need for a product across planned work orders.
+// Requirements are netted against available stock level by level: only the
+// shortage of a manufactured component is exploded into its own components.
+type MaterialRequirement struct {
+	ProductID    string  `json:"product_id"`
+	ProductCode  string  `json:"product_code"`
+	ProductName  string  `json:"product_name"`
+	Level        int     `json:"level"`
+	Manufactured bool    `json:"manufactured"`
+	Required     float64 `json:"required"`
+	Available    float64 `json:"available"`
+	Shortage     float64 `json:"shortage"`
+}
+
+// ManufacturingError is a manufacturing rule violated by a request
+type ManufacturingError struct {
+	Message string
+}
+
+func (e *ManufacturingError) Error() string {
+	return e.Message
+}
+
+// Manufacturing errors
+var (
+	ErrWorkOrderStatus      = &ManufacturingError{"Work order status does not allow this action"}
+	ErrBOMCycle             = &ManufacturingError{"Bill of materials cannot contain the product it makes, directly or through its components"}
+	ErrNoBOM                = &ManufacturingError{"Product has no bill of materials"}
+	ErrInvalidBOMQuantity   = &ManufacturingError{"Quantity must be positive"}
+	ErrNegativeScrapPercent = &ManufacturingError{"Scrap percent cannot be negative"}
+	ErrInvalidProduction    = &ManufacturingError{"Produced quantity must be positive and scrapped quantity cannot be negative"}
+	ErrComponentLots        = &ManufacturingError{"Component lots must belong to a component and not exceed the quantity consumed"}
+)
+
+// BillOfMaterialsService provides methods to interact with bills of materials.
+// A product has at most one bill of materials.
+type BillOfMaterialsService interface {
+	Create(bom *BillOfMaterials) error
+	GetByID(tenantID, id string) (*BillOfMaterials, error)
+	GetByProduct(tenantID, productID string) (*BillOfMaterials, error)
+	List(tenantID string) ([]*BillOfMaterials, error)
+	Update(bom *BillOfMaterials) error
+	Delete(tenantID, id string) error
+}
+
+// WorkOrderService provides methods to interact with work orders.
+// Only planned work orders can be updated or deleted.
+type WorkOrderService interface {
+	Create(order *WorkOrder) error
+	GetByID(tenantID, id string) (*WorkOrder, error)
+	GetByNumber(tenantID, number string) (*WorkOrder, error)
+	List(tenantID, status string) ([]*WorkOrder, error)
+	Update(order *WorkOrder) error
+	Release(tenantID, id, userID string) error
+	Complete(tenantID, id, userID string, completion *WorkOrderCompletion) error
+	Cancel(tenantID, id string) error
+	Delete(tenantID, id string) error
+	MaterialRequirements(tenantID string) ([]*MaterialRequirement, error)
+}
\ No newline at end of file
@@ -0,0 +1,187 @@
+package models
+
+import (
+	"time"
+)
+
+// Lead statuses
+const (
+	LeadNew          = "new"
+	LeadContacted    = "contacted"
+	LeadQualified    = "qualified"
+	LeadDisqualified = "disqualified"
+	LeadConverted    = "converted"
+)
+
+// Pipeline stage types. Opportunities in won or lost stages are closed.
+const (
+	StageOpen = "open"
+	StageWon  = "won"
+	StageLost = "lost"
+)
+
+// Lead is a prospective customer that is not yet a customer. Converting a lead creates
+// a customer and contact from it, or adds the contact to an existing customer, and can
+// open an opportunity for it.
+type Lead struct {
+	ID            string     `json:"id"`
+	TenantID      string     `json:"tenant_id"`
+	Company       string     `json:"company"`
+	FirstName     string     `json:"first_name"`
+	LastName      string     `json:"last_name"`
+	Email         string     `json:"email"`
+	Phone         string     `json:"phone"`
+	Source        string     `json:"source"`
+	Status        string     `json:"status"`
+	OwnerID       string     `json:"owner_id,omitempty"`
+	Notes         string     `json:"notes"`
+	CustomerID    string     `json:"customer_id,omitempty"`
+	ContactID     string     `json:"contact_id,omitempty"`
+	OpportunityID string     `json:"opportunity_id,omitempty"`
+	ConvertedAt   *time.Time `json:"converted_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// LeadConversion says how to convert a lead. The lead becomes a new customer unless
+// CustomerID names an existing one, and an opportunity is opened when Opportunity is set.
+type LeadConversion struct {
+	CustomerID  string       `json:"customer_id,omitempty"`
+	Opportunity *Opportunity `json:"opportunity,omitempty"`
+}
+
+// PipelineStage is a tenant's stage of the sales pipeline. Probability is the percentage
+// chance of winning an opportunity in the stage, which opportunities take on entering it.
+type PipelineStage struct {
+	ID          string    `json:"id"`
+	TenantID    string    `json:"tenant_id"`
+	Name        string    `json:"name"`
+	Position    int       `json:"position"`
+	Probability float64   `json:"probability"`
+	Type        string    `json:"type"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Opportunity is a potential sale to a customer moving through the pipeline stages.
+// Probability is the percentage chance of winning it and WeightedAmount is the amount
+// times the probability. ClosedAt is set when it enters a won or lost stage.
+type Opportunity struct {
+	ID                string     `json:"id"`
+	TenantID          string     `json:"tenant_id"`
+	Name              string     `json:"name"`
+	CustomerID        string     `json:"customer_id"`
+	ContactID         string     `json:"contact_id,omitempty"`
+	LeadID            string     `json:"lead_id,omitempty"`
+	StageID           string     `json:"stage_id"`
+	StageName         string     `json:"stage_name"`
+	StageType         string     `json:"stage_type"`
+	OwnerID           string     `json:"owner_id,omitempty"`
+	Amount            float64    `json:"amount"`
+	Probability       float64    `json:"probability"`
+	WeightedAmount    float64    `json:"weighted_amount"`
+	ExpectedCloseDate *time.Time `json:"expected_close_date,omitempty"`
+	ClosedAt          *time.Time `json:"closed_at,omitempty"`
+	Notes             string     `json:"notes"`
+	CreatedBy         string     `json:"created_by"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+// OpportunityStageChange records an opportunity moving between pipeline stages.
+// FromStageID is empty for the stage an opportunity was opened in.
+type OpportunityStageChange struct {
+	ID            string    `json:"id"`
+	TenantID      string    `json:"tenant_id"`
+	OpportunityID string    `json:"opportunity_id"`
+	FromStageID   string    `json:"from_stage_id,omitempty"`
+	FromStageName string    `json:"from_stage_name,omitempty"`
+	ToStageID     string    `json:"to_stage_id"`
+	ToStageName   string    `json:"to_stage_name"`
+	ChangedBy     string    `json:"changed_by"`
+	ChangedAt     time.Time `json:"changed_at"`
+}
+
+// OpportunityFilter limits the opportunities listed. Empty fields match any opportunity.
+type OpportunityFilter struct {
+	StageID    string
+	OwnerID    string
+	CustomerID string
+}
+
+// PipelineSummary sums the open opportunities of a tenant by stage and by owner. The
+// weighted forecast is the sum of their weighted amounts.
+type PipelineSummary struct {
+	Count            int                     `json:"count"`
+	Amount           float64                 `json:"amount"`
+	WeightedForecast float64                 `json:"weighted_forecast"`
+	Stages           []*PipelineStageSummary `json:"stages"`
+	Owners           []*PipelineOwnerSummary `json:"owners"`
+}
+
+// PipelineStageSummary sums the open opportunities in a stage
+type PipelineStageSummary struct {
+	StageID          string  `json:"stage_id"`
+	StageName        string  `json:"stage_name"`
+	Position         int     `json:"position"`
+	Count            int     `json:"count"`
+	Amount           float64 `json:"amount"`
+	WeightedForecast float64 `json:"weighted_forecast"`
+}
+
+// PipelineOwnerSummary sums the open opportunities of an owner. OwnerID is empty for
+// unassigned opportunities.
+type PipelineOwnerSummary struct {
+	OwnerID          string  `json:"owner_id"`
+	Count            int     `json:"count"`
+	Amount           float64 `json:"amount"`
+	WeightedForecast float64 `json:"weighted_forecast"`
+}
+
+// Pipeline errors
+var (
+	ErrLeadStatus       = &CRMError{"Lead status must be new, contacted, qualified or disqualified"}
+	ErrLeadConverted    = &CRMError{"Lead is already converted"}
+	ErrLeadDisqualified = &CRMError{"Disqualified leads cannot be converted"}
+	ErrLeadName         = &CRMError{"Lead needs a company or a first and last name to become a customer"}
+	ErrStageType        = &CRMError{"Stage type must be open, won or lost"}
+	ErrStageProbability = &CRMError{"Probability must be between 0 and 100"}
+	ErrStageInUse       = &CRMError{"Stage is used by opportunities and cannot be deleted"}
+	ErrOpportunityStage = &CRMError{"Opportunity is already in this stage"}
+	ErrNegativeAmount   = &CRMError{"Amount cannot be negative"}
+)
+
+// LeadService provides methods to interact with leads.
+// Converted leads cannot be updated or converted again.
+type LeadService interface {
+	Create(lead *Lead) error
+	GetByID(tenantID, id string) (*Lead, error)
+	List(tenantID, status string) ([]*Lead, error)
+	Update(lead *Lead) error
+	Delete(tenantID, id string) error
+	Convert(tenantID, id, userID string, conversion *LeadConversion) (*Lead, error)
+}
+
+// PipelineStageService provides methods to interact with pipeline stages.
+// Stages are listed by position.
+type PipelineStageService interface {
+	Create(stage *PipelineStage) error
+	GetByID(tenantID, id string) (*PipelineStage, error)
+	GetByName(tenantID, name string) (*PipelineStage, error)
+	List(tenantID string) ([]*PipelineStage, error)
+	Update(stage *PipelineStage) error
+	Delete(tenantID, id string) error
+}
+
+// OpportunityService provides methods to interact with opportunities. Opportunities
+// change stage only through ChangeStage, which records the change in their history.
+type OpportunityService interface {
+	Create(opportunity *Opportunity) error
+	GetByID(tenantID, id string) (*Opportunity, error)
+	List(tenantID string, filter OpportunityFilter) ([]*Opportunity, error)
+	Update(opportunity *Opportunity) error
+	Delete(tenantID, id string) error
+	ChangeStage(tenantID, id, stageID, userID string) error
+	ListStageChanges(tenantID, id string) ([]*OpportunityStageChange, error)
+	Summary(tenantID string) (*PipelineSummary, error)
+}
\ No newline at end of file
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"time"
+)
+
+// Price sources, from the most to the least specific
+const (
+	PriceSourceCustomer = "customer"
+	PriceSourceGroup    = "group"
+	PriceSourceGeneral  = "general"
+	PriceSourceProduct  = "product"
+)
+
+// PriceList holds prices in a currency that apply between ValidFrom and ValidTo, both
+// inclusive and open-ended when empty. A price list assigned to customers or customer
+// groups applies to them only; one without assignments applies to every customer.
+// DiscountPercent applies to products the list has no item for.
+type PriceList struct {
+	ID               string          `json:"id"`
+	TenantID         string          `json:"tenant_id"`
+	Code             string          `json:"code"`
+	Name             string          `json:"name"`
+	Currency         string          `json:"currency"`
+	ValidFrom        *time.Time      `json:"valid_from,omitempty"`
+	ValidTo          *time.Time      `json:"valid_to,omitempty"`
+	DiscountPercent  float64         `json:"discount_percent"`
+	Active           bool            `json:"active"`
+	CustomerIDs      []string        `json:"customer_ids"`
+	CustomerGroupIDs []string        `json:"customer_group_ids"`
+	Items            []PriceListItem `json:"items"`
+	CreatedAt        time.Time       `json:"created_at"`
+	UpdatedAt        time.Time       `json:"updated_at"`
+}
+
+// PriceListItem is the price of a product from a minimum quantity in the base unit.
+// Items with higher minimum quantities give quantity breaks. A zero UnitPrice keeps
+// the product's unit price, and DiscountPercent is taken off the price.
+type PriceListItem struct {
+	ID              string    `json:"id"`
+	TenantID        string    `json:"tenant_id"`
+	PriceListID     string    `json:"price_list_id"`
+	ProductID       string    `json:"product_id"`
+	MinQuantity     float64   `json:"min_quantity"`
+	UnitPrice       float64   `json:"unit_price"`
+	DiscountPercent float64   `json:"discount_percent"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// PriceQuery asks for the price of a quantity of a product in the base unit for a
+// customer on a date. An empty currency matches price lists in any currency as long
+// as those that apply all share one currency.
+type PriceQuery struct {
+	CustomerID string    `json:"customer_id"`
+	ProductID  string    `json:"product_id"`
+	Quantity   float64   `json:"quantity"`
+	Date       time.Time `json:"date"`
+	Currency   string    `json:"currency"`
+}
+
+// PriceResolution is the effective price of a product for a price query. Customer
+// price lists take precedence over customer group lists and those over general lists;
+// among lists of the same kind the lowest price wins. Without a list the product's
+// unit price applies.
+type PriceResolution struct {
+	CustomerID      string    `json:"customer_id,omitempty"`
+	ProductID       string    `json:"product_id"`
+	Quantity        float64   `json:"quantity"`
+	Date            time.Time `json:"date"`
+	Currency        string    `json:"currency,omitempty"`
+	Source          string    `json:"source"`
+	PriceListID     string    `json:"price_list_id,omitempty"`
+	PriceListItemID string    `json:"price_list_item_id,omitempty"`
+	ListPrice       float64   `json:"list_price"`
+	DiscountPercent float64   `json:"discount_percent"`
+	UnitPrice       float64   `json:"unit_price"`
+	Total           float64   `json:"total"`
+}
+
+// Pricing errors
+var (
+	ErrPriceListDates       = &SalesError{"Valid from cannot be after valid to"}
+	ErrInvalidDiscount      = &SalesError{"Discount percent must be between 0 and 100"}
+	ErrInvalidPriceListItem = &SalesError{"Price list items cannot have a negative price or minimum quantity"}
+	ErrDuplicatePriceBreak  = &SalesError{"Price list has more than one price for a product and minimum quantity"}
+	ErrPriceCurrency        = &SalesError{"Price lists in more than one currency apply; a currency is required"}
+)
+
+// PriceListService provides methods to interact with price lists and resolves
+// the effective price of products
+type PriceListService interface {
+	Create(priceList *PriceList) error
+	GetByID(tenantID, id string) (*PriceList, error)
+	GetByCode(tenantID, code string) (*PriceList, error)
+	List(tenantID string) ([]*PriceList, error)
+	Update(priceList *PriceList) error
+	Delete(tenantID, id string) error
+	Resolve(tenantID string, query *PriceQuery) (*PriceResolution, error)
+}
\ No newline at end of file
@@ -0,0 +1,187 @@
+package models
+
+import (
+	"time"
+)
+
+// Purchase order statuses
+const (
+	PurchaseOrderDraft             = "draft"
+	PurchaseOrderApproved          = "approved"
+	PurchaseOrderSent              = "sent"
+	PurchaseOrderPartiallyReceived = "partially_received"
+	PurchaseOrderReceived          = "received"
+	PurchaseOrderCancelled         = "cancelled"
+)
+
+// Supplier represents a supplier goods are purchased from
+type Supplier struct {
+	ID        string    `json:"id"`
+	TenantID  string    `json:"tenant_id"`
+	Code      string    `json:"code"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	Phone     string    `json:"phone"`
+	Address   string    `json:"address"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ProductSupplier is a supplier's terms for a product. The purchase price and minimum order
+// quantity are per UnitID, which defaults to the product's base unit, and the price is in
+// Currency. BaseUnitPrice is the purchase price per base unit. A product has at most one
+// preferred supplier.
+type ProductSupplier struct {
+	ID                   string    `json:"id"`
+	TenantID             string    `json:"tenant_id"`
+	ProductID            string    `json:"product_id"`
+	SupplierID           string    `json:"supplier_id"`
+	SupplierCode         string    `json:"supplier_code"`
+	SupplierName         string    `json:"supplier_name"`
+	SupplierItemCode     string    `json:"supplier_item_code"`
+	PurchasePrice        float64   `json:"purchase_price"`
+	Currency             string    `json:"currency"`
+	UnitID               string    `json:"unit_id,omitempty"`
+	BaseUnitPrice        float64   `json:"base_unit_price"`
+	LeadTimeDays         int       `json:"lead_time_days"`
+	MinimumOrderQuantity float64   `json:"minimum_order_quantity"`
+	Preferred            bool      `json:"preferred"`
+	CreatedAt            time.Time `json:"created_at"`
+	UpdatedAt            time.Time `json:"updated_at"`
+}
+
+// PurchaseOrder represents an order placed with a supplier.
+// OverReceiptTolerance is the percentage by which a line may be over-received.
+type PurchaseOrder struct {
+	ID                   string              `json:"id"`
+	TenantID             string              `json:"tenant_id"`
+	SupplierID           string              `json:"supplier_id"`
+	Number               string              `json:"number"`
+	Status               string              `json:"status"`
+	OrderDate            time.Time           `json:"order_date"`
+	ExpectedDate         *time.Time          `json:"expected_date,omitempty"`
+	OverReceiptTolerance float64             `json:"over_receipt_tolerance"`
+	Notes                string              `json:"notes"`
+	Total                float64             `json:"total"`
+	Lines                []PurchaseOrderLine `json:"lines"`
+	CreatedBy            string              `json:"created_by"`
+	ApprovedBy           string              `json:"approved_by,omitempty"`
+	ApprovedAt           *time.Time          `json:"approved_at,omitempty"`
+	SentAt               *time.Time          `json:"sent_at,omitempty"`
+	CreatedAt            time.Time           `json:"created_at"`
+	UpdatedAt            time.Time           `json:"updated_at"`
+}
+
+// PurchaseOrderLine represents a product ordered on a purchase order.
+// Quantities and the unit price are per UnitID, which defaults to the product's purchase unit.
+type PurchaseOrderLine struct {
+	ID                  string    `json:"id"`
+	TenantID            string    `json:"tenant_id"`
+	PurchaseOrderID     string    `json:"purchase_order_id"`
+	LineNumber          int       `json:"line_number"`
+	ProductID           string    `json:"product_id"`
+	LocationID          string    `json:"location_id,omitempty"`
+	UnitID              string    `json:"unit_id,omitempty"`
+	Quantity            float64   `json:"quantity"`
+	UnitPrice           float64   `json:"unit_price"`
+	ReceivedQuantity    float64   `json:"received_quantity"`
+	OutstandingQuantity float64   `json:"outstanding_quantity"`
+	CreatedAt           time.Time `json:"created_at"`
+	UpdatedAt           time.Time `json:"updated_at"`
+}
+
+// GoodsReceipt records goods received against a purchase order
+type GoodsReceipt struct {
+	ID              string             `json:"id"`
+	TenantID        string             `json:"tenant_id"`
+	PurchaseOrderID string             `json:"purchase_order_id"`
+	Reference       string             `json:"reference"`
+	Notes           string             `json:"notes"`
+	Lines           []GoodsReceiptLine `json:"lines"`
+	CreatedBy       string             `json:"created_by"`
+	CreatedAt       time.Time          `json:"created_at"`
+	UpdatedAt       time.Time          `json:"updated_at"`
+}
+
+// GoodsReceiptLine records the quantity received against a purchase order line.
+// LocationID defaults to the location of the purchase order line.
+type GoodsReceiptLine struct {
+	ID                     string     `json:"id"`
+	TenantID               string     `json:"tenant_id"`
+	GoodsReceiptID         string     `json:"goods_receipt_id"`
+	PurchaseOrderLineID    string     `json:"purchase_order_line_id"`
+	ProductID              string     `json:"product_id"`
+	LocationID             string     `json:"location_id,omitempty"`
+	Quantity               float64    `json:"quantity"`
+	LotNumber              string     `json:"lot_number,omitempty"`
+	SerialNumber           string     `json:"serial_number,omitempty"`
+	ExpiryDate             *time.Time `json:"expiry_date,omitempty"`
+	InventoryTransactionID string     `json:"inventory_transaction_id"`
+	CreatedAt              time.Time  `json:"created_at"`
+	UpdatedAt              time.Time  `json:"updated_at"`
+}
+
+// PurchasingError is a purchasing rule violated by a request
+type PurchasingError struct {
+	Message string
+}
+
+func (e *PurchasingError) Error() string {
+	return e.Message
+}
+
+// Purchasing errors
+var (
+	ErrPurchaseOrderStatus  = &PurchasingError{"Purchase order status does not allow this action"}
+	ErrPurchaseOrderLine    = &PurchasingError{"Receipt line does not belong to the purchase order"}
+	ErrOverReceipt          = &PurchasingError{"Receipt exceeds the ordered quantity and tolerance"}
+	ErrInvalidOrderQuantity = &PurchasingError{"Quantity must be positive"}
+	ErrNegativeOrderPrice   = &PurchasingError{"Unit price cannot be negative"}
+	ErrNegativeOverReceipt  = &PurchasingError{"Over-receipt tolerance cannot be negative"}
+	ErrSupplierCurrency     = &PurchasingError{"Suppliers price the product in more than one currency; a currency is required"}
+)
+
+// SupplierService provides methods to interact with suppliers
+type SupplierService interface {
+	Create(supplier *Supplier) error
+	GetByID(tenantID, id string) (*Supplier, error)
+	GetByCode(tenantID, code string) (*Supplier, error)
+	List(tenantID string) ([]*Supplier, error)
+	Update(supplier *Supplier) error
+	Delete(tenantID, id string) error
+}
+
+// ProductSupplierService provides methods to interact with the suppliers of products.
+// Suppliers of a product are listed in rank order: the preferred supplier first, then
+// by price per base unit, lead time and supplier code.
+type ProductSupplierService interface {
+	Create(productSupplier *ProductSupplier) error
+	GetByID(tenantID, id string) (*ProductSupplier, error)
+	GetByProductAndSupplier(tenantID, productID, supplierID string) (*ProductSupplier, error)
+	ListByProduct(tenantID, productID string) ([]*ProductSupplier, error)
+	ListBySupplier(tenantID, supplierID string) ([]*ProductSupplier, error)
+	Rank(tenantID, productID string, quantity float64, currency string) ([]*ProductSupplier, error)
+	Update(productSupplier *ProductSupplier) error
+	Delete(tenantID, id string) error
+}
+
+// PurchaseOrderService provides methods to interact with purchase orders.
+// Only draft orders can be updated or deleted.
+type PurchaseOrderService interface {
+	Create(order *PurchaseOrder) error
+	GetByID(tenantID, id string) (*PurchaseOrder, error)
+	GetByNumber(tenantID, number string) (*PurchaseOrder, error)
+	List(tenantID, status string) ([]*PurchaseOrder, error)
+	Update(order *PurchaseOrder) error
+	Approve(tenantID, id, userID string) error
+	Send(tenantID, id string) error
+	Cancel(tenantID, id string) error
+	Delete(tenantID, id string) error
+}
+
+// GoodsReceiptService provides methods to interact with goods receipts
+type GoodsReceiptService interface {
+	Create(receipt *GoodsReceipt) error
+	GetByID(tenantID, id string) (*GoodsReceipt, error)
+	ListByPurchaseOrder(tenantID, purchaseOrderID string) ([]*GoodsReceipt, error)
+}
\ No newline at end of file
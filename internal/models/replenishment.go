@@ -0,0 +1,76 @@
+package models
+
+import (
+	"time"
+)
+
+// Reorder policies
+const (
+	// ReorderMinMax orders up to MaxQuantity once the position falls to ReorderPoint
+	ReorderMinMax = "min_max"
+	// ReorderFixedQuantity orders multiples of ReorderQuantity once the position falls to ReorderPoint
+	ReorderFixedQuantity = "reorder_point"
+)
+
+// DefaultConsumptionDays is the period average consumption is computed over by default
+const DefaultConsumptionDays = 30
+
+// ReorderRule holds the replenishment settings of a product, either across all
+// stock or, when LocationID is set, for a single warehouse
+type ReorderRule struct {
+	ID              string    `json:"id"`
+	TenantID        string    `json:"tenant_id"`
+	ProductID       string    `json:"product_id"`
+	LocationID      string    `json:"location_id,omitempty"`
+	Policy          string    `json:"policy"`
+	ReorderPoint    float64   `json:"reorder_point"`
+	ReorderQuantity float64   `json:"reorder_quantity"`
+	MaxQuantity     float64   `json:"max_quantity"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// ReplenishmentLine is the replenishment suggestion for a reorder rule.
+// Position is on hand less reserved plus scheduled receipts and open purchase order lines.
+type ReplenishmentLine struct {
+	Rule              *ReorderRule `json:"rule"`
+	ProductCode       string       `json:"product_code"`
+	ProductName       string       `json:"product_name"`
+	OnHand            float64      `json:"on_hand"`
+	Reserved          float64      `json:"reserved"`
+	Inbound           float64      `json:"inbound"`
+	Position          float64      `json:"position"`
+	AverageDailyUsage float64      `json:"average_daily_usage"`
+	DaysOfCover       *float64     `json:"days_of_cover"`
+	NeedsReorder      bool         `json:"needs_reorder"`
+	SuggestedQuantity float64      `json:"suggested_quantity"`
+}
+
+// LowStockAlert is sent when an inventory movement takes a product to or below its reorder point
+type LowStockAlert struct {
+	TenantID      string             `json:"tenant_id"`
+	TransactionID string             `json:"transaction_id"`
+	Line          *ReplenishmentLine `json:"line"`
+}
+
+// ReorderRuleService provides methods to interact with reorder rules
+type ReorderRuleService interface {
+	Create(rule *ReorderRule) error
+	GetByID(tenantID, id string) (*ReorderRule, error)
+	GetByProductAndLocation(tenantID, productID, locationID string) (*ReorderRule, error)
+	List(tenantID string) ([]*ReorderRule, error)
+	Update(rule *ReorderRule) error
+	Delete(tenantID, id string) error
+}
+
+// ReplenishmentService computes replenishment suggestions from stock and average
+// consumption over the last days
+type ReplenishmentService interface {
+	Report(tenantID string, days int) ([]*ReplenishmentLine, error)
+	ReportByProduct(tenantID, productID string, days int) ([]*ReplenishmentLine, error)
+}
+
+// LowStockNotifier is notified of products that fell to or below their reorder point
+type LowStockNotifier interface {
+	NotifyLowStock(alert *LowStockAlert) error
+}
\ No newline at end of file
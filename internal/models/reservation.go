@@ -0,0 +1,83 @@
+package models
+
+import (
+	"time"
+)
+
+// Reservation statuses
+const (
+	ReservationActive    = "active"
+	ReservationReleased  = "released"
+	ReservationFulfilled = "fulfilled"
+)
+
+// Reservation commits a quantity of a product, optionally at a location, to an order.
+// Active reservations stop counting once they expire.
+type Reservation struct {
+	ID           string     `json:"id"`
+	TenantID     string     `json:"tenant_id"`
+	ProductID    string     `json:"product_id"`
+	LocationID   string     `json:"location_id,omitempty"`
+	Quantity     float64    `json:"quantity"`
+	Reference    string     `json:"reference"`
+	Status       string     `json:"status"`
+	RequiredDate *time.Time `json:"required_date,omitempty"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	CreatedBy    string     `json:"created_by"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// ScheduledReceipt is an inbound movement of a product expected on a date
+type ScheduledReceipt struct {
+	ID           string    `json:"id"`
+	TenantID     string    `json:"tenant_id"`
+	ProductID    string    `json:"product_id"`
+	LocationID   string    `json:"location_id,omitempty"`
+	Quantity     float64   `json:"quantity"`
+	ExpectedDate time.Time `json:"expected_date"`
+	Reference    string    `json:"reference"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// AvailabilityLine is the projected stock of a product on a date.
+// AvailableToPromise is the quantity that can still be promised on that
+// date without shorting a later reservation.
+type AvailabilityLine struct {
+	Date               time.Time `json:"date"`
+	Inbound            float64   `json:"inbound"`
+	Reserved           float64   `json:"reserved"`
+	Projected          float64   `json:"projected"`
+	AvailableToPromise float64   `json:"available_to_promise"`
+}
+
+// AvailabilityProjection projects the availability of a product by date
+type AvailabilityProjection struct {
+	ProductID   string              `json:"product_id"`
+	OnHand      float64             `json:"on_hand"`
+	Quarantined float64             `json:"quarantined"`
+	Lines       []*AvailabilityLine `json:"lines"`
+}
+
+// Reservation errors
+var (
+	ErrInsufficientAvailable = &InventoryError{"Insufficient available stock"}
+)
+
+// ReservationService provides methods to interact with stock reservations
+type ReservationService interface {
+	Create(reservation *Reservation) error
+	GetByID(tenantID, id string) (*Reservation, error)
+	ListByProduct(tenantID, productID string) ([]*Reservation, error)
+	UpdateStatus(tenantID, id, status string) error
+	ProjectAvailability(tenantID, productID string) (*AvailabilityProjection, error)
+}
+
+// ScheduledReceiptService provides methods to interact with scheduled receipts
+type ScheduledReceiptService interface {
+	Create(receipt *ScheduledReceipt) error
+	GetByID(tenantID, id string) (*ScheduledReceipt, error)
+	ListByProduct(tenantID, productID string) ([]*ScheduledReceipt, error)
+	Delete(tenantID, id string) error
+}
\ No newline at end of file
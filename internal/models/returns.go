@@ -0,0 +1,106 @@
+package models
+
+import (
+	"time"
+)
+
+// Return authorization statuses
+const (
+	ReturnAuthorized = "authorized"
+	ReturnReceived   = "received"
+	ReturnCredited   = "credited"
+	ReturnCancelled  = "cancelled"
+)
+
+// Return inspection outcomes
+const (
+	ReturnRestock = "restock"
+	ReturnScrap   = "scrap"
+	ReturnRepair  = "repair"
+)
+
+// ReturnAuthorization represents a customer's authorization to return goods,
+// optionally against the shipment they were sent with.
+// CreditNoteID is the journal entry crediting the customer for the return.
+type ReturnAuthorization struct {
+	ID           string       `json:"id"`
+	TenantID     string       `json:"tenant_id"`
+	CustomerID   string       `json:"customer_id"`
+	ShipmentID   string       `json:"shipment_id,omitempty"`
+	Number       string       `json:"number"`
+	Status       string       `json:"status"`
+	Reason       string       `json:"reason"`
+	Notes        string       `json:"notes"`
+	Total        float64      `json:"total"`
+	Lines        []ReturnLine `json:"lines"`
+	CreditNoteID string       `json:"credit_note_id,omitempty"`
+	CreatedBy    string       `json:"created_by"`
+	ReceivedAt   *time.Time   `json:"received_at,omitempty"`
+	CreditedAt   *time.Time   `json:"credited_at,omitempty"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+}
+
+// ReturnLine represents a product returned under a return authorization.
+// Outcome and LocationID are set when the goods are inspected on receipt;
+// InventoryTransactionID books them back into stock and ScrapTransactionID
+// writes scrapped goods off again. Quantities are per UnitID; lines returned
+// against a shipment are in the unit the goods were sold in.
+type ReturnLine struct {
+	ID                     string    `json:"id"`
+	TenantID               string    `json:"tenant_id"`
+	ReturnAuthorizationID  string    `json:"return_authorization_id"`
+	LineNumber             int       `json:"line_number"`
+	ShipmentLineID         string    `json:"shipment_line_id,omitempty"`
+	ProductID              string    `json:"product_id"`
+	UnitID                 string    `json:"unit_id,omitempty"`
+	Quantity               float64   `json:"quantity"`
+	UnitPrice              float64   `json:"unit_price"`
+	LotNumber              string    `json:"lot_number,omitempty"`
+	SerialNumber           string    `json:"serial_number,omitempty"`
+	Outcome                string    `json:"outcome,omitempty"`
+	LocationID             string    `json:"location_id,omitempty"`
+	InventoryTransactionID string    `json:"inventory_transaction_id,omitempty"`
+	ScrapTransactionID     string    `json:"scrap_transaction_id,omitempty"`
+	CreatedAt              time.Time `json:"created_at"`
+	UpdatedAt              time.Time `json:"updated_at"`
+}
+
+// ReturnInspection is the outcome of inspecting a returned line.
+// Restocked goods go to a sellable location, scrapped and repaired goods to a quarantine location.
+type ReturnInspection struct {
+	ReturnLineID string `json:"return_line_id"`
+	Outcome      string `json:"outcome"`
+	LocationID   string `json:"location_id"`
+}
+
+// CreditNote holds the accounts a return's credit note posts to.
+// The sales returns account is debited and the receivable account credited.
+type CreditNote struct {
+	SalesReturnsAccountID string `json:"sales_returns_account_id"`
+	ReceivableAccountID   string `json:"receivable_account_id"`
+}
+
+// Return errors
+var (
+	ErrReturnStatus         = &SalesError{"Return authorization status does not allow this action"}
+	ErrReturnShipment       = &SalesError{"Shipment was not shipped to the customer"}
+	ErrReturnShipmentLine   = &SalesError{"Return line does not belong to the shipment"}
+	ErrOverReturn           = &SalesError{"Return exceeds the quantity shipped"}
+	ErrReturnLine           = &SalesError{"Inspection does not belong to the return authorization"}
+	ErrReturnOutcome        = &SalesError{"Outcome must be restock, scrap or repair"}
+	ErrReturnLocation       = &SalesError{"Restocked goods must go to a sellable location, scrapped and repaired goods to a quarantine location"}
+	ErrIncompleteInspection = &SalesError{"Every return line must be inspected"}
+	ErrNothingToCredit      = &SalesError{"Return authorization has no value to credit"}
+)
+
+// ReturnAuthorizationService provides methods to interact with return authorizations
+type ReturnAuthorizationService interface {
+	Create(rma *ReturnAuthorization) error
+	GetByID(tenantID, id string) (*ReturnAuthorization, error)
+	GetByNumber(tenantID, number string) (*ReturnAuthorization, error)
+	List(tenantID, customerID string) ([]*ReturnAuthorization, error)
+	Receive(tenantID, id, userID string, inspections []ReturnInspection) error
+	Credit(tenantID, id, userID string, creditNote *CreditNote) error
+	Cancel(tenantID, id string) error
+}
\ No newline at end of file
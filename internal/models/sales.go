@@ -0,0 +1,151 @@
+package models
+
+import (
+	"time"
+)
+
+// Sales order statuses
+const (
+	SalesOrderDraft            = "draft"
+	SalesOrderConfirmed        = "confirmed"
+	SalesOrderPicked           = "picked"
+	SalesOrderPartiallyShipped = "partially_shipped"
+	SalesOrderShipped          = "shipped"
+	SalesOrderInvoiced         = "invoiced"
+	SalesOrderCancelled        = "cancelled"
+)
+
+// Shipment statuses
+const (
+	ShipmentDraft   = "draft"
+	ShipmentShipped = "shipped"
+)
+
+// SalesOrder represents an order placed by a customer
+type SalesOrder struct {
+	ID            string           `json:"id"`
+	TenantID      string           `json:"tenant_id"`
+	CustomerID    string           `json:"customer_id"`
+	Number        string           `json:"number"`
+	Status        string           `json:"status"`
+	OrderDate     time.Time        `json:"order_date"`
+	RequestedDate *time.Time       `json:"requested_date,omitempty"`
+	Currency      string           `json:"currency,omitempty"`
+	Notes         string           `json:"notes"`
+	Total         float64          `json:"total"`
+	Lines         []SalesOrderLine `json:"lines"`
+	CreatedBy     string           `json:"created_by"`
+	ConfirmedAt   *time.Time       `json:"confirmed_at,omitempty"`
+	InvoicedAt    *time.Time       `json:"invoiced_at,omitempty"`
+	CreatedAt     time.Time        `json:"created_at"`
+	UpdatedAt     time.Time        `json:"updated_at"`
+}
+
+// SalesOrderLine represents a product ordered on a sales order. Confirmed orders
+// reserve what stock is available; the rest of the unshipped quantity is backordered.
+// Quantities and the unit price are per UnitID, which defaults to the product's sales unit.
+type SalesOrderLine struct {
+	ID                string    `json:"id"`
+	TenantID          string    `json:"tenant_id"`
+	SalesOrderID      string    `json:"sales_order_id"`
+	LineNumber        int       `json:"line_number"`
+	ProductID         string    `json:"product_id"`
+	LocationID        string    `json:"location_id,omitempty"`
+	UnitID            string    `json:"unit_id,omitempty"`
+	Quantity          float64   `json:"quantity"`
+	UnitPrice         float64   `json:"unit_price"`
+	ReservationID     string    `json:"reservation_id,omitempty"`
+	ReservedQuantity  float64   `json:"reserved_quantity"`
+	ShippedQuantity   float64   `json:"shipped_quantity"`
+	BackorderQuantity float64   `json:"backorder_quantity"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// Backorder is a sales order line waiting for stock
+type Backorder struct {
+	SalesOrderNumber string          `json:"sales_order_number"`
+	CustomerID       string          `json:"customer_id"`
+	RequestedDate    *time.Time      `json:"requested_date,omitempty"`
+	Line             *SalesOrderLine `json:"line"`
+}
+
+// Shipment is a shipping document for goods packed against a sales order.
+// Shipping it issues the goods from stock.
+type Shipment struct {
+	ID             string         `json:"id"`
+	TenantID       string         `json:"tenant_id"`
+	SalesOrderID   string         `json:"sales_order_id"`
+	Number         string         `json:"number"`
+	Status         string         `json:"status"`
+	Carrier        string         `json:"carrier"`
+	TrackingNumber string         `json:"tracking_number"`
+	Notes          string         `json:"notes"`
+	Lines          []ShipmentLine `json:"lines"`
+	ShippedAt      *time.Time     `json:"shipped_at,omitempty"`
+	CreatedBy      string         `json:"created_by"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
+
+// ShipmentLine records the quantity shipped against a sales order line.
+// LocationID defaults to the location of the sales order line.
+type ShipmentLine struct {
+	ID                     string    `json:"id"`
+	TenantID               string    `json:"tenant_id"`
+	ShipmentID             string    `json:"shipment_id"`
+	SalesOrderLineID       string    `json:"sales_order_line_id"`
+	ProductID              string    `json:"product_id"`
+	LocationID             string    `json:"location_id,omitempty"`
+	Quantity               float64   `json:"quantity"`
+	LotNumber              string    `json:"lot_number,omitempty"`
+	SerialNumber           string    `json:"serial_number,omitempty"`
+	InventoryTransactionID string    `json:"inventory_transaction_id,omitempty"`
+	CreatedAt              time.Time `json:"created_at"`
+	UpdatedAt              time.Time `json:"updated_at"`
+}
+
+// SalesError is a sales rule violated by a request
+type SalesError struct {
+	Message string
+}
+
+func (e *SalesError) Error() string {
+	return e.Message
+}
+
+// Sales errors
+var (
+	ErrSalesOrderStatus     = &SalesError{"Sales order status does not allow this action"}
+	ErrShipmentStatus       = &SalesError{"Shipment has already been shipped"}
+	ErrSalesOrderLine       = &SalesError{"Shipment line does not belong to the sales order"}
+	ErrOverShipment         = &SalesError{"Shipment exceeds the quantity left to ship"}
+	ErrInvalidSalesQuantity = &SalesError{"Quantity must be positive"}
+	ErrNegativeSalesPrice   = &SalesError{"Unit price cannot be negative"}
+	ErrQuarantineLocation   = &SalesError{"Stock cannot be sold from a quarantine location"}
+)
+
+// SalesOrderService provides methods to interact with sales orders.
+// Only draft orders can be updated or deleted.
+type SalesOrderService interface {
+	Create(order *SalesOrder) error
+	GetByID(tenantID, id string) (*SalesOrder, error)
+	GetByNumber(tenantID, number string) (*SalesOrder, error)
+	List(tenantID, status string) ([]*SalesOrder, error)
+	ListBackorders(tenantID string) ([]*Backorder, error)
+	Update(order *SalesOrder) error
+	Confirm(tenantID, id, userID string) error
+	Allocate(tenantID, id, userID string) error
+	Pick(tenantID, id string) error
+	Invoice(tenantID, id string) error
+	Cancel(tenantID, id string) error
+	Delete(tenantID, id string) error
+}
+
+// ShipmentService provides methods to interact with shipments
+type ShipmentService interface {
+	Create(shipment *Shipment) error
+	GetByID(tenantID, id string) (*Shipment, error)
+	ListBySalesOrder(tenantID, salesOrderID string) ([]*Shipment, error)
+	Ship(tenantID, id, userID string) error
+}
\ No newline at end of file
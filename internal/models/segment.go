@@ -0,0 +1,84 @@
+package models
+
+import (
+	"time"
+)
+
+// Segment rule types
+const (
+	SegmentRuleTag             = "tag"
+	SegmentRuleCustomField     = "custom_field"
+	SegmentRuleLastInteraction = "last_interaction"
+	SegmentRuleRevenue         = "revenue"
+)
+
+// Segment rule operators. Has and not_has apply to tags; within and not_within to the
+// last interaction; the comparisons to custom fields and revenue.
+const (
+	SegmentOpEqual          = "eq"
+	SegmentOpNotEqual       = "ne"
+	SegmentOpGreater        = "gt"
+	SegmentOpGreaterOrEqual = "gte"
+	SegmentOpLess           = "lt"
+	SegmentOpLessOrEqual    = "lte"
+	SegmentOpHas            = "has"
+	SegmentOpNotHas         = "not_has"
+	SegmentOpWithin         = "within"
+	SegmentOpNotWithin      = "not_within"
+)
+
+// Segment matching. A customer is a member of a segment matching all rules when it
+// satisfies every rule, and of one matching any rule when it satisfies at least one.
+const (
+	SegmentMatchAll = "all"
+	SegmentMatchAny = "any"
+)
+
+// SegmentRule is a condition on customers. Key names the custom field of a custom field
+// rule. Days is the number of days of a last interaction rule, and limits a revenue
+// rule to orders of the last days when above 0.
+type SegmentRule struct {
+	Type     string `json:"type"`
+	Operator string `json:"operator"`
+	Key      string `json:"key,omitempty"`
+	Value    string `json:"value,omitempty"`
+	Days     int    `json:"days,omitempty"`
+}
+
+// Segment is a saved set of rules selecting customers. Its members are evaluated when
+// they are listed, so they follow changes to the customers.
+type Segment struct {
+	ID          string        `json:"id"`
+	TenantID    string        `json:"tenant_id"`
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	Match       string        `json:"match"`
+	Rules       []SegmentRule `json:"rules"`
+	CreatedBy   string        `json:"created_by"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+}
+
+// SegmentMember is a customer in a segment with the revenue of its sales orders that
+// are neither draft nor cancelled and the date of its last interaction
+type SegmentMember struct {
+	Customer
+	Revenue           float64    `json:"revenue"`
+	LastInteractionAt *time.Time `json:"last_interaction_at,omitempty"`
+}
+
+// Segment errors
+var (
+	ErrSegmentRule = &CRMError{"Segment rule has an unknown type or operator"}
+)
+
+// SegmentService provides methods to interact with customer segments
+type SegmentService interface {
+	Create(segment *Segment) error
+	GetByID(tenantID, id string) (*Segment, error)
+	GetByName(tenantID, name string) (*Segment, error)
+	List(tenantID string) ([]*Segment, error)
+	Update(segment *Segment) error
+	Delete(tenantID, id string) error
+	ListMembers(tenantID string, segment *Segment) ([]*SegmentMember, error)
+}
\ No newline at end of file
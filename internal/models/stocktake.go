@@ -0,0 +1,122 @@
+package models
+
+import (
+	"time"
+)
+
+// Stocktake statuses
+const (
+	StocktakeOpen      = "open"
+	StocktakeApproved  = "approved"
+	StocktakeCancelled = "cancelled"
+)
+
+// ABC classes of products by consumption value
+const (
+	ABCClassA = "A"
+	ABCClassB = "B"
+	ABCClassC = "C"
+)
+
+// Stocktake is a physical count of the stock held at a location, or of all stock when
+// LocationID is empty. Expected quantities are frozen when the stocktake is created, for
+// every product in stock or only for ProductIDs when they are given. Approving it adjusts
+// stock by the difference between the counted and expected quantities.
+type Stocktake struct {
+	ID            string          `json:"id"`
+	TenantID      string          `json:"tenant_id"`
+	Number        string          `json:"number"`
+	LocationID    string          `json:"location_id,omitempty"`
+	ProductIDs    []string        `json:"product_ids,omitempty"`
+	Status        string          `json:"status"`
+	Notes         string          `json:"notes"`
+	Lines         []StocktakeLine `json:"lines"`
+	CountedLines  int             `json:"counted_lines"`
+	VarianceValue float64         `json:"variance_value"`
+	CreatedBy     string          `json:"created_by"`
+	ApprovedBy    string          `json:"approved_by,omitempty"`
+	ApprovedAt    *time.Time      `json:"approved_at,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+}
+
+// StocktakeLine is the expected and counted quantity of a product, or of a lot or serial
+// number of a tracked product. Variance is the counted less the expected quantity and
+// VarianceValue prices it at the product's average cost when the line was frozen.
+type StocktakeLine struct {
+	ID               string     `json:"id"`
+	TenantID         string     `json:"tenant_id"`
+	StocktakeID      string     `json:"stocktake_id"`
+	LineNumber       int        `json:"line_number"`
+	ProductID        string     `json:"product_id"`
+	LotNumber        string     `json:"lot_number,omitempty"`
+	SerialNumber     string     `json:"serial_number,omitempty"`
+	ExpectedQuantity float64    `json:"expected_quantity"`
+	CountedQuantity  *float64   `json:"counted_quantity"`
+	Variance         float64    `json:"variance"`
+	UnitCost         float64    `json:"unit_cost"`
+	VarianceValue    float64    `json:"variance_value"`
+	TransactionID    string     `json:"transaction_id,omitempty"`
+	CountedBy        string     `json:"counted_by,omitempty"`
+	CountedAt        *time.Time `json:"counted_at,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+// StocktakeCount is a counted quantity entered for a stocktake. The product is given
+// by ID or by code, and tracked products are counted per lot or serial number.
+type StocktakeCount struct {
+	ProductID       string  `json:"product_id"`
+	ProductCode     string  `json:"product_code"`
+	LotNumber       string  `json:"lot_number,omitempty"`
+	SerialNumber    string  `json:"serial_number,omitempty"`
+	CountedQuantity float64 `json:"counted_quantity"`
+}
+
+// CycleCountPolicy sets how often products of each ABC class are counted. Products are
+// ranked by the value issued over the last Days: those making up the first 80% of the
+// value are class A, the next 15% class B and the rest class C.
+type CycleCountPolicy struct {
+	Days           int `json:"days"`
+	ClassAInterval int `json:"class_a_interval"`
+	ClassBInterval int `json:"class_b_interval"`
+	ClassCInterval int `json:"class_c_interval"`
+}
+
+// CycleCountItem is the ABC class of a product and when it is next due to be counted.
+// Products never counted in an approved stocktake are due at once.
+type CycleCountItem struct {
+	ProductID        string     `json:"product_id"`
+	ProductCode      string     `json:"product_code"`
+	ProductName      string     `json:"product_name"`
+	Class            string     `json:"class"`
+	ConsumptionValue float64    `json:"consumption_value"`
+	IntervalDays     int        `json:"interval_days"`
+	LastCountedAt    *time.Time `json:"last_counted_at,omitempty"`
+	NextCountDate    time.Time  `json:"next_count_date"`
+	Due              bool       `json:"due"`
+}
+
+// Stocktake errors
+var (
+	ErrStocktakeStatus     = &InventoryError{"Stocktake status does not allow this action"}
+	ErrStocktakeEmpty      = &InventoryError{"Stocktake has no products to count"}
+	ErrStocktakeNotCounted = &InventoryError{"Every stocktake line must be counted before approval"}
+	ErrStocktakeProduct    = &InventoryError{"Product is not part of the stocktake"}
+	ErrNegativeCount       = &InventoryError{"Counted quantity cannot be negative"}
+	ErrSerialCount         = &InventoryError{"Serial numbers are counted as 0 or 1"}
+)
+
+// StocktakeService provides methods to interact with stocktakes.
+// Counts can only be entered while a stocktake is open.
+type StocktakeService interface {
+	Create(stocktake *Stocktake) error
+	GetByID(tenantID, id string) (*Stocktake, error)
+	GetByNumber(tenantID, number string) (*Stocktake, error)
+	List(tenantID, status string) ([]*Stocktake, error)
+	RecordCounts(tenantID, id, userID string, counts []StocktakeCount) error
+	CountLine(tenantID, id, lineID, userID string, quantity float64) error
+	Approve(tenantID, id, userID string) error
+	Cancel(tenantID, id string) error
+	CycleCountSchedule(tenantID string, policy *CycleCountPolicy) ([]*CycleCountItem, error)
+}
\ No newline at end of file
@@ -4,13 +4,21 @@ import (
 	"time"
 )
 
+// Inventory costing methods
+const (
+	CostingFIFO    = "fifo"
+	CostingAverage = "average"
+)
+
 // Tenant represents a tenant in the system
 type Tenant struct {
-	ID        string    `json:"id"`
-	Name      string    `json:"name"`
-	Subdomain string    `json:"subdomain"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID                 string    `json:"id"`
+	Name               string    `json:"name"`
+	Subdomain          string    `json:"subdomain"`
+	CostingMethod      string    `json:"costing_method"`
+	AllowNegativeStock bool      `json:"allow_negative_stock"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
 }
 
 // TenantService provides methods to interact with tenants
@@ -21,4 +29,5 @@ type TenantService interface {
 	List() ([]*Tenant, error)
 	Update(tenant *Tenant) error
 	Delete(id string) error
+	HasStock(id string) (bool, error)
 }
\ No newline at end of file
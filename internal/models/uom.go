@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+)
+
+// UnitOfMeasure represents a unit products are stocked, bought or sold in, such as each, kg or case
+type UnitOfMeasure struct {
+	ID          string    `json:"id"`
+	TenantID    string    `json:"tenant_id"`
+	Code        string    `json:"code"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// ProductUnit converts a unit of a product to its base unit.
+// Factor is the number of base units in one UnitID, e.g. 12 for a case of 12.
+type ProductUnit struct {
+	ID        string    `json:"id"`
+	TenantID  string    `json:"tenant_id"`
+	ProductID string    `json:"product_id"`
+	UnitID    string    `json:"unit_id"`
+	Factor    float64   `json:"factor"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// UnitOfMeasureService provides methods to interact with units of measure
+type UnitOfMeasureService interface {
+	Create(unit *UnitOfMeasure) error
+	GetByID(tenantID, id string) (*UnitOfMeasure, error)
+	GetByCode(tenantID, code string) (*UnitOfMeasure, error)
+	List(tenantID string) ([]*UnitOfMeasure, error)
+	Update(unit *UnitOfMeasure) error
+	Delete(tenantID, id string) error
+}
+
+// ProductUnitService provides methods to interact with product unit conversions
+type ProductUnitService interface {
+	Create(productUnit *ProductUnit) error
+	GetByID(tenantID, id string) (*ProductUnit, error)
+	GetByProductAndUnit(tenantID, productID, unitID string) (*ProductUnit, error)
+	ListByProduct(tenantID, productID string) ([]*ProductUnit, error)
+	Update(productUnit *ProductUnit) error
+	Delete(tenantID, id string) error
+}
\ No newline at end of file
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+)
+
+// ProductTemplate groups product variants that differ only in their attribute values,
+// such as a T-shirt sold in several sizes and colors. Each variant is a Product with its
+// own code, price and stock; new variants start with the template's description, price,
+// base unit and tracking mode. StockQuantity is the stock of the variants listed.
+type ProductTemplate struct {
+	ID            string             `json:"id"`
+	TenantID      string             `json:"tenant_id"`
+	Code          string             `json:"code"`
+	Name          string             `json:"name"`
+	Description   string             `json:"description"`
+	UnitPrice     float64            `json:"unit_price"`
+	BaseUnitID    string             `json:"base_unit_id,omitempty"`
+	TrackingMode  string             `json:"tracking_mode"`
+	Attributes    []ProductAttribute `json:"attributes"`
+	Variants      []*Product         `json:"variants"`
+	StockQuantity float64            `json:"stock_quantity"`
+	CreatedAt     time.Time          `json:"created_at"`
+	UpdatedAt     time.Time          `json:"updated_at"`
+}
+
+// ProductAttribute is an attribute the variants of a template differ by, such as size,
+// with the values it can take in display order
+type ProductAttribute struct {
+	ID         string   `json:"id"`
+	TenantID   string   `json:"tenant_id"`
+	TemplateID string   `json:"template_id"`
+	Name       string   `json:"name"`
+	Position   int      `json:"position"`
+	Values     []string `json:"values"`
+}
+
+// Product variant errors
+var (
+	ErrVariantAttributes   = &InventoryError{"Variant must have one allowed value for each template attribute"}
+	ErrDuplicateVariant    = &InventoryError{"Template already has a variant with these attribute values"}
+	ErrVariantCode         = &InventoryError{"A product with the variant code already exists"}
+	ErrAttributeValueInUse = &InventoryError{"Attribute value is used by a variant"}
+	ErrTemplateAttributes  = &InventoryError{"Attributes cannot be added to a template with variants"}
+	ErrTemplateHasVariants = &InventoryError{"Template has variants"}
+)
+
+// ProductTemplateService provides methods to interact with product templates and their variants.
+// Filters match variants by attribute name and value; templates without a matching variant are left out.
+type ProductTemplateService interface {
+	Create(template *ProductTemplate) error
+	GetByID(tenantID, id string) (*ProductTemplate, error)
+	GetByCode(tenantID, code string) (*ProductTemplate, error)
+	List(tenantID string, filter map[string]string) ([]*ProductTemplate, error)
+	Update(template *ProductTemplate) error
+	Delete(tenantID, id string) error
+	CreateVariant(product *Product) error
+	GenerateVariants(tenantID, id string) ([]*Product, error)
+	ListVariants(tenantID, id string, filter map[string]string) ([]*Product, error)
+}
\ No newline at end of file
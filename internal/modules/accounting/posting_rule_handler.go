@@ -0,0 +1,232 @@
+package accounting
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/yookibooki/erp/internal/auth"
+	"github.com/yookibooki/erp/internal/models"
+)
+
+// PostingRuleHandler handles posting rule requests
+type PostingRuleHandler struct {
+	postingRuleService models.PostingRuleService
+	accountService     models.AccountService
+	categoryService    models.ProductCategoryService
+}
+
+// NewPostingRuleHandler creates a new posting rule handler
+func NewPostingRuleHandler(
+	postingRuleService models.PostingRuleService,
+	accountService models.AccountService,
+	categoryService models.ProductCategoryService,
+) *PostingRuleHandler {
+	return &PostingRuleHandler{
+		postingRuleService: postingRuleService,
+		accountService:     accountService,
+		categoryService:    categoryService,
+	}
+}
+
+// GetPostingRule gets a posting rule by ID
+func (h *PostingRuleHandler) GetPostingRule(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	rule, err := h.postingRuleService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error getting posting rule")
+		return
+	}
+
+	if rule == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Posting rule not found")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, rule)
+}
+
+// ListPostingRules lists all posting rules for a tenant
+func (h *PostingRuleHandler) ListPostingRules(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	rules, err := h.postingRuleService.List(tenantID)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error listing posting rules")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, rules)
+}
+
+// CreatePostingRule creates a new posting rule
+func (h *PostingRuleHandler) CreatePostingRule(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	var rule models.PostingRule
+	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	// Set tenant ID from context
+	rule.TenantID = tenantID
+
+	if !h.validatePostingRule(w, &rule) {
+		return
+	}
+
+	// Check if a rule already exists for the transaction type, category and reason code
+	existingRule, err := h.postingRuleService.GetByScope(tenantID, rule.TransactionType, rule.CategoryID, rule.ReasonCode)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking posting rule")
+		return
+	}
+
+	if existingRule != nil {
+		auth.RespondWithError(w, http.StatusConflict, "Posting rule for this transaction type, category and reason code already exists")
+		return
+	}
+
+	// Create posting rule
+	if err := h.postingRuleService.Create(&rule); err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error creating posting rule")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusCreated, rule)
+}
+
+// UpdatePostingRule updates a posting rule
+func (h *PostingRuleHandler) UpdatePostingRule(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	var rule models.PostingRule
+	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	// Set ID and tenant ID
+	rule.ID = id
+	rule.TenantID = tenantID
+
+	if !h.validatePostingRule(w, &rule) {
+		return
+	}
+
+	// Check if posting rule exists
+	existingRule, err := h.postingRuleService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking posting rule")
+		return
+	}
+
+	if existingRule == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Posting rule not found")
+		return
+	}
+
+	// Check if another rule exists for the transaction type, category and reason code
+	conflictingRule, err := h.postingRuleService.GetByScope(tenantID, rule.TransactionType, rule.CategoryID, rule.ReasonCode)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking posting rule")
+		return
+	}
+
+	if conflictingRule != nil && conflictingRule.ID != id {
+		auth.RespondWithError(w, http.StatusConflict, "Posting rule for this transaction type, category and reason code already exists")
+		return
+	}
+
+	// Update posting rule
+	if err := h.postingRuleService.Update(&rule); err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error updating posting rule")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, rule)
+}
+
+// DeletePostingRule deletes a posting rule
+func (h *PostingRuleHandler) DeletePostingRule(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	// Check if posting rule exists
+	existingRule, err := h.postingRuleService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking posting rule")
+		return
+	}
+
+	if existingRule == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Posting rule not found")
+		return
+	}
+
+	// Delete posting rule
+	if err := h.postingRuleService.Delete(tenantID, id); err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error deleting posting rule")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Posting rule deleted successfully"})
+}
+
+// validatePostingRule validates a posting rule and responds with an error if it is invalid
+func (h *PostingRuleHandler) validatePostingRule(w http.ResponseWriter, rule *models.PostingRule) bool {
+	if rule.TransactionType == "" || rule.InventoryAccountID == "" || rule.CounterAccountID == "" {
+		auth.RespondWithError(w, http.StatusBadRequest, "Transaction type, inventory account ID and counter account ID are required")
+		return false
+	}
+
+	// Reason codes only distinguish adjustments
+	if rule.ReasonCode != "" {
+		switch rule.ReasonCode {
+		case models.ReasonDamaged, models.ReasonLost, models.ReasonFound, models.ReasonExpired,
+			models.ReasonCount, models.ReasonWriteOff, models.ReasonOther:
+		default:
+			auth.RespondWithError(w, http.StatusBadRequest, "Reason code must be DAMAGED, LOST, FOUND, EXPIRED, COUNT, WRITE_OFF or OTHER")
+			return false
+		}
+		if rule.TransactionType != models.TransactionTypeAdjustment {
+			auth.RespondWithError(w, http.StatusBadRequest, "Reason codes only apply to ADJUSTMENT posting rules")
+			return false
+		}
+	}
+
+	if rule.CategoryID != "" {
+		category, err := h.categoryService.GetByID(rule.TenantID, rule.CategoryID)
+		if err != nil {
+			auth.RespondWithError(w, http.StatusInternalServerError, "Error checking category")
+			return false
+		}
+
+		if category == nil {
+			auth.RespondWithError(w, http.StatusNotFound, "Category not found")
+			return false
+		}
+	}
+
+	for _, accountID := range []string{rule.InventoryAccountID, rule.CounterAccountID} {
+		account, err := h.accountService.GetByID(rule.TenantID, accountID)
+		if err != nil {
+			auth.RespondWithError(w, http.StatusInternalServerError, "Error checking account")
+			return false
+		}
+
+		if account == nil {
+			auth.RespondWithError(w, http.StatusNotFound, "Account not found")
+			return false
+		}
+	}
+
+	return true
+}
\ No newline at end of file
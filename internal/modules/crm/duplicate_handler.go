@@ -0,0 +1,190 @@
+package crm
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/yookibooki/erp/internal/auth"
+	"github.com/yookibooki/erp/internal/models"
+)
+
+// defaultMinDuplicateScore is the score pairs must reach to be listed as duplicates
+// when no min_score is given
+const defaultMinDuplicateScore = 0.6
+
+// DuplicateHandler handles duplicate review and merge requests
+type DuplicateHandler struct {
+	duplicateService models.DuplicateService
+	customerService  models.CustomerService
+	contactService   models.ContactService
+}
+
+// NewDuplicateHandler creates a new duplicate handler
+func NewDuplicateHandler(
+	duplicateService models.DuplicateService,
+	customerService models.CustomerService,
+	contactService models.ContactService,
+) *DuplicateHandler {
+	return &DuplicateHandler{
+		duplicateService: duplicateService,
+		customerService:  customerService,
+		contactService:   contactService,
+	}
+}
+
+// ListCustomerDuplicates lists the pairs of customers that may be the same, scoring at
+// least the min_score query parameter
+func (h *DuplicateHandler) ListCustomerDuplicates(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	minScore, ok := parseMinScore(w, r)
+	if !ok {
+		return
+	}
+
+	pairs, err := h.duplicateService.FindCustomerDuplicates(tenantID, minScore)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error finding duplicate customers")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, pairs)
+}
+
+// ListContactDuplicates lists the pairs of contacts of the same customer that may be the
+// same person, scoring at least the min_score query parameter
+func (h *DuplicateHandler) ListContactDuplicates(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	minScore, ok := parseMinScore(w, r)
+	if !ok {
+		return
+	}
+
+	pairs, err := h.duplicateService.FindContactDuplicates(tenantID, minScore)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error finding duplicate contacts")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, pairs)
+}
+
+// MergeCustomer merges the customer given by duplicate_id in the body into the customer
+// and responds with the surviving customer
+func (h *DuplicateHandler) MergeCustomer(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	duplicateID, ok := decodeDuplicateID(w, r)
+	if !ok {
+		return
+	}
+
+	// Check if both customers exist
+	for _, customerID := range []string{id, duplicateID} {
+		customer, err := h.customerService.GetByID(tenantID, customerID)
+		if err != nil {
+			auth.RespondWithError(w, http.StatusInternalServerError, "Error checking customer")
+			return
+		}
+
+		if customer == nil {
+			auth.RespondWithError(w, http.StatusNotFound, "Customer not found")
+			return
+		}
+	}
+
+	// Merge customers
+	if err := h.duplicateService.MergeCustomers(tenantID, id, duplicateID); err != nil {
+		respondWithCRMError(w, err, "Error merging customers")
+		return
+	}
+
+	customer, err := h.customerService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error getting customer")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, customer)
+}
+
+// MergeContact merges the contact given by duplicate_id in the body into the contact and
+// responds with the surviving contact
+func (h *DuplicateHandler) MergeContact(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	duplicateID, ok := decodeDuplicateID(w, r)
+	if !ok {
+		return
+	}
+
+	// Check if both contacts exist
+	for _, contactID := range []string{id, duplicateID} {
+		contact, err := h.contactService.GetByID(tenantID, contactID)
+		if err != nil {
+			auth.RespondWithError(w, http.StatusInternalServerError, "Error checking contact")
+			return
+		}
+
+		if contact == nil {
+			auth.RespondWithError(w, http.StatusNotFound, "Contact not found")
+			return
+		}
+	}
+
+	// Merge contacts
+	if err := h.duplicateService.MergeContacts(tenantID, id, duplicateID); err != nil {
+		respondWithCRMError(w, err, "Error merging contacts")
+		return
+	}
+
+	contact, err := h.contactService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error getting contact")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, contact)
+}
+
+// parseMinScore reads the min_score query parameter and responds with an error if it is invalid
+func parseMinScore(w http.ResponseWriter, r *http.Request) (float64, bool) {
+	value := r.URL.Query().Get("min_score")
+	if value == "" {
+		return defaultMinDuplicateScore, true
+	}
+
+	minScore, err := strconv.ParseFloat(value, 64)
+	if err != nil || minScore <= 0 || minScore > 1 {
+		auth.RespondWithError(w, http.StatusBadRequest, "Minimum score must be a number above 0 and at most 1")
+		return 0, false
+	}
+
+	return minScore, true
+}
+
+// decodeDuplicateID reads the duplicate_id of a merge request and responds with an error
+// if it is missing
+func decodeDuplicateID(w http.ResponseWriter, r *http.Request) (string, bool) {
+	var request struct {
+		DuplicateID string `json:"duplicate_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return "", false
+	}
+
+	if request.DuplicateID == "" {
+		auth.RespondWithError(w, http.StatusBadRequest, "Duplicate ID is required")
+		return "", false
+	}
+
+	return request.DuplicateID, true
+}
\ No newline at end of file
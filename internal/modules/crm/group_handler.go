@@ -0,0 +1,179 @@
+package crm
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/yookibooki/erp/internal/auth"
+	"github.com/yookibooki/erp/internal/models"
+)
+
+// CustomerGroupHandler handles customer group requests
+type CustomerGroupHandler struct {
+	groupService models.CustomerGroupService
+}
+
+// NewCustomerGroupHandler creates a new customer group handler
+func NewCustomerGroupHandler(groupService models.CustomerGroupService) *CustomerGroupHandler {
+	return &CustomerGroupHandler{
+		groupService: groupService,
+	}
+}
+
+// GetCustomerGroup gets a customer group by ID
+func (h *CustomerGroupHandler) GetCustomerGroup(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	group, err := h.groupService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error getting customer group")
+		return
+	}
+
+	if group == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Customer group not found")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, group)
+}
+
+// ListCustomerGroups lists all customer groups for a tenant
+func (h *CustomerGroupHandler) ListCustomerGroups(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	groups, err := h.groupService.List(tenantID)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error listing customer groups")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, groups)
+}
+
+// CreateCustomerGroup creates a new customer group
+func (h *CustomerGroupHandler) CreateCustomerGroup(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	var group models.CustomerGroup
+	if err := json.NewDecoder(r.Body).Decode(&group); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	// Set tenant ID from context
+	group.TenantID = tenantID
+
+	// Validate customer group
+	if group.Name == "" {
+		auth.RespondWithError(w, http.StatusBadRequest, "Name is required")
+		return
+	}
+
+	// Check if customer group already exists
+	existingGroup, err := h.groupService.GetByName(tenantID, group.Name)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking customer group")
+		return
+	}
+
+	if existingGroup != nil {
+		auth.RespondWithError(w, http.StatusConflict, "Customer group with this name already exists")
+		return
+	}
+
+	// Create customer group
+	if err := h.groupService.Create(&group); err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error creating customer group")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusCreated, group)
+}
+
+// UpdateCustomerGroup updates a customer group
+func (h *CustomerGroupHandler) UpdateCustomerGroup(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	var group models.CustomerGroup
+	if err := json.NewDecoder(r.Body).Decode(&group); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	// Set ID and tenant ID
+	group.ID = id
+	group.TenantID = tenantID
+
+	// Validate customer group
+	if group.Name == "" {
+		auth.RespondWithError(w, http.StatusBadRequest, "Name is required")
+		return
+	}
+
+	// Check if customer group exists
+	existingGroup, err := h.groupService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking customer group")
+		return
+	}
+
+	if existingGroup == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Customer group not found")
+		return
+	}
+
+	// Check if another customer group has the name
+	conflictingGroup, err := h.groupService.GetByName(tenantID, group.Name)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking customer group")
+		return
+	}
+
+	if conflictingGroup != nil && conflictingGroup.ID != id {
+		auth.RespondWithError(w, http.StatusConflict, "Customer group with this name already exists")
+		return
+	}
+
+	group.CreatedAt = existingGroup.CreatedAt
+
+	// Update customer group
+	if err := h.groupService.Update(&group); err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error updating customer group")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, group)
+}
+
+// DeleteCustomerGroup deletes a customer group
+func (h *CustomerGroupHandler) DeleteCustomerGroup(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	// Check if customer group exists
+	existingGroup, err := h.groupService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking customer group")
+		return
+	}
+
+	if existingGroup == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Customer group not found")
+		return
+	}
+
+	// Delete customer group
+	if err := h.groupService.Delete(tenantID, id); err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error deleting customer group")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Customer group deleted successfully"})
+}
\ No newline at end of file
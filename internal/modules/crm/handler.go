@@ -3,26 +3,35 @@ package crm
 import (
 	"encoding/json"
 	"net/http"
+	"sort"
+	"strings"
 
 	"github.com/gorilla/mux"
 	"github.com/yookibooki/erp/internal/auth"
+	"github.com/yookibooki/erp/internal/customfield"
 	"github.com/yookibooki/erp/internal/models"
 )
 
 // CustomerHandler handles customer requests
 type CustomerHandler struct {
-	customerService models.CustomerService
-	contactService  models.ContactService
+	customerService    models.CustomerService
+	contactService     models.ContactService
+	groupService       models.CustomerGroupService
+	customFieldService models.CustomFieldService
 }
 
 // NewCustomerHandler creates a new customer handler
 func NewCustomerHandler(
 	customerService models.CustomerService,
 	contactService models.ContactService,
+	groupService models.CustomerGroupService,
+	customFieldService models.CustomFieldService,
 ) *CustomerHandler {
 	return &CustomerHandler{
-		customerService: customerService,
-		contactService:  contactService,
+		customerService:    customerService,
+		contactService:     contactService,
+		groupService:       groupService,
+		customFieldService: customFieldService,
 	}
 }
 
@@ -44,7 +53,7 @@ func (h *CustomerHandler) GetCustomer(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get contacts for customer
-	contacts, err := h.contactService.ListByCustomer(tenantID, id)
+	contacts, err := h.contactService.ListByCustomer(tenantID, id, models.ContactFilter{})
 	if err != nil {
 		auth.RespondWithError(w, http.StatusInternalServerError, "Error getting contacts")
 		return
@@ -59,11 +68,16 @@ func (h *CustomerHandler) GetCustomer(w http.ResponseWriter, r *http.Request) {
 	auth.RespondWithJSON(w, http.StatusOK, customer)
 }
 
-// ListCustomers lists all customers for a tenant
+// ListCustomers lists all customers for a tenant, optionally filtered by the tag query
+// parameter and by custom field values given as cf.<key> query parameters
 func (h *CustomerHandler) ListCustomers(w http.ResponseWriter, r *http.Request) {
 	tenantID := auth.GetTenantIDFromContext(r.Context())
+	params := r.URL.Query()
 
-	customers, err := h.customerService.List(tenantID)
+	customers, err := h.customerService.List(tenantID, models.CustomerFilter{
+		Tag:          strings.ToLower(strings.TrimSpace(params.Get("tag"))),
+		CustomFields: customfield.ParseFilter(params),
+	})
 	if err != nil {
 		auth.RespondWithError(w, http.StatusInternalServerError, "Error listing customers")
 		return
@@ -85,9 +99,15 @@ func (h *CustomerHandler) CreateCustomer(w http.ResponseWriter, r *http.Request)
 	// Set tenant ID from context
 	customer.TenantID = tenantID
 
-	// Validate customer
-	if customer.Name == "" {
-		auth.RespondWithError(w, http.StatusBadRequest, "Name is required")
+	if !h.validateCustomer(w, &customer) {
+		return
+	}
+
+	if !normalizeTags(w, &customer.Tags) {
+		return
+	}
+
+	if !checkCustomFields(w, h.customFieldService, tenantID, models.CustomFieldCustomer, customer.CustomFields) {
 		return
 	}
 
@@ -116,9 +136,7 @@ func (h *CustomerHandler) UpdateCustomer(w http.ResponseWriter, r *http.Request)
 	customer.ID = id
 	customer.TenantID = tenantID
 
-	// Validate customer
-	if customer.Name == "" {
-		auth.RespondWithError(w, http.StatusBadRequest, "Name is required")
+	if !h.validateCustomer(w, &customer) {
 		return
 	}
 
@@ -134,6 +152,22 @@ func (h *CustomerHandler) UpdateCustomer(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// Keep the tags and custom fields unless new ones are given
+	if customer.Tags == nil {
+		customer.Tags = existingCustomer.Tags
+	}
+	if customer.CustomFields == nil {
+		customer.CustomFields = existingCustomer.CustomFields
+	}
+
+	if !normalizeTags(w, &customer.Tags) {
+		return
+	}
+
+	if !checkCustomFields(w, h.customFieldService, tenantID, models.CustomFieldCustomer, customer.CustomFields) {
+		return
+	}
+
 	// Update customer
 	if err := h.customerService.Update(&customer); err != nil {
 		auth.RespondWithError(w, http.StatusInternalServerError, "Error updating customer")
@@ -170,20 +204,47 @@ func (h *CustomerHandler) DeleteCustomer(w http.ResponseWriter, r *http.Request)
 	auth.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Customer deleted successfully"})
 }
 
+// validateCustomer validates a customer and responds with an error if it is invalid
+func (h *CustomerHandler) validateCustomer(w http.ResponseWriter, customer *models.Customer) bool {
+	if customer.Name == "" {
+		auth.RespondWithError(w, http.StatusBadRequest, "Name is required")
+		return false
+	}
+
+	// Check if customer group exists
+	if customer.GroupID != "" {
+		group, err := h.groupService.GetByID(customer.TenantID, customer.GroupID)
+		if err != nil {
+			auth.RespondWithError(w, http.StatusInternalServerError, "Error checking customer group")
+			return false
+		}
+
+		if group == nil {
+			auth.RespondWithError(w, http.StatusNotFound, "Customer group not found")
+			return false
+		}
+	}
+
+	return true
+}
+
 // ContactHandler handles contact requests
 type ContactHandler struct {
-	contactService models.ContactService
-	customerService models.CustomerService
+	contactService     models.ContactService
+	customerService    models.CustomerService
+	customFieldService models.CustomFieldService
 }
 
 // NewContactHandler creates a new contact handler
 func NewContactHandler(
 	contactService models.ContactService,
 	customerService models.CustomerService,
+	customFieldService models.CustomFieldService,
 ) *ContactHandler {
 	return &ContactHandler{
-		contactService: contactService,
-		customerService: customerService,
+		contactService:     contactService,
+		customerService:    customerService,
+		customFieldService: customFieldService,
 	}
 }
 
@@ -207,13 +268,18 @@ func (h *ContactHandler) GetContact(w http.ResponseWriter, r *http.Request) {
 	auth.RespondWithJSON(w, http.StatusOK, contact)
 }
 
-// ListContactsByCustomer lists all contacts for a customer
+// ListContactsByCustomer lists all contacts for a customer, optionally filtered by the
+// tag query parameter and by custom field values given as cf.<key> query parameters
 func (h *ContactHandler) ListContactsByCustomer(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	customerID := vars["customerId"]
 	tenantID := auth.GetTenantIDFromContext(r.Context())
+	params := r.URL.Query()
 
-	contacts, err := h.contactService.ListByCustomer(tenantID, customerID)
+	contacts, err := h.contactService.ListByCustomer(tenantID, customerID, models.ContactFilter{
+		Tag:          strings.ToLower(strings.TrimSpace(params.Get("tag"))),
+		CustomFields: customfield.ParseFilter(params),
+	})
 	if err != nil {
 		auth.RespondWithError(w, http.StatusInternalServerError, "Error listing contacts")
 		return
@@ -253,6 +319,14 @@ func (h *ContactHandler) CreateContact(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !normalizeTags(w, &contact.Tags) {
+		return
+	}
+
+	if !checkCustomFields(w, h.customFieldService, tenantID, models.CustomFieldContact, contact.CustomFields) {
+		return
+	}
+
 	// Create contact
 	if err := h.contactService.Create(&contact); err != nil {
 		auth.RespondWithError(w, http.StatusInternalServerError, "Error creating contact")
@@ -296,6 +370,22 @@ func (h *ContactHandler) UpdateContact(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Keep the tags and custom fields unless new ones are given
+	if contact.Tags == nil {
+		contact.Tags = existingContact.Tags
+	}
+	if contact.CustomFields == nil {
+		contact.CustomFields = existingContact.CustomFields
+	}
+
+	if !normalizeTags(w, &contact.Tags) {
+		return
+	}
+
+	if !checkCustomFields(w, h.customFieldService, tenantID, models.CustomFieldContact, contact.CustomFields) {
+		return
+	}
+
 	// Update contact
 	if err := h.contactService.Update(&contact); err != nil {
 		auth.RespondWithError(w, http.StatusInternalServerError, "Error updating contact")
@@ -336,16 +426,19 @@ func (h *ContactHandler) DeleteContact(w http.ResponseWriter, r *http.Request) {
 type InteractionHandler struct {
 	interactionService models.InteractionService
 	customerService    models.CustomerService
+	customFieldService models.CustomFieldService
 }
 
 // NewInteractionHandler creates a new interaction handler
 func NewInteractionHandler(
 	interactionService models.InteractionService,
 	customerService models.CustomerService,
+	customFieldService models.CustomFieldService,
 ) *InteractionHandler {
 	return &InteractionHandler{
 		interactionService: interactionService,
 		customerService:    customerService,
+		customFieldService: customFieldService,
 	}
 }
 
@@ -369,13 +462,14 @@ func (h *InteractionHandler) GetInteraction(w http.ResponseWriter, r *http.Reque
 	auth.RespondWithJSON(w, http.StatusOK, interaction)
 }
 
-// ListInteractionsByCustomer lists all interactions for a customer
+// ListInteractionsByCustomer lists all interactions for a customer, optionally filtered
+// by custom field values given as cf.<key> query parameters
 func (h *InteractionHandler) ListInteractionsByCustomer(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	customerID := vars["customerId"]
 	tenantID := auth.GetTenantIDFromContext(r.Context())
 
-	interactions, err := h.interactionService.ListByCustomer(tenantID, customerID)
+	interactions, err := h.interactionService.ListByCustomer(tenantID, customerID, customfield.ParseFilter(r.URL.Query()))
 	if err != nil {
 		auth.RespondWithError(w, http.StatusInternalServerError, "Error listing interactions")
 		return
@@ -417,6 +511,10 @@ func (h *InteractionHandler) CreateInteraction(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	if !checkCustomFields(w, h.customFieldService, tenantID, models.CustomFieldInteraction, interaction.CustomFields) {
+		return
+	}
+
 	// Create interaction
 	if err := h.interactionService.Create(&interaction); err != nil {
 		auth.RespondWithError(w, http.StatusInternalServerError, "Error creating interaction")
@@ -460,6 +558,15 @@ func (h *InteractionHandler) UpdateInteraction(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	// Keep the custom fields unless new ones are given
+	if interaction.CustomFields == nil {
+		interaction.CustomFields = existingInteraction.CustomFields
+	}
+
+	if !checkCustomFields(w, h.customFieldService, tenantID, models.CustomFieldInteraction, interaction.CustomFields) {
+		return
+	}
+
 	// Update interaction
 	if err := h.interactionService.Update(&interaction); err != nil {
 		auth.RespondWithError(w, http.StatusInternalServerError, "Error updating interaction")
@@ -494,4 +601,51 @@ func (h *InteractionHandler) DeleteInteraction(w http.ResponseWriter, r *http.Re
 	}
 
 	auth.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Interaction deleted successfully"})
+}
+
+// checkCustomFields validates the custom field values of a record against the tenant's
+// definitions for its type and responds with an error if they are invalid
+func checkCustomFields(
+	w http.ResponseWriter,
+	customFieldService models.CustomFieldService,
+	tenantID, entityType string,
+	fields models.CustomFields,
+) bool {
+	definitions, err := customFieldService.List(tenantID, entityType)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking custom fields")
+		return false
+	}
+
+	if err := customfield.Validate(definitions, fields); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return false
+	}
+
+	return true
+}
+
+// maxTagLength is the longest tag accepted on a customer or contact
+const maxTagLength = 50
+
+// normalizeTags trims and lowercases tags, removes duplicates and sorts them, and
+// responds with an error if a tag is empty or too long
+func normalizeTags(w http.ResponseWriter, tags *[]string) bool {
+	seen := make(map[string]bool, len(*tags))
+	normalized := []string{}
+	for _, tag := range *tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || len(tag) > maxTagLength {
+			auth.RespondWithError(w, http.StatusBadRequest, "Tags must be between 1 and 50 characters")
+			return false
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+
+	sort.Strings(normalized)
+	*tags = normalized
+	return true
 }
\ No newline at end of file
@@ -0,0 +1,784 @@
+package crm
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/yookibooki/erp/internal/auth"
+	"github.com/yookibooki/erp/internal/models"
+)
+
+// PipelineStageHandler handles pipeline stage requests
+type PipelineStageHandler struct {
+	stageService models.PipelineStageService
+}
+
+// NewPipelineStageHandler creates a new pipeline stage handler
+func NewPipelineStageHandler(stageService models.PipelineStageService) *PipelineStageHandler {
+	return &PipelineStageHandler{
+		stageService: stageService,
+	}
+}
+
+// GetStage gets a pipeline stage by ID
+func (h *PipelineStageHandler) GetStage(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	stage := h.findStage(w, tenantID, id)
+	if stage == nil {
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, stage)
+}
+
+// ListStages lists the pipeline stages of a tenant by position
+func (h *PipelineStageHandler) ListStages(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	stages, err := h.stageService.List(tenantID)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error listing pipeline stages")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, stages)
+}
+
+// CreateStage creates a new pipeline stage
+func (h *PipelineStageHandler) CreateStage(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	var stage models.PipelineStage
+	if err := json.NewDecoder(r.Body).Decode(&stage); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	// Set tenant ID from context
+	stage.TenantID = tenantID
+
+	if !h.validateStage(w, &stage) {
+		return
+	}
+
+	// Create pipeline stage
+	if err := h.stageService.Create(&stage); err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error creating pipeline stage")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusCreated, stage)
+}
+
+// UpdateStage updates a pipeline stage
+func (h *PipelineStageHandler) UpdateStage(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	var stage models.PipelineStage
+	if err := json.NewDecoder(r.Body).Decode(&stage); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	existingStage := h.findStage(w, tenantID, id)
+	if existingStage == nil {
+		return
+	}
+
+	// Set ID and tenant ID
+	stage.ID = id
+	stage.TenantID = tenantID
+	stage.CreatedAt = existingStage.CreatedAt
+
+	if !h.validateStage(w, &stage) {
+		return
+	}
+
+	// Update pipeline stage
+	if err := h.stageService.Update(&stage); err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error updating pipeline stage")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, stage)
+}
+
+// DeleteStage deletes a pipeline stage that no opportunity is or has been in
+func (h *PipelineStageHandler) DeleteStage(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	if h.findStage(w, tenantID, id) == nil {
+		return
+	}
+
+	// Delete pipeline stage
+	if err := h.stageService.Delete(tenantID, id); err != nil {
+		respondWithCRMError(w, err, "Error deleting pipeline stage")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Pipeline stage deleted successfully"})
+}
+
+// findStage gets a pipeline stage and responds with an error if it cannot be found
+func (h *PipelineStageHandler) findStage(w http.ResponseWriter, tenantID, id string) *models.PipelineStage {
+	stage, err := h.stageService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error getting pipeline stage")
+		return nil
+	}
+
+	if stage == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Pipeline stage not found")
+		return nil
+	}
+
+	return stage
+}
+
+// validateStage validates a pipeline stage and responds with an error if it is invalid
+func (h *PipelineStageHandler) validateStage(w http.ResponseWriter, stage *models.PipelineStage) bool {
+	if stage.Name == "" {
+		auth.RespondWithError(w, http.StatusBadRequest, "Name is required")
+		return false
+	}
+
+	if stage.Type == "" {
+		stage.Type = models.StageOpen
+	}
+
+	switch stage.Type {
+	case models.StageOpen, models.StageWon, models.StageLost:
+	default:
+		auth.RespondWithError(w, http.StatusBadRequest, models.ErrStageType.Error())
+		return false
+	}
+
+	if stage.Probability < 0 || stage.Probability > 100 {
+		auth.RespondWithError(w, http.StatusBadRequest, models.ErrStageProbability.Error())
+		return false
+	}
+
+	// Check if another stage has the name
+	existingStage, err := h.stageService.GetByName(stage.TenantID, stage.Name)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking pipeline stage")
+		return false
+	}
+
+	if existingStage != nil && existingStage.ID != stage.ID {
+		auth.RespondWithError(w, http.StatusConflict, "Pipeline stage with this name already exists")
+		return false
+	}
+
+	return true
+}
+
+// LeadHandler handles lead requests
+type LeadHandler struct {
+	leadService     models.LeadService
+	customerService models.CustomerService
+	userService     models.UserService
+	stageService    models.PipelineStageService
+}
+
+// NewLeadHandler creates a new lead handler
+func NewLeadHandler(
+	leadService models.LeadService,
+	customerService models.CustomerService,
+	userService models.UserService,
+	stageService models.PipelineStageService,
+) *LeadHandler {
+	return &LeadHandler{
+		leadService:     leadService,
+		customerService: customerService,
+		userService:     userService,
+		stageService:    stageService,
+	}
+}
+
+// GetLead gets a lead by ID
+func (h *LeadHandler) GetLead(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	lead := h.findLead(w, tenantID, id)
+	if lead == nil {
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, lead)
+}
+
+// ListLeads lists all leads for a tenant, optionally filtered by status
+func (h *LeadHandler) ListLeads(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	leads, err := h.leadService.List(tenantID, r.URL.Query().Get("status"))
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error listing leads")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, leads)
+}
+
+// CreateLead creates a new lead
+func (h *LeadHandler) CreateLead(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	var lead models.Lead
+	if err := json.NewDecoder(r.Body).Decode(&lead); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	// Set tenant ID from context
+	lead.TenantID = tenantID
+
+	if !h.validateLead(w, &lead) {
+		return
+	}
+
+	// Create lead
+	if err := h.leadService.Create(&lead); err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error creating lead")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusCreated, lead)
+}
+
+// UpdateLead updates a lead that is not converted
+func (h *LeadHandler) UpdateLead(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	var lead models.Lead
+	if err := json.NewDecoder(r.Body).Decode(&lead); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	existingLead := h.findLead(w, tenantID, id)
+	if existingLead == nil {
+		return
+	}
+
+	// Set ID and tenant ID
+	lead.ID = id
+	lead.TenantID = tenantID
+	lead.CreatedAt = existingLead.CreatedAt
+
+	if !h.validateLead(w, &lead) {
+		return
+	}
+
+	// Update lead
+	if err := h.leadService.Update(&lead); err != nil {
+		respondWithCRMError(w, err, "Error updating lead")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, lead)
+}
+
+// DeleteLead deletes a lead
+func (h *LeadHandler) DeleteLead(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	if h.findLead(w, tenantID, id) == nil {
+		return
+	}
+
+	// Delete lead
+	if err := h.leadService.Delete(tenantID, id); err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error deleting lead")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Lead deleted successfully"})
+}
+
+// ConvertLead converts a lead into a customer and contact, optionally opening an
+// opportunity. The body may name an existing customer_id and an opportunity to open,
+// or be empty.
+func (h *LeadHandler) ConvertLead(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+	userID := auth.GetUserIDFromContext(r.Context())
+
+	var conversion models.LeadConversion
+	if err := json.NewDecoder(r.Body).Decode(&conversion); err != nil && err != io.EOF {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if h.findLead(w, tenantID, id) == nil {
+		return
+	}
+
+	// Check if customer exists
+	if conversion.CustomerID != "" {
+		customer, err := h.customerService.GetByID(tenantID, conversion.CustomerID)
+		if err != nil {
+			auth.RespondWithError(w, http.StatusInternalServerError, "Error checking customer")
+			return
+		}
+
+		if customer == nil {
+			auth.RespondWithError(w, http.StatusNotFound, "Customer not found")
+			return
+		}
+	}
+
+	// Check the opportunity to open
+	if opportunity := conversion.Opportunity; opportunity != nil {
+		if opportunity.Name == "" || opportunity.StageID == "" {
+			auth.RespondWithError(w, http.StatusBadRequest, "Opportunity name and stage ID are required")
+			return
+		}
+
+		if !validateOpportunityTerms(w, opportunity) {
+			return
+		}
+
+		stage, err := h.stageService.GetByID(tenantID, opportunity.StageID)
+		if err != nil {
+			auth.RespondWithError(w, http.StatusInternalServerError, "Error checking pipeline stage")
+			return
+		}
+
+		if stage == nil {
+			auth.RespondWithError(w, http.StatusNotFound, "Pipeline stage not found")
+			return
+		}
+
+		if !checkOwner(w, h.userService, tenantID, opportunity.OwnerID) {
+			return
+		}
+	}
+
+	// Convert lead
+	lead, err := h.leadService.Convert(tenantID, id, userID, &conversion)
+	if err != nil {
+		respondWithCRMError(w, err, "Error converting lead")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, lead)
+}
+
+// findLead gets a lead and responds with an error if it cannot be found
+func (h *LeadHandler) findLead(w http.ResponseWriter, tenantID, id string) *models.Lead {
+	lead, err := h.leadService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error getting lead")
+		return nil
+	}
+
+	if lead == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Lead not found")
+		return nil
+	}
+
+	return lead
+}
+
+// validateLead validates a lead and responds with an error if it is invalid. Leads are
+// converted only through ConvertLead, so converted is not a valid status here.
+func (h *LeadHandler) validateLead(w http.ResponseWriter, lead *models.Lead) bool {
+	if lead.Company == "" && lead.LastName == "" {
+		auth.RespondWithError(w, http.StatusBadRequest, "Company or last name is required")
+		return false
+	}
+
+	if lead.Status == "" {
+		lead.Status = models.LeadNew
+	}
+
+	switch lead.Status {
+	case models.LeadNew, models.LeadContacted, models.LeadQualified, models.LeadDisqualified:
+	default:
+		auth.RespondWithError(w, http.StatusBadRequest, models.ErrLeadStatus.Error())
+		return false
+	}
+
+	return checkOwner(w, h.userService, lead.TenantID, lead.OwnerID)
+}
+
+// OpportunityHandler handles opportunity and pipeline summary requests
+type OpportunityHandler struct {
+	opportunityService models.OpportunityService
+	stageService       models.PipelineStageService
+	customerService    models.CustomerService
+	contactService     models.ContactService
+	userService        models.UserService
+}
+
+// NewOpportunityHandler creates a new opportunity handler
+func NewOpportunityHandler(
+	opportunityService models.OpportunityService,
+	stageService models.PipelineStageService,
+	customerService models.CustomerService,
+	contactService models.ContactService,
+	userService models.UserService,
+) *OpportunityHandler {
+	return &OpportunityHandler{
+		opportunityService: opportunityService,
+		stageService:       stageService,
+		customerService:    customerService,
+		contactService:     contactService,
+		userService:        userService,
+	}
+}
+
+// GetOpportunity gets an opportunity by ID
+func (h *OpportunityHandler) GetOpportunity(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	opportunity := h.findOpportunity(w, tenantID, id)
+	if opportunity == nil {
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, opportunity)
+}
+
+// ListOpportunities lists the opportunities of a tenant, optionally filtered by the
+// stage_id, owner_id and customer_id query parameters
+func (h *OpportunityHandler) ListOpportunities(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+	params := r.URL.Query()
+
+	filter := models.OpportunityFilter{
+		StageID:    params.Get("stage_id"),
+		OwnerID:    params.Get("owner_id"),
+		CustomerID: params.Get("customer_id"),
+	}
+
+	opportunities, err := h.opportunityService.List(tenantID, filter)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error listing opportunities")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, opportunities)
+}
+
+// CreateOpportunity opens a new opportunity in a pipeline stage
+func (h *OpportunityHandler) CreateOpportunity(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+	userID := auth.GetUserIDFromContext(r.Context())
+
+	var opportunity models.Opportunity
+	if err := json.NewDecoder(r.Body).Decode(&opportunity); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	// Set tenant ID and created by from context
+	opportunity.TenantID = tenantID
+	opportunity.CreatedBy = userID
+
+	if opportunity.StageID == "" {
+		auth.RespondWithError(w, http.StatusBadRequest, "Stage ID is required")
+		return
+	}
+
+	if !h.validateOpportunity(w, &opportunity) {
+		return
+	}
+
+	// Check if stage exists
+	stage, err := h.stageService.GetByID(tenantID, opportunity.StageID)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking pipeline stage")
+		return
+	}
+
+	if stage == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Pipeline stage not found")
+		return
+	}
+
+	// Create opportunity
+	if err := h.opportunityService.Create(&opportunity); err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error creating opportunity")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusCreated, opportunity)
+}
+
+// UpdateOpportunity updates an opportunity other than its stage
+func (h *OpportunityHandler) UpdateOpportunity(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	var opportunity models.Opportunity
+	if err := json.NewDecoder(r.Body).Decode(&opportunity); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	existingOpportunity := h.findOpportunity(w, tenantID, id)
+	if existingOpportunity == nil {
+		return
+	}
+
+	// Set ID and tenant ID, and keep the probability unless a new one is given
+	opportunity.ID = id
+	opportunity.TenantID = tenantID
+	if opportunity.Probability == 0 {
+		opportunity.Probability = existingOpportunity.Probability
+	}
+
+	if !h.validateOpportunity(w, &opportunity) {
+		return
+	}
+
+	// Update opportunity
+	if err := h.opportunityService.Update(&opportunity); err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error updating opportunity")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, opportunity)
+}
+
+// DeleteOpportunity deletes an opportunity
+func (h *OpportunityHandler) DeleteOpportunity(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	if h.findOpportunity(w, tenantID, id) == nil {
+		return
+	}
+
+	// Delete opportunity
+	if err := h.opportunityService.Delete(tenantID, id); err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error deleting opportunity")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Opportunity deleted successfully"})
+}
+
+// ChangeStage moves an opportunity to the stage_id in the body
+func (h *OpportunityHandler) ChangeStage(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+	userID := auth.GetUserIDFromContext(r.Context())
+
+	var request struct {
+		StageID string `json:"stage_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if request.StageID == "" {
+		auth.RespondWithError(w, http.StatusBadRequest, "Stage ID is required")
+		return
+	}
+
+	if h.findOpportunity(w, tenantID, id) == nil {
+		return
+	}
+
+	// Check if stage exists
+	stage, err := h.stageService.GetByID(tenantID, request.StageID)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking pipeline stage")
+		return
+	}
+
+	if stage == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Pipeline stage not found")
+		return
+	}
+
+	// Change stage
+	if err := h.opportunityService.ChangeStage(tenantID, id, request.StageID, userID); err != nil {
+		respondWithCRMError(w, err, "Error changing opportunity stage")
+		return
+	}
+
+	opportunity, err := h.opportunityService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error getting opportunity")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, opportunity)
+}
+
+// ListStageChanges lists the stage history of an opportunity
+func (h *OpportunityHandler) ListStageChanges(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	if h.findOpportunity(w, tenantID, id) == nil {
+		return
+	}
+
+	changes, err := h.opportunityService.ListStageChanges(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error listing stage changes")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, changes)
+}
+
+// GetPipelineSummary sums the open opportunities by stage and owner with their weighted forecast
+func (h *OpportunityHandler) GetPipelineSummary(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	summary, err := h.opportunityService.Summary(tenantID)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error getting pipeline summary")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, summary)
+}
+
+// findOpportunity gets an opportunity and responds with an error if it cannot be found
+func (h *OpportunityHandler) findOpportunity(w http.ResponseWriter, tenantID, id string) *models.Opportunity {
+	opportunity, err := h.opportunityService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error getting opportunity")
+		return nil
+	}
+
+	if opportunity == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Opportunity not found")
+		return nil
+	}
+
+	return opportunity
+}
+
+// validateOpportunity validates an opportunity and responds with an error if it is invalid
+func (h *OpportunityHandler) validateOpportunity(w http.ResponseWriter, opportunity *models.Opportunity) bool {
+	if opportunity.Name == "" || opportunity.CustomerID == "" {
+		auth.RespondWithError(w, http.StatusBadRequest, "Name and customer ID are required")
+		return false
+	}
+
+	if !validateOpportunityTerms(w, opportunity) {
+		return false
+	}
+
+	// Check if customer exists
+	customer, err := h.customerService.GetByID(opportunity.TenantID, opportunity.CustomerID)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking customer")
+		return false
+	}
+
+	if customer == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Customer not found")
+		return false
+	}
+
+	// Check if contact exists and belongs to the customer
+	if opportunity.ContactID != "" {
+		contact, err := h.contactService.GetByID(opportunity.TenantID, opportunity.ContactID)
+		if err != nil {
+			auth.RespondWithError(w, http.StatusInternalServerError, "Error checking contact")
+			return false
+		}
+
+		if contact == nil {
+			auth.RespondWithError(w, http.StatusNotFound, "Contact not found")
+			return false
+		}
+
+		if contact.CustomerID != opportunity.CustomerID {
+			auth.RespondWithError(w, http.StatusBadRequest, "Contact does not belong to the customer")
+			return false
+		}
+	}
+
+	return checkOwner(w, h.userService, opportunity.TenantID, opportunity.OwnerID)
+}
+
+// validateOpportunityTerms validates the amount and probability of an opportunity and
+// responds with an error if they are invalid
+func validateOpportunityTerms(w http.ResponseWriter, opportunity *models.Opportunity) bool {
+	if opportunity.Amount < 0 {
+		auth.RespondWithError(w, http.StatusBadRequest, models.ErrNegativeAmount.Error())
+		return false
+	}
+
+	if opportunity.Probability < 0 || opportunity.Probability > 100 {
+		auth.RespondWithError(w, http.StatusBadRequest, models.ErrStageProbability.Error())
+		return false
+	}
+
+	return true
+}
+
+// checkOwner checks that an owner, if given, is a user of the tenant and responds with
+// an error if not
+func checkOwner(w http.ResponseWriter, userService models.UserService, tenantID, ownerID string) bool {
+	if ownerID == "" {
+		return true
+	}
+
+	user, err := userService.GetByID(tenantID, ownerID)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking user")
+		return false
+	}
+
+	if user == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "User not found")
+		return false
+	}
+
+	return true
+}
+
+// respondWithCRMError responds with the error of a failed CRM request. Conflicts with
+// the state of a lead, opportunity, stage or task are reported as such.
+func respondWithCRMError(w http.ResponseWriter, err error, message string) {
+	if errors.Is(err, models.ErrLeadConverted) || errors.Is(err, models.ErrStageInUse) ||
+		errors.Is(err, models.ErrOpportunityStage) || errors.Is(err, models.ErrTaskStatus) {
+		auth.RespondWithError(w, http.StatusConflict, err.Error())
+		return
+	}
+
+	var crmErr *models.CRMError
+	if errors.As(err, &crmErr) {
+		auth.RespondWithError(w, http.StatusBadRequest, crmErr.Error())
+		return
+	}
+
+	auth.RespondWithError(w, http.StatusInternalServerError, message)
+}
\ No newline at end of file
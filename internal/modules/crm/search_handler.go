@@ -0,0 +1,73 @@
+package crm
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/yookibooki/erp/internal/auth"
+	"github.com/yookibooki/erp/internal/models"
+)
+
+// defaultSearchLimit is the number of hits returned when no limit is given
+const defaultSearchLimit = 20
+
+// SearchHandler handles CRM search requests
+type SearchHandler struct {
+	searchService models.SearchService
+}
+
+// NewSearchHandler creates a new search handler
+func NewSearchHandler(searchService models.SearchService) *SearchHandler {
+	return &SearchHandler{
+		searchService: searchService,
+	}
+}
+
+// Search searches customers, contacts and interactions for the q query parameter. The
+// language parameter names the text search configuration, types is a comma-separated
+// list of customer, contact and interaction, and limit caps the number of hits.
+func (h *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+	params := r.URL.Query()
+
+	query := models.SearchQuery{
+		Text:     strings.TrimSpace(params.Get("q")),
+		Language: params.Get("language"),
+		Limit:    defaultSearchLimit,
+	}
+
+	if query.Text == "" {
+		auth.RespondWithError(w, http.StatusBadRequest, "Search text is required")
+		return
+	}
+
+	if value := params.Get("types"); value != "" {
+		for _, searchType := range strings.Split(value, ",") {
+			switch searchType {
+			case models.SearchCustomer, models.SearchContact, models.SearchInteraction:
+				query.Types = append(query.Types, searchType)
+			default:
+				auth.RespondWithError(w, http.StatusBadRequest, "Types must be customer, contact or interaction")
+				return
+			}
+		}
+	}
+
+	if value := params.Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > 100 {
+			auth.RespondWithError(w, http.StatusBadRequest, "Limit must be between 1 and 100")
+			return
+		}
+		query.Limit = parsed
+	}
+
+	hits, err := h.searchService.Search(tenantID, query)
+	if err != nil {
+		respondWithCRMError(w, err, "Error searching")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, hits)
+}
\ No newline at end of file
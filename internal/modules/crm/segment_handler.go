@@ -0,0 +1,445 @@
+package crm
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/yookibooki/erp/internal/auth"
+	"github.com/yookibooki/erp/internal/models"
+)
+
+// SegmentHandler handles customer segment requests
+type SegmentHandler struct {
+	segmentService     models.SegmentService
+	customFieldService models.CustomFieldService
+}
+
+// NewSegmentHandler creates a new segment handler
+func NewSegmentHandler(
+	segmentService models.SegmentService,
+	customFieldService models.CustomFieldService,
+) *SegmentHandler {
+	return &SegmentHandler{
+		segmentService:     segmentService,
+		customFieldService: customFieldService,
+	}
+}
+
+// GetSegment gets a segment by ID
+func (h *SegmentHandler) GetSegment(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	segment := h.findSegment(w, tenantID, id)
+	if segment == nil {
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, segment)
+}
+
+// ListSegments lists all segments for a tenant
+func (h *SegmentHandler) ListSegments(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	segments, err := h.segmentService.List(tenantID)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error listing segments")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, segments)
+}
+
+// CreateSegment creates a new segment
+func (h *SegmentHandler) CreateSegment(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+	userID := auth.GetUserIDFromContext(r.Context())
+
+	var segment models.Segment
+	if err := json.NewDecoder(r.Body).Decode(&segment); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	// Set tenant ID and created by from context
+	segment.TenantID = tenantID
+	segment.CreatedBy = userID
+
+	if !h.validateSegment(w, &segment) {
+		return
+	}
+
+	// Check if segment already exists
+	existingSegment, err := h.segmentService.GetByName(tenantID, segment.Name)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking segment")
+		return
+	}
+
+	if existingSegment != nil {
+		auth.RespondWithError(w, http.StatusConflict, "Segment with this name already exists")
+		return
+	}
+
+	// Create segment
+	if err := h.segmentService.Create(&segment); err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error creating segment")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusCreated, segment)
+}
+
+// UpdateSegment updates a segment
+func (h *SegmentHandler) UpdateSegment(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	var segment models.Segment
+	if err := json.NewDecoder(r.Body).Decode(&segment); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	existingSegment := h.findSegment(w, tenantID, id)
+	if existingSegment == nil {
+		return
+	}
+
+	// Set ID and tenant ID
+	segment.ID = id
+	segment.TenantID = tenantID
+	segment.CreatedBy = existingSegment.CreatedBy
+	segment.CreatedAt = existingSegment.CreatedAt
+
+	if !h.validateSegment(w, &segment) {
+		return
+	}
+
+	// Check if another segment has the name
+	conflictingSegment, err := h.segmentService.GetByName(tenantID, segment.Name)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking segment")
+		return
+	}
+
+	if conflictingSegment != nil && conflictingSegment.ID != id {
+		auth.RespondWithError(w, http.StatusConflict, "Segment with this name already exists")
+		return
+	}
+
+	// Update segment
+	if err := h.segmentService.Update(&segment); err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error updating segment")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, segment)
+}
+
+// DeleteSegment deletes a segment. Its members are not affected.
+func (h *SegmentHandler) DeleteSegment(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	if h.findSegment(w, tenantID, id) == nil {
+		return
+	}
+
+	// Delete segment
+	if err := h.segmentService.Delete(tenantID, id); err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error deleting segment")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Segment deleted successfully"})
+}
+
+// ListSegmentMembers lists the customers currently matching a segment
+func (h *SegmentHandler) ListSegmentMembers(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	segment := h.findSegment(w, tenantID, id)
+	if segment == nil {
+		return
+	}
+
+	members, err := h.segmentService.ListMembers(tenantID, segment)
+	if err != nil {
+		respondWithCRMError(w, err, "Error listing segment members")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, members)
+}
+
+// PreviewSegment lists the customers that would match a segment without saving it
+func (h *SegmentHandler) PreviewSegment(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	var segment models.Segment
+	if err := json.NewDecoder(r.Body).Decode(&segment); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	// Set tenant ID from context
+	segment.TenantID = tenantID
+
+	// A preview needs no name
+	if segment.Name == "" {
+		segment.Name = "Preview"
+	}
+
+	if !h.validateSegment(w, &segment) {
+		return
+	}
+
+	members, err := h.segmentService.ListMembers(tenantID, &segment)
+	if err != nil {
+		respondWithCRMError(w, err, "Error listing segment members")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, members)
+}
+
+// ExportSegmentMembers exports the customers currently matching a segment as a CSV file
+// with a header row and a column for each customer custom field
+func (h *SegmentHandler) ExportSegmentMembers(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	segment := h.findSegment(w, tenantID, id)
+	if segment == nil {
+		return
+	}
+
+	members, err := h.segmentService.ListMembers(tenantID, segment)
+	if err != nil {
+		respondWithCRMError(w, err, "Error listing segment members")
+		return
+	}
+
+	definitions, err := h.customFieldService.List(tenantID, models.CustomFieldCustomer)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error getting custom fields")
+		return
+	}
+
+	writeSegmentMembersCSV(w, segment, members, definitions)
+}
+
+// findSegment gets a segment and responds with an error if it cannot be found
+func (h *SegmentHandler) findSegment(w http.ResponseWriter, tenantID, id string) *models.Segment {
+	segment, err := h.segmentService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error getting segment")
+		return nil
+	}
+
+	if segment == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Segment not found")
+		return nil
+	}
+
+	return segment
+}
+
+// validateSegment validates a segment and its rules and responds with an error if it is invalid
+func (h *SegmentHandler) validateSegment(w http.ResponseWriter, segment *models.Segment) bool {
+	if segment.Name == "" {
+		auth.RespondWithError(w, http.StatusBadRequest, "Name is required")
+		return false
+	}
+
+	if segment.Match == "" {
+		segment.Match = models.SegmentMatchAll
+	}
+
+	if segment.Match != models.SegmentMatchAll && segment.Match != models.SegmentMatchAny {
+		auth.RespondWithError(w, http.StatusBadRequest, "Match must be all or any")
+		return false
+	}
+
+	if len(segment.Rules) == 0 {
+		auth.RespondWithError(w, http.StatusBadRequest, "At least one rule is required")
+		return false
+	}
+
+	for i := range segment.Rules {
+		if !h.validateRule(w, segment.TenantID, &segment.Rules[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// validateRule validates a segment rule and responds with an error if it is invalid
+func (h *SegmentHandler) validateRule(w http.ResponseWriter, tenantID string, rule *models.SegmentRule) bool {
+	switch rule.Type {
+	case models.SegmentRuleTag:
+		rule.Value = strings.ToLower(strings.TrimSpace(rule.Value))
+		if rule.Operator != models.SegmentOpHas && rule.Operator != models.SegmentOpNotHas {
+			auth.RespondWithError(w, http.StatusBadRequest, "Tag rule operator must be has or not_has")
+			return false
+		}
+		if rule.Value == "" {
+			auth.RespondWithError(w, http.StatusBadRequest, "Tag rule value is required")
+			return false
+		}
+
+	case models.SegmentRuleCustomField:
+		return h.validateCustomFieldRule(w, tenantID, rule)
+
+	case models.SegmentRuleLastInteraction:
+		if rule.Operator != models.SegmentOpWithin && rule.Operator != models.SegmentOpNotWithin {
+			auth.RespondWithError(w, http.StatusBadRequest, "Last interaction rule operator must be within or not_within")
+			return false
+		}
+		if rule.Days <= 0 {
+			auth.RespondWithError(w, http.StatusBadRequest, "Last interaction rule days must be positive")
+			return false
+		}
+
+	case models.SegmentRuleRevenue:
+		if !isComparison(rule.Operator) {
+			auth.RespondWithError(w, http.StatusBadRequest, "Revenue rule operator must be eq, ne, gt, gte, lt or lte")
+			return false
+		}
+		if _, err := strconv.ParseFloat(rule.Value, 64); err != nil {
+			auth.RespondWithError(w, http.StatusBadRequest, "Revenue rule value must be a number")
+			return false
+		}
+		if rule.Days < 0 {
+			auth.RespondWithError(w, http.StatusBadRequest, "Revenue rule days must not be negative")
+			return false
+		}
+
+	default:
+		auth.RespondWithError(w, http.StatusBadRequest, "Rule type must be tag, custom_field, last_interaction or revenue")
+		return false
+	}
+
+	return true
+}
+
+// validateCustomFieldRule validates a custom field rule against the definition of its
+// customer field and responds with an error if it is invalid. Number and date fields
+// can be compared with any operator, other fields only with eq and ne.
+func (h *SegmentHandler) validateCustomFieldRule(w http.ResponseWriter, tenantID string, rule *models.SegmentRule) bool {
+	if !isComparison(rule.Operator) {
+		auth.RespondWithError(w, http.StatusBadRequest, "Custom field rule operator must be eq, ne, gt, gte, lt or lte")
+		return false
+	}
+
+	definition, err := h.customFieldService.GetByKey(tenantID, models.CustomFieldCustomer, rule.Key)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking custom field")
+		return false
+	}
+
+	if definition == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Custom field not found")
+		return false
+	}
+
+	switch definition.FieldType {
+	case models.CustomFieldNumber:
+		if _, err := strconv.ParseFloat(rule.Value, 64); err != nil {
+			auth.RespondWithError(w, http.StatusBadRequest, "Custom field rule value must be a number")
+			return false
+		}
+	case models.CustomFieldDate:
+		if _, err := time.Parse("2006-01-02", rule.Value); err != nil {
+			auth.RespondWithError(w, http.StatusBadRequest, "Custom field rule value must be a date in YYYY-MM-DD format")
+			return false
+		}
+	default:
+		if rule.Operator != models.SegmentOpEqual && rule.Operator != models.SegmentOpNotEqual {
+			auth.RespondWithError(w, http.StatusBadRequest, "Only number and date custom fields can be compared with gt, gte, lt or lte")
+			return false
+		}
+	}
+
+	return true
+}
+
+// isComparison reports whether operator compares values
+func isComparison(operator string) bool {
+	switch operator {
+	case models.SegmentOpEqual, models.SegmentOpNotEqual, models.SegmentOpGreater,
+		models.SegmentOpGreaterOrEqual, models.SegmentOpLess, models.SegmentOpLessOrEqual:
+		return true
+	}
+	return false
+}
+
+// writeSegmentMembersCSV writes the members of a segment as a CSV file with a header row
+func writeSegmentMembersCSV(
+	w http.ResponseWriter,
+	segment *models.Segment,
+	members []*models.SegmentMember,
+	definitions []*models.CustomFieldDefinition,
+) {
+	filename := "segment-" + segment.ID + ".csv"
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	w.WriteHeader(http.StatusOK)
+
+	header := []string{"id", "name", "email", "phone", "address", "tags", "revenue", "last_interaction_at"}
+	for _, definition := range definitions {
+		header = append(header, definition.Key)
+	}
+
+	writer := csv.NewWriter(w)
+	writer.Write(header)
+	for _, member := range members {
+		lastInteraction := ""
+		if member.LastInteractionAt != nil {
+			lastInteraction = member.LastInteractionAt.Format(time.RFC3339)
+		}
+
+		record := []string{
+			member.ID,
+			member.Name,
+			member.Email,
+			member.Phone,
+			member.Address,
+			strings.Join(member.Tags, ";"),
+			strconv.FormatFloat(member.Revenue, 'f', 2, 64),
+			lastInteraction,
+		}
+		for _, definition := range definitions {
+			record = append(record, formatCustomFieldValue(member.CustomFields[definition.Key]))
+		}
+		writer.Write(record)
+	}
+	writer.Flush()
+}
+
+// formatCustomFieldValue formats a custom field value for a CSV file
+func formatCustomFieldValue(value interface{}) string {
+	switch value := value.(type) {
+	case string:
+		return value
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(value)
+	}
+	return ""
+}
\ No newline at end of file
@@ -0,0 +1,349 @@
+package crm
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/yookibooki/erp/internal/auth"
+	"github.com/yookibooki/erp/internal/models"
+)
+
+// TaskHandler handles task requests
+type TaskHandler struct {
+	taskService        models.TaskService
+	customerService    models.CustomerService
+	contactService     models.ContactService
+	opportunityService models.OpportunityService
+	userService        models.UserService
+}
+
+// NewTaskHandler creates a new task handler
+func NewTaskHandler(
+	taskService models.TaskService,
+	customerService models.CustomerService,
+	contactService models.ContactService,
+	opportunityService models.OpportunityService,
+	userService models.UserService,
+) *TaskHandler {
+	return &TaskHandler{
+		taskService:        taskService,
+		customerService:    customerService,
+		contactService:     contactService,
+		opportunityService: opportunityService,
+		userService:        userService,
+	}
+}
+
+// GetTask gets a task by ID
+func (h *TaskHandler) GetTask(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	task := h.findTask(w, tenantID, id)
+	if task == nil {
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, task)
+}
+
+// ListTasks lists the tasks of a tenant, optionally filtered by the assignee_id,
+// customer_id, opportunity_id, status and overdue query parameters
+func (h *TaskHandler) ListTasks(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+	params := r.URL.Query()
+
+	h.listTasks(w, tenantID, models.TaskFilter{
+		AssigneeID:    params.Get("assignee_id"),
+		CustomerID:    params.Get("customer_id"),
+		OpportunityID: params.Get("opportunity_id"),
+		Status:        params.Get("status"),
+		Overdue:       params.Get("overdue") == "true",
+	})
+}
+
+// ListMyTasks lists the tasks assigned to the requesting user, optionally filtered by
+// the status and overdue query parameters
+func (h *TaskHandler) ListMyTasks(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+	userID := auth.GetUserIDFromContext(r.Context())
+	params := r.URL.Query()
+
+	h.listTasks(w, tenantID, models.TaskFilter{
+		AssigneeID: userID,
+		Status:     params.Get("status"),
+		Overdue:    params.Get("overdue") == "true",
+	})
+}
+
+// listTasks responds with the tasks matching a filter
+func (h *TaskHandler) listTasks(w http.ResponseWriter, tenantID string, filter models.TaskFilter) {
+	tasks, err := h.taskService.List(tenantID, filter)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error listing tasks")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, tasks)
+}
+
+// CreateTask creates a new task, assigned to the requesting user unless an assignee is given
+func (h *TaskHandler) CreateTask(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+	userID := auth.GetUserIDFromContext(r.Context())
+
+	var task models.Task
+	if err := json.NewDecoder(r.Body).Decode(&task); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	// Set tenant ID and created by from context
+	task.TenantID = tenantID
+	task.CreatedBy = userID
+	if task.AssigneeID == "" {
+		task.AssigneeID = userID
+	}
+
+	if !h.validateTask(w, &task) {
+		return
+	}
+
+	// Create task
+	if err := h.taskService.Create(&task); err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error creating task")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusCreated, task)
+}
+
+// UpdateTask updates an open or in progress task
+func (h *TaskHandler) UpdateTask(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	var task models.Task
+	if err := json.NewDecoder(r.Body).Decode(&task); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	existingTask := h.findTask(w, tenantID, id)
+	if existingTask == nil {
+		return
+	}
+
+	// Set ID and tenant ID, and keep the assignee unless a new one is given
+	task.ID = id
+	task.TenantID = tenantID
+	task.CreatedBy = existingTask.CreatedBy
+	task.CreatedAt = existingTask.CreatedAt
+	if task.AssigneeID == "" {
+		task.AssigneeID = existingTask.AssigneeID
+	}
+
+	if !h.validateTask(w, &task) {
+		return
+	}
+
+	// Update task
+	if err := h.taskService.Update(&task); err != nil {
+		respondWithCRMError(w, err, "Error updating task")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, task)
+}
+
+// DeleteTask deletes a task
+func (h *TaskHandler) DeleteTask(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	if h.findTask(w, tenantID, id) == nil {
+		return
+	}
+
+	// Delete task
+	if err := h.taskService.Delete(tenantID, id); err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error deleting task")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Task deleted successfully"})
+}
+
+// CompleteTask completes a task and logs it as an interaction. The body may give notes
+// for the interaction or be empty.
+func (h *TaskHandler) CompleteTask(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+	userID := auth.GetUserIDFromContext(r.Context())
+
+	var request struct {
+		Notes string `json:"notes"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil && err != io.EOF {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	h.changeStatus(w, r, func(id string) error {
+		return h.taskService.Complete(tenantID, id, userID, request.Notes)
+	})
+}
+
+// CancelTask cancels a task
+func (h *TaskHandler) CancelTask(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	h.changeStatus(w, r, func(id string) error {
+		return h.taskService.Cancel(tenantID, id)
+	})
+}
+
+// changeStatus applies a status change to a task and responds with the updated task
+func (h *TaskHandler) changeStatus(w http.ResponseWriter, r *http.Request, change func(id string) error) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	if h.findTask(w, tenantID, id) == nil {
+		return
+	}
+
+	if err := change(id); err != nil {
+		respondWithCRMError(w, err, "Error updating task")
+		return
+	}
+
+	task, err := h.taskService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error getting task")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, task)
+}
+
+// findTask gets a task and responds with an error if it cannot be found
+func (h *TaskHandler) findTask(w http.ResponseWriter, tenantID, id string) *models.Task {
+	task, err := h.taskService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error getting task")
+		return nil
+	}
+
+	if task == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Task not found")
+		return nil
+	}
+
+	return task
+}
+
+// validateTask validates a task and responds with an error if it is invalid. A task
+// linked to a contact or opportunity is about its customer, which is filled in when
+// not given.
+func (h *TaskHandler) validateTask(w http.ResponseWriter, task *models.Task) bool {
+	if task.Title == "" {
+		auth.RespondWithError(w, http.StatusBadRequest, "Title is required")
+		return false
+	}
+
+	if task.Priority == "" {
+		task.Priority = models.TaskPriorityNormal
+	}
+
+	switch task.Priority {
+	case models.TaskPriorityLow, models.TaskPriorityNormal, models.TaskPriorityHigh:
+	default:
+		auth.RespondWithError(w, http.StatusBadRequest, models.ErrTaskPriority.Error())
+		return false
+	}
+
+	// Tasks are closed only through CompleteTask and CancelTask
+	if task.Status == "" {
+		task.Status = models.TaskOpen
+	}
+
+	if task.Status != models.TaskOpen && task.Status != models.TaskInProgress {
+		auth.RespondWithError(w, http.StatusBadRequest, "Status must be open or in_progress")
+		return false
+	}
+
+	// Check if opportunity exists
+	if task.OpportunityID != "" {
+		opportunity, err := h.opportunityService.GetByID(task.TenantID, task.OpportunityID)
+		if err != nil {
+			auth.RespondWithError(w, http.StatusInternalServerError, "Error checking opportunity")
+			return false
+		}
+
+		if opportunity == nil {
+			auth.RespondWithError(w, http.StatusNotFound, "Opportunity not found")
+			return false
+		}
+
+		if !linkCustomer(w, task, opportunity.CustomerID, "Opportunity") {
+			return false
+		}
+	}
+
+	// Check if contact exists
+	if task.ContactID != "" {
+		contact, err := h.contactService.GetByID(task.TenantID, task.ContactID)
+		if err != nil {
+			auth.RespondWithError(w, http.StatusInternalServerError, "Error checking contact")
+			return false
+		}
+
+		if contact == nil {
+			auth.RespondWithError(w, http.StatusNotFound, "Contact not found")
+			return false
+		}
+
+		if !linkCustomer(w, task, contact.CustomerID, "Contact") {
+			return false
+		}
+	}
+
+	if task.CustomerID == "" {
+		auth.RespondWithError(w, http.StatusBadRequest, "Customer, contact or opportunity ID is required")
+		return false
+	}
+
+	// Check if customer exists
+	customer, err := h.customerService.GetByID(task.TenantID, task.CustomerID)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking customer")
+		return false
+	}
+
+	if customer == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Customer not found")
+		return false
+	}
+
+	return checkOwner(w, h.userService, task.TenantID, task.AssigneeID)
+}
+
+// linkCustomer links a task to the customer of its contact or opportunity and responds
+// with an error if the task is about another customer
+func linkCustomer(w http.ResponseWriter, task *models.Task, customerID, linked string) bool {
+	if task.CustomerID == "" {
+		task.CustomerID = customerID
+	}
+
+	if task.CustomerID != customerID {
+		auth.RespondWithError(w, http.StatusBadRequest, linked+" does not belong to the customer")
+		return false
+	}
+
+	return true
+}
\ No newline at end of file
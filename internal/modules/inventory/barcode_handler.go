@@ -0,0 +1,454 @@
+package inventory
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/yookibooki/erp/internal/auth"
+	"github.com/yookibooki/erp/internal/barcode"
+	"github.com/yookibooki/erp/internal/models"
+)
+
+// BarcodeHandler handles product barcode requests
+type BarcodeHandler struct {
+	barcodeService     models.BarcodeService
+	productService     models.ProductService
+	productUnitService models.ProductUnitService
+	locationService    models.LocationService
+}
+
+// NewBarcodeHandler creates a new barcode handler
+func NewBarcodeHandler(
+	barcodeService models.BarcodeService,
+	productService models.ProductService,
+	productUnitService models.ProductUnitService,
+	locationService models.LocationService,
+) *BarcodeHandler {
+	return &BarcodeHandler{
+		barcodeService:     barcodeService,
+		productService:     productService,
+		productUnitService: productUnitService,
+		locationService:    locationService,
+	}
+}
+
+// GetBarcode gets a product barcode by ID
+func (h *BarcodeHandler) GetBarcode(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	productBarcode, err := h.barcodeService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error getting barcode")
+		return
+	}
+
+	if productBarcode == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Barcode not found")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, productBarcode)
+}
+
+// ListProductBarcodes lists the barcodes of a product
+func (h *BarcodeHandler) ListProductBarcodes(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	productID := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	barcodes, err := h.barcodeService.ListByProduct(tenantID, productID)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error listing barcodes")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, barcodes)
+}
+
+// CreateProductBarcode adds a barcode to a product
+func (h *BarcodeHandler) CreateProductBarcode(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	productID := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	var productBarcode models.ProductBarcode
+	if err := json.NewDecoder(r.Body).Decode(&productBarcode); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	// Set product and tenant ID
+	productBarcode.ProductID = productID
+	productBarcode.TenantID = tenantID
+
+	if !h.validateBarcode(w, &productBarcode) {
+		return
+	}
+
+	// Create barcode
+	if err := h.barcodeService.Create(&productBarcode); err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error creating barcode")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusCreated, productBarcode)
+}
+
+// UpdateBarcode updates the code, type and unit of a product barcode
+func (h *BarcodeHandler) UpdateBarcode(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	var productBarcode models.ProductBarcode
+	if err := json.NewDecoder(r.Body).Decode(&productBarcode); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	// Check if barcode exists
+	existingBarcode, err := h.barcodeService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking barcode")
+		return
+	}
+
+	if existingBarcode == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Barcode not found")
+		return
+	}
+
+	// Set ID, tenant ID and product ID
+	productBarcode.ID = id
+	productBarcode.TenantID = tenantID
+	productBarcode.ProductID = existingBarcode.ProductID
+	productBarcode.CreatedAt = existingBarcode.CreatedAt
+
+	if !h.validateBarcode(w, &productBarcode) {
+		return
+	}
+
+	// Update barcode
+	if err := h.barcodeService.Update(&productBarcode); err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error updating barcode")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, productBarcode)
+}
+
+// DeleteBarcode deletes a product barcode
+func (h *BarcodeHandler) DeleteBarcode(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	// Check if barcode exists
+	existingBarcode, err := h.barcodeService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking barcode")
+		return
+	}
+
+	if existingBarcode == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Barcode not found")
+		return
+	}
+
+	// Delete barcode
+	if err := h.barcodeService.Delete(tenantID, id); err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error deleting barcode")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Barcode deleted successfully"})
+}
+
+// LookupBarcode finds the product with the barcode given by code. GTINs match barcodes
+// of any GTIN length, so a GTIN-14 finds the EAN-13 it extends.
+func (h *BarcodeHandler) LookupBarcode(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	code := r.URL.Query().Get("code")
+	if code == "" {
+		auth.RespondWithError(w, http.StatusBadRequest, "Code is required")
+		return
+	}
+
+	productBarcode, err := h.findBarcode(tenantID, code)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error getting barcode")
+		return
+	}
+
+	if productBarcode == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Barcode not found")
+		return
+	}
+
+	product, err := h.productService.GetByID(tenantID, productBarcode.ProductID)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error getting product")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, models.ScanResult{Barcode: productBarcode, Product: product})
+}
+
+// Scan identifies the product in scanned barcode data and drafts an inventory transaction
+// for it. GS1 element strings give the lot, serial number, expiry date and quantity of the
+// transaction; other data is looked up as a product barcode and drafts a quantity of one.
+func (h *BarcodeHandler) Scan(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	var req models.ScanRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if req.Data == "" {
+		auth.RespondWithError(w, http.StatusBadRequest, "Data is required")
+		return
+	}
+
+	if req.TransactionType == "" {
+		req.TransactionType = models.TransactionTypeReceipt
+	}
+
+	switch req.TransactionType {
+	case models.TransactionTypeReceipt, models.TransactionTypeIssue, models.TransactionTypeAdjustment,
+		models.TransactionTypeTransfer, models.TransactionTypeReturn:
+	default:
+		auth.RespondWithError(w, http.StatusBadRequest, models.ErrInvalidTransactionType.Error())
+		return
+	}
+
+	// Check if location exists
+	if req.LocationID != "" {
+		location, err := h.locationService.GetByID(tenantID, req.LocationID)
+		if err != nil {
+			auth.RespondWithError(w, http.StatusInternalServerError, "Error checking location")
+			return
+		}
+
+		if location == nil {
+			auth.RespondWithError(w, http.StatusNotFound, "Location not found")
+			return
+		}
+	}
+
+	// Data from a GS1 symbol is parsed; other data is a product barcode unless it is an
+	// element string read without its symbology identifier
+	var gs1 *models.GS1Data
+	var productBarcode *models.ProductBarcode
+	var err error
+	if barcode.IsGS1(req.Data) {
+		gs1, err = barcode.ParseGS1(req.Data)
+		if err != nil {
+			respondWithBarcodeError(w, err, "Error parsing barcode")
+			return
+		}
+	} else {
+		productBarcode, err = h.findBarcode(tenantID, req.Data)
+		if err != nil {
+			auth.RespondWithError(w, http.StatusInternalServerError, "Error getting barcode")
+			return
+		}
+
+		if productBarcode == nil {
+			gs1, _ = barcode.ParseGS1(req.Data)
+		}
+	}
+
+	if productBarcode == nil && gs1 != nil && gs1.GTIN != "" {
+		productBarcode, err = h.barcodeService.GetByGTIN(tenantID, gs1.GTIN)
+		if err != nil {
+			auth.RespondWithError(w, http.StatusInternalServerError, "Error getting barcode")
+			return
+		}
+	}
+
+	if productBarcode == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Barcode not found")
+		return
+	}
+
+	product, err := h.productService.GetByID(tenantID, productBarcode.ProductID)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error getting product")
+		return
+	}
+
+	// Draft the transaction in the unit of the scanned pack
+	transaction := &models.InventoryTransaction{
+		TenantID:        tenantID,
+		ProductID:       productBarcode.ProductID,
+		TransactionType: req.TransactionType,
+		Quantity:        1,
+		UnitID:          productBarcode.UnitID,
+		LocationID:      req.LocationID,
+	}
+	if gs1 != nil {
+		if gs1.Quantity > 0 {
+			transaction.Quantity = gs1.Quantity
+		}
+		transaction.LotNumber = gs1.LotNumber
+		transaction.SerialNumber = gs1.SerialNumber
+		transaction.ExpiryDate = gs1.ExpiryDate
+		if transaction.ExpiryDate == nil {
+			transaction.ExpiryDate = gs1.BestBefore
+		}
+	}
+	transaction.UnitQuantity = transaction.Quantity
+
+	auth.RespondWithJSON(w, http.StatusOK, models.ScanResult{
+		Barcode:     productBarcode,
+		GS1:         gs1,
+		Product:     product,
+		Transaction: transaction,
+	})
+}
+
+// GetBarcodeLabel renders a product barcode as a label in its symbology. format=png
+// returns a PNG image instead of SVG, and scale sets the width of a module in pixels.
+func (h *BarcodeHandler) GetBarcodeLabel(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+	params := r.URL.Query()
+
+	format := params.Get("format")
+	if format != "" && format != "svg" && format != "png" {
+		auth.RespondWithError(w, http.StatusBadRequest, "Format must be svg or png")
+		return
+	}
+
+	scale := 2
+	if value := params.Get("scale"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > 10 {
+			auth.RespondWithError(w, http.StatusBadRequest, "Scale must be between 1 and 10")
+			return
+		}
+		scale = parsed
+	}
+
+	productBarcode, err := h.barcodeService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error getting barcode")
+		return
+	}
+
+	if productBarcode == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Barcode not found")
+		return
+	}
+
+	modules, text, err := barcode.Encode(productBarcode.Type, productBarcode.Code)
+	if err != nil {
+		respondWithBarcodeError(w, err, "Error generating barcode label")
+		return
+	}
+
+	// Render into a buffer so a rendering error can still be reported
+	var label bytes.Buffer
+	contentType := "image/svg+xml"
+	if format == "png" {
+		contentType = "image/png"
+		err = barcode.WritePNG(&label, modules, scale, 50*scale)
+	} else {
+		err = barcode.WriteSVG(&label, modules, scale, 50*scale, text)
+	}
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error generating barcode label")
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(label.Bytes())
+}
+
+// findBarcode finds a product barcode by its code, or for a GTIN by its GTIN
+func (h *BarcodeHandler) findBarcode(tenantID, code string) (*models.ProductBarcode, error) {
+	productBarcode, err := h.barcodeService.GetByCode(tenantID, code)
+	if err != nil || productBarcode != nil || !barcode.IsGTIN(code) {
+		return productBarcode, err
+	}
+	return h.barcodeService.GetByGTIN(tenantID, code)
+}
+
+// validateBarcode validates a product barcode and responds with an error if it is invalid
+func (h *BarcodeHandler) validateBarcode(w http.ResponseWriter, productBarcode *models.ProductBarcode) bool {
+	if productBarcode.Code == "" {
+		auth.RespondWithError(w, http.StatusBadRequest, "Code is required")
+		return false
+	}
+
+	if productBarcode.Type == "" {
+		productBarcode.Type = models.BarcodeInternal
+	}
+
+	if err := barcode.Validate(productBarcode.Type, productBarcode.Code); err != nil {
+		respondWithBarcodeError(w, err, "Error checking barcode")
+		return false
+	}
+
+	// Check if product exists
+	product, err := h.productService.GetByID(productBarcode.TenantID, productBarcode.ProductID)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking product")
+		return false
+	}
+
+	if product == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Product not found")
+		return false
+	}
+
+	// Check if the product converts the unit of the barcode
+	if productBarcode.UnitID != "" && productBarcode.UnitID != product.BaseUnitID {
+		productUnit, err := h.productUnitService.GetByProductAndUnit(productBarcode.TenantID, product.ID, productBarcode.UnitID)
+		if err != nil {
+			auth.RespondWithError(w, http.StatusInternalServerError, "Error checking product unit")
+			return false
+		}
+
+		if productUnit == nil {
+			auth.RespondWithError(w, http.StatusBadRequest, models.ErrUnitConversion.Error())
+			return false
+		}
+	}
+
+	// Check if another barcode has the code, including as a GTIN
+	existingBarcode, err := h.findBarcode(productBarcode.TenantID, productBarcode.Code)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking barcode")
+		return false
+	}
+
+	if existingBarcode != nil && existingBarcode.ID != productBarcode.ID {
+		auth.RespondWithError(w, http.StatusConflict, "Barcode with this code already exists")
+		return false
+	}
+
+	return true
+}
+
+// respondWithBarcodeError responds with the error of invalid barcode data
+func respondWithBarcodeError(w http.ResponseWriter, err error, message string) {
+	var inventoryErr *models.InventoryError
+	if errors.As(err, &inventoryErr) {
+		auth.RespondWithError(w, http.StatusBadRequest, inventoryErr.Error())
+		return
+	}
+
+	auth.RespondWithError(w, http.StatusInternalServerError, message)
+}
\ No newline at end of file
@@ -0,0 +1,307 @@
+package inventory
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/yookibooki/erp/internal/auth"
+	"github.com/yookibooki/erp/internal/models"
+)
+
+// ProductCategoryHandler handles product category requests
+type ProductCategoryHandler struct {
+	categoryService models.ProductCategoryService
+	accountService  models.AccountService
+	unitService     models.UnitOfMeasureService
+}
+
+// NewProductCategoryHandler creates a new product category handler
+func NewProductCategoryHandler(
+	categoryService models.ProductCategoryService,
+	accountService models.AccountService,
+	unitService models.UnitOfMeasureService,
+) *ProductCategoryHandler {
+	return &ProductCategoryHandler{
+		categoryService: categoryService,
+		accountService:  accountService,
+		unitService:     unitService,
+	}
+}
+
+// GetCategory gets a product category by ID
+func (h *ProductCategoryHandler) GetCategory(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	category, err := h.categoryService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error getting category")
+		return
+	}
+
+	if category == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Category not found")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, category)
+}
+
+// ListCategories lists all product categories for a tenant
+func (h *ProductCategoryHandler) ListCategories(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	categories, err := h.categoryService.List(tenantID)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error listing categories")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, categories)
+}
+
+// CreateCategory creates a new product category
+func (h *ProductCategoryHandler) CreateCategory(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	var category models.ProductCategory
+	if err := json.NewDecoder(r.Body).Decode(&category); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	// Set tenant ID from context
+	category.TenantID = tenantID
+
+	if !h.validateCategory(w, &category) {
+		return
+	}
+
+	// Check if category already exists
+	existingCategory, err := h.categoryService.GetByCode(tenantID, category.Code)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking category")
+		return
+	}
+
+	if existingCategory != nil {
+		auth.RespondWithError(w, http.StatusConflict, "Category with this code already exists")
+		return
+	}
+
+	// Create category
+	if err := h.categoryService.Create(&category); err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error creating category")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusCreated, category)
+}
+
+// UpdateCategory updates a product category
+func (h *ProductCategoryHandler) UpdateCategory(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	var category models.ProductCategory
+	if err := json.NewDecoder(r.Body).Decode(&category); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	// Set ID and tenant ID
+	category.ID = id
+	category.TenantID = tenantID
+
+	if !h.validateCategory(w, &category) {
+		return
+	}
+
+	// Check if category exists
+	existingCategory, err := h.categoryService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking category")
+		return
+	}
+
+	if existingCategory == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Category not found")
+		return
+	}
+
+	// Check if another category has the code
+	conflictingCategory, err := h.categoryService.GetByCode(tenantID, category.Code)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking category")
+		return
+	}
+
+	if conflictingCategory != nil && conflictingCategory.ID != id {
+		auth.RespondWithError(w, http.StatusConflict, "Category with this code already exists")
+		return
+	}
+
+	category.CreatedAt = existingCategory.CreatedAt
+
+	// Update category
+	if err := h.categoryService.Update(&category); err != nil {
+		respondWithCategoryError(w, err, "Error updating category")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, category)
+}
+
+// DeleteCategory deletes a product category
+func (h *ProductCategoryHandler) DeleteCategory(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	// Check if category exists
+	existingCategory, err := h.categoryService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking category")
+		return
+	}
+
+	if existingCategory == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Category not found")
+		return
+	}
+
+	// Delete category
+	if err := h.categoryService.Delete(tenantID, id); err != nil {
+		respondWithCategoryError(w, err, "Error deleting category")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Category deleted successfully"})
+}
+
+// GetStockByCategory values stock on hand per category as of the end of the as_of date, or now
+func (h *ProductCategoryHandler) GetStockByCategory(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	asOf := time.Now()
+	if value := r.URL.Query().Get("as_of"); value != "" {
+		date, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			auth.RespondWithError(w, http.StatusBadRequest, "As of date must be formatted as YYYY-MM-DD")
+			return
+		}
+		asOf = date.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+
+	report, err := h.categoryService.StockByCategory(tenantID, asOf)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error getting stock by category")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, report)
+}
+
+// validateCategory validates a product category and responds with an error if it is invalid
+func (h *ProductCategoryHandler) validateCategory(w http.ResponseWriter, category *models.ProductCategory) bool {
+	if category.Code == "" || category.Name == "" {
+		auth.RespondWithError(w, http.StatusBadRequest, "Code and name are required")
+		return false
+	}
+
+	// Check if parent category exists
+	if category.ParentID != "" {
+		if category.ParentID == category.ID {
+			auth.RespondWithError(w, http.StatusBadRequest, models.ErrCategoryCycle.Error())
+			return false
+		}
+
+		parent, err := h.categoryService.GetByID(category.TenantID, category.ParentID)
+		if err != nil {
+			auth.RespondWithError(w, http.StatusInternalServerError, "Error checking category")
+			return false
+		}
+
+		if parent == nil {
+			auth.RespondWithError(w, http.StatusNotFound, "Parent category not found")
+			return false
+		}
+	}
+
+	// Check if unit of measure exists
+	if category.UnitID != "" {
+		unit, err := h.unitService.GetByID(category.TenantID, category.UnitID)
+		if err != nil {
+			auth.RespondWithError(w, http.StatusInternalServerError, "Error checking unit of measure")
+			return false
+		}
+
+		if unit == nil {
+			auth.RespondWithError(w, http.StatusNotFound, "Unit of measure not found")
+			return false
+		}
+	}
+
+	defaults := models.ProductDefaults{
+		InventoryAccountID: category.InventoryAccountID,
+		COGSAccountID:      category.COGSAccountID,
+		TaxCode:            category.TaxCode,
+		CostingMethod:      category.CostingMethod,
+	}
+	return validateDefaults(w, h.accountService, category.TenantID, defaults)
+}
+
+// validateDefaults validates the GL accounts, tax code and costing method a category or
+// product sets and responds with an error if they are invalid. Empty values are inherited.
+func validateDefaults(w http.ResponseWriter, accountService models.AccountService, tenantID string, defaults models.ProductDefaults) bool {
+	if defaults.CostingMethod != "" && defaults.CostingMethod != models.CostingFIFO && defaults.CostingMethod != models.CostingAverage {
+		auth.RespondWithError(w, http.StatusBadRequest, "Costing method must be fifo or average")
+		return false
+	}
+
+	if len(defaults.TaxCode) > 20 {
+		auth.RespondWithError(w, http.StatusBadRequest, "Tax code must be at most 20 characters")
+		return false
+	}
+
+	// Check if accounts exist
+	for _, accountID := range []string{defaults.InventoryAccountID, defaults.COGSAccountID} {
+		if accountID == "" {
+			continue
+		}
+
+		account, err := accountService.GetByID(tenantID, accountID)
+		if err != nil {
+			auth.RespondWithError(w, http.StatusInternalServerError, "Error checking account")
+			return false
+		}
+
+		if account == nil {
+			auth.RespondWithError(w, http.StatusNotFound, "Account not found")
+			return false
+		}
+	}
+
+	return true
+}
+
+// respondWithCategoryError responds with the error of a failed category change
+func respondWithCategoryError(w http.ResponseWriter, err error, message string) {
+	if errors.Is(err, models.ErrCategoryHasChildren) {
+		auth.RespondWithError(w, http.StatusConflict, err.Error())
+		return
+	}
+
+	var inventoryErr *models.InventoryError
+	if errors.As(err, &inventoryErr) {
+		auth.RespondWithError(w, http.StatusBadRequest, inventoryErr.Error())
+		return
+	}
+
+	auth.RespondWithError(w, http.StatusInternalServerError, message)
+}
\ No newline at end of file
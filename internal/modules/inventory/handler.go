@@ -2,22 +2,38 @@ package inventory
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/gorilla/mux"
 	"github.com/yookibooki/erp/internal/auth"
+	"github.com/yookibooki/erp/internal/customfield"
 	"github.com/yookibooki/erp/internal/models"
 )
 
 // ProductHandler handles product requests
 type ProductHandler struct {
-	productService models.ProductService
+	productService     models.ProductService
+	unitService        models.UnitOfMeasureService
+	categoryService    models.ProductCategoryService
+	accountService     models.AccountService
+	customFieldService models.CustomFieldService
 }
 
 // NewProductHandler creates a new product handler
-func NewProductHandler(productService models.ProductService) *ProductHandler {
+func NewProductHandler(
+	productService models.ProductService,
+	unitService models.UnitOfMeasureService,
+	categoryService models.ProductCategoryService,
+	accountService models.AccountService,
+	customFieldService models.CustomFieldService,
+) *ProductHandler {
 	return &ProductHandler{
-		productService: productService,
+		productService:     productService,
+		unitService:        unitService,
+		categoryService:    categoryService,
+		accountService:     accountService,
+		customFieldService: customFieldService,
 	}
 }
 
@@ -41,11 +57,35 @@ func (h *ProductHandler) GetProduct(w http.ResponseWriter, r *http.Request) {
 	auth.RespondWithJSON(w, http.StatusOK, product)
 }
 
-// ListProducts lists all products for a tenant
+// ListProducts lists all products for a tenant, or those of the category_id given as a
+// query parameter and its subcategories, optionally filtered by custom field values
+// given as cf.<key> query parameters
 func (h *ProductHandler) ListProducts(w http.ResponseWriter, r *http.Request) {
 	tenantID := auth.GetTenantIDFromContext(r.Context())
 
-	products, err := h.productService.List(tenantID)
+	categoryID := r.URL.Query().Get("category_id")
+	if categoryID != "" {
+		category, err := h.categoryService.GetByID(tenantID, categoryID)
+		if err != nil {
+			auth.RespondWithError(w, http.StatusInternalServerError, "Error checking category")
+			return
+		}
+
+		if category == nil {
+			auth.RespondWithError(w, http.StatusNotFound, "Category not found")
+			return
+		}
+	}
+
+	customFields := customfield.ParseFilter(r.URL.Query())
+
+	var products []*models.Product
+	var err error
+	if categoryID != "" {
+		products, err = h.productService.ListByCategory(tenantID, categoryID, customFields)
+	} else {
+		products, err = h.productService.List(tenantID, customFields)
+	}
 	if err != nil {
 		auth.RespondWithError(w, http.StatusInternalServerError, "Error listing products")
 		return
@@ -54,7 +94,7 @@ func (h *ProductHandler) ListProducts(w http.ResponseWriter, r *http.Request) {
 	auth.RespondWithJSON(w, http.StatusOK, products)
 }
 
-// CreateProduct creates a new product
+// CreateProduct creates a new product. Products without a base unit take the unit of their category.
 func (h *ProductHandler) CreateProduct(w http.ResponseWriter, r *http.Request) {
 	tenantID := auth.GetTenantIDFromContext(r.Context())
 
@@ -67,12 +107,33 @@ func (h *ProductHandler) CreateProduct(w http.ResponseWriter, r *http.Request) {
 	// Set tenant ID from context
 	product.TenantID = tenantID
 
+	// Variants are created through their template
+	product.TemplateID = ""
+	product.Attributes = nil
+
 	// Validate product
 	if product.Code == "" || product.Name == "" {
 		auth.RespondWithError(w, http.StatusBadRequest, "Code and name are required")
 		return
 	}
 
+	if product.TrackingMode == "" {
+		product.TrackingMode = models.TrackingNone
+	}
+
+	if !validTrackingMode(product.TrackingMode) {
+		auth.RespondWithError(w, http.StatusBadRequest, "Tracking mode must be none, lot or serial")
+		return
+	}
+
+	if !h.validateCategory(w, &product) {
+		return
+	}
+
+	if !h.validateUnits(w, &product) {
+		return
+	}
+
 	// Check if product already exists
 	existingProduct, err := h.productService.GetByCode(tenantID, product.Code)
 	if err != nil {
@@ -85,6 +146,10 @@ func (h *ProductHandler) CreateProduct(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !h.validateCustomFields(w, &product) {
+		return
+	}
+
 	// Create product
 	if err := h.productService.Create(&product); err != nil {
 		auth.RespondWithError(w, http.StatusInternalServerError, "Error creating product")
@@ -116,6 +181,19 @@ func (h *ProductHandler) UpdateProduct(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if product.TrackingMode != "" && !validTrackingMode(product.TrackingMode) {
+		auth.RespondWithError(w, http.StatusBadRequest, "Tracking mode must be none, lot or serial")
+		return
+	}
+
+	if !h.validateCategory(w, &product) {
+		return
+	}
+
+	if !h.validateUnits(w, &product) {
+		return
+	}
+
 	// Check if product exists
 	existingProduct, err := h.productService.GetByID(tenantID, id)
 	if err != nil {
@@ -128,6 +206,35 @@ func (h *ProductHandler) UpdateProduct(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Stock is held in the base unit, so it cannot change while there is stock
+	if existingProduct.BaseUnitID != product.BaseUnitID && existingProduct.StockQuantity != 0 {
+		auth.RespondWithError(w, http.StatusConflict, "Base unit cannot change while the product has stock")
+		return
+	}
+
+	// Keep the tracking mode unless a new one is given. Stock on hand has no lots or
+	// serial numbers to draw from under another mode, so it cannot change while there
+	// is stock.
+	if product.TrackingMode == "" {
+		product.TrackingMode = existingProduct.TrackingMode
+	}
+	if existingProduct.TrackingMode != product.TrackingMode && existingProduct.StockQuantity != 0 {
+		auth.RespondWithError(w, http.StatusConflict, "Tracking mode cannot change while the product has stock")
+		return
+	}
+
+	// Stock only changes through inventory transactions
+	product.StockQuantity = existingProduct.StockQuantity
+
+	// Keep the custom fields unless new ones are given
+	if product.CustomFields == nil {
+		product.CustomFields = existingProduct.CustomFields
+	}
+
+	if !h.validateCustomFields(w, &product) {
+		return
+	}
+
 	// Update product
 	if err := h.productService.Update(&product); err != nil {
 		auth.RespondWithError(w, http.StatusInternalServerError, "Error updating product")
@@ -168,16 +275,19 @@ func (h *ProductHandler) DeleteProduct(w http.ResponseWriter, r *http.Request) {
 type InventoryTransactionHandler struct {
 	transactionService models.InventoryTransactionService
 	productService     models.ProductService
+	locationService    models.LocationService
 }
 
 // NewInventoryTransactionHandler creates a new inventory transaction handler
 func NewInventoryTransactionHandler(
 	transactionService models.InventoryTransactionService,
 	productService models.ProductService,
+	locationService models.LocationService,
 ) *InventoryTransactionHandler {
 	return &InventoryTransactionHandler{
 		transactionService: transactionService,
 		productService:     productService,
+		locationService:    locationService,
 	}
 }
 
@@ -250,6 +360,11 @@ func (h *InventoryTransactionHandler) CreateTransaction(w http.ResponseWriter, r
 		return
 	}
 
+	if transaction.UnitCost < 0 {
+		auth.RespondWithError(w, http.StatusBadRequest, "Unit cost cannot be negative")
+		return
+	}
+
 	// Check if product exists
 	product, err := h.productService.GetByID(tenantID, transaction.ProductID)
 	if err != nil {
@@ -262,11 +377,129 @@ func (h *InventoryTransactionHandler) CreateTransaction(w http.ResponseWriter, r
 		return
 	}
 
+	// Check if locations exist
+	for _, locationID := range []string{transaction.LocationID, transaction.DestinationLocationID} {
+		if locationID == "" {
+			continue
+		}
+
+		location, err := h.locationService.GetByID(tenantID, locationID)
+		if err != nil {
+			auth.RespondWithError(w, http.StatusInternalServerError, "Error checking location")
+			return
+		}
+
+		if location == nil {
+			auth.RespondWithError(w, http.StatusNotFound, "Location not found")
+			return
+		}
+	}
+
 	// Create transaction
 	if err := h.transactionService.Create(&transaction); err != nil {
-		auth.RespondWithError(w, http.StatusInternalServerError, "Error creating transaction")
+		respondWithTransactionError(w, err)
 		return
 	}
 
 	auth.RespondWithJSON(w, http.StatusCreated, transaction)
+}
+
+// validateUnits checks that the units of a product exist and its weight and volume per base unit
+// are not negative, and responds with an error if they are invalid. Purchase and sales units need
+// a base unit to convert to.
+func (h *ProductHandler) validateUnits(w http.ResponseWriter, product *models.Product) bool {
+	if product.Weight < 0 || product.Volume < 0 {
+		auth.RespondWithError(w, http.StatusBadRequest, "Weight and volume cannot be negative")
+		return false
+	}
+
+	if product.BaseUnitID == "" && (product.PurchaseUnitID != "" || product.SalesUnitID != "") {
+		auth.RespondWithError(w, http.StatusBadRequest, "Purchase and sales units require a base unit")
+		return false
+	}
+
+	for _, unitID := range []string{product.BaseUnitID, product.PurchaseUnitID, product.SalesUnitID} {
+		if unitID == "" {
+			continue
+		}
+
+		unit, err := h.unitService.GetByID(product.TenantID, unitID)
+		if err != nil {
+			auth.RespondWithError(w, http.StatusInternalServerError, "Error checking unit of measure")
+			return false
+		}
+
+		if unit == nil {
+			auth.RespondWithError(w, http.StatusNotFound, "Unit of measure not found")
+			return false
+		}
+	}
+
+	return true
+}
+
+// validateCategory checks the category and the defaults a product overrides and
+// responds with an error if they are invalid. A new product without a base unit
+// takes the unit of its category.
+func (h *ProductHandler) validateCategory(w http.ResponseWriter, product *models.Product) bool {
+	if product.CategoryID != "" {
+		category, err := h.categoryService.GetByID(product.TenantID, product.CategoryID)
+		if err != nil {
+			auth.RespondWithError(w, http.StatusInternalServerError, "Error checking category")
+			return false
+		}
+
+		if category == nil {
+			auth.RespondWithError(w, http.StatusNotFound, "Category not found")
+			return false
+		}
+
+		if product.ID == "" && product.BaseUnitID == "" {
+			product.BaseUnitID = category.Defaults.UnitID
+		}
+	}
+
+	defaults := models.ProductDefaults{
+		InventoryAccountID: product.InventoryAccountID,
+		COGSAccountID:      product.COGSAccountID,
+		TaxCode:            product.TaxCode,
+		CostingMethod:      product.CostingMethod,
+	}
+	return validateDefaults(w, h.accountService, product.TenantID, defaults)
+}
+
+// validateCustomFields validates the custom field values of a product against the
+// tenant's product field definitions and responds with an error if they are invalid
+func (h *ProductHandler) validateCustomFields(w http.ResponseWriter, product *models.Product) bool {
+	definitions, err := h.customFieldService.List(product.TenantID, models.CustomFieldProduct)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking custom fields")
+		return false
+	}
+
+	if err := customfield.Validate(definitions, product.CustomFields); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return false
+	}
+
+	return true
+}
+
+// validTrackingMode reports whether mode is a known product tracking mode
+func validTrackingMode(mode string) bool {
+	switch mode {
+	case models.TrackingNone, models.TrackingLot, models.TrackingSerial:
+		return true
+	}
+	return false
+}
+
+// respondWithTransactionError responds with the error of a failed inventory movement
+func respondWithTransactionError(w http.ResponseWriter, err error) {
+	var inventoryErr *models.InventoryError
+	if errors.As(err, &inventoryErr) {
+		auth.RespondWithError(w, http.StatusBadRequest, inventoryErr.Error())
+		return
+	}
+	auth.RespondWithError(w, http.StatusInternalServerError, "Error creating transaction")
 }
\ No newline at end of file
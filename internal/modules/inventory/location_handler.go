@@ -0,0 +1,180 @@
+package inventory
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/yookibooki/erp/internal/auth"
+	"github.com/yookibooki/erp/internal/models"
+)
+
+// LocationHandler handles stock location requests
+type LocationHandler struct {
+	locationService models.LocationService
+}
+
+// NewLocationHandler creates a new location handler
+func NewLocationHandler(locationService models.LocationService) *LocationHandler {
+	return &LocationHandler{
+		locationService: locationService,
+	}
+}
+
+// GetLocation gets a location by ID
+func (h *LocationHandler) GetLocation(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	location, err := h.locationService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error getting location")
+		return
+	}
+
+	if location == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Location not found")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, location)
+}
+
+// ListLocations lists all locations for a tenant
+func (h *LocationHandler) ListLocations(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	locations, err := h.locationService.List(tenantID)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error listing locations")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, locations)
+}
+
+// ListStockByProduct lists the stock of a product at each location
+func (h *LocationHandler) ListStockByProduct(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	productID := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	levels, err := h.locationService.ListStockByProduct(tenantID, productID)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error listing stock levels")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, levels)
+}
+
+// CreateLocation creates a new location
+func (h *LocationHandler) CreateLocation(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	var location models.Location
+	if err := json.NewDecoder(r.Body).Decode(&location); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	// Set tenant ID from context
+	location.TenantID = tenantID
+
+	// Validate location
+	if location.Code == "" || location.Name == "" {
+		auth.RespondWithError(w, http.StatusBadRequest, "Code and name are required")
+		return
+	}
+
+	// Check if location already exists
+	existingLocation, err := h.locationService.GetByCode(tenantID, location.Code)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking location")
+		return
+	}
+
+	if existingLocation != nil {
+		auth.RespondWithError(w, http.StatusConflict, "Location with this code already exists")
+		return
+	}
+
+	// Create location
+	if err := h.locationService.Create(&location); err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error creating location")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusCreated, location)
+}
+
+// UpdateLocation updates a location
+func (h *LocationHandler) UpdateLocation(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	var location models.Location
+	if err := json.NewDecoder(r.Body).Decode(&location); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	// Set ID and tenant ID
+	location.ID = id
+	location.TenantID = tenantID
+
+	// Validate location
+	if location.Code == "" || location.Name == "" {
+		auth.RespondWithError(w, http.StatusBadRequest, "Code and name are required")
+		return
+	}
+
+	// Check if location exists
+	existingLocation, err := h.locationService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking location")
+		return
+	}
+
+	if existingLocation == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Location not found")
+		return
+	}
+
+	// Update location
+	if err := h.locationService.Update(&location); err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error updating location")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, location)
+}
+
+// DeleteLocation deletes a location
+func (h *LocationHandler) DeleteLocation(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	// Check if location exists
+	existingLocation, err := h.locationService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking location")
+		return
+	}
+
+	if existingLocation == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Location not found")
+		return
+	}
+
+	// Delete location
+	if err := h.locationService.Delete(tenantID, id); err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error deleting location")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Location deleted successfully"})
+}
\ No newline at end of file
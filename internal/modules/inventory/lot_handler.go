@@ -0,0 +1,92 @@
+package inventory
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/yookibooki/erp/internal/auth"
+	"github.com/yookibooki/erp/internal/models"
+)
+
+// LotHandler handles lot and serial number requests
+type LotHandler struct {
+	lotService     models.LotService
+	productService models.ProductService
+}
+
+// NewLotHandler creates a new lot handler
+func NewLotHandler(lotService models.LotService, productService models.ProductService) *LotHandler {
+	return &LotHandler{
+		lotService:     lotService,
+		productService: productService,
+	}
+}
+
+// ListLotsByProduct lists all lots of a product
+func (h *LotHandler) ListLotsByProduct(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	productID := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	lots, err := h.lotService.ListByProduct(tenantID, productID)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error listing lots")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, lots)
+}
+
+// SuggestFEFO suggests the lots to pick an outbound quantity from
+func (h *LotHandler) SuggestFEFO(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	productID := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	quantity, err := strconv.ParseFloat(r.URL.Query().Get("quantity"), 64)
+	if err != nil || quantity <= 0 {
+		auth.RespondWithError(w, http.StatusBadRequest, "Quantity must be a positive number")
+		return
+	}
+
+	// Check if product exists
+	product, err := h.productService.GetByID(tenantID, productID)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking product")
+		return
+	}
+
+	if product == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Product not found")
+		return
+	}
+
+	suggestion, err := h.lotService.SuggestFEFO(tenantID, productID, quantity)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error suggesting lots")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, suggestion)
+}
+
+// TraceLot lists every movement of a lot or serial number
+func (h *LotHandler) TraceLot(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+	lotNumber := r.URL.Query().Get("lot_number")
+	serialNumber := r.URL.Query().Get("serial_number")
+
+	if lotNumber == "" && serialNumber == "" {
+		auth.RespondWithError(w, http.StatusBadRequest, "Lot number or serial number is required")
+		return
+	}
+
+	trace, err := h.lotService.Trace(tenantID, lotNumber, serialNumber)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error tracing lot")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, trace)
+}
\ No newline at end of file
@@ -0,0 +1,265 @@
+package inventory
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/yookibooki/erp/internal/auth"
+	"github.com/yookibooki/erp/internal/models"
+)
+
+// ReplenishmentHandler handles reorder rule and replenishment requests
+type ReplenishmentHandler struct {
+	reorderRuleService   models.ReorderRuleService
+	replenishmentService models.ReplenishmentService
+	productService       models.ProductService
+	locationService      models.LocationService
+}
+
+// NewReplenishmentHandler creates a new replenishment handler
+func NewReplenishmentHandler(
+	reorderRuleService models.ReorderRuleService,
+	replenishmentService models.ReplenishmentService,
+	productService models.ProductService,
+	locationService models.LocationService,
+) *ReplenishmentHandler {
+	return &ReplenishmentHandler{
+		reorderRuleService:   reorderRuleService,
+		replenishmentService: replenishmentService,
+		productService:       productService,
+		locationService:      locationService,
+	}
+}
+
+// GetReorderRule gets a reorder rule by ID
+func (h *ReplenishmentHandler) GetReorderRule(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	rule, err := h.reorderRuleService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error getting reorder rule")
+		return
+	}
+
+	if rule == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Reorder rule not found")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, rule)
+}
+
+// ListReorderRules lists all reorder rules for a tenant
+func (h *ReplenishmentHandler) ListReorderRules(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	rules, err := h.reorderRuleService.List(tenantID)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error listing reorder rules")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, rules)
+}
+
+// CreateReorderRule creates a new reorder rule
+func (h *ReplenishmentHandler) CreateReorderRule(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	var rule models.ReorderRule
+	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	// Set tenant ID from context
+	rule.TenantID = tenantID
+
+	if !h.validateReorderRule(w, &rule) {
+		return
+	}
+
+	// Create reorder rule
+	if err := h.reorderRuleService.Create(&rule); err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error creating reorder rule")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusCreated, rule)
+}
+
+// UpdateReorderRule updates a reorder rule
+func (h *ReplenishmentHandler) UpdateReorderRule(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	var rule models.ReorderRule
+	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	// Set ID and tenant ID
+	rule.ID = id
+	rule.TenantID = tenantID
+
+	// Check if reorder rule exists
+	existingRule, err := h.reorderRuleService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking reorder rule")
+		return
+	}
+
+	if existingRule == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Reorder rule not found")
+		return
+	}
+
+	if !h.validateReorderRule(w, &rule) {
+		return
+	}
+
+	// Update reorder rule
+	if err := h.reorderRuleService.Update(&rule); err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error updating reorder rule")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, rule)
+}
+
+// DeleteReorderRule deletes a reorder rule
+func (h *ReplenishmentHandler) DeleteReorderRule(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	// Check if reorder rule exists
+	existingRule, err := h.reorderRuleService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking reorder rule")
+		return
+	}
+
+	if existingRule == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Reorder rule not found")
+		return
+	}
+
+	// Delete reorder rule
+	if err := h.reorderRuleService.Delete(tenantID, id); err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error deleting reorder rule")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Reorder rule deleted successfully"})
+}
+
+// GetReplenishmentReport suggests what to reorder from current stock and the
+// average daily consumption over the last days (30 by default). Only lines that
+// need reordering are returned unless all=true.
+func (h *ReplenishmentHandler) GetReplenishmentReport(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	days := models.DefaultConsumptionDays
+	if value := r.URL.Query().Get("days"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			auth.RespondWithError(w, http.StatusBadRequest, "Days must be a positive integer")
+			return
+		}
+		days = parsed
+	}
+
+	lines, err := h.replenishmentService.Report(tenantID, days)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error computing replenishment report")
+		return
+	}
+
+	if r.URL.Query().Get("all") != "true" {
+		needed := []*models.ReplenishmentLine{}
+		for _, line := range lines {
+			if line.NeedsReorder {
+				needed = append(needed, line)
+			}
+		}
+		lines = needed
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, lines)
+}
+
+// validateReorderRule validates a reorder rule and responds with an error if it is invalid
+func (h *ReplenishmentHandler) validateReorderRule(w http.ResponseWriter, rule *models.ReorderRule) bool {
+	if rule.ProductID == "" {
+		auth.RespondWithError(w, http.StatusBadRequest, "Product ID is required")
+		return false
+	}
+
+	if rule.ReorderPoint < 0 || rule.ReorderQuantity < 0 || rule.MaxQuantity < 0 {
+		auth.RespondWithError(w, http.StatusBadRequest, "Quantities cannot be negative")
+		return false
+	}
+
+	switch rule.Policy {
+	case models.ReorderMinMax:
+		if rule.MaxQuantity <= rule.ReorderPoint {
+			auth.RespondWithError(w, http.StatusBadRequest, "Max quantity must be greater than the reorder point")
+			return false
+		}
+	case models.ReorderFixedQuantity:
+		if rule.ReorderQuantity <= 0 {
+			auth.RespondWithError(w, http.StatusBadRequest, "Reorder quantity must be positive")
+			return false
+		}
+	default:
+		auth.RespondWithError(w, http.StatusBadRequest, "Policy must be min_max or reorder_point")
+		return false
+	}
+
+	// Check if product exists
+	product, err := h.productService.GetByID(rule.TenantID, rule.ProductID)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking product")
+		return false
+	}
+
+	if product == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Product not found")
+		return false
+	}
+
+	// Check if location exists
+	if rule.LocationID != "" {
+		location, err := h.locationService.GetByID(rule.TenantID, rule.LocationID)
+		if err != nil {
+			auth.RespondWithError(w, http.StatusInternalServerError, "Error checking location")
+			return false
+		}
+
+		if location == nil {
+			auth.RespondWithError(w, http.StatusNotFound, "Location not found")
+			return false
+		}
+	}
+
+	// Check if another rule exists for the product and location
+	existingRule, err := h.reorderRuleService.GetByProductAndLocation(rule.TenantID, rule.ProductID, rule.LocationID)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking reorder rule")
+		return false
+	}
+
+	if existingRule != nil && existingRule.ID != rule.ID {
+		auth.RespondWithError(w, http.StatusConflict, "Reorder rule for this product and location already exists")
+		return false
+	}
+
+	return true
+}
\ No newline at end of file
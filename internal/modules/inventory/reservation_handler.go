@@ -0,0 +1,291 @@
+package inventory
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/yookibooki/erp/internal/auth"
+	"github.com/yookibooki/erp/internal/models"
+)
+
+// ReservationHandler handles stock reservation and availability requests
+type ReservationHandler struct {
+	reservationService      models.ReservationService
+	scheduledReceiptService models.ScheduledReceiptService
+	productService          models.ProductService
+	locationService         models.LocationService
+}
+
+// NewReservationHandler creates a new reservation handler
+func NewReservationHandler(
+	reservationService models.ReservationService,
+	scheduledReceiptService models.ScheduledReceiptService,
+	productService models.ProductService,
+	locationService models.LocationService,
+) *ReservationHandler {
+	return &ReservationHandler{
+		reservationService:      reservationService,
+		scheduledReceiptService: scheduledReceiptService,
+		productService:          productService,
+		locationService:         locationService,
+	}
+}
+
+// GetReservation gets a reservation by ID
+func (h *ReservationHandler) GetReservation(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	reservation, err := h.reservationService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error getting reservation")
+		return
+	}
+
+	if reservation == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Reservation not found")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, reservation)
+}
+
+// ListReservationsByProduct lists all reservations for a product
+func (h *ReservationHandler) ListReservationsByProduct(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	productID := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	reservations, err := h.reservationService.ListByProduct(tenantID, productID)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error listing reservations")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, reservations)
+}
+
+// CreateReservation reserves available stock of a product
+func (h *ReservationHandler) CreateReservation(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+	userID := auth.GetUserIDFromContext(r.Context())
+
+	var reservation models.Reservation
+	if err := json.NewDecoder(r.Body).Decode(&reservation); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	// Set tenant ID and created by from context
+	reservation.TenantID = tenantID
+	reservation.CreatedBy = userID
+
+	// Validate reservation
+	if reservation.ProductID == "" {
+		auth.RespondWithError(w, http.StatusBadRequest, "Product ID is required")
+		return
+	}
+
+	if reservation.Quantity <= 0 {
+		auth.RespondWithError(w, http.StatusBadRequest, "Quantity must be positive")
+		return
+	}
+
+	if reservation.ExpiresAt != nil && !reservation.ExpiresAt.After(time.Now()) {
+		auth.RespondWithError(w, http.StatusBadRequest, "Expiry must be in the future")
+		return
+	}
+
+	if !h.checkProductAndLocation(w, tenantID, reservation.ProductID, reservation.LocationID) {
+		return
+	}
+
+	// Create reservation
+	if err := h.reservationService.Create(&reservation); err != nil {
+		var inventoryErr *models.InventoryError
+		if errors.As(err, &inventoryErr) {
+			auth.RespondWithError(w, http.StatusConflict, inventoryErr.Error())
+			return
+		}
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error creating reservation")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusCreated, reservation)
+}
+
+// ReleaseReservation releases the stock held by an active reservation
+func (h *ReservationHandler) ReleaseReservation(w http.ResponseWriter, r *http.Request) {
+	h.closeReservation(w, r, models.ReservationReleased)
+}
+
+// FulfillReservation marks an active reservation as fulfilled
+func (h *ReservationHandler) FulfillReservation(w http.ResponseWriter, r *http.Request) {
+	h.closeReservation(w, r, models.ReservationFulfilled)
+}
+
+// closeReservation moves an active reservation to status
+func (h *ReservationHandler) closeReservation(w http.ResponseWriter, r *http.Request, status string) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	// Check if reservation exists
+	reservation, err := h.reservationService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking reservation")
+		return
+	}
+
+	if reservation == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Reservation not found")
+		return
+	}
+
+	if reservation.Status != models.ReservationActive {
+		auth.RespondWithError(w, http.StatusConflict, "Reservation is not active")
+		return
+	}
+
+	// Update reservation
+	if err := h.reservationService.UpdateStatus(tenantID, id, status); err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error updating reservation")
+		return
+	}
+
+	reservation.Status = status
+	auth.RespondWithJSON(w, http.StatusOK, reservation)
+}
+
+// GetAvailability projects the available-to-promise quantity of a product by date
+func (h *ReservationHandler) GetAvailability(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	productID := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	if !h.checkProductAndLocation(w, tenantID, productID, "") {
+		return
+	}
+
+	projection, err := h.reservationService.ProjectAvailability(tenantID, productID)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error projecting availability")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, projection)
+}
+
+// ListScheduledReceiptsByProduct lists all scheduled receipts for a product
+func (h *ReservationHandler) ListScheduledReceiptsByProduct(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	productID := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	receipts, err := h.scheduledReceiptService.ListByProduct(tenantID, productID)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error listing scheduled receipts")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, receipts)
+}
+
+// CreateScheduledReceipt records an expected inbound movement of a product
+func (h *ReservationHandler) CreateScheduledReceipt(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	var receipt models.ScheduledReceipt
+	if err := json.NewDecoder(r.Body).Decode(&receipt); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	// Set tenant ID from context
+	receipt.TenantID = tenantID
+
+	// Validate scheduled receipt
+	if receipt.ProductID == "" || receipt.ExpectedDate.IsZero() {
+		auth.RespondWithError(w, http.StatusBadRequest, "Product ID and expected date are required")
+		return
+	}
+
+	if receipt.Quantity <= 0 {
+		auth.RespondWithError(w, http.StatusBadRequest, "Quantity must be positive")
+		return
+	}
+
+	if !h.checkProductAndLocation(w, tenantID, receipt.ProductID, receipt.LocationID) {
+		return
+	}
+
+	// Create scheduled receipt
+	if err := h.scheduledReceiptService.Create(&receipt); err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error creating scheduled receipt")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusCreated, receipt)
+}
+
+// DeleteScheduledReceipt deletes a scheduled receipt
+func (h *ReservationHandler) DeleteScheduledReceipt(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	// Check if scheduled receipt exists
+	receipt, err := h.scheduledReceiptService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking scheduled receipt")
+		return
+	}
+
+	if receipt == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Scheduled receipt not found")
+		return
+	}
+
+	// Delete scheduled receipt
+	if err := h.scheduledReceiptService.Delete(tenantID, id); err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error deleting scheduled receipt")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Scheduled receipt deleted successfully"})
+}
+
+// checkProductAndLocation responds with an error if the product or the optional location does not exist
+func (h *ReservationHandler) checkProductAndLocation(w http.ResponseWriter, tenantID, productID, locationID string) bool {
+	product, err := h.productService.GetByID(tenantID, productID)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking product")
+		return false
+	}
+
+	if product == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Product not found")
+		return false
+	}
+
+	if locationID == "" {
+		return true
+	}
+
+	location, err := h.locationService.GetByID(tenantID, locationID)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking location")
+		return false
+	}
+
+	if location == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Location not found")
+		return false
+	}
+
+	return true
+}
\ No newline at end of file
@@ -0,0 +1,110 @@
+package inventory
+
+import (
+	"encoding/csv"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/yookibooki/erp/internal/auth"
+	"github.com/yookibooki/erp/internal/models"
+)
+
+// StockLedgerHandler handles point-in-time stock and stock consistency requests
+type StockLedgerHandler struct {
+	ledgerService   models.StockLedgerService
+	locationService models.LocationService
+}
+
+// NewStockLedgerHandler creates a new stock ledger handler
+func NewStockLedgerHandler(ledgerService models.StockLedgerService, locationService models.LocationService) *StockLedgerHandler {
+	return &StockLedgerHandler{
+		ledgerService:   ledgerService,
+		locationService: locationService,
+	}
+}
+
+// GetStockOnHand reports the stock on hand as of the end of the as_of date, or now,
+// recomputed from the inventory transactions. The stock is limited to location_id when
+// given, and format=csv returns the report as a CSV file.
+func (h *StockLedgerHandler) GetStockOnHand(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+	params := r.URL.Query()
+
+	asOf := time.Now()
+	if value := params.Get("as_of"); value != "" {
+		date, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			auth.RespondWithError(w, http.StatusBadRequest, "As of date must be formatted as YYYY-MM-DD")
+			return
+		}
+		asOf = date.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+
+	format := params.Get("format")
+	if format != "" && format != "json" && format != "csv" {
+		auth.RespondWithError(w, http.StatusBadRequest, "Format must be json or csv")
+		return
+	}
+
+	// Check if location exists
+	locationID := params.Get("location_id")
+	if locationID != "" {
+		location, err := h.locationService.GetByID(tenantID, locationID)
+		if err != nil {
+			auth.RespondWithError(w, http.StatusInternalServerError, "Error checking location")
+			return
+		}
+
+		if location == nil {
+			auth.RespondWithError(w, http.StatusNotFound, "Location not found")
+			return
+		}
+	}
+
+	stock, err := h.ledgerService.StockOnHand(tenantID, asOf, locationID)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error getting stock on hand")
+		return
+	}
+
+	if format == "csv" {
+		writeStockOnHandCSV(w, stock)
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, stock)
+}
+
+// CheckConsistency reports the drift between stored stock quantities and the ledger
+func (h *StockLedgerHandler) CheckConsistency(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	report, err := h.ledgerService.CheckConsistency(tenantID)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking stock consistency")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, report)
+}
+
+// writeStockOnHandCSV writes a stock on hand report as a CSV file with a header row
+func writeStockOnHandCSV(w http.ResponseWriter, stock *models.StockOnHand) {
+	filename := "stock-" + stock.AsOf.Format("2006-01-02") + ".csv"
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	w.WriteHeader(http.StatusOK)
+
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"product_id", "product_code", "product_name", "quantity"})
+	for _, line := range stock.Lines {
+		writer.Write([]string{
+			line.ProductID,
+			line.ProductCode,
+			line.ProductName,
+			strconv.FormatFloat(line.Quantity, 'f', -1, 64),
+		})
+	}
+	writer.Flush()
+}
\ No newline at end of file
@@ -0,0 +1,487 @@
+package inventory
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/yookibooki/erp/internal/auth"
+	"github.com/yookibooki/erp/internal/models"
+)
+
+// Default cycle count policy
+const (
+	defaultCycleCountDays = 365
+	defaultClassAInterval = 30
+	defaultClassBInterval = 90
+	defaultClassCInterval = 180
+)
+
+// StocktakeHandler handles stocktake and cycle count requests
+type StocktakeHandler struct {
+	stocktakeService models.StocktakeService
+	productService   models.ProductService
+	locationService  models.LocationService
+}
+
+// NewStocktakeHandler creates a new stocktake handler
+func NewStocktakeHandler(
+	stocktakeService models.StocktakeService,
+	productService models.ProductService,
+	locationService models.LocationService,
+) *StocktakeHandler {
+	return &StocktakeHandler{
+		stocktakeService: stocktakeService,
+		productService:   productService,
+		locationService:  locationService,
+	}
+}
+
+// GetStocktake gets a stocktake by ID with its lines
+func (h *StocktakeHandler) GetStocktake(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	stocktake, err := h.stocktakeService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error getting stocktake")
+		return
+	}
+
+	if stocktake == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Stocktake not found")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, stocktake)
+}
+
+// ListStocktakes lists all stocktakes for a tenant, optionally filtered by status
+func (h *StocktakeHandler) ListStocktakes(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	stocktakes, err := h.stocktakeService.List(tenantID, r.URL.Query().Get("status"))
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error listing stocktakes")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, stocktakes)
+}
+
+// CreateStocktake creates a new stocktake and freezes the quantities expected to be counted
+func (h *StocktakeHandler) CreateStocktake(w http.ResponseWriter, r *http.Request) {
+	var stocktake models.Stocktake
+	if err := json.NewDecoder(r.Body).Decode(&stocktake); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	h.createStocktake(w, r, &stocktake)
+}
+
+// GetVariances lists the counted lines of a stocktake whose quantity differs from the expected quantity
+func (h *StocktakeHandler) GetVariances(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	stocktake := h.findStocktake(w, tenantID, id)
+	if stocktake == nil {
+		return
+	}
+
+	variances := []models.StocktakeLine{}
+	for _, line := range stocktake.Lines {
+		if line.CountedQuantity != nil && line.Variance != 0 {
+			variances = append(variances, line)
+		}
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, variances)
+}
+
+// RecordCounts records counted quantities on an open stocktake. The body is a JSON array
+// of counts or, with a text/csv content type, a CSV file with a header row naming the
+// product_code or product_id, lot_number, serial_number and counted_quantity columns.
+func (h *StocktakeHandler) RecordCounts(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+	userID := auth.GetUserIDFromContext(r.Context())
+
+	var counts []models.StocktakeCount
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "text/csv" {
+		parsed, err := parseCountsCSV(r.Body)
+		if err != nil {
+			auth.RespondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		counts = parsed
+	} else if err := json.NewDecoder(r.Body).Decode(&counts); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	for _, count := range counts {
+		if count.ProductID == "" && count.ProductCode == "" {
+			auth.RespondWithError(w, http.StatusBadRequest, "Product ID or code is required")
+			return
+		}
+	}
+
+	h.changeStocktake(w, r, func(id string) error {
+		return h.stocktakeService.RecordCounts(tenantID, id, userID, counts)
+	})
+}
+
+// CountLine records the counted quantity of a line of an open stocktake
+func (h *StocktakeHandler) CountLine(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	lineID := vars["lineId"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+	userID := auth.GetUserIDFromContext(r.Context())
+
+	var count models.StocktakeCount
+	if err := json.NewDecoder(r.Body).Decode(&count); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	stocktake := h.findStocktake(w, tenantID, vars["id"])
+	if stocktake == nil {
+		return
+	}
+
+	found := false
+	for _, line := range stocktake.Lines {
+		if line.ID == lineID {
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		auth.RespondWithError(w, http.StatusNotFound, "Stocktake line not found")
+		return
+	}
+
+	h.changeStocktake(w, r, func(id string) error {
+		return h.stocktakeService.CountLine(tenantID, id, lineID, userID, count.CountedQuantity)
+	})
+}
+
+// ApproveStocktake approves a counted stocktake and adjusts stock for its variances
+func (h *StocktakeHandler) ApproveStocktake(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+	userID := auth.GetUserIDFromContext(r.Context())
+
+	h.changeStocktake(w, r, func(id string) error {
+		return h.stocktakeService.Approve(tenantID, id, userID)
+	})
+}
+
+// CancelStocktake cancels an open stocktake
+func (h *StocktakeHandler) CancelStocktake(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	h.changeStocktake(w, r, func(id string) error {
+		return h.stocktakeService.Cancel(tenantID, id)
+	})
+}
+
+// GetCycleCountSchedule lists products with their ABC class and next count date.
+// Only products due to be counted are returned unless all=true.
+func (h *StocktakeHandler) GetCycleCountSchedule(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	policy, ok := cycleCountPolicy(w, r)
+	if !ok {
+		return
+	}
+
+	items, err := h.stocktakeService.CycleCountSchedule(tenantID, policy)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error computing cycle count schedule")
+		return
+	}
+
+	if r.URL.Query().Get("all") != "true" {
+		due := []*models.CycleCountItem{}
+		for _, item := range items {
+			if item.Due {
+				due = append(due, item)
+			}
+		}
+		items = due
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, items)
+}
+
+// CreateCycleCount creates a stocktake of the products due to be counted,
+// optionally only those of the ABC class given by ?class=
+func (h *StocktakeHandler) CreateCycleCount(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	var stocktake models.Stocktake
+	if err := json.NewDecoder(r.Body).Decode(&stocktake); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	policy, ok := cycleCountPolicy(w, r)
+	if !ok {
+		return
+	}
+
+	class := r.URL.Query().Get("class")
+	if class != "" && class != models.ABCClassA && class != models.ABCClassB && class != models.ABCClassC {
+		auth.RespondWithError(w, http.StatusBadRequest, "Class must be A, B or C")
+		return
+	}
+
+	items, err := h.stocktakeService.CycleCountSchedule(tenantID, policy)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error computing cycle count schedule")
+		return
+	}
+
+	stocktake.ProductIDs = []string{}
+	for _, item := range items {
+		if item.Due && (class == "" || item.Class == class) {
+			stocktake.ProductIDs = append(stocktake.ProductIDs, item.ProductID)
+		}
+	}
+
+	if len(stocktake.ProductIDs) == 0 {
+		auth.RespondWithError(w, http.StatusBadRequest, models.ErrStocktakeEmpty.Error())
+		return
+	}
+
+	h.createStocktake(w, r, &stocktake)
+}
+
+// createStocktake validates and creates a stocktake and responds with it
+func (h *StocktakeHandler) createStocktake(w http.ResponseWriter, r *http.Request, stocktake *models.Stocktake) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+	userID := auth.GetUserIDFromContext(r.Context())
+
+	// Set tenant ID and created by from context
+	stocktake.TenantID = tenantID
+	stocktake.CreatedBy = userID
+
+	if !h.validateStocktake(w, stocktake) {
+		return
+	}
+
+	// Check if stocktake already exists
+	existingStocktake, err := h.stocktakeService.GetByNumber(tenantID, stocktake.Number)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking stocktake")
+		return
+	}
+
+	if existingStocktake != nil {
+		auth.RespondWithError(w, http.StatusConflict, "Stocktake with this number already exists")
+		return
+	}
+
+	// Create stocktake
+	if err := h.stocktakeService.Create(stocktake); err != nil {
+		respondWithStocktakeError(w, err, "Error creating stocktake")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusCreated, stocktake)
+}
+
+// changeStocktake applies a change to a stocktake and responds with the updated stocktake
+func (h *StocktakeHandler) changeStocktake(w http.ResponseWriter, r *http.Request, change func(id string) error) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	if h.findStocktake(w, tenantID, id) == nil {
+		return
+	}
+
+	if err := change(id); err != nil {
+		respondWithStocktakeError(w, err, "Error updating stocktake")
+		return
+	}
+
+	stocktake, err := h.stocktakeService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error getting stocktake")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, stocktake)
+}
+
+// findStocktake gets a stocktake and responds with an error if it cannot be found
+func (h *StocktakeHandler) findStocktake(w http.ResponseWriter, tenantID, id string) *models.Stocktake {
+	stocktake, err := h.stocktakeService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking stocktake")
+		return nil
+	}
+
+	if stocktake == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Stocktake not found")
+		return nil
+	}
+
+	return stocktake
+}
+
+// validateStocktake validates a stocktake and responds with an error if it is invalid
+func (h *StocktakeHandler) validateStocktake(w http.ResponseWriter, stocktake *models.Stocktake) bool {
+	if stocktake.Number == "" {
+		auth.RespondWithError(w, http.StatusBadRequest, "Number is required")
+		return false
+	}
+
+	// Check if location exists
+	if stocktake.LocationID != "" {
+		location, err := h.locationService.GetByID(stocktake.TenantID, stocktake.LocationID)
+		if err != nil {
+			auth.RespondWithError(w, http.StatusInternalServerError, "Error checking location")
+			return false
+		}
+
+		if location == nil {
+			auth.RespondWithError(w, http.StatusNotFound, "Location not found")
+			return false
+		}
+	}
+
+	// Check if products exist
+	for _, productID := range stocktake.ProductIDs {
+		product, err := h.productService.GetByID(stocktake.TenantID, productID)
+		if err != nil {
+			auth.RespondWithError(w, http.StatusInternalServerError, "Error checking product")
+			return false
+		}
+
+		if product == nil {
+			auth.RespondWithError(w, http.StatusNotFound, "Product not found")
+			return false
+		}
+	}
+
+	return true
+}
+
+// cycleCountPolicy reads a cycle count policy from the query parameters of a request:
+// days sets the consumption period and a_days, b_days and c_days the count interval of
+// each class. It responds with an error if a parameter is invalid.
+func cycleCountPolicy(w http.ResponseWriter, r *http.Request) (*models.CycleCountPolicy, bool) {
+	policy := &models.CycleCountPolicy{
+		Days:           defaultCycleCountDays,
+		ClassAInterval: defaultClassAInterval,
+		ClassBInterval: defaultClassBInterval,
+		ClassCInterval: defaultClassCInterval,
+	}
+
+	params := map[string]*int{
+		"days":   &policy.Days,
+		"a_days": &policy.ClassAInterval,
+		"b_days": &policy.ClassBInterval,
+		"c_days": &policy.ClassCInterval,
+	}
+	for name, target := range params {
+		value := r.URL.Query().Get(name)
+		if value == "" {
+			continue
+		}
+
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			auth.RespondWithError(w, http.StatusBadRequest, "Days must be a positive integer")
+			return nil, false
+		}
+		*target = parsed
+	}
+
+	return policy, true
+}
+
+// parseCountsCSV reads stocktake counts from a CSV file with a header row
+func parseCountsCSV(body io.Reader) ([]models.StocktakeCount, error) {
+	reader := csv.NewReader(body)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.New("CSV file must start with a header row")
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	quantityColumn, ok := columns["counted_quantity"]
+	if !ok {
+		return nil, errors.New("CSV file must have a counted_quantity column")
+	}
+
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	counts := []models.StocktakeCount{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.New("Invalid CSV file")
+		}
+
+		quantity, err := strconv.ParseFloat(strings.TrimSpace(record[quantityColumn]), 64)
+		if err != nil {
+			return nil, errors.New("Counted quantity must be a number")
+		}
+
+		counts = append(counts, models.StocktakeCount{
+			ProductID:       field(record, "product_id"),
+			ProductCode:     field(record, "product_code"),
+			LotNumber:       field(record, "lot_number"),
+			SerialNumber:    field(record, "serial_number"),
+			CountedQuantity: quantity,
+		})
+	}
+
+	return counts, nil
+}
+
+// respondWithStocktakeError responds with the error of a failed stocktake request.
+// Actions the stocktake's status does not allow are conflicts; other inventory rule
+// violations, including those of the adjustments posted on approval, are bad requests.
+func respondWithStocktakeError(w http.ResponseWriter, err error, message string) {
+	if errors.Is(err, models.ErrStocktakeStatus) {
+		auth.RespondWithError(w, http.StatusConflict, err.Error())
+		return
+	}
+
+	var inventoryErr *models.InventoryError
+	if errors.As(err, &inventoryErr) {
+		auth.RespondWithError(w, http.StatusBadRequest, inventoryErr.Error())
+		return
+	}
+
+	auth.RespondWithError(w, http.StatusInternalServerError, message)
+}
\ No newline at end of file
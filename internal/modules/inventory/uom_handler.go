@@ -0,0 +1,350 @@
+package inventory
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/yookibooki/erp/internal/auth"
+	"github.com/yookibooki/erp/internal/models"
+)
+
+// UnitOfMeasureHandler handles unit of measure and product unit conversion requests
+type UnitOfMeasureHandler struct {
+	unitService        models.UnitOfMeasureService
+	productUnitService models.ProductUnitService
+	productService     models.ProductService
+}
+
+// NewUnitOfMeasureHandler creates a new unit of measure handler
+func NewUnitOfMeasureHandler(
+	unitService models.UnitOfMeasureService,
+	productUnitService models.ProductUnitService,
+	productService models.ProductService,
+) *UnitOfMeasureHandler {
+	return &UnitOfMeasureHandler{
+		unitService:        unitService,
+		productUnitService: productUnitService,
+		productService:     productService,
+	}
+}
+
+// GetUnit gets a unit of measure by ID
+func (h *UnitOfMeasureHandler) GetUnit(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	unit, err := h.unitService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error getting unit of measure")
+		return
+	}
+
+	if unit == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Unit of measure not found")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, unit)
+}
+
+// ListUnits lists all units of measure for a tenant
+func (h *UnitOfMeasureHandler) ListUnits(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	units, err := h.unitService.List(tenantID)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error listing units of measure")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, units)
+}
+
+// CreateUnit creates a new unit of measure
+func (h *UnitOfMeasureHandler) CreateUnit(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	var unit models.UnitOfMeasure
+	if err := json.NewDecoder(r.Body).Decode(&unit); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	// Set tenant ID from context
+	unit.TenantID = tenantID
+
+	// Validate unit of measure
+	if unit.Code == "" || unit.Name == "" {
+		auth.RespondWithError(w, http.StatusBadRequest, "Code and name are required")
+		return
+	}
+
+	// Check if unit of measure already exists
+	existingUnit, err := h.unitService.GetByCode(tenantID, unit.Code)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking unit of measure")
+		return
+	}
+
+	if existingUnit != nil {
+		auth.RespondWithError(w, http.StatusConflict, "Unit of measure with this code already exists")
+		return
+	}
+
+	// Create unit of measure
+	if err := h.unitService.Create(&unit); err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error creating unit of measure")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusCreated, unit)
+}
+
+// UpdateUnit updates a unit of measure
+func (h *UnitOfMeasureHandler) UpdateUnit(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	var unit models.UnitOfMeasure
+	if err := json.NewDecoder(r.Body).Decode(&unit); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	// Set ID and tenant ID
+	unit.ID = id
+	unit.TenantID = tenantID
+
+	// Validate unit of measure
+	if unit.Code == "" || unit.Name == "" {
+		auth.RespondWithError(w, http.StatusBadRequest, "Code and name are required")
+		return
+	}
+
+	// Check if unit of measure exists
+	existingUnit, err := h.unitService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking unit of measure")
+		return
+	}
+
+	if existingUnit == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Unit of measure not found")
+		return
+	}
+
+	// Check if another unit of measure has the code
+	conflictingUnit, err := h.unitService.GetByCode(tenantID, unit.Code)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking unit of measure")
+		return
+	}
+
+	if conflictingUnit != nil && conflictingUnit.ID != id {
+		auth.RespondWithError(w, http.StatusConflict, "Unit of measure with this code already exists")
+		return
+	}
+
+	// Update unit of measure
+	if err := h.unitService.Update(&unit); err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error updating unit of measure")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, unit)
+}
+
+// DeleteUnit deletes a unit of measure
+func (h *UnitOfMeasureHandler) DeleteUnit(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	// Check if unit of measure exists
+	existingUnit, err := h.unitService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking unit of measure")
+		return
+	}
+
+	if existingUnit == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Unit of measure not found")
+		return
+	}
+
+	// Delete unit of measure
+	if err := h.unitService.Delete(tenantID, id); err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error deleting unit of measure")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Unit of measure deleted successfully"})
+}
+
+// ListProductUnits lists the unit conversions of a product
+func (h *UnitOfMeasureHandler) ListProductUnits(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	productID := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	productUnits, err := h.productUnitService.ListByProduct(tenantID, productID)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error listing product units")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, productUnits)
+}
+
+// CreateProductUnit adds a unit conversion to a product
+func (h *UnitOfMeasureHandler) CreateProductUnit(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	productID := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	var productUnit models.ProductUnit
+	if err := json.NewDecoder(r.Body).Decode(&productUnit); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	// Set product and tenant ID
+	productUnit.ProductID = productID
+	productUnit.TenantID = tenantID
+
+	// Validate product unit
+	if productUnit.UnitID == "" {
+		auth.RespondWithError(w, http.StatusBadRequest, "Unit ID is required")
+		return
+	}
+
+	if productUnit.Factor <= 0 {
+		auth.RespondWithError(w, http.StatusBadRequest, "Factor must be positive")
+		return
+	}
+
+	// Check if product exists and has a base unit to convert to
+	product, err := h.productService.GetByID(tenantID, productID)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking product")
+		return
+	}
+
+	if product == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Product not found")
+		return
+	}
+
+	if product.BaseUnitID == "" {
+		auth.RespondWithError(w, http.StatusBadRequest, "Product has no base unit")
+		return
+	}
+
+	if product.BaseUnitID == productUnit.UnitID {
+		auth.RespondWithError(w, http.StatusBadRequest, "The base unit needs no conversion")
+		return
+	}
+
+	// Check if unit of measure exists
+	unit, err := h.unitService.GetByID(tenantID, productUnit.UnitID)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking unit of measure")
+		return
+	}
+
+	if unit == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Unit of measure not found")
+		return
+	}
+
+	// Check if product unit already exists
+	existingProductUnit, err := h.productUnitService.GetByProductAndUnit(tenantID, productID, productUnit.UnitID)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking product unit")
+		return
+	}
+
+	if existingProductUnit != nil {
+		auth.RespondWithError(w, http.StatusConflict, "Product already has a conversion for this unit")
+		return
+	}
+
+	// Create product unit
+	if err := h.productUnitService.Create(&productUnit); err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error creating product unit")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusCreated, productUnit)
+}
+
+// UpdateProductUnit updates the factor of a product unit conversion
+func (h *UnitOfMeasureHandler) UpdateProductUnit(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	var productUnit models.ProductUnit
+	if err := json.NewDecoder(r.Body).Decode(&productUnit); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if productUnit.Factor <= 0 {
+		auth.RespondWithError(w, http.StatusBadRequest, "Factor must be positive")
+		return
+	}
+
+	// Check if product unit exists
+	existingProductUnit, err := h.productUnitService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking product unit")
+		return
+	}
+
+	if existingProductUnit == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Product unit not found")
+		return
+	}
+
+	// Only the factor can change
+	existingProductUnit.Factor = productUnit.Factor
+
+	// Update product unit
+	if err := h.productUnitService.Update(existingProductUnit); err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error updating product unit")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, existingProductUnit)
+}
+
+// DeleteProductUnit deletes a product unit conversion
+func (h *UnitOfMeasureHandler) DeleteProductUnit(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	// Check if product unit exists
+	existingProductUnit, err := h.productUnitService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking product unit")
+		return
+	}
+
+	if existingProductUnit == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Product unit not found")
+		return
+	}
+
+	// Delete product unit
+	if err := h.productUnitService.Delete(tenantID, id); err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error deleting product unit")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Product unit deleted successfully"})
+}
\ No newline at end of file
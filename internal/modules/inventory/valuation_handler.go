@@ -0,0 +1,44 @@
+package inventory
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/yookibooki/erp/internal/auth"
+	"github.com/yookibooki/erp/internal/models"
+)
+
+// ValuationHandler handles stock valuation requests
+type ValuationHandler struct {
+	valuationService models.StockValuationService
+}
+
+// NewValuationHandler creates a new valuation handler
+func NewValuationHandler(valuationService models.StockValuationService) *ValuationHandler {
+	return &ValuationHandler{
+		valuationService: valuationService,
+	}
+}
+
+// GetValuation values stock on hand as of the end of the as_of date, or now
+func (h *ValuationHandler) GetValuation(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	asOf := time.Now()
+	if value := r.URL.Query().Get("as_of"); value != "" {
+		date, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			auth.RespondWithError(w, http.StatusBadRequest, "As of date must be formatted as YYYY-MM-DD")
+			return
+		}
+		asOf = date.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+
+	valuation, err := h.valuationService.Valuation(tenantID, asOf)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error valuing stock")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, valuation)
+}
\ No newline at end of file
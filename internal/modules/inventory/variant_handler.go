@@ -0,0 +1,353 @@
+package inventory
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/yookibooki/erp/internal/auth"
+	"github.com/yookibooki/erp/internal/models"
+)
+
+// ProductTemplateHandler handles product template and variant requests
+type ProductTemplateHandler struct {
+	templateService models.ProductTemplateService
+	unitService     models.UnitOfMeasureService
+}
+
+// NewProductTemplateHandler creates a new product template handler
+func NewProductTemplateHandler(
+	templateService models.ProductTemplateService,
+	unitService models.UnitOfMeasureService,
+) *ProductTemplateHandler {
+	return &ProductTemplateHandler{
+		templateService: templateService,
+		unitService:     unitService,
+	}
+}
+
+// GetTemplate gets a product template by ID with its variants
+func (h *ProductTemplateHandler) GetTemplate(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	template, err := h.templateService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error getting product template")
+		return
+	}
+
+	if template == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Product template not found")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, template)
+}
+
+// ListTemplates lists product templates with their variants grouped under them.
+// Query parameters filter variants by attribute, e.g. ?size=M&color=Red.
+func (h *ProductTemplateHandler) ListTemplates(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	templates, err := h.templateService.List(tenantID, attributeFilter(r))
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error listing product templates")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, templates)
+}
+
+// CreateTemplate creates a new product template
+func (h *ProductTemplateHandler) CreateTemplate(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	var template models.ProductTemplate
+	if err := json.NewDecoder(r.Body).Decode(&template); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	// Set tenant ID from context
+	template.TenantID = tenantID
+
+	if !h.validateTemplate(w, &template) {
+		return
+	}
+
+	// Check if product template already exists
+	existingTemplate, err := h.templateService.GetByCode(tenantID, template.Code)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking product template")
+		return
+	}
+
+	if existingTemplate != nil {
+		auth.RespondWithError(w, http.StatusConflict, "Product template with this code already exists")
+		return
+	}
+
+	// Create product template
+	if err := h.templateService.Create(&template); err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error creating product template")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusCreated, template)
+}
+
+// UpdateTemplate updates a product template and its attributes
+func (h *ProductTemplateHandler) UpdateTemplate(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	var template models.ProductTemplate
+	if err := json.NewDecoder(r.Body).Decode(&template); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	// Set ID and tenant ID
+	template.ID = id
+	template.TenantID = tenantID
+
+	if !h.validateTemplate(w, &template) {
+		return
+	}
+
+	// Check if product template exists
+	existingTemplate, err := h.templateService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking product template")
+		return
+	}
+
+	if existingTemplate == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Product template not found")
+		return
+	}
+
+	// Check if another product template has the code
+	conflictingTemplate, err := h.templateService.GetByCode(tenantID, template.Code)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking product template")
+		return
+	}
+
+	if conflictingTemplate != nil && conflictingTemplate.ID != id {
+		auth.RespondWithError(w, http.StatusConflict, "Product template with this code already exists")
+		return
+	}
+
+	// Update product template
+	if err := h.templateService.Update(&template); err != nil {
+		respondWithTemplateError(w, err, "Error updating product template")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, template)
+}
+
+// DeleteTemplate deletes a product template without variants
+func (h *ProductTemplateHandler) DeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	// Check if product template exists
+	existingTemplate, err := h.templateService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking product template")
+		return
+	}
+
+	if existingTemplate == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Product template not found")
+		return
+	}
+
+	// Delete product template
+	if err := h.templateService.Delete(tenantID, id); err != nil {
+		respondWithTemplateError(w, err, "Error deleting product template")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Product template deleted successfully"})
+}
+
+// ListVariants lists the variants of a product template, filtered by attribute like ListTemplates
+func (h *ProductTemplateHandler) ListVariants(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	variants, err := h.templateService.ListVariants(tenantID, id, attributeFilter(r))
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error listing variants")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, variants)
+}
+
+// CreateVariant creates a variant of a product template from its attribute values
+func (h *ProductTemplateHandler) CreateVariant(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	var variant models.Product
+	if err := json.NewDecoder(r.Body).Decode(&variant); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	// Set template and tenant ID
+	variant.TemplateID = id
+	variant.TenantID = tenantID
+
+	if variant.TrackingMode != "" && !validTrackingMode(variant.TrackingMode) {
+		auth.RespondWithError(w, http.StatusBadRequest, "Tracking mode must be none, lot or serial")
+		return
+	}
+
+	if !h.findTemplate(w, tenantID, id) {
+		return
+	}
+
+	// Create variant
+	if err := h.templateService.CreateVariant(&variant); err != nil {
+		respondWithTemplateError(w, err, "Error creating variant")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusCreated, variant)
+}
+
+// GenerateVariants creates the variants a product template is missing for its attribute values
+func (h *ProductTemplateHandler) GenerateVariants(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	if !h.findTemplate(w, tenantID, id) {
+		return
+	}
+
+	variants, err := h.templateService.GenerateVariants(tenantID, id)
+	if err != nil {
+		respondWithTemplateError(w, err, "Error generating variants")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusCreated, variants)
+}
+
+// findTemplate responds with an error if a product template does not exist
+func (h *ProductTemplateHandler) findTemplate(w http.ResponseWriter, tenantID, id string) bool {
+	template, err := h.templateService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking product template")
+		return false
+	}
+
+	if template == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Product template not found")
+		return false
+	}
+
+	return true
+}
+
+// validateTemplate checks a product template and its attributes and responds with an error if they are invalid
+func (h *ProductTemplateHandler) validateTemplate(w http.ResponseWriter, template *models.ProductTemplate) bool {
+	if template.Code == "" || template.Name == "" {
+		auth.RespondWithError(w, http.StatusBadRequest, "Code and name are required")
+		return false
+	}
+
+	if template.TrackingMode == "" {
+		template.TrackingMode = models.TrackingNone
+	}
+
+	if !validTrackingMode(template.TrackingMode) {
+		auth.RespondWithError(w, http.StatusBadRequest, "Tracking mode must be none, lot or serial")
+		return false
+	}
+
+	if len(template.Attributes) == 0 {
+		auth.RespondWithError(w, http.StatusBadRequest, "At least one attribute is required")
+		return false
+	}
+
+	names := map[string]bool{}
+	for _, attribute := range template.Attributes {
+		if attribute.Name == "" || len(attribute.Values) == 0 {
+			auth.RespondWithError(w, http.StatusBadRequest, "Attributes require a name and at least one value")
+			return false
+		}
+
+		if names[attribute.Name] {
+			auth.RespondWithError(w, http.StatusBadRequest, "Attribute names must be unique")
+			return false
+		}
+		names[attribute.Name] = true
+
+		values := map[string]bool{}
+		for _, value := range attribute.Values {
+			if value == "" || values[value] {
+				auth.RespondWithError(w, http.StatusBadRequest, "Attribute values must be unique and not empty")
+				return false
+			}
+			values[value] = true
+		}
+	}
+
+	// Check if base unit exists
+	if template.BaseUnitID != "" {
+		unit, err := h.unitService.GetByID(template.TenantID, template.BaseUnitID)
+		if err != nil {
+			auth.RespondWithError(w, http.StatusInternalServerError, "Error checking unit of measure")
+			return false
+		}
+
+		if unit == nil {
+			auth.RespondWithError(w, http.StatusNotFound, "Unit of measure not found")
+			return false
+		}
+	}
+
+	return true
+}
+
+// attributeFilter reads an attribute filter from the query parameters of a request
+func attributeFilter(r *http.Request) map[string]string {
+	filter := map[string]string{}
+	for name, values := range r.URL.Query() {
+		filter[name] = values[0]
+	}
+	return filter
+}
+
+// respondWithTemplateError responds with the error of a failed template or variant request.
+// Conflicts with existing variants are reported as such; other inventory rule violations are bad requests.
+func respondWithTemplateError(w http.ResponseWriter, err error, message string) {
+	if errors.Is(err, models.ErrDuplicateVariant) || errors.Is(err, models.ErrVariantCode) ||
+		errors.Is(err, models.ErrAttributeValueInUse) || errors.Is(err, models.ErrTemplateAttributes) ||
+		errors.Is(err, models.ErrTemplateHasVariants) {
+		auth.RespondWithError(w, http.StatusConflict, err.Error())
+		return
+	}
+
+	var inventoryErr *models.InventoryError
+	if errors.As(err, &inventoryErr) {
+		auth.RespondWithError(w, http.StatusBadRequest, inventoryErr.Error())
+		return
+	}
+
+	auth.RespondWithError(w, http.StatusInternalServerError, message)
+}
\ No newline at end of file
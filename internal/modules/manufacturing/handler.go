@@ -0,0 +1,516 @@
+package manufacturing
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/yookibooki/erp/internal/auth"
+	"github.com/yookibooki/erp/internal/models"
+)
+
+// BOMHandler handles bill of materials requests
+type BOMHandler struct {
+	bomService     models.BillOfMaterialsService
+	productService models.ProductService
+}
+
+// NewBOMHandler creates a new bill of materials handler
+func NewBOMHandler(bomService models.BillOfMaterialsService, productService models.ProductService) *BOMHandler {
+	return &BOMHandler{
+		bomService:     bomService,
+		productService: productService,
+	}
+}
+
+// GetBOM gets a bill of materials by ID
+func (h *BOMHandler) GetBOM(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	bom, err := h.bomService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error getting bill of materials")
+		return
+	}
+
+	if bom == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Bill of materials not found")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, bom)
+}
+
+// ListBOMs lists all bills of materials for a tenant
+func (h *BOMHandler) ListBOMs(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	boms, err := h.bomService.List(tenantID)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error listing bills of materials")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, boms)
+}
+
+// CreateBOM creates a new bill of materials
+func (h *BOMHandler) CreateBOM(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	var bom models.BillOfMaterials
+	if err := json.NewDecoder(r.Body).Decode(&bom); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	// Set tenant ID from context
+	bom.TenantID = tenantID
+
+	if !h.validateBOM(w, &bom) {
+		return
+	}
+
+	// Check if product already has a bill of materials
+	existingBOM, err := h.bomService.GetByProduct(tenantID, bom.ProductID)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking bill of materials")
+		return
+	}
+
+	if existingBOM != nil {
+		auth.RespondWithError(w, http.StatusConflict, "Product already has a bill of materials")
+		return
+	}
+
+	// Create bill of materials
+	if err := h.bomService.Create(&bom); err != nil {
+		respondWithManufacturingError(w, err, "Error creating bill of materials")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusCreated, bom)
+}
+
+// UpdateBOM updates a bill of materials and replaces its lines
+func (h *BOMHandler) UpdateBOM(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	var bom models.BillOfMaterials
+	if err := json.NewDecoder(r.Body).Decode(&bom); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	// Check if bill of materials exists
+	existingBOM, err := h.bomService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking bill of materials")
+		return
+	}
+
+	if existingBOM == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Bill of materials not found")
+		return
+	}
+
+	// The product a bill of materials makes cannot change
+	bom.ID = id
+	bom.TenantID = tenantID
+	bom.ProductID = existingBOM.ProductID
+	bom.CreatedAt = existingBOM.CreatedAt
+
+	if !h.validateBOM(w, &bom) {
+		return
+	}
+
+	// Update bill of materials
+	if err := h.bomService.Update(&bom); err != nil {
+		respondWithManufacturingError(w, err, "Error updating bill of materials")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, bom)
+}
+
+// DeleteBOM deletes a bill of materials
+func (h *BOMHandler) DeleteBOM(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	// Check if bill of materials exists
+	existingBOM, err := h.bomService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking bill of materials")
+		return
+	}
+
+	if existingBOM == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Bill of materials not found")
+		return
+	}
+
+	// Delete bill of materials
+	if err := h.bomService.Delete(tenantID, id); err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error deleting bill of materials")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Bill of materials deleted successfully"})
+}
+
+// validateBOM validates a bill of materials and responds with an error if it is invalid
+func (h *BOMHandler) validateBOM(w http.ResponseWriter, bom *models.BillOfMaterials) bool {
+	if bom.ProductID == "" {
+		auth.RespondWithError(w, http.StatusBadRequest, "Product ID is required")
+		return false
+	}
+
+	if len(bom.Lines) == 0 {
+		auth.RespondWithError(w, http.StatusBadRequest, "At least one bill of materials line is required")
+		return false
+	}
+
+	if bom.Quantity == 0 {
+		bom.Quantity = 1
+	}
+
+	// Check if the product and components exist
+	productIDs := []string{bom.ProductID}
+	for _, line := range bom.Lines {
+		if line.ComponentID == "" {
+			auth.RespondWithError(w, http.StatusBadRequest, "Component ID is required")
+			return false
+		}
+		productIDs = append(productIDs, line.ComponentID)
+	}
+
+	for _, productID := range productIDs {
+		product, err := h.productService.GetByID(bom.TenantID, productID)
+		if err != nil {
+			auth.RespondWithError(w, http.StatusInternalServerError, "Error checking product")
+			return false
+		}
+
+		if product == nil {
+			auth.RespondWithError(w, http.StatusNotFound, "Product not found")
+			return false
+		}
+	}
+
+	return true
+}
+
+// WorkOrderHandler handles work order and material requirement requests
+type WorkOrderHandler struct {
+	workOrderService models.WorkOrderService
+	productService   models.ProductService
+	locationService  models.LocationService
+}
+
+// NewWorkOrderHandler creates a new work order handler
+func NewWorkOrderHandler(
+	workOrderService models.WorkOrderService,
+	productService models.ProductService,
+	locationService models.LocationService,
+) *WorkOrderHandler {
+	return &WorkOrderHandler{
+		workOrderService: workOrderService,
+		productService:   productService,
+		locationService:  locationService,
+	}
+}
+
+// GetWorkOrder gets a work order by ID
+func (h *WorkOrderHandler) GetWorkOrder(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	order, err := h.workOrderService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error getting work order")
+		return
+	}
+
+	if order == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Work order not found")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, order)
+}
+
+// ListWorkOrders lists all work orders for a tenant, optionally filtered by status
+func (h *WorkOrderHandler) ListWorkOrders(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	orders, err := h.workOrderService.List(tenantID, r.URL.Query().Get("status"))
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error listing work orders")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, orders)
+}
+
+// CreateWorkOrder creates a new planned work order
+func (h *WorkOrderHandler) CreateWorkOrder(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+	userID := auth.GetUserIDFromContext(r.Context())
+
+	var order models.WorkOrder
+	if err := json.NewDecoder(r.Body).Decode(&order); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	// Set tenant ID and created by from context
+	order.TenantID = tenantID
+	order.CreatedBy = userID
+
+	if !h.validateWorkOrder(w, &order) {
+		return
+	}
+
+	// Check if work order already exists
+	existingOrder, err := h.workOrderService.GetByNumber(tenantID, order.Number)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking work order")
+		return
+	}
+
+	if existingOrder != nil {
+		auth.RespondWithError(w, http.StatusConflict, "Work order with this number already exists")
+		return
+	}
+
+	// Create work order
+	if err := h.workOrderService.Create(&order); err != nil {
+		respondWithManufacturingError(w, err, "Error creating work order")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusCreated, order)
+}
+
+// UpdateWorkOrder updates a planned work order
+func (h *WorkOrderHandler) UpdateWorkOrder(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	var order models.WorkOrder
+	if err := json.NewDecoder(r.Body).Decode(&order); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	// Set ID and tenant ID
+	order.ID = id
+	order.TenantID = tenantID
+
+	existingOrder := h.findWorkOrder(w, tenantID, id)
+	if existingOrder == nil {
+		return
+	}
+
+	order.CreatedBy = existingOrder.CreatedBy
+	order.CreatedAt = existingOrder.CreatedAt
+
+	if !h.validateWorkOrder(w, &order) {
+		return
+	}
+
+	// Check if another work order has the number
+	conflictingOrder, err := h.workOrderService.GetByNumber(tenantID, order.Number)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking work order")
+		return
+	}
+
+	if conflictingOrder != nil && conflictingOrder.ID != id {
+		auth.RespondWithError(w, http.StatusConflict, "Work order with this number already exists")
+		return
+	}
+
+	// Update work order
+	if err := h.workOrderService.Update(&order); err != nil {
+		respondWithManufacturingError(w, err, "Error updating work order")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, order)
+}
+
+// DeleteWorkOrder deletes a planned work order
+func (h *WorkOrderHandler) DeleteWorkOrder(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	if h.findWorkOrder(w, tenantID, id) == nil {
+		return
+	}
+
+	// Delete work order
+	if err := h.workOrderService.Delete(tenantID, id); err != nil {
+		respondWithManufacturingError(w, err, "Error deleting work order")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Work order deleted successfully"})
+}
+
+// ReleaseWorkOrder releases a planned work order and reserves its components
+func (h *WorkOrderHandler) ReleaseWorkOrder(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+	userID := auth.GetUserIDFromContext(r.Context())
+
+	h.changeStatus(w, r, func(id string) error {
+		return h.workOrderService.Release(tenantID, id, userID)
+	})
+}
+
+// CompleteWorkOrder consumes the components of a released work order and receives its output
+func (h *WorkOrderHandler) CompleteWorkOrder(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+	userID := auth.GetUserIDFromContext(r.Context())
+
+	var completion models.WorkOrderCompletion
+	if err := json.NewDecoder(r.Body).Decode(&completion); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	h.changeStatus(w, r, func(id string) error {
+		return h.workOrderService.Complete(tenantID, id, userID, &completion)
+	})
+}
+
+// CancelWorkOrder cancels a work order that has not been completed
+func (h *WorkOrderHandler) CancelWorkOrder(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	h.changeStatus(w, r, func(id string) error {
+		return h.workOrderService.Cancel(tenantID, id)
+	})
+}
+
+// GetMaterialRequirements reports the components needed by planned work orders
+func (h *WorkOrderHandler) GetMaterialRequirements(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	requirements, err := h.workOrderService.MaterialRequirements(tenantID)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error computing material requirements")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, requirements)
+}
+
+// changeStatus applies a status change to a work order and responds with the updated order
+func (h *WorkOrderHandler) changeStatus(w http.ResponseWriter, r *http.Request, change func(id string) error) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	if h.findWorkOrder(w, tenantID, id) == nil {
+		return
+	}
+
+	if err := change(id); err != nil {
+		respondWithManufacturingError(w, err, "Error updating work order")
+		return
+	}
+
+	order, err := h.workOrderService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error getting work order")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, order)
+}
+
+// findWorkOrder gets a work order and responds with an error if it cannot be found
+func (h *WorkOrderHandler) findWorkOrder(w http.ResponseWriter, tenantID, id string) *models.WorkOrder {
+	order, err := h.workOrderService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking work order")
+		return nil
+	}
+
+	if order == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Work order not found")
+		return nil
+	}
+
+	return order
+}
+
+// validateWorkOrder validates a work order and responds with an error if it is invalid
+func (h *WorkOrderHandler) validateWorkOrder(w http.ResponseWriter, order *models.WorkOrder) bool {
+	if order.ProductID == "" || order.Number == "" {
+		auth.RespondWithError(w, http.StatusBadRequest, "Product ID and number are required")
+		return false
+	}
+
+	// Check if product exists
+	product, err := h.productService.GetByID(order.TenantID, order.ProductID)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking product")
+		return false
+	}
+
+	if product == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Product not found")
+		return false
+	}
+
+	// Check if location exists
+	if order.LocationID != "" {
+		location, err := h.locationService.GetByID(order.TenantID, order.LocationID)
+		if err != nil {
+			auth.RespondWithError(w, http.StatusInternalServerError, "Error checking location")
+			return false
+		}
+
+		if location == nil {
+			auth.RespondWithError(w, http.StatusNotFound, "Location not found")
+			return false
+		}
+	}
+
+	return true
+}
+
+// respondWithManufacturingError responds with the error of a failed manufacturing request.
+// Manufacturing and inventory rule violations are reported to the client.
+func respondWithManufacturingError(w http.ResponseWriter, err error, message string) {
+	if errors.Is(err, models.ErrWorkOrderStatus) {
+		auth.RespondWithError(w, http.StatusConflict, err.Error())
+		return
+	}
+
+	var manufacturingErr *models.ManufacturingError
+	if errors.As(err, &manufacturingErr) {
+		auth.RespondWithError(w, http.StatusBadRequest, manufacturingErr.Error())
+		return
+	}
+
+	var inventoryErr *models.InventoryError
+	if errors.As(err, &inventoryErr) {
+		auth.RespondWithError(w, http.StatusBadRequest, inventoryErr.Error())
+		return
+	}
+
+	auth.RespondWithError(w, http.StatusInternalServerError, message)
+}
\ No newline at end of file
@@ -0,0 +1,588 @@
+package purchasing
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/yookibooki/erp/internal/auth"
+	"github.com/yookibooki/erp/internal/models"
+)
+
+// SupplierHandler handles supplier requests
+type SupplierHandler struct {
+	supplierService models.SupplierService
+}
+
+// NewSupplierHandler creates a new supplier handler
+func NewSupplierHandler(supplierService models.SupplierService) *SupplierHandler {
+	return &SupplierHandler{
+		supplierService: supplierService,
+	}
+}
+
+// GetSupplier gets a supplier by ID
+func (h *SupplierHandler) GetSupplier(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	supplier, err := h.supplierService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error getting supplier")
+		return
+	}
+
+	if supplier == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Supplier not found")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, supplier)
+}
+
+// ListSuppliers lists all suppliers for a tenant
+func (h *SupplierHandler) ListSuppliers(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	suppliers, err := h.supplierService.List(tenantID)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error listing suppliers")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, suppliers)
+}
+
+// CreateSupplier creates a new supplier
+func (h *SupplierHandler) CreateSupplier(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	var supplier models.Supplier
+	if err := json.NewDecoder(r.Body).Decode(&supplier); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	// Set tenant ID from context
+	supplier.TenantID = tenantID
+
+	// Validate supplier
+	if supplier.Code == "" || supplier.Name == "" {
+		auth.RespondWithError(w, http.StatusBadRequest, "Code and name are required")
+		return
+	}
+
+	// Check if supplier already exists
+	existingSupplier, err := h.supplierService.GetByCode(tenantID, supplier.Code)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking supplier")
+		return
+	}
+
+	if existingSupplier != nil {
+		auth.RespondWithError(w, http.StatusConflict, "Supplier with this code already exists")
+		return
+	}
+
+	// Create supplier
+	if err := h.supplierService.Create(&supplier); err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error creating supplier")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusCreated, supplier)
+}
+
+// UpdateSupplier updates a supplier
+func (h *SupplierHandler) UpdateSupplier(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	var supplier models.Supplier
+	if err := json.NewDecoder(r.Body).Decode(&supplier); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	// Set ID and tenant ID
+	supplier.ID = id
+	supplier.TenantID = tenantID
+
+	// Validate supplier
+	if supplier.Code == "" || supplier.Name == "" {
+		auth.RespondWithError(w, http.StatusBadRequest, "Code and name are required")
+		return
+	}
+
+	// Check if supplier exists
+	existingSupplier, err := h.supplierService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking supplier")
+		return
+	}
+
+	if existingSupplier == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Supplier not found")
+		return
+	}
+
+	// Update supplier
+	if err := h.supplierService.Update(&supplier); err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error updating supplier")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, supplier)
+}
+
+// DeleteSupplier deletes a supplier
+func (h *SupplierHandler) DeleteSupplier(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	// Check if supplier exists
+	existingSupplier, err := h.supplierService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking supplier")
+		return
+	}
+
+	if existingSupplier == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Supplier not found")
+		return
+	}
+
+	// Delete supplier
+	if err := h.supplierService.Delete(tenantID, id); err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error deleting supplier")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Supplier deleted successfully"})
+}
+
+// PurchaseOrderHandler handles purchase order and goods receipt requests
+type PurchaseOrderHandler struct {
+	purchaseOrderService models.PurchaseOrderService
+	goodsReceiptService  models.GoodsReceiptService
+	supplierService      models.SupplierService
+	productService       models.ProductService
+	locationService      models.LocationService
+}
+
+// NewPurchaseOrderHandler creates a new purchase order handler
+func NewPurchaseOrderHandler(
+	purchaseOrderService models.PurchaseOrderService,
+	goodsReceiptService models.GoodsReceiptService,
+	supplierService models.SupplierService,
+	productService models.ProductService,
+	locationService models.LocationService,
+) *PurchaseOrderHandler {
+	return &PurchaseOrderHandler{
+		purchaseOrderService: purchaseOrderService,
+		goodsReceiptService:  goodsReceiptService,
+		supplierService:      supplierService,
+		productService:       productService,
+		locationService:      locationService,
+	}
+}
+
+// GetPurchaseOrder gets a purchase order by ID
+func (h *PurchaseOrderHandler) GetPurchaseOrder(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	order, err := h.purchaseOrderService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error getting purchase order")
+		return
+	}
+
+	if order == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Purchase order not found")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, order)
+}
+
+// ListPurchaseOrders lists all purchase orders for a tenant, optionally filtered by status
+func (h *PurchaseOrderHandler) ListPurchaseOrders(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	orders, err := h.purchaseOrderService.List(tenantID, r.URL.Query().Get("status"))
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error listing purchase orders")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, orders)
+}
+
+// CreatePurchaseOrder creates a new draft purchase order
+func (h *PurchaseOrderHandler) CreatePurchaseOrder(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+	userID := auth.GetUserIDFromContext(r.Context())
+
+	var order models.PurchaseOrder
+	if err := json.NewDecoder(r.Body).Decode(&order); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	// Set tenant ID and created by from context
+	order.TenantID = tenantID
+	order.CreatedBy = userID
+
+	if !h.validatePurchaseOrder(w, &order) {
+		return
+	}
+
+	// Check if purchase order already exists
+	existingOrder, err := h.purchaseOrderService.GetByNumber(tenantID, order.Number)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking purchase order")
+		return
+	}
+
+	if existingOrder != nil {
+		auth.RespondWithError(w, http.StatusConflict, "Purchase order with this number already exists")
+		return
+	}
+
+	// Create purchase order
+	if err := h.purchaseOrderService.Create(&order); err != nil {
+		respondWithPurchasingError(w, err, "Error creating purchase order")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusCreated, order)
+}
+
+// UpdatePurchaseOrder updates a draft purchase order
+func (h *PurchaseOrderHandler) UpdatePurchaseOrder(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	var order models.PurchaseOrder
+	if err := json.NewDecoder(r.Body).Decode(&order); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	// Set ID and tenant ID
+	order.ID = id
+	order.TenantID = tenantID
+
+	// Check if purchase order exists
+	existingOrder, err := h.purchaseOrderService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking purchase order")
+		return
+	}
+
+	if existingOrder == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Purchase order not found")
+		return
+	}
+
+	order.CreatedBy = existingOrder.CreatedBy
+	order.CreatedAt = existingOrder.CreatedAt
+
+	if !h.validatePurchaseOrder(w, &order) {
+		return
+	}
+
+	// Check if another purchase order has the number
+	conflictingOrder, err := h.purchaseOrderService.GetByNumber(tenantID, order.Number)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking purchase order")
+		return
+	}
+
+	if conflictingOrder != nil && conflictingOrder.ID != id {
+		auth.RespondWithError(w, http.StatusConflict, "Purchase order with this number already exists")
+		return
+	}
+
+	// Update purchase order
+	if err := h.purchaseOrderService.Update(&order); err != nil {
+		respondWithPurchasingError(w, err, "Error updating purchase order")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, order)
+}
+
+// DeletePurchaseOrder deletes a draft purchase order
+func (h *PurchaseOrderHandler) DeletePurchaseOrder(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	if h.findPurchaseOrder(w, tenantID, id) == nil {
+		return
+	}
+
+	// Delete purchase order
+	if err := h.purchaseOrderService.Delete(tenantID, id); err != nil {
+		respondWithPurchasingError(w, err, "Error deleting purchase order")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Purchase order deleted successfully"})
+}
+
+// ApprovePurchaseOrder approves a draft purchase order
+func (h *PurchaseOrderHandler) ApprovePurchaseOrder(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+	userID := auth.GetUserIDFromContext(r.Context())
+
+	h.changeStatus(w, r, func(id string) error {
+		return h.purchaseOrderService.Approve(tenantID, id, userID)
+	})
+}
+
+// SendPurchaseOrder marks an approved purchase order as sent to the supplier
+func (h *PurchaseOrderHandler) SendPurchaseOrder(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	h.changeStatus(w, r, func(id string) error {
+		return h.purchaseOrderService.Send(tenantID, id)
+	})
+}
+
+// CancelPurchaseOrder cancels a purchase order nothing has been received against
+func (h *PurchaseOrderHandler) CancelPurchaseOrder(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	h.changeStatus(w, r, func(id string) error {
+		return h.purchaseOrderService.Cancel(tenantID, id)
+	})
+}
+
+// changeStatus applies a status change to a purchase order and responds with the updated order
+func (h *PurchaseOrderHandler) changeStatus(w http.ResponseWriter, r *http.Request, change func(id string) error) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	if h.findPurchaseOrder(w, tenantID, id) == nil {
+		return
+	}
+
+	if err := change(id); err != nil {
+		respondWithPurchasingError(w, err, "Error updating purchase order")
+		return
+	}
+
+	order, err := h.purchaseOrderService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error getting purchase order")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, order)
+}
+
+// ListGoodsReceipts lists all goods receipts of a purchase order
+func (h *PurchaseOrderHandler) ListGoodsReceipts(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	receipts, err := h.goodsReceiptService.ListByPurchaseOrder(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error listing goods receipts")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, receipts)
+}
+
+// GetGoodsReceipt gets a goods receipt by ID
+func (h *PurchaseOrderHandler) GetGoodsReceipt(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	receipt, err := h.goodsReceiptService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error getting goods receipt")
+		return
+	}
+
+	if receipt == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Goods receipt not found")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, receipt)
+}
+
+// CreateGoodsReceipt receives goods against the lines of a sent purchase order
+func (h *PurchaseOrderHandler) CreateGoodsReceipt(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+	userID := auth.GetUserIDFromContext(r.Context())
+
+	var receipt models.GoodsReceipt
+	if err := json.NewDecoder(r.Body).Decode(&receipt); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	// Set purchase order, tenant ID and created by
+	receipt.PurchaseOrderID = id
+	receipt.TenantID = tenantID
+	receipt.CreatedBy = userID
+
+	if len(receipt.Lines) == 0 {
+		auth.RespondWithError(w, http.StatusBadRequest, "At least one goods receipt line is required")
+		return
+	}
+
+	if h.findPurchaseOrder(w, tenantID, id) == nil {
+		return
+	}
+
+	for _, line := range receipt.Lines {
+		if line.PurchaseOrderLineID == "" {
+			auth.RespondWithError(w, http.StatusBadRequest, "Purchase order line ID is required")
+			return
+		}
+		if line.LocationID != "" && !h.checkLocation(w, tenantID, line.LocationID) {
+			return
+		}
+	}
+
+	// Create goods receipt
+	if err := h.goodsReceiptService.Create(&receipt); err != nil {
+		respondWithPurchasingError(w, err, "Error creating goods receipt")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusCreated, receipt)
+}
+
+// findPurchaseOrder gets a purchase order and responds with an error if it cannot be found
+func (h *PurchaseOrderHandler) findPurchaseOrder(w http.ResponseWriter, tenantID, id string) *models.PurchaseOrder {
+	order, err := h.purchaseOrderService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking purchase order")
+		return nil
+	}
+
+	if order == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Purchase order not found")
+		return nil
+	}
+
+	return order
+}
+
+// validatePurchaseOrder validates a purchase order and responds with an error if it is invalid
+func (h *PurchaseOrderHandler) validatePurchaseOrder(w http.ResponseWriter, order *models.PurchaseOrder) bool {
+	if order.SupplierID == "" || order.Number == "" {
+		auth.RespondWithError(w, http.StatusBadRequest, "Supplier ID and number are required")
+		return false
+	}
+
+	if len(order.Lines) == 0 {
+		auth.RespondWithError(w, http.StatusBadRequest, "At least one purchase order line is required")
+		return false
+	}
+
+	if order.OrderDate.IsZero() {
+		order.OrderDate = time.Now()
+	}
+
+	// Check if supplier exists
+	supplier, err := h.supplierService.GetByID(order.TenantID, order.SupplierID)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking supplier")
+		return false
+	}
+
+	if supplier == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Supplier not found")
+		return false
+	}
+
+	// Check if products and locations exist
+	for i := range order.Lines {
+		line := &order.Lines[i]
+		if line.ProductID == "" {
+			auth.RespondWithError(w, http.StatusBadRequest, "Product ID is required")
+			return false
+		}
+
+		product, err := h.productService.GetByID(order.TenantID, line.ProductID)
+		if err != nil {
+			auth.RespondWithError(w, http.StatusInternalServerError, "Error checking product")
+			return false
+		}
+
+		if product == nil {
+			auth.RespondWithError(w, http.StatusNotFound, "Product not found")
+			return false
+		}
+
+		if line.UnitID == "" {
+			line.UnitID = product.PurchaseUnitID
+		}
+
+		if line.LocationID != "" && !h.checkLocation(w, order.TenantID, line.LocationID) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// checkLocation responds with an error if a location does not exist
+func (h *PurchaseOrderHandler) checkLocation(w http.ResponseWriter, tenantID, locationID string) bool {
+	location, err := h.locationService.GetByID(tenantID, locationID)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking location")
+		return false
+	}
+
+	if location == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Location not found")
+		return false
+	}
+
+	return true
+}
+
+// respondWithPurchasingError responds with the error of a failed purchasing request.
+// Purchasing and inventory rule violations are reported to the client.
+func respondWithPurchasingError(w http.ResponseWriter, err error, message string) {
+	if errors.Is(err, models.ErrPurchaseOrderStatus) || errors.Is(err, models.ErrLandedCostStatus) {
+		auth.RespondWithError(w, http.StatusConflict, err.Error())
+		return
+	}
+
+	var purchasingErr *models.PurchasingError
+	if errors.As(err, &purchasingErr) {
+		auth.RespondWithError(w, http.StatusBadRequest, purchasingErr.Error())
+		return
+	}
+
+	var inventoryErr *models.InventoryError
+	if errors.As(err, &inventoryErr) {
+		auth.RespondWithError(w, http.StatusBadRequest, inventoryErr.Error())
+		return
+	}
+
+	auth.RespondWithError(w, http.StatusInternalServerError, message)
+}
\ No newline at end of file
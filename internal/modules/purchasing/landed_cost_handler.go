@@ -0,0 +1,171 @@
+package purchasing
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/yookibooki/erp/internal/auth"
+	"github.com/yookibooki/erp/internal/models"
+)
+
+// LandedCostHandler handles landed cost requests
+type LandedCostHandler struct {
+	landedCostService models.LandedCostService
+	accountService    models.AccountService
+}
+
+// NewLandedCostHandler creates a new landed cost handler
+func NewLandedCostHandler(landedCostService models.LandedCostService, accountService models.AccountService) *LandedCostHandler {
+	return &LandedCostHandler{
+		landedCostService: landedCostService,
+		accountService:    accountService,
+	}
+}
+
+// GetLandedCost gets a landed cost by ID
+func (h *LandedCostHandler) GetLandedCost(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	landedCost := h.findLandedCost(w, tenantID, id)
+	if landedCost == nil {
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, landedCost)
+}
+
+// ListLandedCosts lists all landed costs for a tenant, optionally filtered by status,
+// or the landed costs allocated to the receipt transaction given by transaction_id
+func (h *LandedCostHandler) ListLandedCosts(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+	params := r.URL.Query()
+
+	var landedCosts []*models.LandedCost
+	var err error
+	if transactionID := params.Get("transaction_id"); transactionID != "" {
+		landedCosts, err = h.landedCostService.ListByTransaction(tenantID, transactionID)
+	} else {
+		landedCosts, err = h.landedCostService.List(tenantID, params.Get("status"))
+	}
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error listing landed costs")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, landedCosts)
+}
+
+// CreateLandedCost creates a new draft landed cost and allocates its charges
+func (h *LandedCostHandler) CreateLandedCost(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+	userID := auth.GetUserIDFromContext(r.Context())
+
+	var landedCost models.LandedCost
+	if err := json.NewDecoder(r.Body).Decode(&landedCost); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	// Set tenant ID and created by from context
+	landedCost.TenantID = tenantID
+	landedCost.CreatedBy = userID
+
+	if landedCost.Number == "" || landedCost.CounterAccountID == "" {
+		auth.RespondWithError(w, http.StatusBadRequest, "Number and counter account ID are required")
+		return
+	}
+
+	// Check if counter account exists
+	account, err := h.accountService.GetByID(tenantID, landedCost.CounterAccountID)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking account")
+		return
+	}
+
+	if account == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Account not found")
+		return
+	}
+
+	// Check if landed cost already exists
+	existingLandedCost, err := h.landedCostService.GetByNumber(tenantID, landedCost.Number)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking landed cost")
+		return
+	}
+
+	if existingLandedCost != nil {
+		auth.RespondWithError(w, http.StatusConflict, "Landed cost with this number already exists")
+		return
+	}
+
+	// Create landed cost
+	if err := h.landedCostService.Create(&landedCost); err != nil {
+		respondWithPurchasingError(w, err, "Error creating landed cost")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusCreated, landedCost)
+}
+
+// PostLandedCost adds the costs of a draft landed cost to the received stock and journals them
+func (h *LandedCostHandler) PostLandedCost(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+	userID := auth.GetUserIDFromContext(r.Context())
+
+	h.changeStatus(w, r, func(id string) error {
+		return h.landedCostService.Post(tenantID, id, userID)
+	})
+}
+
+// CancelLandedCost cancels a draft landed cost
+func (h *LandedCostHandler) CancelLandedCost(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	h.changeStatus(w, r, func(id string) error {
+		return h.landedCostService.Cancel(tenantID, id)
+	})
+}
+
+// changeStatus applies a status change to a landed cost and responds with the updated landed cost
+func (h *LandedCostHandler) changeStatus(w http.ResponseWriter, r *http.Request, change func(id string) error) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	if h.findLandedCost(w, tenantID, id) == nil {
+		return
+	}
+
+	if err := change(id); err != nil {
+		respondWithPurchasingError(w, err, "Error updating landed cost")
+		return
+	}
+
+	landedCost, err := h.landedCostService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error getting landed cost")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, landedCost)
+}
+
+// findLandedCost gets a landed cost and responds with an error if it cannot be found
+func (h *LandedCostHandler) findLandedCost(w http.ResponseWriter, tenantID, id string) *models.LandedCost {
+	landedCost, err := h.landedCostService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error getting landed cost")
+		return nil
+	}
+
+	if landedCost == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Landed cost not found")
+		return nil
+	}
+
+	return landedCost
+}
\ No newline at end of file
@@ -0,0 +1,291 @@
+package purchasing
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/yookibooki/erp/internal/auth"
+	"github.com/yookibooki/erp/internal/models"
+)
+
+// ProductSupplierHandler handles requests for the suppliers of products
+type ProductSupplierHandler struct {
+	productSupplierService models.ProductSupplierService
+	supplierService        models.SupplierService
+	productService         models.ProductService
+	productUnitService     models.ProductUnitService
+}
+
+// NewProductSupplierHandler creates a new product supplier handler
+func NewProductSupplierHandler(
+	productSupplierService models.ProductSupplierService,
+	supplierService models.SupplierService,
+	productService models.ProductService,
+	productUnitService models.ProductUnitService,
+) *ProductSupplierHandler {
+	return &ProductSupplierHandler{
+		productSupplierService: productSupplierService,
+		supplierService:        supplierService,
+		productService:         productService,
+		productUnitService:     productUnitService,
+	}
+}
+
+// ListProductSuppliers lists the suppliers of a product in rank order
+func (h *ProductSupplierHandler) ListProductSuppliers(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	productID := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	productSuppliers, err := h.productSupplierService.ListByProduct(tenantID, productID)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error listing product suppliers")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, productSuppliers)
+}
+
+// RankProductSuppliers answers who to buy a product from: its suppliers in rank order,
+// optionally limited to those whose minimum order quantity allows the quantity in base
+// units and to those pricing in the currency given as query parameters
+func (h *ProductSupplierHandler) RankProductSuppliers(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	productID := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+	params := r.URL.Query()
+
+	var quantity float64
+	if value := params.Get("quantity"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil || parsed <= 0 {
+			auth.RespondWithError(w, http.StatusBadRequest, "Quantity must be a positive number")
+			return
+		}
+		quantity = parsed
+	}
+
+	// Check if product exists
+	product, err := h.productService.GetByID(tenantID, productID)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking product")
+		return
+	}
+
+	if product == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Product not found")
+		return
+	}
+
+	productSuppliers, err := h.productSupplierService.Rank(tenantID, productID, quantity, strings.ToUpper(params.Get("currency")))
+	if err != nil {
+		respondWithPurchasingError(w, err, "Error ranking product suppliers")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, productSuppliers)
+}
+
+// ListSupplierProducts lists the products a supplier supplies
+func (h *ProductSupplierHandler) ListSupplierProducts(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	supplierID := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	productSuppliers, err := h.productSupplierService.ListBySupplier(tenantID, supplierID)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error listing product suppliers")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, productSuppliers)
+}
+
+// CreateProductSupplier adds a supplier to a product
+func (h *ProductSupplierHandler) CreateProductSupplier(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	productID := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	var productSupplier models.ProductSupplier
+	if err := json.NewDecoder(r.Body).Decode(&productSupplier); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	// Set product and tenant ID
+	productSupplier.ProductID = productID
+	productSupplier.TenantID = tenantID
+
+	if !h.validateProductSupplier(w, &productSupplier) {
+		return
+	}
+
+	// Check if the supplier is already a supplier of the product
+	existingProductSupplier, err := h.productSupplierService.GetByProductAndSupplier(tenantID, productID, productSupplier.SupplierID)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking product supplier")
+		return
+	}
+
+	if existingProductSupplier != nil {
+		auth.RespondWithError(w, http.StatusConflict, "Supplier is already a supplier of this product")
+		return
+	}
+
+	// Create product supplier
+	if err := h.productSupplierService.Create(&productSupplier); err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error creating product supplier")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusCreated, productSupplier)
+}
+
+// UpdateProductSupplier updates the terms of a supplier for a product
+func (h *ProductSupplierHandler) UpdateProductSupplier(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	var productSupplier models.ProductSupplier
+	if err := json.NewDecoder(r.Body).Decode(&productSupplier); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	existingProductSupplier := h.findProductSupplier(w, tenantID, id)
+	if existingProductSupplier == nil {
+		return
+	}
+
+	// Set ID, tenant ID, product ID and supplier ID
+	productSupplier.ID = id
+	productSupplier.TenantID = tenantID
+	productSupplier.ProductID = existingProductSupplier.ProductID
+	productSupplier.SupplierID = existingProductSupplier.SupplierID
+
+	if !h.validateProductSupplier(w, &productSupplier) {
+		return
+	}
+
+	// Update product supplier
+	if err := h.productSupplierService.Update(&productSupplier); err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error updating product supplier")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, productSupplier)
+}
+
+// DeleteProductSupplier removes a supplier from a product
+func (h *ProductSupplierHandler) DeleteProductSupplier(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	if h.findProductSupplier(w, tenantID, id) == nil {
+		return
+	}
+
+	// Delete product supplier
+	if err := h.productSupplierService.Delete(tenantID, id); err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error deleting product supplier")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Product supplier deleted successfully"})
+}
+
+// findProductSupplier gets a product supplier and responds with an error if it cannot be found
+func (h *ProductSupplierHandler) findProductSupplier(w http.ResponseWriter, tenantID, id string) *models.ProductSupplier {
+	productSupplier, err := h.productSupplierService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error getting product supplier")
+		return nil
+	}
+
+	if productSupplier == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Product supplier not found")
+		return nil
+	}
+
+	return productSupplier
+}
+
+// validateProductSupplier validates the terms of a product supplier and responds with an
+// error if they are invalid
+func (h *ProductSupplierHandler) validateProductSupplier(w http.ResponseWriter, productSupplier *models.ProductSupplier) bool {
+	if productSupplier.SupplierID == "" {
+		auth.RespondWithError(w, http.StatusBadRequest, "Supplier ID is required")
+		return false
+	}
+
+	productSupplier.Currency = strings.ToUpper(productSupplier.Currency)
+	if len(productSupplier.Currency) != 3 {
+		auth.RespondWithError(w, http.StatusBadRequest, "Currency must be a three-letter ISO 4217 code")
+		return false
+	}
+
+	if productSupplier.PurchasePrice < 0 {
+		auth.RespondWithError(w, http.StatusBadRequest, "Purchase price cannot be negative")
+		return false
+	}
+
+	if productSupplier.LeadTimeDays < 0 {
+		auth.RespondWithError(w, http.StatusBadRequest, "Lead time cannot be negative")
+		return false
+	}
+
+	if productSupplier.MinimumOrderQuantity < 0 {
+		auth.RespondWithError(w, http.StatusBadRequest, "Minimum order quantity cannot be negative")
+		return false
+	}
+
+	// Check if supplier exists
+	supplier, err := h.supplierService.GetByID(productSupplier.TenantID, productSupplier.SupplierID)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking supplier")
+		return false
+	}
+
+	if supplier == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Supplier not found")
+		return false
+	}
+
+	// Check if product exists
+	product, err := h.productService.GetByID(productSupplier.TenantID, productSupplier.ProductID)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking product")
+		return false
+	}
+
+	if product == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Product not found")
+		return false
+	}
+
+	// Check if the product converts the unit the supplier sells in
+	if productSupplier.UnitID == product.BaseUnitID {
+		productSupplier.UnitID = ""
+	}
+
+	if productSupplier.UnitID != "" {
+		productUnit, err := h.productUnitService.GetByProductAndUnit(productSupplier.TenantID, product.ID, productSupplier.UnitID)
+		if err != nil {
+			auth.RespondWithError(w, http.StatusInternalServerError, "Error checking product unit")
+			return false
+		}
+
+		if productUnit == nil {
+			auth.RespondWithError(w, http.StatusBadRequest, models.ErrUnitConversion.Error())
+			return false
+		}
+	}
+
+	return true
+}
\ No newline at end of file
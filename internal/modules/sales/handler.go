@@ -0,0 +1,547 @@
+package sales
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/yookibooki/erp/internal/auth"
+	"github.com/yookibooki/erp/internal/models"
+)
+
+// SalesOrderHandler handles sales order, shipment and backorder requests
+type SalesOrderHandler struct {
+	salesOrderService  models.SalesOrderService
+	shipmentService    models.ShipmentService
+	customerService    models.CustomerService
+	productService     models.ProductService
+	locationService    models.LocationService
+	productUnitService models.ProductUnitService
+	priceListService   models.PriceListService
+}
+
+// NewSalesOrderHandler creates a new sales order handler
+func NewSalesOrderHandler(
+	salesOrderService models.SalesOrderService,
+	shipmentService models.ShipmentService,
+	customerService models.CustomerService,
+	productService models.ProductService,
+	locationService models.LocationService,
+	productUnitService models.ProductUnitService,
+	priceListService models.PriceListService,
+) *SalesOrderHandler {
+	return &SalesOrderHandler{
+		salesOrderService:  salesOrderService,
+		shipmentService:    shipmentService,
+		customerService:    customerService,
+		productService:     productService,
+		locationService:    locationService,
+		productUnitService: productUnitService,
+		priceListService:   priceListService,
+	}
+}
+
+// GetSalesOrder gets a sales order by ID
+func (h *SalesOrderHandler) GetSalesOrder(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	order, err := h.salesOrderService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error getting sales order")
+		return
+	}
+
+	if order == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Sales order not found")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, order)
+}
+
+// ListSalesOrders lists all sales orders for a tenant, optionally filtered by status
+func (h *SalesOrderHandler) ListSalesOrders(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	orders, err := h.salesOrderService.List(tenantID, r.URL.Query().Get("status"))
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error listing sales orders")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, orders)
+}
+
+// ListBackorders lists the lines of open sales orders waiting for stock
+func (h *SalesOrderHandler) ListBackorders(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	backorders, err := h.salesOrderService.ListBackorders(tenantID)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error listing backorders")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, backorders)
+}
+
+// CreateSalesOrder creates a new draft sales order
+func (h *SalesOrderHandler) CreateSalesOrder(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+	userID := auth.GetUserIDFromContext(r.Context())
+
+	var order models.SalesOrder
+	if err := json.NewDecoder(r.Body).Decode(&order); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	// Set tenant ID and created by from context
+	order.TenantID = tenantID
+	order.CreatedBy = userID
+
+	if !h.validateSalesOrder(w, &order) {
+		return
+	}
+
+	// Check if sales order already exists
+	existingOrder, err := h.salesOrderService.GetByNumber(tenantID, order.Number)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking sales order")
+		return
+	}
+
+	if existingOrder != nil {
+		auth.RespondWithError(w, http.StatusConflict, "Sales order with this number already exists")
+		return
+	}
+
+	// Create sales order
+	if err := h.salesOrderService.Create(&order); err != nil {
+		respondWithSalesError(w, err, "Error creating sales order")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusCreated, order)
+}
+
+// UpdateSalesOrder updates a draft sales order
+func (h *SalesOrderHandler) UpdateSalesOrder(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	var order models.SalesOrder
+	if err := json.NewDecoder(r.Body).Decode(&order); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	// Set ID and tenant ID
+	order.ID = id
+	order.TenantID = tenantID
+
+	existingOrder := h.findSalesOrder(w, tenantID, id)
+	if existingOrder == nil {
+		return
+	}
+
+	order.CreatedBy = existingOrder.CreatedBy
+	order.CreatedAt = existingOrder.CreatedAt
+
+	if !h.validateSalesOrder(w, &order) {
+		return
+	}
+
+	// Check if another sales order has the number
+	conflictingOrder, err := h.salesOrderService.GetByNumber(tenantID, order.Number)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking sales order")
+		return
+	}
+
+	if conflictingOrder != nil && conflictingOrder.ID != id {
+		auth.RespondWithError(w, http.StatusConflict, "Sales order with this number already exists")
+		return
+	}
+
+	// Update sales order
+	if err := h.salesOrderService.Update(&order); err != nil {
+		respondWithSalesError(w, err, "Error updating sales order")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, order)
+}
+
+// DeleteSalesOrder deletes a draft sales order
+func (h *SalesOrderHandler) DeleteSalesOrder(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	if h.findSalesOrder(w, tenantID, id) == nil {
+		return
+	}
+
+	// Delete sales order
+	if err := h.salesOrderService.Delete(tenantID, id); err != nil {
+		respondWithSalesError(w, err, "Error deleting sales order")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Sales order deleted successfully"})
+}
+
+// ConfirmSalesOrder confirms a draft sales order and reserves the available stock
+func (h *SalesOrderHandler) ConfirmSalesOrder(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+	userID := auth.GetUserIDFromContext(r.Context())
+
+	h.changeStatus(w, r, func(id string) error {
+		return h.salesOrderService.Confirm(tenantID, id, userID)
+	})
+}
+
+// AllocateSalesOrder reserves stock that has become available for backordered lines
+func (h *SalesOrderHandler) AllocateSalesOrder(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+	userID := auth.GetUserIDFromContext(r.Context())
+
+	h.changeStatus(w, r, func(id string) error {
+		return h.salesOrderService.Allocate(tenantID, id, userID)
+	})
+}
+
+// PickSalesOrder marks a confirmed sales order as picked
+func (h *SalesOrderHandler) PickSalesOrder(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	h.changeStatus(w, r, func(id string) error {
+		return h.salesOrderService.Pick(tenantID, id)
+	})
+}
+
+// InvoiceSalesOrder marks a shipped sales order as invoiced
+func (h *SalesOrderHandler) InvoiceSalesOrder(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	h.changeStatus(w, r, func(id string) error {
+		return h.salesOrderService.Invoice(tenantID, id)
+	})
+}
+
+// CancelSalesOrder cancels a sales order nothing has been shipped against
+func (h *SalesOrderHandler) CancelSalesOrder(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	h.changeStatus(w, r, func(id string) error {
+		return h.salesOrderService.Cancel(tenantID, id)
+	})
+}
+
+// changeStatus applies a status change to a sales order and responds with the updated order
+func (h *SalesOrderHandler) changeStatus(w http.ResponseWriter, r *http.Request, change func(id string) error) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	if h.findSalesOrder(w, tenantID, id) == nil {
+		return
+	}
+
+	if err := change(id); err != nil {
+		respondWithSalesError(w, err, "Error updating sales order")
+		return
+	}
+
+	order, err := h.salesOrderService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error getting sales order")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, order)
+}
+
+// ListShipments lists all shipments of a sales order
+func (h *SalesOrderHandler) ListShipments(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	shipments, err := h.shipmentService.ListBySalesOrder(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error listing shipments")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, shipments)
+}
+
+// GetShipment gets a shipment by ID
+func (h *SalesOrderHandler) GetShipment(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	shipment, err := h.shipmentService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error getting shipment")
+		return
+	}
+
+	if shipment == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Shipment not found")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, shipment)
+}
+
+// CreateShipment creates a draft shipment of goods packed against a sales order
+func (h *SalesOrderHandler) CreateShipment(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+	userID := auth.GetUserIDFromContext(r.Context())
+
+	var shipment models.Shipment
+	if err := json.NewDecoder(r.Body).Decode(&shipment); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	// Set sales order, tenant ID and created by
+	shipment.SalesOrderID = id
+	shipment.TenantID = tenantID
+	shipment.CreatedBy = userID
+
+	if len(shipment.Lines) == 0 {
+		auth.RespondWithError(w, http.StatusBadRequest, "At least one shipment line is required")
+		return
+	}
+
+	if h.findSalesOrder(w, tenantID, id) == nil {
+		return
+	}
+
+	for _, line := range shipment.Lines {
+		if line.SalesOrderLineID == "" {
+			auth.RespondWithError(w, http.StatusBadRequest, "Sales order line ID is required")
+			return
+		}
+		if line.LocationID != "" && !h.checkLocation(w, tenantID, line.LocationID) {
+			return
+		}
+	}
+
+	// Create shipment
+	if err := h.shipmentService.Create(&shipment); err != nil {
+		respondWithSalesError(w, err, "Error creating shipment")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusCreated, shipment)
+}
+
+// ShipShipment confirms a shipment and issues its goods from stock
+func (h *SalesOrderHandler) ShipShipment(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+	userID := auth.GetUserIDFromContext(r.Context())
+
+	// Check if shipment exists
+	shipment, err := h.shipmentService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking shipment")
+		return
+	}
+
+	if shipment == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Shipment not found")
+		return
+	}
+
+	// Ship shipment
+	if err := h.shipmentService.Ship(tenantID, id, userID); err != nil {
+		respondWithSalesError(w, err, "Error shipping shipment")
+		return
+	}
+
+	shipment, err = h.shipmentService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error getting shipment")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, shipment)
+}
+
+// findSalesOrder gets a sales order and responds with an error if it cannot be found
+func (h *SalesOrderHandler) findSalesOrder(w http.ResponseWriter, tenantID, id string) *models.SalesOrder {
+	order, err := h.salesOrderService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking sales order")
+		return nil
+	}
+
+	if order == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Sales order not found")
+		return nil
+	}
+
+	return order
+}
+
+// validateSalesOrder validates a sales order and responds with an error if it is invalid.
+// Lines without a unit price are priced from the customer's price lists in the order's
+// currency on the order date.
+func (h *SalesOrderHandler) validateSalesOrder(w http.ResponseWriter, order *models.SalesOrder) bool {
+	if order.CustomerID == "" || order.Number == "" {
+		auth.RespondWithError(w, http.StatusBadRequest, "Customer ID and number are required")
+		return false
+	}
+
+	if len(order.Lines) == 0 {
+		auth.RespondWithError(w, http.StatusBadRequest, "At least one sales order line is required")
+		return false
+	}
+
+	order.Currency = strings.ToUpper(order.Currency)
+	if order.Currency != "" && len(order.Currency) != 3 {
+		auth.RespondWithError(w, http.StatusBadRequest, "Currency must be a three-letter ISO 4217 code")
+		return false
+	}
+
+	if order.OrderDate.IsZero() {
+		order.OrderDate = time.Now()
+	}
+
+	// Check if customer exists
+	customer, err := h.customerService.GetByID(order.TenantID, order.CustomerID)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking customer")
+		return false
+	}
+
+	if customer == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Customer not found")
+		return false
+	}
+
+	// Check if products and locations exist
+	for i := range order.Lines {
+		line := &order.Lines[i]
+		if line.ProductID == "" {
+			auth.RespondWithError(w, http.StatusBadRequest, "Product ID is required")
+			return false
+		}
+
+		product, err := h.productService.GetByID(order.TenantID, line.ProductID)
+		if err != nil {
+			auth.RespondWithError(w, http.StatusInternalServerError, "Error checking product")
+			return false
+		}
+
+		if product == nil {
+			auth.RespondWithError(w, http.StatusNotFound, "Product not found")
+			return false
+		}
+
+		if line.UnitID == "" {
+			line.UnitID = product.SalesUnitID
+		}
+
+		if line.UnitPrice == 0 {
+			// Prices are resolved per base unit
+			factor := 1.0
+			if line.UnitID != "" && line.UnitID != product.BaseUnitID {
+				productUnit, err := h.productUnitService.GetByProductAndUnit(order.TenantID, product.ID, line.UnitID)
+				if err != nil {
+					auth.RespondWithError(w, http.StatusInternalServerError, "Error checking product unit")
+					return false
+				}
+
+				if productUnit == nil {
+					auth.RespondWithError(w, http.StatusBadRequest, models.ErrUnitConversion.Error())
+					return false
+				}
+
+				factor = productUnit.Factor
+			}
+
+			price, err := h.priceListService.Resolve(order.TenantID, &models.PriceQuery{
+				CustomerID: order.CustomerID,
+				ProductID:  product.ID,
+				Quantity:   line.Quantity * factor,
+				Date:       order.OrderDate,
+				Currency:   order.Currency,
+			})
+			if err != nil {
+				respondWithSalesError(w, err, "Error resolving price")
+				return false
+			}
+
+			line.UnitPrice = price.UnitPrice * factor
+		}
+
+		if line.LocationID != "" && !h.checkLocation(w, order.TenantID, line.LocationID) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// checkLocation responds with an error if a location does not exist or holds
+// quarantined stock, which is not available to sell
+func (h *SalesOrderHandler) checkLocation(w http.ResponseWriter, tenantID, locationID string) bool {
+	location, err := h.locationService.GetByID(tenantID, locationID)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking location")
+		return false
+	}
+
+	if location == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Location not found")
+		return false
+	}
+
+	if location.Quarantine {
+		auth.RespondWithError(w, http.StatusBadRequest, models.ErrQuarantineLocation.Error())
+		return false
+	}
+
+	return true
+}
+
+// respondWithSalesError responds with the error of a failed sales request.
+// Sales and inventory rule violations are reported to the client.
+func respondWithSalesError(w http.ResponseWriter, err error, message string) {
+	if errors.Is(err, models.ErrSalesOrderStatus) || errors.Is(err, models.ErrShipmentStatus) ||
+		errors.Is(err, models.ErrReturnStatus) {
+		auth.RespondWithError(w, http.StatusConflict, err.Error())
+		return
+	}
+
+	var salesErr *models.SalesError
+	if errors.As(err, &salesErr) {
+		auth.RespondWithError(w, http.StatusBadRequest, salesErr.Error())
+		return
+	}
+
+	var inventoryErr *models.InventoryError
+	if errors.As(err, &inventoryErr) {
+		auth.RespondWithError(w, http.StatusBadRequest, inventoryErr.Error())
+		return
+	}
+
+	auth.RespondWithError(w, http.StatusInternalServerError, message)
+}
\ No newline at end of file
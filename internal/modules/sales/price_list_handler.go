@@ -0,0 +1,332 @@
+package sales
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/yookibooki/erp/internal/auth"
+	"github.com/yookibooki/erp/internal/models"
+)
+
+// PriceListHandler handles price list and price resolution requests
+type PriceListHandler struct {
+	priceListService models.PriceListService
+	customerService  models.CustomerService
+	groupService     models.CustomerGroupService
+	productService   models.ProductService
+}
+
+// NewPriceListHandler creates a new price list handler
+func NewPriceListHandler(
+	priceListService models.PriceListService,
+	customerService models.CustomerService,
+	groupService models.CustomerGroupService,
+	productService models.ProductService,
+) *PriceListHandler {
+	return &PriceListHandler{
+		priceListService: priceListService,
+		customerService:  customerService,
+		groupService:     groupService,
+		productService:   productService,
+	}
+}
+
+// GetPriceList gets a price list by ID
+func (h *PriceListHandler) GetPriceList(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	priceList, err := h.priceListService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error getting price list")
+		return
+	}
+
+	if priceList == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Price list not found")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, priceList)
+}
+
+// ListPriceLists lists all price lists for a tenant
+func (h *PriceListHandler) ListPriceLists(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	priceLists, err := h.priceListService.List(tenantID)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error listing price lists")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, priceLists)
+}
+
+// CreatePriceList creates a new price list. Price lists are active unless stated otherwise.
+func (h *PriceListHandler) CreatePriceList(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	priceList := models.PriceList{Active: true}
+	if err := json.NewDecoder(r.Body).Decode(&priceList); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	// Set tenant ID from context
+	priceList.TenantID = tenantID
+
+	if !h.validatePriceList(w, &priceList) {
+		return
+	}
+
+	// Check if price list already exists
+	existingPriceList, err := h.priceListService.GetByCode(tenantID, priceList.Code)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking price list")
+		return
+	}
+
+	if existingPriceList != nil {
+		auth.RespondWithError(w, http.StatusConflict, "Price list with this code already exists")
+		return
+	}
+
+	// Create price list
+	if err := h.priceListService.Create(&priceList); err != nil {
+		respondWithSalesError(w, err, "Error creating price list")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusCreated, priceList)
+}
+
+// UpdatePriceList updates a price list and replaces its items and assignments
+func (h *PriceListHandler) UpdatePriceList(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	priceList := models.PriceList{Active: true}
+	if err := json.NewDecoder(r.Body).Decode(&priceList); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	// Set ID and tenant ID
+	priceList.ID = id
+	priceList.TenantID = tenantID
+
+	if !h.validatePriceList(w, &priceList) {
+		return
+	}
+
+	// Check if price list exists
+	existingPriceList, err := h.priceListService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking price list")
+		return
+	}
+
+	if existingPriceList == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Price list not found")
+		return
+	}
+
+	// Check if another price list has the code
+	conflictingPriceList, err := h.priceListService.GetByCode(tenantID, priceList.Code)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking price list")
+		return
+	}
+
+	if conflictingPriceList != nil && conflictingPriceList.ID != id {
+		auth.RespondWithError(w, http.StatusConflict, "Price list with this code already exists")
+		return
+	}
+
+	priceList.CreatedAt = existingPriceList.CreatedAt
+
+	// Update price list
+	if err := h.priceListService.Update(&priceList); err != nil {
+		respondWithSalesError(w, err, "Error updating price list")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, priceList)
+}
+
+// DeletePriceList deletes a price list
+func (h *PriceListHandler) DeletePriceList(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	// Check if price list exists
+	existingPriceList, err := h.priceListService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking price list")
+		return
+	}
+
+	if existingPriceList == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Price list not found")
+		return
+	}
+
+	// Delete price list
+	if err := h.priceListService.Delete(tenantID, id); err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error deleting price list")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Price list deleted successfully"})
+}
+
+// ResolvePrice returns the effective price of a product for the customer_id, quantity
+// (1 by default), date (today by default) and currency given as query parameters
+func (h *PriceListHandler) ResolvePrice(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+	params := r.URL.Query()
+
+	query := models.PriceQuery{
+		CustomerID: params.Get("customer_id"),
+		ProductID:  params.Get("product_id"),
+		Quantity:   1,
+		Date:       time.Now(),
+		Currency:   strings.ToUpper(params.Get("currency")),
+	}
+
+	if query.ProductID == "" {
+		auth.RespondWithError(w, http.StatusBadRequest, "Product ID is required")
+		return
+	}
+
+	if value := params.Get("quantity"); value != "" {
+		quantity, err := strconv.ParseFloat(value, 64)
+		if err != nil || quantity <= 0 {
+			auth.RespondWithError(w, http.StatusBadRequest, "Quantity must be a positive number")
+			return
+		}
+		query.Quantity = quantity
+	}
+
+	if value := params.Get("date"); value != "" {
+		date, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			auth.RespondWithError(w, http.StatusBadRequest, "Date must be formatted as YYYY-MM-DD")
+			return
+		}
+		query.Date = date
+	}
+
+	// Check if customer exists
+	if query.CustomerID != "" {
+		customer, err := h.customerService.GetByID(tenantID, query.CustomerID)
+		if err != nil {
+			auth.RespondWithError(w, http.StatusInternalServerError, "Error checking customer")
+			return
+		}
+
+		if customer == nil {
+			auth.RespondWithError(w, http.StatusNotFound, "Customer not found")
+			return
+		}
+	}
+
+	// Check if product exists
+	product, err := h.productService.GetByID(tenantID, query.ProductID)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking product")
+		return
+	}
+
+	if product == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Product not found")
+		return
+	}
+
+	resolution, err := h.priceListService.Resolve(tenantID, &query)
+	if err != nil {
+		respondWithSalesError(w, err, "Error resolving price")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, resolution)
+}
+
+// validatePriceList validates a price list and responds with an error if it is invalid
+func (h *PriceListHandler) validatePriceList(w http.ResponseWriter, priceList *models.PriceList) bool {
+	if priceList.Code == "" || priceList.Name == "" {
+		auth.RespondWithError(w, http.StatusBadRequest, "Code and name are required")
+		return false
+	}
+
+	priceList.Currency = strings.ToUpper(priceList.Currency)
+	if len(priceList.Currency) != 3 {
+		auth.RespondWithError(w, http.StatusBadRequest, "Currency must be a three-letter ISO 4217 code")
+		return false
+	}
+
+	if priceList.CustomerIDs == nil {
+		priceList.CustomerIDs = []string{}
+	}
+
+	if priceList.CustomerGroupIDs == nil {
+		priceList.CustomerGroupIDs = []string{}
+	}
+
+	// Check if products exist
+	for _, item := range priceList.Items {
+		if item.ProductID == "" {
+			auth.RespondWithError(w, http.StatusBadRequest, "Product ID is required")
+			return false
+		}
+
+		product, err := h.productService.GetByID(priceList.TenantID, item.ProductID)
+		if err != nil {
+			auth.RespondWithError(w, http.StatusInternalServerError, "Error checking product")
+			return false
+		}
+
+		if product == nil {
+			auth.RespondWithError(w, http.StatusNotFound, "Product not found")
+			return false
+		}
+	}
+
+	// Check if customers exist
+	for _, customerID := range priceList.CustomerIDs {
+		customer, err := h.customerService.GetByID(priceList.TenantID, customerID)
+		if err != nil {
+			auth.RespondWithError(w, http.StatusInternalServerError, "Error checking customer")
+			return false
+		}
+
+		if customer == nil {
+			auth.RespondWithError(w, http.StatusNotFound, "Customer not found")
+			return false
+		}
+	}
+
+	// Check if customer groups exist
+	for _, groupID := range priceList.CustomerGroupIDs {
+		group, err := h.groupService.GetByID(priceList.TenantID, groupID)
+		if err != nil {
+			auth.RespondWithError(w, http.StatusInternalServerError, "Error checking customer group")
+			return false
+		}
+
+		if group == nil {
+			auth.RespondWithError(w, http.StatusNotFound, "Customer group not found")
+			return false
+		}
+	}
+
+	return true
+}
\ No newline at end of file
@@ -0,0 +1,262 @@
+package sales
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/yookibooki/erp/internal/auth"
+	"github.com/yookibooki/erp/internal/models"
+)
+
+// ReturnHandler handles customer return authorization requests
+type ReturnHandler struct {
+	returnService   models.ReturnAuthorizationService
+	customerService models.CustomerService
+	productService  models.ProductService
+	locationService models.LocationService
+	accountService  models.AccountService
+}
+
+// NewReturnHandler creates a new return handler
+func NewReturnHandler(
+	returnService models.ReturnAuthorizationService,
+	customerService models.CustomerService,
+	productService models.ProductService,
+	locationService models.LocationService,
+	accountService models.AccountService,
+) *ReturnHandler {
+	return &ReturnHandler{
+		returnService:   returnService,
+		customerService: customerService,
+		productService:  productService,
+		locationService: locationService,
+		accountService:  accountService,
+	}
+}
+
+// GetReturn gets a return authorization by ID
+func (h *ReturnHandler) GetReturn(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	rma, err := h.returnService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error getting return authorization")
+		return
+	}
+
+	if rma == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Return authorization not found")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, rma)
+}
+
+// ListReturns lists all return authorizations for a tenant, optionally filtered by customer
+func (h *ReturnHandler) ListReturns(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	rmas, err := h.returnService.List(tenantID, r.URL.Query().Get("customer_id"))
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error listing return authorizations")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, rmas)
+}
+
+// CreateReturn authorizes a customer to return goods
+func (h *ReturnHandler) CreateReturn(w http.ResponseWriter, r *http.Request) {
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+	userID := auth.GetUserIDFromContext(r.Context())
+
+	var rma models.ReturnAuthorization
+	if err := json.NewDecoder(r.Body).Decode(&rma); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	// Set tenant ID and created by from context
+	rma.TenantID = tenantID
+	rma.CreatedBy = userID
+
+	// Validate return authorization
+	if rma.CustomerID == "" || rma.Number == "" {
+		auth.RespondWithError(w, http.StatusBadRequest, "Customer ID and number are required")
+		return
+	}
+
+	if len(rma.Lines) == 0 {
+		auth.RespondWithError(w, http.StatusBadRequest, "At least one return line is required")
+		return
+	}
+
+	// Check if customer exists
+	customer, err := h.customerService.GetByID(tenantID, rma.CustomerID)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking customer")
+		return
+	}
+
+	if customer == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Customer not found")
+		return
+	}
+
+	// Lines returned against a shipment take their product from it
+	for _, line := range rma.Lines {
+		if line.ShipmentLineID != "" {
+			continue
+		}
+		if line.ProductID == "" {
+			auth.RespondWithError(w, http.StatusBadRequest, "Product ID or shipment line ID is required")
+			return
+		}
+
+		product, err := h.productService.GetByID(tenantID, line.ProductID)
+		if err != nil {
+			auth.RespondWithError(w, http.StatusInternalServerError, "Error checking product")
+			return
+		}
+
+		if product == nil {
+			auth.RespondWithError(w, http.StatusNotFound, "Product not found")
+			return
+		}
+	}
+
+	// Check if return authorization already exists
+	existingReturn, err := h.returnService.GetByNumber(tenantID, rma.Number)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking return authorization")
+		return
+	}
+
+	if existingReturn != nil {
+		auth.RespondWithError(w, http.StatusConflict, "Return authorization with this number already exists")
+		return
+	}
+
+	// Create return authorization
+	if err := h.returnService.Create(&rma); err != nil {
+		respondWithSalesError(w, err, "Error creating return authorization")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusCreated, rma)
+}
+
+// ReceiveReturn books the returned goods into stock according to their inspection outcomes
+func (h *ReturnHandler) ReceiveReturn(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+	userID := auth.GetUserIDFromContext(r.Context())
+
+	var inspections []models.ReturnInspection
+	if err := json.NewDecoder(r.Body).Decode(&inspections); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	for _, inspection := range inspections {
+		if inspection.ReturnLineID == "" || inspection.LocationID == "" {
+			auth.RespondWithError(w, http.StatusBadRequest, "Return line ID and location ID are required")
+			return
+		}
+
+		location, err := h.locationService.GetByID(tenantID, inspection.LocationID)
+		if err != nil {
+			auth.RespondWithError(w, http.StatusInternalServerError, "Error checking location")
+			return
+		}
+
+		if location == nil {
+			auth.RespondWithError(w, http.StatusNotFound, "Location not found")
+			return
+		}
+	}
+
+	h.changeStatus(w, tenantID, id, func() error {
+		return h.returnService.Receive(tenantID, id, userID, inspections)
+	})
+}
+
+// CreditReturn raises a credit note for a received return
+func (h *ReturnHandler) CreditReturn(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+	userID := auth.GetUserIDFromContext(r.Context())
+
+	var creditNote models.CreditNote
+	if err := json.NewDecoder(r.Body).Decode(&creditNote); err != nil {
+		auth.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if creditNote.SalesReturnsAccountID == "" || creditNote.ReceivableAccountID == "" {
+		auth.RespondWithError(w, http.StatusBadRequest, "Sales returns account ID and receivable account ID are required")
+		return
+	}
+
+	// Check if accounts exist
+	for _, accountID := range []string{creditNote.SalesReturnsAccountID, creditNote.ReceivableAccountID} {
+		account, err := h.accountService.GetByID(tenantID, accountID)
+		if err != nil {
+			auth.RespondWithError(w, http.StatusInternalServerError, "Error checking account")
+			return
+		}
+
+		if account == nil {
+			auth.RespondWithError(w, http.StatusNotFound, "Account not found")
+			return
+		}
+	}
+
+	h.changeStatus(w, tenantID, id, func() error {
+		return h.returnService.Credit(tenantID, id, userID, &creditNote)
+	})
+}
+
+// CancelReturn cancels a return authorization whose goods have not been received
+func (h *ReturnHandler) CancelReturn(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	tenantID := auth.GetTenantIDFromContext(r.Context())
+
+	h.changeStatus(w, tenantID, id, func() error {
+		return h.returnService.Cancel(tenantID, id)
+	})
+}
+
+// changeStatus applies a status change to a return authorization and responds with the updated return
+func (h *ReturnHandler) changeStatus(w http.ResponseWriter, tenantID, id string, change func() error) {
+	// Check if return authorization exists
+	rma, err := h.returnService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error checking return authorization")
+		return
+	}
+
+	if rma == nil {
+		auth.RespondWithError(w, http.StatusNotFound, "Return authorization not found")
+		return
+	}
+
+	if err := change(); err != nil {
+		respondWithSalesError(w, err, "Error updating return authorization")
+		return
+	}
+
+	rma, err = h.returnService.GetByID(tenantID, id)
+	if err != nil {
+		auth.RespondWithError(w, http.StatusInternalServerError, "Error getting return authorization")
+		return
+	}
+
+	auth.RespondWithJSON(w, http.StatusOK, rma)
+}
\ No newline at end of file
@@ -0,0 +1,52 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/yookibooki/erp/internal/config"
+	"github.com/yookibooki/erp/internal/models"
+)
+
+// WebhookNotifier posts notifications as JSON to a webhook URL
+type WebhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookNotifier creates a new webhook notifier
+func NewWebhookNotifier(cfg config.NotificationConfig) *WebhookNotifier {
+	return &WebhookNotifier{
+		url:    cfg.LowStockWebhookURL,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// NotifyLowStock posts a low stock alert. Nothing is sent when no URL is configured.
+func (n *WebhookNotifier) NotifyLowStock(alert *models.LowStockAlert) error {
+	if n.url == "" {
+		return nil
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"event": "inventory.low_stock",
+		"data":  alert,
+	})
+	if err != nil {
+		return err
+	}
+
+	resp, err := n.client.Post(n.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("low stock webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
\ No newline at end of file
@@ -0,0 +1,29 @@
+-- Lot and serial number tracking
+
+ALTER TABLE products
+    ADD COLUMN tracking_mode VARCHAR(10) NOT NULL DEFAULT 'none'
+        CHECK (tracking_mode IN ('none', 'lot', 'serial'));
+
+ALTER TABLE inventory_transactions
+    ADD COLUMN lot_number VARCHAR(100) NOT NULL DEFAULT '',
+    ADD COLUMN serial_number VARCHAR(100) NOT NULL DEFAULT '',
+    ADD COLUMN expiry_date DATE;
+
+CREATE INDEX idx_inventory_transactions_lot_number ON inventory_transactions (tenant_id, lot_number) WHERE lot_number <> '';
+CREATE INDEX idx_inventory_transactions_serial_number ON inventory_transactions (tenant_id, serial_number) WHERE serial_number <> '';
+
+CREATE TABLE inventory_lots (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    lot_number VARCHAR(100) NOT NULL DEFAULT '',
+    serial_number VARCHAR(100) NOT NULL DEFAULT '',
+    expiry_date DATE,
+    quantity INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_inventory_lots_lot ON inventory_lots (tenant_id, product_id, lot_number) WHERE serial_number = '';
+CREATE UNIQUE INDEX idx_inventory_lots_serial ON inventory_lots (tenant_id, product_id, serial_number) WHERE serial_number <> '';
+CREATE INDEX idx_inventory_lots_expiry ON inventory_lots (tenant_id, product_id, expiry_date);
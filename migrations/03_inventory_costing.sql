@@ -0,0 +1,27 @@
+-- Inventory costing (FIFO and moving weighted average)
+
+ALTER TABLE tenants
+    ADD COLUMN costing_method VARCHAR(10) NOT NULL DEFAULT 'fifo'
+        CHECK (costing_method IN ('fifo', 'average'));
+
+ALTER TABLE products
+    ADD COLUMN average_cost NUMERIC(15, 4) NOT NULL DEFAULT 0;
+
+ALTER TABLE inventory_transactions
+    ADD COLUMN unit_cost NUMERIC(15, 4) NOT NULL DEFAULT 0,
+    ADD COLUMN total_cost NUMERIC(15, 2) NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_inventory_transactions_created_at ON inventory_transactions (tenant_id, created_at);
+
+CREATE TABLE cost_layers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    transaction_id UUID NOT NULL REFERENCES inventory_transactions(id) ON DELETE CASCADE,
+    quantity INTEGER NOT NULL,
+    remaining_quantity INTEGER NOT NULL,
+    unit_cost NUMERIC(15, 4) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_cost_layers_open ON cost_layers (tenant_id, product_id, created_at) WHERE remaining_quantity > 0;
@@ -0,0 +1,16 @@
+-- Automatic GL postings from inventory movements
+
+CREATE TABLE posting_rules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    transaction_type VARCHAR(20) NOT NULL,
+    inventory_account_id UUID NOT NULL REFERENCES accounts(id),
+    counter_account_id UUID NOT NULL REFERENCES accounts(id),
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (tenant_id, transaction_type)
+);
+
+ALTER TABLE inventory_transactions
+    ADD COLUMN journal_entry_id UUID REFERENCES journal_entries(id) ON DELETE SET NULL;
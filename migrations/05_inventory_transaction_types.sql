@@ -0,0 +1,37 @@
+-- Strict inventory transaction types, stock locations and negative stock guard
+
+ALTER TABLE tenants
+    ADD COLUMN allow_negative_stock BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE products
+    ADD COLUMN allow_negative_stock BOOLEAN;
+
+CREATE TABLE locations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    code VARCHAR(50) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (tenant_id, code)
+);
+
+CREATE TABLE stock_levels (
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    location_id UUID NOT NULL REFERENCES locations(id) ON DELETE CASCADE,
+    quantity INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (tenant_id, product_id, location_id)
+);
+
+ALTER TABLE inventory_transactions
+    ADD COLUMN reason_code VARCHAR(20) NOT NULL DEFAULT '',
+    ADD COLUMN location_id UUID REFERENCES locations(id),
+    ADD COLUMN destination_location_id UUID REFERENCES locations(id);
+
+-- Legacy rows with unknown types changed no stock and are left as they are
+ALTER TABLE inventory_transactions
+    ADD CONSTRAINT inventory_transactions_type_check
+        CHECK (transaction_type IN ('IN', 'OUT', 'ADJUSTMENT', 'TRANSFER', 'RETURN')) NOT VALID;
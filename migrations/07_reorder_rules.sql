@@ -0,0 +1,18 @@
+-- Reorder points and replenishment settings per product and warehouse
+
+CREATE TABLE reorder_rules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    location_id UUID REFERENCES locations(id) ON DELETE CASCADE,
+    policy VARCHAR(20) NOT NULL CHECK (policy IN ('min_max', 'reorder_point')),
+    reorder_point INTEGER NOT NULL DEFAULT 0 CHECK (reorder_point >= 0),
+    reorder_quantity INTEGER NOT NULL DEFAULT 0 CHECK (reorder_quantity >= 0),
+    max_quantity INTEGER NOT NULL DEFAULT 0 CHECK (max_quantity >= 0),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- One rule per product across all locations and one per product and location
+CREATE UNIQUE INDEX idx_reorder_rules_product
+    ON reorder_rules (tenant_id, product_id, COALESCE(location_id, '00000000-0000-0000-0000-000000000000'));
@@ -0,0 +1,68 @@
+-- Sales orders, shipments and backorders
+
+CREATE TABLE sales_orders (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    customer_id UUID NOT NULL REFERENCES customers(id),
+    number VARCHAR(50) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'draft'
+        CHECK (status IN ('draft', 'confirmed', 'picked', 'partially_shipped', 'shipped', 'invoiced', 'cancelled')),
+    order_date DATE NOT NULL DEFAULT CURRENT_DATE,
+    requested_date DATE,
+    notes TEXT NOT NULL DEFAULT '',
+    created_by UUID NOT NULL REFERENCES users(id),
+    confirmed_at TIMESTAMP WITH TIME ZONE,
+    invoiced_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (tenant_id, number)
+);
+
+CREATE TABLE sales_order_lines (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    sales_order_id UUID NOT NULL REFERENCES sales_orders(id) ON DELETE CASCADE,
+    line_number INTEGER NOT NULL,
+    product_id UUID NOT NULL REFERENCES products(id),
+    location_id UUID REFERENCES locations(id),
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    unit_price NUMERIC(15, 2) NOT NULL DEFAULT 0,
+    reservation_id UUID REFERENCES stock_reservations(id) ON DELETE SET NULL,
+    shipped_quantity INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (sales_order_id, line_number)
+);
+
+CREATE INDEX idx_sales_order_lines_product ON sales_order_lines (tenant_id, product_id);
+
+CREATE TABLE shipments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    sales_order_id UUID NOT NULL REFERENCES sales_orders(id),
+    number VARCHAR(60) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'draft' CHECK (status IN ('draft', 'shipped')),
+    carrier VARCHAR(100) NOT NULL DEFAULT '',
+    tracking_number VARCHAR(100) NOT NULL DEFAULT '',
+    notes TEXT NOT NULL DEFAULT '',
+    shipped_at TIMESTAMP WITH TIME ZONE,
+    created_by UUID NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (tenant_id, number)
+);
+
+CREATE TABLE shipment_lines (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    shipment_id UUID NOT NULL REFERENCES shipments(id) ON DELETE CASCADE,
+    sales_order_line_id UUID NOT NULL REFERENCES sales_order_lines(id),
+    product_id UUID NOT NULL REFERENCES products(id),
+    location_id UUID REFERENCES locations(id),
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    lot_number VARCHAR(100) NOT NULL DEFAULT '',
+    serial_number VARCHAR(100) NOT NULL DEFAULT '',
+    inventory_transaction_id UUID REFERENCES inventory_transactions(id),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,46 @@
+-- Customer return authorizations and quarantine locations
+
+ALTER TABLE locations ADD COLUMN quarantine BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE return_authorizations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    customer_id UUID NOT NULL REFERENCES customers(id),
+    shipment_id UUID REFERENCES shipments(id),
+    number VARCHAR(50) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'authorized'
+        CHECK (status IN ('authorized', 'received', 'credited', 'cancelled')),
+    reason TEXT NOT NULL DEFAULT '',
+    notes TEXT NOT NULL DEFAULT '',
+    credit_note_id UUID REFERENCES journal_entries(id),
+    created_by UUID NOT NULL REFERENCES users(id),
+    received_at TIMESTAMP WITH TIME ZONE,
+    credited_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (tenant_id, number)
+);
+
+CREATE INDEX idx_return_authorizations_customer ON return_authorizations (tenant_id, customer_id);
+
+CREATE TABLE return_lines (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    return_authorization_id UUID NOT NULL REFERENCES return_authorizations(id) ON DELETE CASCADE,
+    line_number INTEGER NOT NULL,
+    shipment_line_id UUID REFERENCES shipment_lines(id),
+    product_id UUID NOT NULL REFERENCES products(id),
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    unit_price NUMERIC(15, 2) NOT NULL DEFAULT 0,
+    lot_number VARCHAR(100) NOT NULL DEFAULT '',
+    serial_number VARCHAR(100) NOT NULL DEFAULT '',
+    outcome VARCHAR(20) NOT NULL DEFAULT '' CHECK (outcome IN ('', 'restock', 'scrap', 'repair')),
+    location_id UUID REFERENCES locations(id),
+    inventory_transaction_id UUID REFERENCES inventory_transactions(id),
+    scrap_transaction_id UUID REFERENCES inventory_transactions(id),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (return_authorization_id, line_number)
+);
+
+CREATE INDEX idx_return_lines_shipment_line ON return_lines (shipment_line_id);
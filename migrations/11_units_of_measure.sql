@@ -0,0 +1,65 @@
+-- Units of measure, per-product unit conversions and decimal quantities
+
+CREATE TABLE units_of_measure (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    code VARCHAR(20) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (tenant_id, code)
+);
+
+ALTER TABLE products
+    ADD COLUMN base_unit_id UUID REFERENCES units_of_measure(id),
+    ADD COLUMN purchase_unit_id UUID REFERENCES units_of_measure(id),
+    ADD COLUMN sales_unit_id UUID REFERENCES units_of_measure(id);
+
+-- factor is the number of base units in one unit
+CREATE TABLE product_units (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    unit_id UUID NOT NULL REFERENCES units_of_measure(id) ON DELETE CASCADE,
+    factor NUMERIC(18, 6) NOT NULL CHECK (factor > 0),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (product_id, unit_id)
+);
+
+CREATE INDEX idx_product_units_tenant_product ON product_units (tenant_id, product_id);
+
+ALTER TABLE inventory_transactions
+    ADD COLUMN unit_id UUID REFERENCES units_of_measure(id),
+    ADD COLUMN unit_quantity NUMERIC(15, 4) NOT NULL DEFAULT 0;
+
+UPDATE inventory_transactions SET unit_quantity = quantity;
+
+ALTER TABLE purchase_order_lines ADD COLUMN unit_id UUID REFERENCES units_of_measure(id);
+ALTER TABLE sales_order_lines ADD COLUMN unit_id UUID REFERENCES units_of_measure(id);
+ALTER TABLE return_lines ADD COLUMN unit_id UUID REFERENCES units_of_measure(id);
+
+-- Quantities become decimal so fractional units such as kilograms can be stocked
+ALTER TABLE products ALTER COLUMN stock_quantity TYPE NUMERIC(15, 4);
+ALTER TABLE inventory_transactions ALTER COLUMN quantity TYPE NUMERIC(15, 4);
+ALTER TABLE inventory_lots ALTER COLUMN quantity TYPE NUMERIC(15, 4);
+ALTER TABLE cost_layers
+    ALTER COLUMN quantity TYPE NUMERIC(15, 4),
+    ALTER COLUMN remaining_quantity TYPE NUMERIC(15, 4);
+ALTER TABLE stock_levels ALTER COLUMN quantity TYPE NUMERIC(15, 4);
+ALTER TABLE stock_reservations ALTER COLUMN quantity TYPE NUMERIC(15, 4);
+ALTER TABLE scheduled_receipts ALTER COLUMN quantity TYPE NUMERIC(15, 4);
+ALTER TABLE reorder_rules
+    ALTER COLUMN reorder_point TYPE NUMERIC(15, 4),
+    ALTER COLUMN reorder_quantity TYPE NUMERIC(15, 4),
+    ALTER COLUMN max_quantity TYPE NUMERIC(15, 4);
+ALTER TABLE purchase_order_lines
+    ALTER COLUMN quantity TYPE NUMERIC(15, 4),
+    ALTER COLUMN received_quantity TYPE NUMERIC(15, 4);
+ALTER TABLE goods_receipt_lines ALTER COLUMN quantity TYPE NUMERIC(15, 4);
+ALTER TABLE sales_order_lines
+    ALTER COLUMN quantity TYPE NUMERIC(15, 4),
+    ALTER COLUMN shipped_quantity TYPE NUMERIC(15, 4);
+ALTER TABLE shipment_lines ALTER COLUMN quantity TYPE NUMERIC(15, 4);
+ALTER TABLE return_lines ALTER COLUMN quantity TYPE NUMERIC(15, 4);
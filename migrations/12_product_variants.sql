@@ -0,0 +1,44 @@
+-- Product templates with attributes, generating variants as products
+
+CREATE TABLE product_templates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    code VARCHAR(50) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    unit_price NUMERIC(15, 2) NOT NULL DEFAULT 0,
+    base_unit_id UUID REFERENCES units_of_measure(id),
+    tracking_mode VARCHAR(10) NOT NULL DEFAULT 'none' CHECK (tracking_mode IN ('none', 'lot', 'serial')),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (tenant_id, code)
+);
+
+CREATE TABLE product_attributes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    template_id UUID NOT NULL REFERENCES product_templates(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    UNIQUE (template_id, name)
+);
+
+CREATE TABLE product_attribute_values (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    attribute_id UUID NOT NULL REFERENCES product_attributes(id) ON DELETE CASCADE,
+    value VARCHAR(100) NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    UNIQUE (attribute_id, value)
+);
+
+ALTER TABLE products ADD COLUMN template_id UUID REFERENCES product_templates(id);
+
+CREATE INDEX idx_products_template ON products (tenant_id, template_id);
+
+CREATE TABLE product_variant_values (
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    attribute_value_id UUID NOT NULL REFERENCES product_attribute_values(id),
+    PRIMARY KEY (product_id, attribute_value_id)
+);
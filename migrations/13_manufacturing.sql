@@ -0,0 +1,64 @@
+-- Bills of materials and manufacturing work orders
+
+CREATE TABLE bills_of_materials (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    quantity NUMERIC(15, 4) NOT NULL DEFAULT 1 CHECK (quantity > 0),
+    notes TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (tenant_id, product_id)
+);
+
+CREATE TABLE bom_lines (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    bom_id UUID NOT NULL REFERENCES bills_of_materials(id) ON DELETE CASCADE,
+    line_number INTEGER NOT NULL,
+    component_id UUID NOT NULL REFERENCES products(id),
+    quantity NUMERIC(15, 4) NOT NULL CHECK (quantity > 0),
+    scrap_percent NUMERIC(5, 2) NOT NULL DEFAULT 0 CHECK (scrap_percent >= 0),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (bom_id, line_number)
+);
+
+CREATE INDEX idx_bom_lines_component ON bom_lines (tenant_id, component_id);
+
+CREATE TABLE work_orders (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    number VARCHAR(50) NOT NULL,
+    product_id UUID NOT NULL REFERENCES products(id),
+    bom_id UUID REFERENCES bills_of_materials(id) ON DELETE SET NULL,
+    location_id UUID REFERENCES locations(id),
+    quantity NUMERIC(15, 4) NOT NULL CHECK (quantity > 0),
+    produced_quantity NUMERIC(15, 4) NOT NULL DEFAULT 0,
+    scrapped_quantity NUMERIC(15, 4) NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL DEFAULT 'planned'
+        CHECK (status IN ('planned', 'released', 'completed', 'cancelled')),
+    planned_date DATE,
+    notes TEXT NOT NULL DEFAULT '',
+    output_transaction_id UUID REFERENCES inventory_transactions(id),
+    created_by UUID NOT NULL REFERENCES users(id),
+    released_at TIMESTAMP WITH TIME ZONE,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (tenant_id, number)
+);
+
+CREATE INDEX idx_work_orders_status ON work_orders (tenant_id, status);
+
+CREATE TABLE work_order_components (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    work_order_id UUID NOT NULL REFERENCES work_orders(id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES products(id),
+    quantity NUMERIC(15, 4) NOT NULL,
+    reservation_id UUID REFERENCES stock_reservations(id),
+    consumed_quantity NUMERIC(15, 4) NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,43 @@
+-- Physical stocktakes and cycle counting
+
+CREATE TABLE stocktakes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    number VARCHAR(50) NOT NULL,
+    location_id UUID REFERENCES locations(id),
+    product_ids UUID[] NOT NULL DEFAULT '{}',
+    status VARCHAR(20) NOT NULL DEFAULT 'open'
+        CHECK (status IN ('open', 'approved', 'cancelled')),
+    notes TEXT NOT NULL DEFAULT '',
+    created_by UUID NOT NULL REFERENCES users(id),
+    approved_by UUID REFERENCES users(id),
+    approved_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (tenant_id, number)
+);
+
+CREATE INDEX idx_stocktakes_status ON stocktakes (tenant_id, status);
+
+CREATE TABLE stocktake_lines (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    stocktake_id UUID NOT NULL REFERENCES stocktakes(id) ON DELETE CASCADE,
+    line_number INTEGER NOT NULL,
+    product_id UUID NOT NULL REFERENCES products(id),
+    lot_number VARCHAR(100) NOT NULL DEFAULT '',
+    serial_number VARCHAR(100) NOT NULL DEFAULT '',
+    expected_quantity NUMERIC(15, 4) NOT NULL,
+    counted_quantity NUMERIC(15, 4) CHECK (counted_quantity >= 0),
+    unit_cost NUMERIC(15, 4) NOT NULL DEFAULT 0,
+    transaction_id UUID REFERENCES inventory_transactions(id),
+    counted_by UUID REFERENCES users(id),
+    counted_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (stocktake_id, line_number),
+    UNIQUE (stocktake_id, product_id, lot_number, serial_number)
+);
+
+-- Cycle counting looks up when each product was last counted
+CREATE INDEX idx_stocktake_lines_product ON stocktake_lines (tenant_id, product_id);
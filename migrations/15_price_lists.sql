@@ -0,0 +1,59 @@
+-- Customer groups, price lists, quantity breaks and discounts
+
+CREATE TABLE customer_groups (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (tenant_id, name)
+);
+
+ALTER TABLE customers
+    ADD COLUMN customer_group_id UUID REFERENCES customer_groups(id) ON DELETE SET NULL;
+
+CREATE TABLE price_lists (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    code VARCHAR(50) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    valid_from DATE,
+    valid_to DATE,
+    discount_percent NUMERIC(5, 2) NOT NULL DEFAULT 0
+        CHECK (discount_percent >= 0 AND discount_percent <= 100),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (tenant_id, code),
+    CHECK (valid_from IS NULL OR valid_to IS NULL OR valid_from <= valid_to)
+);
+
+CREATE TABLE price_list_items (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    price_list_id UUID NOT NULL REFERENCES price_lists(id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    min_quantity NUMERIC(15, 4) NOT NULL DEFAULT 0 CHECK (min_quantity >= 0),
+    unit_price NUMERIC(15, 2) NOT NULL DEFAULT 0 CHECK (unit_price >= 0),
+    discount_percent NUMERIC(5, 2) NOT NULL DEFAULT 0
+        CHECK (discount_percent >= 0 AND discount_percent <= 100),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (price_list_id, product_id, min_quantity)
+);
+
+CREATE INDEX idx_price_list_items_product ON price_list_items (tenant_id, product_id);
+
+CREATE TABLE price_list_customers (
+    price_list_id UUID NOT NULL REFERENCES price_lists(id) ON DELETE CASCADE,
+    customer_id UUID NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    PRIMARY KEY (price_list_id, customer_id)
+);
+
+CREATE TABLE price_list_customer_groups (
+    price_list_id UUID NOT NULL REFERENCES price_lists(id) ON DELETE CASCADE,
+    customer_group_id UUID NOT NULL REFERENCES customer_groups(id) ON DELETE CASCADE,
+    PRIMARY KEY (price_list_id, customer_group_id)
+);
@@ -0,0 +1,30 @@
+-- Product categories with defaults inherited by their subcategories and products
+
+CREATE TABLE product_categories (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    parent_id UUID REFERENCES product_categories(id),
+    code VARCHAR(50) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    inventory_account_id UUID REFERENCES accounts(id),
+    cogs_account_id UUID REFERENCES accounts(id),
+    tax_code VARCHAR(20),
+    costing_method VARCHAR(10) CHECK (costing_method IN ('fifo', 'average')),
+    unit_id UUID REFERENCES units_of_measure(id),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (tenant_id, code),
+    CHECK (parent_id <> id)
+);
+
+CREATE INDEX idx_product_categories_parent ON product_categories (tenant_id, parent_id);
+
+ALTER TABLE products
+    ADD COLUMN category_id UUID REFERENCES product_categories(id) ON DELETE SET NULL,
+    ADD COLUMN inventory_account_id UUID REFERENCES accounts(id),
+    ADD COLUMN cogs_account_id UUID REFERENCES accounts(id),
+    ADD COLUMN tax_code VARCHAR(20),
+    ADD COLUMN costing_method VARCHAR(10) CHECK (costing_method IN ('fifo', 'average'));
+
+CREATE INDEX idx_products_category ON products (tenant_id, category_id);
@@ -0,0 +1,20 @@
+-- Product barcodes for lookup and GS1 scanning
+
+-- unit_id is the unit of the pack a barcode identifies, or NULL for the base unit
+CREATE TABLE product_barcodes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    code VARCHAR(80) NOT NULL,
+    type VARCHAR(10) NOT NULL CHECK (type IN ('ean13', 'ean8', 'upca', 'gtin14', 'internal')),
+    unit_id UUID REFERENCES units_of_measure(id),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (tenant_id, code)
+);
+
+CREATE INDEX idx_product_barcodes_tenant_product ON product_barcodes (tenant_id, product_id);
+
+-- GTINs of any length are compared as GTIN-14 so a scanned GS1 GTIN finds its EAN or UPC
+CREATE INDEX idx_product_barcodes_tenant_gtin ON product_barcodes (tenant_id, LPAD(code, 14, '0'))
+    WHERE type <> 'internal';
@@ -0,0 +1,60 @@
+-- Landed costs allocated to receipts, and product weights and volumes to allocate them by
+
+ALTER TABLE products
+    ADD COLUMN weight NUMERIC(15, 4) NOT NULL DEFAULT 0 CHECK (weight >= 0),
+    ADD COLUMN volume NUMERIC(15, 4) NOT NULL DEFAULT 0 CHECK (volume >= 0);
+
+CREATE TABLE landed_costs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    number VARCHAR(50) NOT NULL,
+    reference VARCHAR(100) NOT NULL DEFAULT '',
+    counter_account_id UUID NOT NULL REFERENCES accounts(id),
+    status VARCHAR(20) NOT NULL DEFAULT 'draft'
+        CHECK (status IN ('draft', 'posted', 'cancelled')),
+    notes TEXT NOT NULL DEFAULT '',
+    journal_entry_id UUID REFERENCES journal_entries(id) ON DELETE SET NULL,
+    created_by UUID NOT NULL REFERENCES users(id),
+    posted_by UUID REFERENCES users(id),
+    posted_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (tenant_id, number)
+);
+
+CREATE INDEX idx_landed_costs_status ON landed_costs (tenant_id, status);
+
+CREATE TABLE landed_cost_charges (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    landed_cost_id UUID NOT NULL REFERENCES landed_costs(id) ON DELETE CASCADE,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    amount NUMERIC(15, 2) NOT NULL CHECK (amount > 0),
+    allocation_method VARCHAR(10) NOT NULL
+        CHECK (allocation_method IN ('quantity', 'value', 'weight', 'volume')),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_landed_cost_charges_landed_cost ON landed_cost_charges (landed_cost_id);
+
+-- quantity, value, weight and volume are the allocation bases frozen when the landed cost is created
+CREATE TABLE landed_cost_receipts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    landed_cost_id UUID NOT NULL REFERENCES landed_costs(id) ON DELETE CASCADE,
+    transaction_id UUID NOT NULL REFERENCES inventory_transactions(id),
+    product_id UUID NOT NULL REFERENCES products(id),
+    quantity NUMERIC(15, 4) NOT NULL,
+    value NUMERIC(15, 2) NOT NULL,
+    weight NUMERIC(15, 4) NOT NULL,
+    volume NUMERIC(15, 4) NOT NULL,
+    unit_cost NUMERIC(15, 4) NOT NULL,
+    allocated_cost NUMERIC(15, 2) NOT NULL DEFAULT 0,
+    capitalized_cost NUMERIC(15, 2) NOT NULL DEFAULT 0,
+    expensed_cost NUMERIC(15, 2) NOT NULL DEFAULT 0,
+    adjustment_transaction_id UUID REFERENCES inventory_transactions(id),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (landed_cost_id, transaction_id)
+);
+
+CREATE INDEX idx_landed_cost_receipts_transaction ON landed_cost_receipts (tenant_id, transaction_id);
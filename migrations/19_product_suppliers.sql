@@ -0,0 +1,22 @@
+-- Supplier catalogue of purchase terms per product
+
+-- purchase_price and minimum_order_quantity are per unit_id, or per base unit when it is NULL
+CREATE TABLE product_suppliers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    supplier_id UUID NOT NULL REFERENCES suppliers(id) ON DELETE CASCADE,
+    supplier_item_code VARCHAR(100) NOT NULL DEFAULT '',
+    purchase_price NUMERIC(15, 4) NOT NULL DEFAULT 0 CHECK (purchase_price >= 0),
+    currency VARCHAR(3) NOT NULL,
+    unit_id UUID REFERENCES units_of_measure(id),
+    lead_time_days INTEGER NOT NULL DEFAULT 0 CHECK (lead_time_days >= 0),
+    minimum_order_quantity NUMERIC(15, 4) NOT NULL DEFAULT 0 CHECK (minimum_order_quantity >= 0),
+    preferred BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (tenant_id, product_id, supplier_id)
+);
+
+CREATE INDEX idx_product_suppliers_supplier ON product_suppliers (tenant_id, supplier_id);
+CREATE UNIQUE INDEX idx_product_suppliers_preferred ON product_suppliers (tenant_id, product_id) WHERE preferred;
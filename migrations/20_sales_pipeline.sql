@@ -0,0 +1,76 @@
+-- Sales pipeline: leads, pipeline stages, opportunities and their stage history
+
+CREATE TABLE pipeline_stages (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    probability NUMERIC(5, 2) NOT NULL DEFAULT 0
+        CHECK (probability >= 0 AND probability <= 100),
+    type VARCHAR(10) NOT NULL DEFAULT 'open'
+        CHECK (type IN ('open', 'won', 'lost')),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (tenant_id, name)
+);
+
+CREATE TABLE opportunities (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    customer_id UUID NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    contact_id UUID REFERENCES contacts(id) ON DELETE SET NULL,
+    stage_id UUID NOT NULL REFERENCES pipeline_stages(id),
+    owner_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    amount NUMERIC(15, 2) NOT NULL DEFAULT 0 CHECK (amount >= 0),
+    probability NUMERIC(5, 2) NOT NULL DEFAULT 0
+        CHECK (probability >= 0 AND probability <= 100),
+    expected_close_date DATE,
+    closed_at TIMESTAMP WITH TIME ZONE,
+    notes TEXT NOT NULL DEFAULT '',
+    created_by UUID NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_opportunities_stage ON opportunities (tenant_id, stage_id);
+CREATE INDEX idx_opportunities_owner ON opportunities (tenant_id, owner_id);
+CREATE INDEX idx_opportunities_customer ON opportunities (tenant_id, customer_id);
+
+CREATE TABLE leads (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    company VARCHAR(255) NOT NULL DEFAULT '',
+    first_name VARCHAR(100) NOT NULL DEFAULT '',
+    last_name VARCHAR(100) NOT NULL DEFAULT '',
+    email VARCHAR(255) NOT NULL DEFAULT '',
+    phone VARCHAR(50) NOT NULL DEFAULT '',
+    source VARCHAR(100) NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'new'
+        CHECK (status IN ('new', 'contacted', 'qualified', 'disqualified', 'converted')),
+    owner_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    notes TEXT NOT NULL DEFAULT '',
+    customer_id UUID REFERENCES customers(id) ON DELETE SET NULL,
+    contact_id UUID REFERENCES contacts(id) ON DELETE SET NULL,
+    opportunity_id UUID REFERENCES opportunities(id) ON DELETE SET NULL,
+    converted_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_leads_status ON leads (tenant_id, status);
+
+ALTER TABLE opportunities
+    ADD COLUMN lead_id UUID REFERENCES leads(id) ON DELETE SET NULL;
+
+CREATE TABLE opportunity_stage_changes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    opportunity_id UUID NOT NULL REFERENCES opportunities(id) ON DELETE CASCADE,
+    from_stage_id UUID REFERENCES pipeline_stages(id),
+    to_stage_id UUID NOT NULL REFERENCES pipeline_stages(id),
+    changed_by UUID NOT NULL REFERENCES users(id),
+    changed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_opportunity_stage_changes_opportunity ON opportunity_stage_changes (opportunity_id, changed_at);
\ No newline at end of file
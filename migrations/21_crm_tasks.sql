@@ -0,0 +1,28 @@
+-- CRM tasks and follow-ups assigned to users
+
+CREATE TABLE tasks (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    title VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    customer_id UUID NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    contact_id UUID REFERENCES contacts(id) ON DELETE SET NULL,
+    opportunity_id UUID REFERENCES opportunities(id) ON DELETE SET NULL,
+    assignee_id UUID NOT NULL REFERENCES users(id),
+    due_date TIMESTAMP WITH TIME ZONE,
+    priority VARCHAR(10) NOT NULL DEFAULT 'normal'
+        CHECK (priority IN ('low', 'normal', 'high')),
+    status VARCHAR(20) NOT NULL DEFAULT 'open'
+        CHECK (status IN ('open', 'in_progress', 'completed', 'cancelled')),
+    completed_by UUID REFERENCES users(id),
+    completed_at TIMESTAMP WITH TIME ZONE,
+    interaction_id UUID REFERENCES interactions(id) ON DELETE SET NULL,
+    created_by UUID NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_tasks_assignee ON tasks (tenant_id, assignee_id, status);
+CREATE INDEX idx_tasks_customer ON tasks (tenant_id, customer_id);
+CREATE INDEX idx_tasks_opportunity ON tasks (tenant_id, opportunity_id);
+CREATE INDEX idx_tasks_due_date ON tasks (tenant_id, due_date) WHERE status IN ('open', 'in_progress');
@@ -0,0 +1,11 @@
+-- Trigram and normalized email and phone indexes to find duplicate customers and contacts
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX idx_customers_name_trgm ON customers USING GIN (LOWER(name) gin_trgm_ops);
+CREATE INDEX idx_customers_email_normalized ON customers (tenant_id, LOWER(TRIM(email)));
+CREATE INDEX idx_customers_phone_normalized ON customers (tenant_id, REGEXP_REPLACE(phone, '[^0-9]', '', 'g'));
+
+CREATE INDEX idx_contacts_name_trgm ON contacts USING GIN (LOWER(first_name || ' ' || last_name) gin_trgm_ops);
+CREATE INDEX idx_contacts_email_normalized ON contacts (tenant_id, LOWER(TRIM(email)));
+CREATE INDEX idx_contacts_phone_normalized ON contacts (tenant_id, REGEXP_REPLACE(phone, '[^0-9]', '', 'g'));
@@ -0,0 +1,14 @@
+-- Full-text and trigram indexes to search customers, contacts and interactions
+
+CREATE INDEX idx_customers_search_fts ON customers
+    USING GIN (to_tsvector('simple', name || ' ' || email || ' ' || phone));
+CREATE INDEX idx_customers_search_trgm ON customers
+    USING GIN ((name || ' ' || email || ' ' || phone) gin_trgm_ops);
+
+CREATE INDEX idx_contacts_search_fts ON contacts
+    USING GIN (to_tsvector('simple', first_name || ' ' || last_name || ' ' || email || ' ' || phone));
+CREATE INDEX idx_contacts_search_trgm ON contacts
+    USING GIN ((first_name || ' ' || last_name || ' ' || email || ' ' || phone) gin_trgm_ops);
+
+CREATE INDEX idx_interactions_search_fts ON interactions USING GIN (to_tsvector('simple', description));
+CREATE INDEX idx_interactions_search_trgm ON interactions USING GIN (description gin_trgm_ops);
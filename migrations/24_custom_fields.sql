@@ -0,0 +1,29 @@
+-- Tenant-defined custom fields on customers, contacts, products and interactions
+
+CREATE TABLE custom_field_definitions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    entity_type VARCHAR(20) NOT NULL
+        CHECK (entity_type IN ('customer', 'contact', 'product', 'interaction')),
+    key VARCHAR(63) NOT NULL,
+    label VARCHAR(255) NOT NULL,
+    field_type VARCHAR(20) NOT NULL
+        CHECK (field_type IN ('text', 'number', 'boolean', 'date', 'select')),
+    required BOOLEAN NOT NULL DEFAULT FALSE,
+    options TEXT[] NOT NULL DEFAULT '{}',
+    pattern TEXT NOT NULL DEFAULT '',
+    min_value NUMERIC(20, 6),
+    max_value NUMERIC(20, 6),
+    max_length INTEGER NOT NULL DEFAULT 0 CHECK (max_length >= 0),
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (tenant_id, entity_type, key)
+);
+
+CREATE INDEX idx_custom_field_definitions_entity ON custom_field_definitions (tenant_id, entity_type, position);
+
+ALTER TABLE customers ADD COLUMN custom_fields JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE contacts ADD COLUMN custom_fields JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE products ADD COLUMN custom_fields JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE interactions ADD COLUMN custom_fields JSONB NOT NULL DEFAULT '{}';
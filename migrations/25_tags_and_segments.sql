@@ -0,0 +1,20 @@
+-- Tags on customers and contacts, and customer segments defined by rules
+
+ALTER TABLE customers ADD COLUMN tags TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE contacts ADD COLUMN tags TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX idx_customers_tags ON customers USING GIN (tags);
+CREATE INDEX idx_contacts_tags ON contacts USING GIN (tags);
+
+CREATE TABLE segments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    match VARCHAR(10) NOT NULL DEFAULT 'all' CHECK (match IN ('all', 'any')),
+    rules JSONB NOT NULL DEFAULT '[]',
+    created_by UUID NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (tenant_id, name)
+);
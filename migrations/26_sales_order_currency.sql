@@ -0,0 +1,3 @@
+-- Currency of sales orders, used to pick price lists when pricing their lines
+
+ALTER TABLE sales_orders ADD COLUMN currency VARCHAR(3) NOT NULL DEFAULT '';
@@ -0,0 +1,13 @@
+-- Posting rules scoped to a product category and to the reason code of adjustments
+
+ALTER TABLE posting_rules
+    DROP CONSTRAINT posting_rules_tenant_id_transaction_type_key,
+    ADD COLUMN category_id UUID REFERENCES product_categories(id) ON DELETE CASCADE,
+    ADD COLUMN reason_code VARCHAR(20) NOT NULL DEFAULT '';
+
+CREATE UNIQUE INDEX idx_posting_rules_scope ON posting_rules (
+    tenant_id,
+    transaction_type,
+    COALESCE(category_id, '00000000-0000-0000-0000-000000000000'),
+    reason_code
+);
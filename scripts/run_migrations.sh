@@ -1,4 +1,7 @@
 #!/bin/bash
 
 # Run migrations script for ERP SaaS
-PGPASSWORD=erp_password psql -h localhost -U erp_user -d erp_saas -f ../migrations/01_initial_schema.sql
\ No newline at end of file
+for migration in ../migrations/*.sql; do
+  echo "Applying $migration"
+  PGPASSWORD=erp_password psql -h localhost -U erp_user -d erp_saas -v ON_ERROR_STOP=1 -f "$migration" || exit 1
+done